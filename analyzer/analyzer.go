@@ -0,0 +1,375 @@
+// Package analyzer annotates captured USB traffic with the requests and data it carries,
+// eg. "GET_DESCRIPTOR(CONFIG, idx 0, len 9)" or "HID SET_IDLE(report 0, 0ms)".
+//
+// Submissions and completions are paired into transfers, descriptors returned by GET_DESCRIPTOR
+// are parsed and remembered, so class requests and endpoint data can be attributed to the
+// interface they belong to. Class decoders are registered by class packages with Register.
+package analyzer
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/pcap"
+	"github.com/daedaluz/gousb/usbmon"
+	"io"
+	"sync"
+	"syscall"
+)
+
+// Decoder describes the class-specific traffic of interfaces of one class.
+// The methods return "" for transfers the decoder does not know.
+type Decoder interface {
+	// DecodeControl describes a control transfer addressed to an interface or endpoint of the class,
+	// or to the device for device classes. Standard requests to interfaces, such as GET_DESCRIPTOR
+	// of class descriptors, are passed to the decoder before the standard decoder.
+	DecodeControl(ctx *Context, t *Transfer) string
+	// DecodeData describes a bulk, interrupt or isochronous transfer on an endpoint of the class.
+	DecodeData(ctx *Context, t *Transfer) string
+}
+
+var (
+	decoderLock sync.RWMutex
+	decoders    = map[usb.ClassCode]Decoder{}
+)
+
+// Register sets the decoder of a class, replacing any previous decoder. Class packages call it from init.
+func Register(class usb.ClassCode, decoder Decoder) {
+	decoderLock.Lock()
+	defer decoderLock.Unlock()
+	decoders[class] = decoder
+}
+
+func lookupDecoder(class usb.ClassCode) Decoder {
+	decoderLock.RLock()
+	defer decoderLock.RUnlock()
+	return decoders[class]
+}
+
+// Context is what the analyzer knows about the target of a transfer.
+type Context struct {
+	Device *DeviceState
+	// Interface is the active alternate setting of the interface the transfer is addressed to, nil if unknown.
+	Interface *usb.AltSetting
+	// Endpoint is the endpoint of a non-control transfer or of an endpoint recipient request, nil if unknown.
+	Endpoint *usb.Endpoint
+}
+
+// DeviceState is what the analyzer learned about a device from its traffic.
+type DeviceState struct {
+	BusNumber    uint16
+	DeviceNumber uint8
+
+	Device         *usb.DeviceDescriptor
+	Configurations map[uint8]*usb.Configuration
+	BOS            *usb.BOS
+	Strings        map[uint8]string
+
+	// Configuration is the value of the last SET_CONFIGURATION, 0 if none was seen.
+	Configuration uint8
+	AltSettings   map[uint8]uint8
+}
+
+// ActiveConfiguration returns the configuration set with SET_CONFIGURATION, or the only known
+// configuration if the request was not captured.
+func (s *DeviceState) ActiveConfiguration() *usb.Configuration {
+	if cfg, exist := s.Configurations[s.Configuration]; exist {
+		return cfg
+	}
+	if s.Configuration == 0 && len(s.Configurations) == 1 {
+		for _, cfg := range s.Configurations {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// Interface returns the active alternate setting of an interface.
+func (s *DeviceState) Interface(number uint8) *usb.AltSetting {
+	cfg := s.ActiveConfiguration()
+	if cfg == nil {
+		return nil
+	}
+	iface := cfg.Interface(number)
+	if iface == nil {
+		return nil
+	}
+	if alt := iface.AltSetting(s.AltSettings[number]); alt != nil {
+		return alt
+	}
+	return iface.AltSettings[0]
+}
+
+// Endpoint returns an endpoint of an active alternate setting with the alternate setting it belongs to.
+func (s *DeviceState) Endpoint(address uint8) (*usb.AltSetting, *usb.Endpoint) {
+	cfg := s.ActiveConfiguration()
+	if cfg == nil {
+		return nil, nil
+	}
+	for _, iface := range cfg.Interfaces {
+		alt := s.Interface(iface.Number)
+		for _, ep := range alt.Endpoints {
+			if ep.BEndpointAddress == address {
+				return alt, ep
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Transfer is a submission paired with its completion.
+type Transfer struct {
+	Submit   *pcap.Packet
+	Complete *pcap.Packet
+
+	// Description is the annotation of the request, eg. "SET_CONFIGURATION(1)".
+	Description string
+	// Result describes the outcome, eg. "9 bytes" or "STALL", possibly followed by decoded data.
+	Result string
+	// Descriptors are parsed from the data of GET_DESCRIPTOR transfers.
+	Descriptors []usb.Descriptor
+}
+
+// Type returns the transfer type.
+func (t *Transfer) Type() usb.TransferType {
+	return t.Submit.TransferType
+}
+
+// Endpoint returns the endpoint address including the direction.
+func (t *Transfer) Endpoint() uint8 {
+	return t.Submit.Endpoint
+}
+
+// Setup returns the setup packet of a control transfer, nil for other transfers.
+func (t *Transfer) Setup() *usb.SetupPacket {
+	if !t.Submit.HasSetup {
+		return nil
+	}
+	return &t.Submit.Setup
+}
+
+// Data returns the data sent to the device for OUT transfers and the data received for IN transfers.
+func (t *Transfer) Data() []byte {
+	if t.Submit.In() {
+		return t.Complete.Data
+	}
+	return t.Submit.Data
+}
+
+// Status returns 0 or the negative errno of the completion.
+func (t *Transfer) Status() int32 {
+	return t.Complete.Status
+}
+
+// Actual returns the number of bytes transferred.
+func (t *Transfer) Actual() int {
+	return int(t.Complete.Length)
+}
+
+func (t *Transfer) String() string {
+	return fmt.Sprintf("%d:%.3d %s -> %s", t.Submit.BusNumber, t.Submit.DeviceNumber, t.Description, t.Result)
+}
+
+type urbKey struct {
+	bus uint16
+	id  uint64
+}
+
+type deviceKey struct {
+	bus    uint16
+	devnum uint8
+}
+
+// Analyzer pairs usbmon events into annotated transfers. It is safe for concurrent use, so one
+// analyzer can be the capture hook of several devices, but a DeviceState returned by Device must
+// not be read while transfers are fed.
+type Analyzer struct {
+	lock    sync.Mutex
+	pending map[urbKey]*pcap.Packet
+	devices map[deviceKey]*DeviceState
+}
+
+// New returns an analyzer without any knowledge of the devices.
+func New() *Analyzer {
+	return &Analyzer{
+		pending: make(map[urbKey]*pcap.Packet),
+		devices: make(map[deviceKey]*DeviceState),
+	}
+}
+
+// Device returns the state of a device, creating it if the device has not been seen.
+func (a *Analyzer) Device(bus uint16, devnum uint8) *DeviceState {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.device(bus, devnum)
+}
+
+func (a *Analyzer) device(bus uint16, devnum uint8) *DeviceState {
+	key := deviceKey{bus, devnum}
+	state, exist := a.devices[key]
+	if !exist {
+		state = &DeviceState{
+			BusNumber:      bus,
+			DeviceNumber:   devnum,
+			Configurations: make(map[uint8]*usb.Configuration),
+			Strings:        make(map[uint8]string),
+			AltSettings:    make(map[uint8]uint8),
+		}
+		a.devices[key] = state
+	}
+	return state
+}
+
+// SetDescriptors primes the state of a device with known descriptors, eg. from a snapshot,
+// so traffic captured after enumeration can be attributed to its interfaces.
+func (a *Analyzer) SetDescriptors(bus uint16, devnum uint8, set *usb.DescriptorSet) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	state := a.device(bus, devnum)
+	state.Device = set.Device
+	state.BOS = set.BOS
+	for _, cfg := range set.Configurations {
+		state.Configurations[cfg.BConfigurationValue] = cfg
+	}
+}
+
+// Feed adds an event, returning the transfer it completes or nil.
+// Completions without a captured submission are analyzed on their own.
+func (a *Analyzer) Feed(p *pcap.Packet) *Transfer {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	key := urbKey{p.BusNumber, p.ID}
+	if p.Event == pcap.EventSubmit {
+		a.pending[key] = p
+		return nil
+	}
+	submit, exist := a.pending[key]
+	delete(a.pending, key)
+	if !exist {
+		submit = p
+	}
+	t := &Transfer{Submit: submit, Complete: p}
+	a.analyze(t)
+	return t
+}
+
+// Run feeds all events of r, calling fn for every completed transfer until io.EOF.
+func (a *Analyzer) Run(r usbmon.Reader, fn func(t *Transfer)) error {
+	for {
+		p, err := r.ReadPacket()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t := a.Feed(p); t != nil {
+			fn(t)
+		}
+	}
+}
+
+// Capture returns a usb.Capture hook annotating the live traffic of a device, the transfers are passed to fn.
+func (a *Analyzer) Capture(fn func(t *Transfer)) usb.Capture {
+	return captureFunc(func(dev *usb.Device, record *usb.CaptureRecord) {
+		submit, complete := pcap.Packets(dev, record)
+		a.Feed(submit)
+		fn(a.Feed(complete))
+	})
+}
+
+type captureFunc func(dev *usb.Device, record *usb.CaptureRecord)
+
+func (f captureFunc) CaptureTransfer(dev *usb.Device, record *usb.CaptureRecord) {
+	f(dev, record)
+}
+
+func (a *Analyzer) analyze(t *Transfer) {
+	state := a.device(t.Submit.BusNumber, t.Submit.DeviceNumber)
+	ctx := &Context{Device: state}
+	t.Result = result(t)
+	if t.Type() != usb.TransferTypeControl || t.Setup() == nil {
+		ctx.Interface, ctx.Endpoint = state.Endpoint(t.Endpoint())
+		if ctx.Interface != nil {
+			if decoder := lookupDecoder(ctx.Interface.BInterfaceClass); decoder != nil {
+				t.Description = decoder.DecodeData(ctx, t)
+			}
+		}
+		if t.Description == "" {
+			t.Description = fmt.Sprintf("%s %s 0x%.2x len %d", transferTypeName(t.Type()), direction(t.Submit.In()), t.Endpoint(), t.Submit.Length)
+		}
+		return
+	}
+
+	setup := t.Setup()
+	var decoder Decoder
+	switch setup.Recipient() {
+	case usb.RequestRecipientDevice:
+		if state.Device != nil {
+			decoder = lookupDecoder(state.Device.BDeviceClass)
+		}
+	case usb.RequestRecipientInterface:
+		ctx.Interface = state.Interface(uint8(setup.Index))
+	case usb.RequestRecipientEndpoint:
+		ctx.Interface, ctx.Endpoint = state.Endpoint(uint8(setup.Index))
+	}
+	if ctx.Interface != nil {
+		decoder = lookupDecoder(ctx.Interface.BInterfaceClass)
+	}
+	if decoder != nil && (setup.Type() == usb.RequestTypeClass || setup.Recipient() != usb.RequestRecipientDevice) {
+		t.Description = decoder.DecodeControl(ctx, t)
+	}
+	if t.Description == "" && setup.Type() == usb.RequestTypeStandard {
+		t.Description = a.standard(ctx, t)
+	}
+	if t.Description == "" {
+		t.Description = fmt.Sprintf("%s request 0x%.2x(value 0x%.4x, index 0x%.4x, len %d)",
+			requestTypeName(setup.Type()), setup.Request, setup.Value, setup.Index, setup.Length)
+	}
+}
+
+// result describes the completion status and length of a transfer.
+func result(t *Transfer) string {
+	switch status := t.Status(); {
+	case status == -int32(syscall.EPIPE):
+		return "STALL"
+	case status < 0:
+		return syscall.Errno(-status).Error()
+	}
+	if t.Actual() == 1 {
+		return "1 byte"
+	}
+	return fmt.Sprintf("%d bytes", t.Actual())
+}
+
+func direction(in bool) string {
+	if in {
+		return "IN"
+	}
+	return "OUT"
+}
+
+func transferTypeName(typ usb.TransferType) string {
+	switch typ {
+	case usb.TransferTypeControl:
+		return "CONTROL"
+	case usb.TransferTypeIsochronous:
+		return "ISO"
+	case usb.TransferTypeBulk:
+		return "BULK"
+	case usb.TransferTypeInterrupt:
+		return "INTERRUPT"
+	}
+	return "UNKNOWN"
+}
+
+func requestTypeName(typ usb.RequestType) string {
+	switch typ {
+	case usb.RequestTypeStandard:
+		return "STANDARD"
+	case usb.RequestTypeClass:
+		return "CLASS"
+	case usb.RequestTypeVendor:
+		return "VENDOR"
+	}
+	return "RESERVED"
+}
@@ -0,0 +1,135 @@
+package analyzer_test
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+	"github.com/daedaluz/gousb/cdc"
+	"github.com/daedaluz/gousb/sim"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func serialDevice(t *testing.T) *sim.Device {
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BDeviceClass: usb.ClassCodeCDCControl, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0002, IProduct: 1, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeCDCControl, BInterfaceSubClass: 2}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x83, BmAttributes: 3, WMaxPacketSize: 16, BInterval: 10}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 1, BInterfaceClass: usb.ClassCodeInterfaceCDCData}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x02, BmAttributes: 2, WMaxPacketSize: 64}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Strings = map[uint8]string{1: "Serial"}
+	res.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Type() == usb.RequestTypeClass && setup.Request == cdc.RequestSetLineCoding {
+			return len(data), nil
+		}
+		return 0, syscall.EPIPE
+	}
+	res.Bulk = func(ep uint8, data []byte) (int, error) {
+		return len(data), nil
+	}
+	return res
+}
+
+func TestAnalyzer(t *testing.T) {
+	var transfers []*analyzer.Transfer
+	dev := serialDevice(t).USB()
+	dev.SetCapture(analyzer.New().Capture(func(t *analyzer.Transfer) {
+		transfers = append(transfers, t)
+	}))
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if _, err := dev.GetDescriptorSet(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.GetProduct(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	coding := &cdc.LineCoding{Rate: 115200, DataBits: 8}
+	if err := coding.Set(dev, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Bulk(0x02, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|usb.RequestRecipientDevice, 1, 0, 0, make([]byte, 4)); err != syscall.EPIPE {
+		t.Fatalf("vendor request: expected EPIPE, got %v", err)
+	}
+
+	expected := []string{
+		"GET_DESCRIPTOR(DEVICE, idx 0, len 256) -> 18 bytes, 1209:0002 USB 2.00",
+		"GET_DESCRIPTOR(CONFIG, idx 0, len 9) -> 9 bytes, wTotalLength 48",
+		"GET_DESCRIPTOR(CONFIG, idx 0, len 48) -> 48 bytes, configuration 1 with 2 interfaces",
+	}
+	for i, exp := range expected {
+		if i >= len(transfers) {
+			t.Fatalf("missing transfer %q", exp)
+		}
+		if got := transfers[i].Description + " -> " + transfers[i].Result; got != exp {
+			t.Errorf("transfer %d = %q, expected %q", i, got, exp)
+		}
+	}
+	found := map[string]string{}
+	for _, transfer := range transfers {
+		found[transfer.Description] = transfer.Result
+	}
+	for description, result := range map[string]string{
+		"GET_DESCRIPTOR(STRING, idx 1, lang 0x0409, len 256)":    `14 bytes, "Serial"`,
+		"SET_CONFIGURATION(1)":                                   "0 bytes",
+		"CDC SET_LINE_CODING 115200 8N1":                         "7 bytes",
+		"BULK OUT 0x02 len 5":                                    "5 bytes",
+		"VENDOR request 0x01(value 0x0000, index 0x0000, len 4)": "STALL",
+	} {
+		if got, exist := found[description]; !exist || got != result {
+			t.Errorf("%s -> %q, expected %q", description, got, result)
+		}
+	}
+	if testing.Verbose() {
+		for _, transfer := range transfers {
+			t.Log(transfer)
+		}
+	}
+}
+
+func TestConcurrentCapture(t *testing.T) {
+	a := analyzer.New()
+	var lock sync.Mutex
+	count := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		simDev := serialDevice(t)
+		simDev.DeviceNumber = i + 1
+		dev := simDev.USB()
+		dev.SetCapture(a.Capture(func(t *analyzer.Transfer) {
+			lock.Lock()
+			count++
+			lock.Unlock()
+		}))
+		if err := dev.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer dev.Close()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				dev.GetDeviceDescriptor()
+			}
+		}()
+	}
+	wg.Wait()
+	if count != 80 {
+		t.Fatalf("expected 80 transfers, got %d", count)
+	}
+	if state := a.Device(1, 3); state.Device == nil || state.Device.IDProduct != 0x0002 {
+		t.Fatalf("unexpected device state %+v", state)
+	}
+}
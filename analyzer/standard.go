@@ -0,0 +1,199 @@
+package analyzer
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+)
+
+var requestNames = map[uint8]string{
+	usb.ReqGetStatus:        "GET_STATUS",
+	usb.ReqClearFeature:     "CLEAR_FEATURE",
+	usb.ReqSetFeature:       "SET_FEATURE",
+	usb.ReqSetAddress:       "SET_ADDRESS",
+	usb.ReqGetDescriptor:    "GET_DESCRIPTOR",
+	usb.ReqSetDescriptor:    "SET_DESCRIPTOR",
+	usb.ReqGetConfiguration: "GET_CONFIGURATION",
+	usb.ReqSetConfiguration: "SET_CONFIGURATION",
+	usb.ReqGetInterface:     "GET_INTERFACE",
+	usb.ReqSetInterface:     "SET_INTERFACE",
+	usb.ReqSynchFrame:       "SYNCH_FRAME",
+	usb.ReqSetSel:           "SET_SEL",
+	usb.ReqSetIsochDelay:    "SET_ISOCH_DELAY",
+}
+
+var descriptorNames = map[usb.DescriptorType]string{
+	usb.DescriptorTypeDevice:                  "DEVICE",
+	usb.DescriptorTypeConfig:                  "CONFIG",
+	usb.DescriptorTypeString:                  "STRING",
+	usb.DescriptorTypeInterface:               "INTERFACE",
+	usb.DescriptorTypeEndpoint:                "ENDPOINT",
+	usb.DescriptorTypeDeviceQualifier:         "DEVICE_QUALIFIER",
+	usb.DescriptorTypeOtherSpeedConfiguration: "OTHER_SPEED_CONFIG",
+	usb.DescriptorTypeInterfacePower:          "INTERFACE_POWER",
+	usb.DescriptorTypeOTG:                     "OTG",
+	usb.DescriptorTypeDebug:                   "DEBUG",
+	usb.DescriptorTypeInterfaceAssociation:    "INTERFACE_ASSOCIATION",
+	usb.DescriptorTypeBOS:                     "BOS",
+	usb.DescriptorTypeDeviceCapability:        "DEVICE_CAPABILITY",
+}
+
+// DescriptorName returns the name of a standard descriptor type, eg. "CONFIG".
+func DescriptorName(typ usb.DescriptorType) string {
+	if name, exist := descriptorNames[typ]; exist {
+		return name
+	}
+	return fmt.Sprintf("0x%.2x", uint8(typ))
+}
+
+// featureName names a feature selector, the selectors depend on the recipient.
+func featureName(recipient usb.RequestType, feature usb.Feature) string {
+	switch {
+	case recipient == usb.RequestRecipientEndpoint && feature == usb.FeatureEndpointHalt:
+		return "ENDPOINT_HALT"
+	case recipient == usb.RequestRecipientInterface && feature == usb.FeatureInterfaceFunctionSuspend:
+		return "FUNCTION_SUSPEND"
+	case recipient != usb.RequestRecipientDevice:
+	case feature == usb.FeatureDeviceRemoteWakeUp:
+		return "DEVICE_REMOTE_WAKEUP"
+	case feature == usb.FeatureDeviceTestMode:
+		return "TEST_MODE"
+	case feature == usb.FeatureDeviceU1Enable:
+		return "U1_ENABLE"
+	case feature == usb.FeatureDeviceU2Enable:
+		return "U2_ENABLE"
+	case feature == usb.FeatureDeviceLTMEnable:
+		return "LTM_ENABLE"
+	}
+	return fmt.Sprintf("0x%.4x", uint16(feature))
+}
+
+func recipientName(recipient usb.RequestType, index uint16) string {
+	switch recipient {
+	case usb.RequestRecipientDevice:
+		return "device"
+	case usb.RequestRecipientInterface:
+		return fmt.Sprintf("iface %d", uint8(index))
+	case usb.RequestRecipientEndpoint:
+		return fmt.Sprintf("ep 0x%.2x", uint8(index))
+	}
+	return fmt.Sprintf("recipient %d index 0x%.4x", uint8(recipient), index)
+}
+
+// standard describes a standard request and updates the device state with its effect.
+func (a *Analyzer) standard(ctx *Context, t *Transfer) string {
+	setup := t.Setup()
+	name, exist := requestNames[setup.Request]
+	if !exist {
+		return ""
+	}
+	ok := t.Status() == 0
+	state := ctx.Device
+	switch setup.Request {
+	case usb.ReqGetStatus:
+		if data := t.Data(); ok && len(data) >= 2 {
+			t.Result += fmt.Sprintf(", status 0x%.4x", binary.LittleEndian.Uint16(data))
+		}
+		return fmt.Sprintf("%s(%s)", name, recipientName(setup.Recipient(), setup.Index))
+	case usb.ReqClearFeature, usb.ReqSetFeature:
+		return fmt.Sprintf("%s(%s, %s)", name, featureName(setup.Recipient(), usb.Feature(setup.Value)),
+			recipientName(setup.Recipient(), setup.Index))
+	case usb.ReqSetAddress, usb.ReqSetConfiguration:
+		if ok && setup.Request == usb.ReqSetConfiguration {
+			state.Configuration = uint8(setup.Value)
+			state.AltSettings = make(map[uint8]uint8)
+		}
+		return fmt.Sprintf("%s(%d)", name, setup.Value)
+	case usb.ReqGetConfiguration:
+		if data := t.Data(); ok && len(data) >= 1 {
+			t.Result += fmt.Sprintf(", configuration %d", data[0])
+		}
+		return name
+	case usb.ReqGetInterface:
+		if data := t.Data(); ok && len(data) >= 1 {
+			t.Result += fmt.Sprintf(", alt %d", data[0])
+		}
+		return fmt.Sprintf("%s(iface %d)", name, setup.Index)
+	case usb.ReqSetInterface:
+		if ok {
+			state.AltSettings[uint8(setup.Index)] = uint8(setup.Value)
+		}
+		return fmt.Sprintf("%s(iface %d, alt %d)", name, setup.Index, setup.Value)
+	case usb.ReqGetDescriptor, usb.ReqSetDescriptor:
+		typ, idx := usb.DescriptorType(setup.Value>>8), uint8(setup.Value)
+		if setup.Request == usb.ReqGetDescriptor && ok && setup.Recipient() == usb.RequestRecipientDevice {
+			a.descriptor(state, t, typ, idx)
+		}
+		if typ == usb.DescriptorTypeString && idx != 0 {
+			return fmt.Sprintf("%s(STRING, idx %d, lang 0x%.4x, len %d)", name, idx, setup.Index, setup.Length)
+		}
+		return fmt.Sprintf("%s(%s, idx %d, len %d)", name, DescriptorName(typ), idx, setup.Length)
+	case usb.ReqSynchFrame:
+		if data := t.Data(); ok && len(data) >= 2 {
+			t.Result += fmt.Sprintf(", frame %d", binary.LittleEndian.Uint16(data))
+		}
+		return fmt.Sprintf("%s(ep 0x%.2x)", name, uint8(setup.Index))
+	case usb.ReqSetIsochDelay:
+		return fmt.Sprintf("%s(%dns)", name, setup.Value)
+	}
+	return name
+}
+
+// descriptor parses the response to GET_DESCRIPTOR with the descriptor parser and remembers the
+// descriptors needed to attribute later traffic. Partial reads, such as the 9 byte header of a
+// configuration, only parse what was returned.
+func (a *Analyzer) descriptor(state *DeviceState, t *Transfer, typ usb.DescriptorType, idx uint8) {
+	data := t.Data()
+	if len(data) < 2 {
+		return
+	}
+	switch typ {
+	case usb.DescriptorTypeConfig:
+		if len(data) >= 4 && int(binary.LittleEndian.Uint16(data[2:])) == len(data) {
+			cfg, err := usb.ParseConfiguration(data)
+			if err != nil {
+				t.Result += fmt.Sprintf(", invalid: %v", err)
+				return
+			}
+			state.Configurations[cfg.BConfigurationValue] = cfg
+			t.Descriptors = cfg.Descriptors()
+			t.Result += fmt.Sprintf(", configuration %d with %d interfaces", cfg.BConfigurationValue, len(cfg.Interfaces))
+			return
+		}
+	case usb.DescriptorTypeBOS:
+		if len(data) >= 4 && int(binary.LittleEndian.Uint16(data[2:])) == len(data) {
+			bos, err := usb.ParseBOS(data)
+			if err != nil {
+				t.Result += fmt.Sprintf(", invalid: %v", err)
+				return
+			}
+			state.BOS = bos
+			t.Descriptors = append([]usb.Descriptor{bos.BOSDescriptor}, bos.Capabilities...)
+			t.Result += fmt.Sprintf(", %d capabilities", len(bos.Capabilities))
+			return
+		}
+	}
+	desc, err := usb.ParseDescriptor(data)
+	if err != nil {
+		// a short read of a header is not an error worth reporting
+		if len(data) >= int(data[0]) {
+			t.Result += fmt.Sprintf(", invalid: %v", err)
+		}
+		return
+	}
+	t.Descriptors = []usb.Descriptor{desc}
+	switch x := desc.(type) {
+	case *usb.DeviceDescriptor:
+		state.Device = x
+		t.Result += fmt.Sprintf(", %.4x:%.4x USB %x.%.2x", x.IDVendor, x.IDProduct, x.BcdUSB>>8, x.BcdUSB&0xFF)
+	case *usb.StringDescriptor:
+		if idx == 0 {
+			t.Result += fmt.Sprintf(", languages %v", x.Languages())
+			return
+		}
+		state.Strings[idx] = x.String()
+		t.Result += fmt.Sprintf(", %q", x.String())
+	case *usb.ConfigurationDescriptor:
+		t.Result += fmt.Sprintf(", wTotalLength %d", x.WTotalLength)
+	}
+}
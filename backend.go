@@ -0,0 +1,164 @@
+package usb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrNotOpen is returned by transfers on a device that is not open.
+	ErrNotOpen = errors.New("device not open")
+	// ErrNotSupported is returned by operations the backend of the device does not implement.
+	ErrNotSupported = errors.New("operation not supported by the device backend")
+)
+
+// SetupPacket is the setup stage of a control transfer.
+type SetupPacket struct {
+	RequestType RequestType
+	Request     uint8
+	Value       uint16
+	Index       uint16
+	Length      uint16
+}
+
+// In reports whether the data stage is device to host.
+func (p SetupPacket) In() bool {
+	return p.RequestType&RequestDirectionIn != 0
+}
+
+// Type returns the request type bits, eg. RequestTypeClass.
+func (p SetupPacket) Type() RequestType {
+	return p.RequestType & RequestTypeReserved
+}
+
+// Recipient returns the recipient bits, eg. RequestRecipientInterface.
+func (p SetupPacket) Recipient() RequestType {
+	return p.RequestType & 0x1F
+}
+
+func (p SetupPacket) String() string {
+	return fmt.Sprintf("%.2x %.2x %.4x %.4x %.4x", uint8(p.RequestType), p.Request, p.Value, p.Index, p.Length)
+}
+
+// MarshalBinary encodes the packet as the 8 bytes sent on the bus.
+func (p SetupPacket) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	data[0] = uint8(p.RequestType)
+	data[1] = p.Request
+	binary.LittleEndian.PutUint16(data[2:], p.Value)
+	binary.LittleEndian.PutUint16(data[4:], p.Index)
+	binary.LittleEndian.PutUint16(data[6:], p.Length)
+	return data, nil
+}
+
+func (p *SetupPacket) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("setup packet too short: %d bytes", len(data))
+	}
+	p.RequestType = RequestType(data[0])
+	p.Request = data[1]
+	p.Value = binary.LittleEndian.Uint16(data[2:])
+	p.Index = binary.LittleEndian.Uint16(data[4:])
+	p.Length = binary.LittleEndian.Uint16(data[6:])
+	return nil
+}
+
+// Backend performs the transfers of an open Device.
+// Devices found by EnumerateDevices use usbfs, see NewVirtualDevice for other backends.
+//
+// Timeouts are in milliseconds. Transfers return the number of bytes transferred, a stalled
+// endpoint is reported as syscall.EPIPE like usbfs does.
+type Backend interface {
+	// Control performs a control transfer on the default pipe, data holds setup.Length bytes.
+	Control(setup SetupPacket, data []byte, timeout uint32) (int, error)
+	Bulk(ep uint8, data []byte, timeout uint32) (int, error)
+	Interrupt(ep uint8, data []byte, timeout uint32) (int, error)
+
+	ClaimInterface(iface uint8) error
+	ReleaseInterface(iface uint8) error
+
+	// Reset performs a port reset of the device.
+	Reset() error
+	Close() error
+}
+
+// KernelDriverBackend is implemented by backends where interfaces can be bound to kernel drivers.
+type KernelDriverBackend interface {
+	GetDriver(iface uint32) (string, error)
+	DetachKernel(iface uint32) error
+	AttachKernel(iface uint32) error
+}
+
+// HostStateBackend is implemented by backends whose host stack tracks the configuration and the
+// endpoint state of the device. SET_CONFIGURATION and clearing a halt are passed to the host stack
+// instead of being sent as raw control requests, so the host binds the interfaces of the new
+// configuration and resets the data toggle of the endpoint.
+type HostStateBackend interface {
+	// SetConfiguration selects a configuration, -1 unconfigures the device.
+	SetConfiguration(value int) error
+	ClearHalt(ep uint8) error
+}
+
+// NewVirtualDevice returns a closed device that is opened with open instead of usbfs.
+// Sysfs attributes are read with sysfs, which may be nil if the device has none.
+// Use AddVirtualDevice to make the device visible to EnumerateDevices.
+func NewVirtualDevice(name string, bus, devnum int, open func() (Backend, error), sysfs func(attr string) ([]byte, error)) *Device {
+	if sysfs == nil {
+		sysfs = func(attr string) ([]byte, error) {
+			return nil, fmt.Errorf("%s: no sysfs attribute %s", name, attr)
+		}
+	}
+	return &Device{
+		Name:         name,
+		BusNumber:    bus,
+		DeviceNumber: devnum,
+		open:         open,
+		sysfs:        sysfs,
+	}
+}
+
+// IsVirtual reports whether the device was created with NewVirtualDevice, eg. a loaded snapshot.
+func (d *Device) IsVirtual() bool {
+	return d.open != nil
+}
+
+var (
+	virtualLock sync.Mutex
+	virtual     []*Device
+)
+
+// AddVirtualDevice makes a virtual device, such as a loaded snapshot, visible to EnumerateDevices and FindDevices.
+func AddVirtualDevice(dev *Device) error {
+	if !dev.IsVirtual() {
+		return fmt.Errorf("%s is not a virtual device", dev.Name)
+	}
+	virtualLock.Lock()
+	defer virtualLock.Unlock()
+	for _, existing := range virtual {
+		if existing == dev {
+			return nil
+		}
+	}
+	virtual = append(virtual, dev)
+	return nil
+}
+
+// RemoveVirtualDevice removes a device added with AddVirtualDevice.
+func RemoveVirtualDevice(dev *Device) {
+	virtualLock.Lock()
+	defer virtualLock.Unlock()
+	for i, existing := range virtual {
+		if existing == dev {
+			virtual = append(virtual[:i], virtual[i+1:]...)
+			return
+		}
+	}
+}
+
+func virtualDevices() []*Device {
+	virtualLock.Lock()
+	defer virtualLock.Unlock()
+	return append([]*Device{}, virtual...)
+}
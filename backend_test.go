@@ -0,0 +1,245 @@
+package usb
+
+import (
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+// requestBackend records the control requests sent to it.
+type requestBackend struct {
+	requests []SetupPacket
+}
+
+func (b *requestBackend) Control(setup SetupPacket, data []byte, timeout uint32) (int, error) {
+	b.requests = append(b.requests, setup)
+	return 0, nil
+}
+
+func (b *requestBackend) Bulk(ep uint8, data []byte, timeout uint32) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func (b *requestBackend) Interrupt(ep uint8, data []byte, timeout uint32) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func (b *requestBackend) ClaimInterface(iface uint8) error   { return nil }
+func (b *requestBackend) ReleaseInterface(iface uint8) error { return nil }
+func (b *requestBackend) Reset() error                       { return nil }
+func (b *requestBackend) Close() error                       { return nil }
+
+// hostBackend is a backend with a host stack tracking the device state, like usbfs.
+type hostBackend struct {
+	requestBackend
+	configuration int
+	cleared       []uint8
+}
+
+func (b *hostBackend) SetConfiguration(value int) error {
+	b.configuration = value
+	return nil
+}
+
+func (b *hostBackend) ClearHalt(ep uint8) error {
+	b.cleared = append(b.cleared, ep)
+	return nil
+}
+
+type captureRecords []*CaptureRecord
+
+func (c *captureRecords) CaptureTransfer(dev *Device, record *CaptureRecord) {
+	*c = append(*c, record)
+}
+
+func openBackend(t *testing.T, backend Backend) *Device {
+	dev := NewVirtualDevice("test", 1, 1, func() (Backend, error) { return backend, nil }, nil)
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	return dev
+}
+
+func TestHostState(t *testing.T) {
+	backend := &hostBackend{}
+	dev := openBackend(t, backend)
+	defer dev.Close()
+	var records captureRecords
+	dev.SetCapture(&records)
+	if err := dev.SetConfiguration(-1); err != nil || backend.configuration != -1 {
+		t.Fatalf("configuration = %d, %v", backend.configuration, err)
+	}
+	if err := dev.ClearHalt(0x81); err != nil || len(backend.cleared) != 1 || backend.cleared[0] != 0x81 {
+		t.Fatalf("cleared = %v, %v", backend.cleared, err)
+	}
+	if len(backend.requests) != 0 {
+		t.Fatalf("unexpected control requests %v", backend.requests)
+	}
+	if len(records) != 2 || records[0].Setup.Request != ReqSetConfiguration || records[0].Setup.Value != 0 ||
+		records[1].Setup.Request != ReqClearFeature || records[1].Setup.Index != 0x81 {
+		t.Fatalf("unexpected capture records %+v", records)
+	}
+}
+
+func TestControlState(t *testing.T) {
+	backend := &requestBackend{}
+	dev := openBackend(t, backend)
+	defer dev.Close()
+	if err := dev.SetConfiguration(-1); err == nil {
+		t.Fatal("expected an error for configuration -1 without a host stack")
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.ClearHalt(0x81); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.requests) != 2 || backend.requests[0].Request != ReqSetConfiguration || backend.requests[0].Value != 1 ||
+		backend.requests[1].Request != ReqClearFeature || backend.requests[1].Value != uint16(FeatureEndpointHalt) ||
+		backend.requests[1].Index != 0x81 {
+		t.Fatalf("unexpected control requests %+v", backend.requests)
+	}
+}
+
+// kernelBackend is a backend with kernel drivers bound to some interfaces.
+type kernelBackend struct {
+	requestBackend
+	drivers  map[uint32]string
+	detached map[uint32]string
+	claimed  map[uint8]bool
+	// busy interfaces can not be claimed.
+	busy     uint8
+	attached []uint32
+}
+
+func (b *kernelBackend) GetDriver(iface uint32) (string, error) {
+	if driver, exist := b.drivers[iface]; exist {
+		return driver, nil
+	}
+	return "", syscall.ENODATA
+}
+
+func (b *kernelBackend) DetachKernel(iface uint32) error {
+	driver, exist := b.drivers[iface]
+	if !exist {
+		return syscall.ENODATA
+	}
+	b.detached[iface] = driver
+	delete(b.drivers, iface)
+	return nil
+}
+
+func (b *kernelBackend) AttachKernel(iface uint32) error {
+	driver, exist := b.detached[iface]
+	if !exist {
+		return syscall.ENODATA
+	}
+	b.drivers[iface] = driver
+	delete(b.detached, iface)
+	b.attached = append(b.attached, iface)
+	return nil
+}
+
+func (b *kernelBackend) ClaimInterface(iface uint8) error {
+	if iface == b.busy {
+		return syscall.EBUSY
+	}
+	b.claimed[iface] = true
+	return nil
+}
+
+func (b *kernelBackend) ReleaseInterface(iface uint8) error {
+	delete(b.claimed, iface)
+	return nil
+}
+
+func TestClaimFunction(t *testing.T) {
+	// A function of interfaces 0 to 2, only 0 and 2 are bound to a driver.
+	function := &Function{Interfaces: []*Interface{{Number: 0}, {Number: 1}, {Number: 2}}}
+	newBackend := func(busy uint8) *kernelBackend {
+		return &kernelBackend{
+			drivers:  map[uint32]string{0: "cdc_acm", 2: "cdc_acm"},
+			detached: make(map[uint32]string),
+			claimed:  make(map[uint8]bool),
+			busy:     busy,
+		}
+	}
+
+	backend := newBackend(0xFF)
+	dev := openBackend(t, backend)
+	defer dev.Close()
+	if err := dev.ClaimFunction(function, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.claimed) != 3 || len(backend.drivers) != 0 || len(backend.detached) != 2 {
+		t.Fatalf("unexpected state after claim: %+v", backend)
+	}
+	if err := dev.ReleaseFunction(function, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.claimed) != 0 || len(backend.drivers) != 2 || len(backend.attached) != 2 {
+		t.Fatalf("unexpected state after release: %+v", backend)
+	}
+	// The drivers were reattached, releasing again must not attach anything.
+	if err := dev.ReleaseFunction(function, true); err != nil || len(backend.attached) != 2 {
+		t.Fatalf("second release attached %v, %v", backend.attached, err)
+	}
+
+	// Claiming interface 2 fails after the driver of interface 0 was detached.
+	backend = newBackend(2)
+	failing := openBackend(t, backend)
+	defer failing.Close()
+	if err := failing.ClaimFunction(function, true); err == nil {
+		t.Fatal("expected an error for a busy interface")
+	}
+	if len(backend.claimed) != 0 || len(backend.detached) != 0 || backend.drivers[0] != "cdc_acm" || backend.drivers[2] != "cdc_acm" {
+		t.Fatalf("unexpected state after failed claim: %+v", backend)
+	}
+
+	// Drivers detached by someone else are not attached.
+	backend = newBackend(0xFF)
+	other := openBackend(t, backend)
+	defer other.Close()
+	if err := other.DetachKernel(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.ClaimFunction(function, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.ReleaseFunction(function, true); err != nil || len(backend.attached) != 0 {
+		t.Fatalf("release attached %v, %v", backend.attached, err)
+	}
+}
+
+type countCapture struct {
+	count int64
+}
+
+func (c *countCapture) CaptureTransfer(dev *Device, record *CaptureRecord) {
+	atomic.AddInt64(&c.count, 1)
+}
+
+func TestSetCaptureConcurrent(t *testing.T) {
+	dev := openBackend(t, &requestBackend{})
+	defer dev.Close()
+	capture := &countCapture{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			dev.Ctrl(RequestDirectionOut|RequestTypeVendor|RequestRecipientDevice, 1, 0, 0, nil)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		dev.SetCapture(capture)
+		dev.SetCapture(nil)
+	}
+	<-done
+	dev.SetCapture(capture)
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&capture.count) == 0 {
+		t.Fatal("transfer not captured")
+	}
+}
@@ -342,3 +342,42 @@ var capabilityMap = map[Capability]reflect.Type{
 	CapPrecisionTime:        reflect.TypeOf(CapPrecisionTimeDescriptor{}),
 	CapConfigurationSummary: reflect.TypeOf(CapConfigurationSummaryDescriptor{}),
 }
+
+// FormatUUID formats a 128-bit UUID stored in descriptor byte order, where the first three
+// groups are little endian, eg. {D8DD60DF-4589-4CC7-9CD2-659D9E648A9F}.
+func FormatUUID(uuid [16]byte) string {
+	return fmt.Sprintf("{%.2X%.2X%.2X%.2X-%.2X%.2X-%.2X%.2X-%.2X%.2X-%.2X%.2X%.2X%.2X%.2X%.2X}",
+		uuid[3], uuid[2], uuid[1], uuid[0], uuid[5], uuid[4], uuid[7], uuid[6],
+		uuid[8], uuid[9], uuid[10], uuid[11], uuid[12], uuid[13], uuid[14], uuid[15])
+}
+
+// UUIDString returns the formatted PlatformCapabilityUUID.
+func (c *CapPlatformDescriptor) UUIDString() string {
+	return FormatUUID(c.PlatformCapabilityUUID)
+}
+
+// UUIDString returns the formatted ContainerID.
+func (c *CapContainerIDDescriptor) UUIDString() string {
+	return FormatUUID(c.ContainerID)
+}
+
+// PlatformCapabilities returns all platform capability descriptors of the BOS.
+func (b *BOS) PlatformCapabilities() []*CapPlatformDescriptor {
+	res := make([]*CapPlatformDescriptor, 0, 2)
+	for _, capability := range b.Capabilities {
+		if platform, ok := capability.(*CapPlatformDescriptor); ok {
+			res = append(res, platform)
+		}
+	}
+	return res
+}
+
+// FindPlatformCapability returns the platform capability with the specified UUID, or nil if the BOS has none.
+func (b *BOS) FindPlatformCapability(uuid [16]byte) *CapPlatformDescriptor {
+	for _, platform := range b.PlatformCapabilities() {
+		if platform.PlatformCapabilityUUID == uuid {
+			return platform
+		}
+	}
+	return nil
+}
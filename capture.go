@@ -0,0 +1,80 @@
+package usb
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CaptureRecord is a transfer as seen by the library, passed to a Capture hook when it completes.
+type CaptureRecord struct {
+	// ID is unique for every transfer of the process.
+	ID uint64
+
+	Type TransferType
+	// Endpoint is the endpoint address including the direction bit, 0x00 or 0x80 for control transfers.
+	Endpoint uint8
+	// Setup is the setup packet of a control transfer.
+	Setup SetupPacket
+
+	// Length is the requested length and Actual the number of bytes transferred.
+	Length int
+	Actual int
+	// Data holds the data sent for OUT transfers and the data received for IN transfers.
+	Data []byte
+	Err  error
+
+	Submitted time.Time
+	Completed time.Time
+}
+
+// In reports whether the data stage is device to host.
+func (r *CaptureRecord) In() bool {
+	return r.Endpoint&0x80 != 0
+}
+
+// Capture is a hook seeing every transfer performed on a device.
+// CaptureTransfer is called after the transfer completed, the record must not be retained.
+type Capture interface {
+	CaptureTransfer(dev *Device, record *CaptureRecord)
+}
+
+var captureID uint64
+
+// captureHook wraps the hook stored in Device.capture, atomic.Value can not store nil.
+type captureHook struct {
+	Capture
+}
+
+// SetCapture installs a capture hook on the device, nil removes it.
+// It may be called while transfers are in progress, they are reported to the hook installed when they started.
+func (d *Device) SetCapture(capture Capture) {
+	d.capture.Store(captureHook{capture})
+}
+
+// captureTransfer performs a transfer through fn, reporting it to the capture hook if one is installed.
+func (d *Device) captureTransfer(typ TransferType, ep uint8, setup SetupPacket, data []byte, fn func() (int, error)) (int, error) {
+	hook, _ := d.capture.Load().(captureHook)
+	capture := hook.Capture
+	if capture == nil {
+		return fn()
+	}
+	record := &CaptureRecord{
+		ID:        atomic.AddUint64(&captureID, 1),
+		Type:      typ,
+		Endpoint:  ep,
+		Setup:     setup,
+		Length:    len(data),
+		Submitted: time.Now(),
+	}
+	if !record.In() {
+		record.Data = append([]byte{}, data...)
+	}
+	n, err := fn()
+	record.Completed = time.Now()
+	record.Actual, record.Err = n, err
+	if record.In() && n > 0 && n <= len(data) {
+		record.Data = data[:n]
+	}
+	capture.CaptureTransfer(d, record)
+	return n, err
+}
@@ -0,0 +1,85 @@
+package cdc
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+)
+
+func init() {
+	analyzer.Register(usb.ClassCodeCDCControl, decoder{})
+}
+
+type decoder struct{}
+
+var requestNames = map[uint8]string{
+	RequestSendEncapsulatedCommand: "SEND_ENCAPSULATED_COMMAND",
+	RequestGetEncapsulatedResponse: "GET_ENCAPSULATED_RESPONSE",
+	RequestSetLineCoding:           "SET_LINE_CODING",
+	RequestGetLineCoding:           "GET_LINE_CODING",
+	RequestSetControlLineState:     "SET_CONTROL_LINE_STATE",
+	RequestSendBreak:               "SEND_BREAK",
+}
+
+func (decoder) DecodeControl(ctx *analyzer.Context, t *analyzer.Transfer) string {
+	setup := t.Setup()
+	if setup.Type() != usb.RequestTypeClass {
+		return ""
+	}
+	name, exist := requestNames[setup.Request]
+	if !exist {
+		return ""
+	}
+	switch setup.Request {
+	case RequestSetLineCoding, RequestGetLineCoding:
+		coding := &LineCoding{}
+		if err := coding.UnmarshalBinary(t.Data()); err != nil {
+			return "CDC " + name
+		}
+		if setup.Request == RequestGetLineCoding {
+			t.Result += ", " + coding.String()
+			return "CDC " + name
+		}
+		return fmt.Sprintf("CDC %s %s", name, coding)
+	case RequestSetControlLineState:
+		return fmt.Sprintf("CDC %s(DTR %d, RTS %d)", name, setup.Value&ControlLineDTR, (setup.Value&ControlLineRTS)>>1)
+	case RequestSendBreak:
+		if setup.Value == 0xFFFF {
+			return fmt.Sprintf("CDC %s(on)", name)
+		}
+		return fmt.Sprintf("CDC %s(%dms)", name, setup.Value)
+	}
+	return fmt.Sprintf("CDC %s(len %d)", name, setup.Length)
+}
+
+// DecodeData describes the notifications of the interrupt endpoint, data on the bulk endpoints
+// of the data interface is left to the generic description.
+func (decoder) DecodeData(ctx *analyzer.Context, t *analyzer.Transfer) string {
+	data := t.Data()
+	if t.Type() != usb.TransferTypeInterrupt || len(data) < 8 {
+		return ""
+	}
+	payload := data[8:]
+	switch data[1] {
+	case NotificationNetworkConnection:
+		if data[2] == 0 {
+			return "CDC NETWORK_CONNECTION disconnected"
+		}
+		return "CDC NETWORK_CONNECTION connected"
+	case NotificationResponseAvailable:
+		return "CDC RESPONSE_AVAILABLE"
+	case NotificationSerialState:
+		if len(payload) < 2 {
+			return "CDC SERIAL_STATE"
+		}
+		return fmt.Sprintf("CDC SERIAL_STATE 0x%.4x", binary.LittleEndian.Uint16(payload))
+	case NotificationConnectionSpeedChange:
+		if len(payload) < 8 {
+			return "CDC CONNECTION_SPEED_CHANGE"
+		}
+		return fmt.Sprintf("CDC CONNECTION_SPEED_CHANGE(down %d, up %d)",
+			binary.LittleEndian.Uint32(payload), binary.LittleEndian.Uint32(payload[4:]))
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package cdc
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+// serial simulates an abstract control model adapter answering the line coding and line state
+// requests, with a SERIAL_STATE notification pending on its interrupt endpoint.
+func serial(t *testing.T) *sim.Device {
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BDeviceClass: usb.ClassCodeCDCControl, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0002, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeCDCControl, BInterfaceSubClass: 2}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x83, BmAttributes: 3, WMaxPacketSize: 16, BInterval: 10}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 1, BInterfaceClass: usb.ClassCodeInterfaceCDCData}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x02, BmAttributes: 2, WMaxPacketSize: 64}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coding := []byte{0x80, 0x25, 0, 0, 0, 0, 8}
+	res.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Type() != usb.RequestTypeClass || setup.Index != 0 {
+			return 0, syscall.EPIPE
+		}
+		switch setup.Request {
+		case RequestGetLineCoding:
+			return copy(data, coding), nil
+		case RequestSetLineCoding:
+			return copy(coding, data), nil
+		case RequestSetControlLineState, RequestSendBreak:
+			return 0, nil
+		}
+		return 0, syscall.EPIPE
+	}
+	res.Interrupt = func(ep uint8, data []byte) (int, error) {
+		return copy(data, []byte{0xA1, NotificationSerialState, 0, 0, 0, 0, 2, 0, 0x03, 0x00}), nil
+	}
+	return res
+}
+
+func TestAnalyzer(t *testing.T) {
+	var transfers []*analyzer.Transfer
+	dev := serial(t).USB()
+	dev.SetCapture(analyzer.New().Capture(func(t *analyzer.Transfer) {
+		transfers = append(transfers, t)
+	}))
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if _, err := dev.GetDescriptorSet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&LineCoding{Rate: 115200, DataBits: 7, Parity: ParityEven, StopBits: StopBits2}).Set(dev, 0); err != nil {
+		t.Fatal(err)
+	}
+	if coding, err := GetLineCoding(dev, 0); err != nil || coding.String() != "115200 7E2" {
+		t.Fatalf("line coding = %v, %v", coding, err)
+	}
+	if err := SetControlLineState(dev, 0, ControlLineDTR|ControlLineRTS); err != nil {
+		t.Fatal(err)
+	}
+	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
+	if _, err := dev.Ctrl(reqType, RequestSendBreak, 250, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Interrupt(0x83, make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]string{}
+	for _, transfer := range transfers {
+		found[transfer.Description] = transfer.Result
+	}
+	for description, result := range map[string]string{
+		"CDC SET_LINE_CODING 115200 7E2":           "7 bytes",
+		"CDC GET_LINE_CODING":                      "7 bytes, 115200 7E2",
+		"CDC SET_CONTROL_LINE_STATE(DTR 1, RTS 1)": "0 bytes",
+		"CDC SEND_BREAK(250ms)":                    "0 bytes",
+		"CDC SERIAL_STATE 0x0003":                  "10 bytes",
+	} {
+		if got, exist := found[description]; !exist || got != result {
+			t.Errorf("%s -> %q, expected %q", description, got, result)
+		}
+	}
+	if t.Failed() {
+		for _, transfer := range transfers {
+			t.Log(transfer)
+		}
+	}
+}
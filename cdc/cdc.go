@@ -0,0 +1,139 @@
+// Package cdc implements the requests and notifications of the Communications Device Class
+// abstract control model used by USB serial adapters, and registers a decoder for them with the analyzer.
+//
+// Documentation: https://www.usb.org/document-library/class-definitions-communication-devices-12
+package cdc
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+)
+
+// Class requests of the communications interface.
+const (
+	RequestSendEncapsulatedCommand = 0x00
+	RequestGetEncapsulatedResponse = 0x01
+	RequestSetLineCoding           = 0x20
+	RequestGetLineCoding           = 0x21
+	RequestSetControlLineState     = 0x22
+	RequestSendBreak               = 0x23
+)
+
+// Notifications sent on the interrupt endpoint of the communications interface.
+const (
+	NotificationNetworkConnection     = 0x00
+	NotificationResponseAvailable     = 0x01
+	NotificationSerialState           = 0x20
+	NotificationConnectionSpeedChange = 0x2A
+)
+
+// Control line state bits of SET_CONTROL_LINE_STATE.
+const (
+	ControlLineDTR = 0x01
+	ControlLineRTS = 0x02
+)
+
+// StopBits is the number of stop bits of a line coding.
+type StopBits uint8
+
+const (
+	StopBits1   = StopBits(0)
+	StopBits1_5 = StopBits(1)
+	StopBits2   = StopBits(2)
+)
+
+func (s StopBits) String() string {
+	switch s {
+	case StopBits1:
+		return "1"
+	case StopBits1_5:
+		return "1.5"
+	case StopBits2:
+		return "2"
+	}
+	return fmt.Sprintf("StopBits(%d)", uint8(s))
+}
+
+// Parity is the parity of a line coding.
+type Parity uint8
+
+const (
+	ParityNone  = Parity(0)
+	ParityOdd   = Parity(1)
+	ParityEven  = Parity(2)
+	ParityMark  = Parity(3)
+	ParitySpace = Parity(4)
+)
+
+func (p Parity) String() string {
+	if p <= ParitySpace {
+		return string("NOEMS"[p])
+	}
+	return fmt.Sprintf("Parity(%d)", uint8(p))
+}
+
+// LineCoding is the data of SET_LINE_CODING and GET_LINE_CODING.
+type LineCoding struct {
+	Rate     uint32
+	StopBits StopBits
+	Parity   Parity
+	DataBits uint8
+}
+
+// String formats the line coding the way terminal programs do, eg. "115200 8N1".
+func (l *LineCoding) String() string {
+	return fmt.Sprintf("%d %d%s%s", l.Rate, l.DataBits, l.Parity, l.StopBits)
+}
+
+// MarshalBinary encodes the 7 byte line coding structure.
+func (l *LineCoding) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 7)
+	binary.LittleEndian.PutUint32(data, l.Rate)
+	data[4] = uint8(l.StopBits)
+	data[5] = uint8(l.Parity)
+	data[6] = l.DataBits
+	return data, nil
+}
+
+// UnmarshalBinary decodes the 7 byte line coding structure.
+func (l *LineCoding) UnmarshalBinary(data []byte) error {
+	if len(data) < 7 {
+		return fmt.Errorf("line coding too short: %d bytes", len(data))
+	}
+	l.Rate = binary.LittleEndian.Uint32(data)
+	l.StopBits = StopBits(data[4])
+	l.Parity = Parity(data[5])
+	l.DataBits = data[6]
+	return nil
+}
+
+// GetLineCoding reads the line coding of a communications interface.
+func GetLineCoding(dev *usb.Device, iface uint8) (*LineCoding, error) {
+	data := make([]byte, 7)
+	reqType := usb.RequestDirectionIn | usb.RequestTypeClass | usb.RequestRecipientInterface
+	n, err := dev.Ctrl(reqType, RequestGetLineCoding, 0, uint16(iface), data)
+	if err != nil {
+		return nil, err
+	}
+	res := &LineCoding{}
+	if err := res.UnmarshalBinary(data[:n]); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Set sets the line coding of a communications interface.
+func (l *LineCoding) Set(dev *usb.Device, iface uint8) error {
+	data, _ := l.MarshalBinary()
+	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
+	_, err := dev.Ctrl(reqType, RequestSetLineCoding, 0, uint16(iface), data)
+	return err
+}
+
+// SetControlLineState sets the DTR and RTS lines of a communications interface.
+func SetControlLineState(dev *usb.Device, iface uint8, state uint16) error {
+	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
+	_, err := dev.Ctrl(reqType, RequestSetControlLineState, state, uint16(iface), nil)
+	return err
+}
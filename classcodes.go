@@ -74,3 +74,278 @@ var (
 		ClassCodeVendorSpecific:               "VendorSpecific",
 	}
 )
+
+func (s SubClass) String() string {
+	return fmt.Sprintf("%.2X", uint8(s))
+}
+
+// ClassUsage tells whether a class code may be used in device descriptors, interface descriptors or both.
+type ClassUsage uint8
+
+const (
+	ClassUsageDevice    = ClassUsage(0x01)
+	ClassUsageInterface = ClassUsage(0x02)
+	ClassUsageBoth      = ClassUsageDevice | ClassUsageInterface
+)
+
+func (u ClassUsage) String() string {
+	switch u {
+	case ClassUsageDevice:
+		return "Device"
+	case ClassUsageInterface:
+		return "Interface"
+	case ClassUsageBoth:
+		return "Both"
+	}
+	return "Reserved"
+}
+
+// ClassTriple is a class, subclass and protocol code as found in device, interface and
+// interface association descriptors.
+type ClassTriple struct {
+	Class    ClassCode
+	SubClass SubClass
+	Protocol uint8
+}
+
+// Class triples with a defined meaning
+var (
+	ClassTripleUseInterface         = ClassTriple{0x00, 0x00, 0x00}
+	ClassTripleFullSpeedHub         = ClassTriple{ClassCodeDeviceHub, 0x00, 0x00}
+	ClassTripleHighSpeedHubSingleTT = ClassTriple{ClassCodeDeviceHub, 0x00, 0x01}
+	ClassTripleHighSpeedHubMultiTT  = ClassTriple{ClassCodeDeviceHub, 0x00, 0x02}
+	ClassTripleBillboard            = ClassTriple{ClassCodeDeviceBillBoard, 0x00, 0x00}
+	ClassTripleUSB2Compliance       = ClassTriple{ClassCodeDiagnostic, 0x01, 0x01}
+	ClassTripleBluetooth            = ClassTriple{ClassCodeInterfaceWirelessController, 0x01, 0x01}
+	ClassTripleRNDIS                = ClassTriple{ClassCodeInterfaceWirelessController, 0x01, 0x03}
+	ClassTripleIAD                  = ClassTriple{ClassCodeMisc, 0x02, 0x01}
+	ClassTripleDFU                  = ClassTriple{ClassCodeInterfaceApplicationSpecific, 0x01, 0x01}
+	ClassTripleIrDABridge           = ClassTriple{ClassCodeInterfaceApplicationSpecific, 0x02, 0x00}
+	ClassTripleUSBTMC               = ClassTriple{ClassCodeInterfaceApplicationSpecific, 0x03, 0x00}
+	ClassTripleUSB488               = ClassTriple{ClassCodeInterfaceApplicationSpecific, 0x03, 0x01}
+)
+
+// ClassMask selects the fields of a ClassPattern that must match.
+type ClassMask uint8
+
+const (
+	ClassMaskClass    = ClassMask(0x01)
+	ClassMaskSubClass = ClassMask(0x02)
+	ClassMaskProtocol = ClassMask(0x04)
+	ClassMaskAll      = ClassMaskClass | ClassMaskSubClass | ClassMaskProtocol
+)
+
+// ClassPattern matches class triples on the fields selected by Mask.
+type ClassPattern struct {
+	ClassTriple
+	Mask ClassMask
+}
+
+// MatchClass returns a pattern matching any triple with the class code.
+func MatchClass(class ClassCode) ClassPattern {
+	return ClassPattern{ClassTriple{Class: class}, ClassMaskClass}
+}
+
+// MatchSubClass returns a pattern matching any triple with the class and subclass code.
+func MatchSubClass(class ClassCode, subClass SubClass) ClassPattern {
+	return ClassPattern{ClassTriple{Class: class, SubClass: subClass}, ClassMaskClass | ClassMaskSubClass}
+}
+
+// Pattern returns a pattern matching exactly the triple.
+func (t ClassTriple) Pattern() ClassPattern {
+	return ClassPattern{t, ClassMaskAll}
+}
+
+// Match reports whether the triple matches the pattern.
+func (p ClassPattern) Match(t ClassTriple) bool {
+	return (p.Mask&ClassMaskClass == 0 || p.Class == t.Class) &&
+		(p.Mask&ClassMaskSubClass == 0 || p.SubClass == t.SubClass) &&
+		(p.Mask&ClassMaskProtocol == 0 || p.Protocol == t.Protocol)
+}
+
+func (p ClassPattern) String() string {
+	field := func(mask ClassMask, value uint8) string {
+		if p.Mask&mask == 0 {
+			return "xx"
+		}
+		return fmt.Sprintf("%.2X", value)
+	}
+	return field(ClassMaskClass, uint8(p.Class)) + "/" + field(ClassMaskSubClass, uint8(p.SubClass)) + "/" + field(ClassMaskProtocol, p.Protocol)
+}
+
+// MatchDescriptors reports whether the device descriptor or any interface or interface association
+// descriptor of the configurations matches the pattern.
+func (p ClassPattern) MatchDescriptors(set *DescriptorSet) bool {
+	if set.Device != nil && p.Match(set.Device.ClassTriple()) {
+		return true
+	}
+	for _, cfg := range set.Configurations {
+		for _, function := range cfg.Functions() {
+			if p.Match(function.ClassTriple()) {
+				return true
+			}
+			for _, iface := range function.Interfaces {
+				for _, alt := range iface.AltSettings {
+					if p.Match(alt.ClassTriple()) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns a FindDevices filter selecting the devices whose sysfs descriptors match the pattern.
+func (p ClassPattern) Filter() func(dev *Device) bool {
+	return func(dev *Device) bool {
+		set, err := dev.GetSysfsDescriptorSet()
+		if err != nil {
+			return false
+		}
+		return p.MatchDescriptors(set)
+	}
+}
+
+// classTripleEntry is one row of the USB-IF defined class codes table.
+type classTripleEntry struct {
+	pattern ClassPattern
+	usage   ClassUsage
+	name    string
+}
+
+// classTripleTable is ordered from the least to the most specific pattern of each class.
+var classTripleTable = []classTripleEntry{
+	{ClassTripleUseInterface.Pattern(), ClassUsageDevice, "Use class information in the Interface Descriptors"},
+	{MatchClass(ClassCodeInterfaceAudio), ClassUsageInterface, "Audio"},
+	{MatchClass(ClassCodeCDCControl), ClassUsageBoth, "Communications and CDC Control"},
+	{MatchClass(ClassCodeInterfaceHID), ClassUsageInterface, "HID (Human Interface Device)"},
+	{MatchClass(ClassCodeInterfacePhysical), ClassUsageInterface, "Physical"},
+	{MatchClass(ClassCodeInterfaceImage), ClassUsageInterface, "Image"},
+	{ClassTriple{ClassCodeInterfaceImage, 0x01, 0x01}.Pattern(), ClassUsageInterface, "Still Imaging device"},
+	{MatchClass(ClassCodeInterfacePrinter), ClassUsageInterface, "Printer"},
+	{MatchClass(ClassCodeInterfaceMassStorage), ClassUsageInterface, "Mass Storage"},
+	// Hubs use the hub class code in their interface descriptor as well
+	{MatchClass(ClassCodeDeviceHub), ClassUsageBoth, "Hub"},
+	{ClassTripleFullSpeedHub.Pattern(), ClassUsageBoth, "Full speed Hub"},
+	{ClassTripleHighSpeedHubSingleTT.Pattern(), ClassUsageBoth, "Hi-speed hub with single TT"},
+	{ClassTripleHighSpeedHubMultiTT.Pattern(), ClassUsageBoth, "Hi-speed hub with multiple TTs"},
+	{MatchClass(ClassCodeInterfaceCDCData), ClassUsageInterface, "CDC-Data"},
+	{MatchClass(ClassCodeInterfaceSmartCard), ClassUsageInterface, "Smart Card"},
+	{MatchClass(ClassCodeInterfaceContentSecurity), ClassUsageInterface, "Content Security"},
+	{MatchClass(ClassCodeInterfaceVideo), ClassUsageInterface, "Video"},
+	{MatchClass(ClassCodeInterfacePersonalHealthcare), ClassUsageInterface, "Personal Healthcare"},
+	{MatchClass(ClassCodeInterfaceAudioVideo), ClassUsageInterface, "Audio/Video Devices"},
+	{ClassTriple{ClassCodeInterfaceAudioVideo, 0x01, 0x00}.Pattern(), ClassUsageInterface, "Audio/Video Device - AVControl Interface"},
+	{ClassTriple{ClassCodeInterfaceAudioVideo, 0x02, 0x00}.Pattern(), ClassUsageInterface, "Audio/Video Device - AVData Video Streaming Interface"},
+	{ClassTriple{ClassCodeInterfaceAudioVideo, 0x03, 0x00}.Pattern(), ClassUsageInterface, "Audio/Video Device - AVData Audio Streaming Interface"},
+	// The billboard interface descriptor repeats the device class code
+	{MatchClass(ClassCodeDeviceBillBoard), ClassUsageBoth, "Billboard Device Class"},
+	{MatchClass(ClassCodeInterfaceTypeCBridgeClass), ClassUsageInterface, "USB Type-C Bridge Class"},
+	{MatchClass(ClassCodeDiagnostic), ClassUsageBoth, "Diagnostic Device"},
+	{ClassTripleUSB2Compliance.Pattern(), ClassUsageBoth, "USB2 Compliance Device"},
+	{ClassTriple{ClassCodeDiagnostic, 0x02, 0x00}.Pattern(), ClassUsageBoth, "Debug Target vendor defined"},
+	{ClassTriple{ClassCodeDiagnostic, 0x02, 0x01}.Pattern(), ClassUsageBoth, "GNU Remote Debug Command Set"},
+	{MatchClass(ClassCodeInterfaceWirelessController), ClassUsageInterface, "Wireless Controller"},
+	{ClassTripleBluetooth.Pattern(), ClassUsageBoth, "Bluetooth Programming Interface"},
+	{ClassTriple{ClassCodeInterfaceWirelessController, 0x01, 0x02}.Pattern(), ClassUsageInterface, "UWB Radio Control Interface"},
+	{ClassTripleRNDIS.Pattern(), ClassUsageInterface, "Remote NDIS"},
+	{ClassTriple{ClassCodeInterfaceWirelessController, 0x01, 0x04}.Pattern(), ClassUsageInterface, "Bluetooth AMP Controller"},
+	{ClassTriple{ClassCodeInterfaceWirelessController, 0x02, 0x01}.Pattern(), ClassUsageInterface, "Host Wire Adapter Control/Data interface"},
+	{ClassTriple{ClassCodeInterfaceWirelessController, 0x02, 0x02}.Pattern(), ClassUsageInterface, "Device Wire Adapter Control/Data interface"},
+	{ClassTriple{ClassCodeInterfaceWirelessController, 0x02, 0x03}.Pattern(), ClassUsageInterface, "Device Wire Adapter Isochronous interface"},
+	{MatchClass(ClassCodeMisc), ClassUsageBoth, "Miscellaneous"},
+	{ClassTriple{ClassCodeMisc, 0x01, 0x01}.Pattern(), ClassUsageInterface, "Active Sync device"},
+	{ClassTriple{ClassCodeMisc, 0x01, 0x02}.Pattern(), ClassUsageInterface, "Palm Sync"},
+	{ClassTripleIAD.Pattern(), ClassUsageDevice, "Interface Association Descriptor"},
+	{ClassTriple{ClassCodeMisc, 0x02, 0x02}.Pattern(), ClassUsageDevice, "Wire Adapter Multifunction Peripheral programming interface"},
+	{ClassTriple{ClassCodeMisc, 0x03, 0x01}.Pattern(), ClassUsageInterface, "Cable Based Association Framework"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x01}.Pattern(), ClassUsageInterface, "RNDIS over Ethernet"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x02}.Pattern(), ClassUsageInterface, "RNDIS over WiFi"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x03}.Pattern(), ClassUsageInterface, "RNDIS over WiMAX"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x04}.Pattern(), ClassUsageInterface, "RNDIS over WWAN"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x05}.Pattern(), ClassUsageInterface, "RNDIS for Raw IPv4"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x06}.Pattern(), ClassUsageInterface, "RNDIS for Raw IPv6"},
+	{ClassTriple{ClassCodeMisc, 0x04, 0x07}.Pattern(), ClassUsageInterface, "RNDIS for GPRS"},
+	{ClassTriple{ClassCodeMisc, 0x05, 0x00}.Pattern(), ClassUsageInterface, "USB3 Vision Control Interface"},
+	{ClassTriple{ClassCodeMisc, 0x05, 0x01}.Pattern(), ClassUsageInterface, "USB3 Vision Event Interface"},
+	{ClassTriple{ClassCodeMisc, 0x05, 0x02}.Pattern(), ClassUsageInterface, "USB3 Vision Streaming Interface"},
+	{ClassTriple{ClassCodeMisc, 0x06, 0x01}.Pattern(), ClassUsageInterface, "STEP. Stream Transport Efficient Protocol for content protection"},
+	{ClassTriple{ClassCodeMisc, 0x06, 0x02}.Pattern(), ClassUsageInterface, "STEP RAW. Stream Transport Efficient Protocol for Raw content protection"},
+	{ClassTriple{ClassCodeMisc, 0x07, 0x01}.Pattern(), ClassUsageInterface, "Command Interface in Interface Descriptor"},
+	{ClassTriple{ClassCodeMisc, 0x07, 0x02}.Pattern(), ClassUsageInterface, "Media Interface in Interface Descriptor"},
+	{MatchClass(ClassCodeInterfaceApplicationSpecific), ClassUsageInterface, "Application Specific"},
+	{ClassTripleDFU.Pattern(), ClassUsageInterface, "Device Firmware Upgrade"},
+	{ClassTripleIrDABridge.Pattern(), ClassUsageInterface, "IRDA Bridge device"},
+	{ClassTripleUSBTMC.Pattern(), ClassUsageInterface, "USB Test and Measurement Device"},
+	{ClassTripleUSB488.Pattern(), ClassUsageInterface, "USB Test and Measurement Device conforming to the USBTMC USB488 Subclass Specification"},
+	{MatchClass(ClassCodeVendorSpecific), ClassUsageBoth, "Vendor Specific"},
+}
+
+// lookup returns the most specific table entry matching the triple.
+func (t ClassTriple) lookup() *classTripleEntry {
+	var res *classTripleEntry
+	for i := range classTripleTable {
+		entry := &classTripleTable[i]
+		if entry.pattern.Match(t) && (res == nil || entry.pattern.Mask > res.pattern.Mask) {
+			res = entry
+		}
+	}
+	return res
+}
+
+// Name returns the USB-IF name of the most specific defined class code matching the triple,
+// or "" if the class code is reserved.
+func (t ClassTriple) Name() string {
+	if entry := t.lookup(); entry != nil {
+		return entry.name
+	}
+	return ""
+}
+
+// Usage returns where the triple may be used, 0 if the class code is reserved.
+func (t ClassTriple) Usage() ClassUsage {
+	if entry := t.lookup(); entry != nil {
+		return entry.usage
+	}
+	return 0
+}
+
+// ValidForDevice reports whether the triple may be used in a device descriptor.
+func (t ClassTriple) ValidForDevice() bool {
+	return t.Usage()&ClassUsageDevice != 0
+}
+
+// ValidForInterface reports whether the triple may be used in an interface or interface association descriptor.
+func (t ClassTriple) ValidForInterface() bool {
+	return t.Usage()&ClassUsageInterface != 0
+}
+
+// String formats the triple as "EF/02/01 (Interface Association Descriptor)".
+func (t ClassTriple) String() string {
+	res := fmt.Sprintf("%.2X/%.2X/%.2X", uint8(t.Class), uint8(t.SubClass), t.Protocol)
+	if name := t.Name(); name != "" {
+		res += " (" + name + ")"
+	}
+	return res
+}
+
+// ClassTriple returns bDeviceClass, bDeviceSubClass and bDeviceProtocol.
+func (d *DeviceDescriptor) ClassTriple() ClassTriple {
+	return ClassTriple{d.BDeviceClass, d.BDeviceSubClass, d.BDeviceProtocol}
+}
+
+// ClassTriple returns bInterfaceClass, bInterfaceSubClass and bInterfaceProtocol.
+func (d *InterfaceDescriptor) ClassTriple() ClassTriple {
+	return ClassTriple{d.BInterfaceClass, d.BInterfaceSubClass, d.BInterfaceProtocol}
+}
+
+// ClassTriple returns bFunctionClass, bFunctionSubClass and bFunctionProtocol.
+func (d *InterfaceAssociationDescriptor) ClassTriple() ClassTriple {
+	return ClassTriple{d.BFunctionClass, d.BFunctionSubClass, d.BFunctionProtocol}
+}
+
+// ClassTriple returns the class, subclass and protocol of the function.
+func (f *Function) ClassTriple() ClassTriple {
+	return ClassTriple{f.Class, f.SubClass, f.Protocol}
+}
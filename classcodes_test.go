@@ -0,0 +1,25 @@
+package usb
+
+import "testing"
+
+func TestClassTriple(t *testing.T) {
+	if ClassTripleIAD.String() != "EF/02/01 (Interface Association Descriptor)" {
+		t.Fatalf("unexpected name %s", ClassTripleIAD)
+	}
+	if !ClassTripleIAD.ValidForDevice() || ClassTripleIAD.ValidForInterface() {
+		t.Fatalf("unexpected usage %v for %v", ClassTripleIAD.Usage(), ClassTripleIAD)
+	}
+	hid := ClassTriple{ClassCodeInterfaceHID, 1, 2}
+	if hid.ValidForDevice() || !hid.ValidForInterface() || hid.Name() != "HID (Human Interface Device)" {
+		t.Fatalf("unexpected HID triple %v, usage %v", hid, hid.Usage())
+	}
+	if ClassTripleBluetooth.Usage() != ClassUsageBoth {
+		t.Fatalf("unexpected usage %v for %v", ClassTripleBluetooth.Usage(), ClassTripleBluetooth)
+	}
+	if (ClassTriple{Class: 0x42}).Usage() != 0 {
+		t.Fatal("reserved class code has usage")
+	}
+	if !MatchSubClass(ClassCodeInterfaceApplicationSpecific, 1).Match(ClassTripleDFU) || MatchClass(ClassCodeMisc).Match(ClassTripleDFU) {
+		t.Fatal("unexpected pattern match")
+	}
+}
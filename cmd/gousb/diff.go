@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/daedaluz/gousb/diff"
+	"os"
+)
+
+func init() {
+	commands["diff"] = &command{
+		usage: "diff OLD NEW",
+		help:  "compare the descriptors of two devices, descriptor dumps or snapshots",
+		run:   runDiff,
+	}
+}
+
+// runDiff exits with 0 if the descriptors are equal, 1 if they differ and 2 on errors, like diff(1).
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	quiet := flags.Bool("q", false, "only report whether the descriptors differ")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n\nOLD and NEW are a snapshot file, a descriptor dump, a sysfs device name or bus:devnum.\n", commands["diff"].usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+	old, err := loadDescriptorSet(flags.Arg(0))
+	if err != nil {
+		fail(err)
+		return 2
+	}
+	new, err := loadDescriptorSet(flags.Arg(1))
+	if err != nil {
+		fail(err)
+		return 2
+	}
+	differences := diff.Compare(old, new)
+	if len(differences) == 0 {
+		return 0
+	}
+	if *quiet {
+		fmt.Printf("%s and %s differ\n", flags.Arg(0), flags.Arg(1))
+	} else if err := diff.Write(os.Stdout, differences); err != nil {
+		fail(err)
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/format"
+	"github.com/daedaluz/gousb/ids"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	commands["list"] = &command{
+		usage: "list [-d vid:pid] [-s [bus][:devnum]] [-p port] [-c class] [-json]",
+		help:  "list devices, like lsusb",
+		run:   runList,
+	}
+}
+
+// summary is what list and tree show of a device. It is read from sysfs, so no permissions are needed.
+type summary struct {
+	dev   *usb.Device
+	desc  *usb.SysfsDescriptors
+	speed string
+	// serial is read separately, usb.SysfsDescriptors has no serial number.
+	serial string
+}
+
+// summarize reads the sysfs descriptors of every device, sorted by bus and device number.
+// Devices without a device descriptor, such as devices being removed, are skipped.
+func summarize() ([]*summary, error) {
+	devices, err := usb.EnumerateDevices()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*summary, 0, len(devices))
+	for _, dev := range devices {
+		desc, err := dev.GetSysfsDescriptors()
+		if err != nil || desc.DeviceDescriptor == nil {
+			continue
+		}
+		s := &summary{dev: dev, desc: desc}
+		s.speed, _ = dev.ReadSysfsString("speed")
+		s.serial, _ = dev.ReadSysfsString("serial")
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].dev.BusNumber != res[j].dev.BusNumber {
+			return res[i].dev.BusNumber < res[j].dev.BusNumber
+		}
+		return res[i].dev.DeviceNumber < res[j].dev.DeviceNumber
+	})
+	return res, nil
+}
+
+// port returns the port path of the device from its sysfs name, eg. "1.2" for "1-1.2".
+func (s *summary) port() string {
+	if idx := strings.IndexByte(s.dev.Name, '-'); idx >= 0 {
+		return s.dev.Name[idx+1:]
+	}
+	return ""
+}
+
+// describe names the device like lsusb, falling back to the strings of the device for names missing in usb.ids.
+func (s *summary) describe(db *ids.Database) string {
+	info := db.DeviceInfo(s.desc.DeviceDescriptor)
+	if info.Vendor == "" {
+		info.Vendor = s.desc.Manufacturer
+	}
+	if info.Product == "" {
+		info.Product = s.desc.Product
+	}
+	return info.String()
+}
+
+// classes returns the device class and the classes of all interfaces.
+func (s *summary) classes() []usb.ClassCode {
+	res := []usb.ClassCode{s.desc.DeviceDescriptor.BDeviceClass}
+	for _, iface := range s.desc.Interfaces {
+		res = append(res, iface.BInterfaceClass)
+	}
+	return res
+}
+
+// className names a class for list and tree, preferring usb.ids.
+func className(db *ids.Database, class usb.ClassCode) string {
+	if name := db.Class(class); name != "" {
+		return name
+	}
+	return class.String()
+}
+
+// formatSpeed formats the sysfs speed attribute like lsusb -t, eg. "480M".
+func formatSpeed(speed string) string {
+	if speed == "" {
+		return "unknown speed"
+	}
+	return speed + "M"
+}
+
+// filter selects devices by the lsusb style arguments of list.
+type filter struct {
+	vendor, product int
+	bus, devnum     int
+	port            string
+	class           int
+}
+
+func parseHexID(s string) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 16)
+	return int(id), err
+}
+
+func (f *filter) parseID(arg string) error {
+	f.vendor, f.product = -1, -1
+	if arg == "" {
+		return nil
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid -d %q, expected vid:pid", arg)
+	}
+	var err error
+	if f.vendor, err = parseHexID(parts[0]); err != nil {
+		return fmt.Errorf("invalid vendor id %q", parts[0])
+	}
+	if f.product, err = parseHexID(parts[1]); err != nil {
+		return fmt.Errorf("invalid product id %q", parts[1])
+	}
+	return nil
+}
+
+func (f *filter) parseAddress(arg string) error {
+	f.bus, f.devnum = -1, -1
+	if arg == "" {
+		return nil
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	for i, dst := range []*int{&f.bus, &f.devnum} {
+		if i >= len(parts) || parts[i] == "" {
+			continue
+		}
+		value, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return fmt.Errorf("invalid -s %q, expected [bus][:devnum]", arg)
+		}
+		*dst = value
+	}
+	return nil
+}
+
+// parseClass accepts a class code in hex or a class name as in usb.ids or usb.ClassCode.String().
+func (f *filter) parseClass(db *ids.Database, arg string) error {
+	f.class = -1
+	if arg == "" {
+		return nil
+	}
+	if class, err := parseHexID(arg); err == nil && class <= 0xFF {
+		f.class = class
+		return nil
+	}
+	for class := 0; class <= 0xFF; class++ {
+		code := usb.ClassCode(class)
+		name := strings.ToLower(arg)
+		if strings.ToLower(db.Class(code)) == name || strings.ToLower(code.String()) == name ||
+			strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(code.String(), "Interface"), "Device")) == name {
+			f.class = class
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown class %q", arg)
+}
+
+func (f *filter) match(s *summary) bool {
+	desc := s.desc.DeviceDescriptor
+	switch {
+	case f.vendor >= 0 && int(desc.IDVendor) != f.vendor,
+		f.product >= 0 && int(desc.IDProduct) != f.product,
+		f.bus >= 0 && s.dev.BusNumber != f.bus,
+		f.devnum >= 0 && s.dev.DeviceNumber != f.devnum,
+		f.port != "" && s.dev.Name != f.port && s.port() != f.port:
+		return false
+	}
+	if f.class < 0 {
+		return true
+	}
+	for _, class := range s.classes() {
+		if int(class) == f.class {
+			return true
+		}
+	}
+	return false
+}
+
+// listEntry is a device in the JSON output of list and tree.
+type listEntry struct {
+	Name          string         `json:"name"`
+	Bus           int            `json:"bus"`
+	Device        int            `json:"device"`
+	Port          string         `json:"port,omitempty"`
+	Speed         string         `json:"speed,omitempty"`
+	Vendor        *format.IDJSON `json:"vendor"`
+	Product       *format.IDJSON `json:"product"`
+	Class         uint8          `json:"class"`
+	Interfaces    []int          `json:"interface_classes,omitempty"`
+	Manufacturer  string         `json:"manufacturer,omitempty"`
+	ProductString string         `json:"product_string,omitempty"`
+	Serial        string         `json:"serial,omitempty"`
+}
+
+func (s *summary) entry(db *ids.Database) *listEntry {
+	desc := s.desc.DeviceDescriptor
+	res := &listEntry{
+		Name:          s.dev.Name,
+		Bus:           s.dev.BusNumber,
+		Device:        s.dev.DeviceNumber,
+		Port:          s.port(),
+		Speed:         s.speed,
+		Vendor:        &format.IDJSON{ID: fmt.Sprintf("%.4x", desc.IDVendor), Name: db.Vendor(desc.IDVendor)},
+		Product:       &format.IDJSON{ID: fmt.Sprintf("%.4x", desc.IDProduct), Name: db.Product(desc.IDVendor, desc.IDProduct)},
+		Class:         uint8(desc.BDeviceClass),
+		Manufacturer:  s.desc.Manufacturer,
+		ProductString: s.desc.Product,
+		Serial:        s.serial,
+	}
+	for _, iface := range s.desc.Interfaces {
+		res.Interfaces = append(res.Interfaces, int(iface.BInterfaceClass))
+	}
+	return res
+}
+
+// runList exits with 1 if no device matched, like lsusb.
+func runList(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	id := flags.String("d", "", "only devices with vendor and product `vid:pid` in hex, either may be omitted")
+	address := flags.String("s", "", "only devices on a bus and/or with a device number, `[bus][:devnum]`")
+	port := flags.String("p", "", "only the device on a `port`, as sysfs name \"1-1.2\" or port path \"1.2\"")
+	class := flags.String("c", "", "only devices with a device or interface `class`, in hex or by name")
+	asJSON := flags.Bool("json", false, "write a JSON array")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n", commands["list"].usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	db := ids.Default()
+	f := &filter{port: *port}
+	for _, err := range []error{f.parseID(*id), f.parseAddress(*address), f.parseClass(db, *class)} {
+		if err != nil {
+			fail(err)
+			return 2
+		}
+	}
+	summaries, err := summarize()
+	if err != nil {
+		return fail(err)
+	}
+	entries := make([]*listEntry, 0, len(summaries))
+	matched := 0
+	for _, s := range summaries {
+		if !f.match(s) {
+			continue
+		}
+		matched++
+		if *asJSON {
+			entries = append(entries, s.entry(db))
+			continue
+		}
+		fmt.Printf("Bus %.3d Device %.3d: ID %s\n", s.dev.BusNumber, s.dev.DeviceNumber, s.describe(db))
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return fail(err)
+		}
+	}
+	if matched == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/ids"
+	"github.com/daedaluz/gousb/sim"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// addDevice adds a simulated device with one interface of class iface as a virtual device,
+// it is removed when the test ends. Buses 91 and 92 are used to stay clear of real devices.
+func addDevice(t *testing.T, name string, bus, devnum int, desc *usb.DeviceDescriptor, iface usb.ClassCode, product, serial string) *sim.Device {
+	desc.BcdUSB, desc.BMaxPacketSize0, desc.BNumConfigurations = 0x0200, 64, 1
+	desc.IProduct, desc.ISerialNumber = 2, 3
+	res, err := sim.Build(desc,
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: iface}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Name, res.BusNumber, res.DeviceNumber, res.Speed = name, bus, devnum, "480"
+	res.Strings = map[uint8]string{2: product, 3: serial}
+	dev := res.USB()
+	if err := usb.AddVirtualDevice(dev); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { usb.RemoveVirtualDevice(dev) })
+	return res
+}
+
+// addDevices adds a hub on port 1 of bus 91 with devices on its ports 2 and 10,
+// a CDC device on port 3 of bus 92 and a device without a port on bus 92.
+func addDevices(t *testing.T) {
+	addDevice(t, "91-1", 91, 2, &usb.DeviceDescriptor{BDeviceClass: usb.ClassCodeDeviceHub, IDVendor: 0x1d6b, IDProduct: 0x0002}, usb.ClassCodeDeviceHub, "Hub", "")
+	addDevice(t, "91-1.10", 91, 4, &usb.DeviceDescriptor{IDVendor: 0x046d, IDProduct: 0xc52b}, usb.ClassCodeInterfaceHID, "Receiver", "K10")
+	addDevice(t, "91-1.2", 91, 3, &usb.DeviceDescriptor{IDVendor: 0x1209, IDProduct: 0x0001}, usb.ClassCodeVendorSpecific, "Gadget", "G2")
+	addDevice(t, "92-3", 92, 2, &usb.DeviceDescriptor{BDeviceClass: usb.ClassCodeCDCControl, IDVendor: 0x1209, IDProduct: 0x0002}, usb.ClassCodeCDCControl, "Modem", "")
+	addDevice(t, "snapshot", 92, 9, &usb.DeviceDescriptor{IDVendor: 0x1209, IDProduct: 0x0003}, usb.ClassCodeVendorSpecific, "Snapshot", "")
+}
+
+// captureStdout returns what run writes to os.Stdout and its exit status.
+func captureStdout(t *testing.T, run func() int) (string, int) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stdout := os.Stdout
+	os.Stdout = file
+	status := run()
+	os.Stdout = stdout
+	data, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), status
+}
+
+func TestFilterParse(t *testing.T) {
+	db := ids.Embedded()
+	tests := []struct {
+		id, address, class string
+		expected           filter
+		err                bool
+	}{
+		{"", "", "", filter{-1, -1, -1, -1, "", -1}, false},
+		{"046d:c52b", "1:4", "03", filter{0x046d, 0xc52b, 1, 4, "", 3}, false},
+		{"0x046d:", "1", "0xff", filter{0x046d, -1, 1, -1, "", 0xFF}, false},
+		{":c52b", ":4", "hub", filter{-1, 0xc52b, -1, 4, "", 9}, false},
+		{"", "", "Human Interface Device", filter{-1, -1, -1, -1, "", 3}, false},
+		{"", "", "InterfaceHID", filter{-1, -1, -1, -1, "", 3}, false},
+		{"", "", "massstorage", filter{-1, -1, -1, -1, "", 8}, false},
+		{"046d", "", "", filter{}, true},
+		{"zz:1", "", "", filter{}, true},
+		{"1:10000", "", "", filter{}, true},
+		{"", "x", "", filter{}, true},
+		{"", "1:y", "", filter{}, true},
+		{"", "", "100", filter{}, true},
+		{"", "", "no such class", filter{}, true},
+	}
+	for _, test := range tests {
+		f := filter{}
+		var err error
+		for _, parseErr := range []error{f.parseID(test.id), f.parseAddress(test.address), f.parseClass(db, test.class)} {
+			if parseErr != nil {
+				err = parseErr
+			}
+		}
+		if test.err {
+			if err == nil {
+				t.Errorf("%q %q %q: expected an error", test.id, test.address, test.class)
+			}
+			continue
+		}
+		if err != nil || f != test.expected {
+			t.Errorf("%q %q %q = %+v, %v, expected %+v", test.id, test.address, test.class, f, err, test.expected)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	addDevices(t)
+	summaries, err := summarize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		filter   filter
+		expected []string
+	}{
+		{filter{-1, -1, 91, -1, "", -1}, []string{"91-1", "91-1.2", "91-1.10"}},
+		{filter{-1, -1, 92, 9, "", -1}, []string{"snapshot"}},
+		{filter{0x1209, -1, -1, -1, "", -1}, []string{"91-1.2", "92-3", "snapshot"}},
+		{filter{0x1209, 0x0002, -1, -1, "", -1}, []string{"92-3"}},
+		{filter{-1, -1, -1, -1, "1.10", -1}, []string{"91-1.10"}},
+		{filter{-1, -1, -1, -1, "snapshot", -1}, []string{"snapshot"}},
+		{filter{-1, -1, 91, -1, "", 0x03}, []string{"91-1.10"}},
+		{filter{-1, -1, 92, -1, "", 0x02}, []string{"92-3"}},
+		{filter{-1, -1, 92, -1, "", 0x09}, nil},
+	}
+	for _, test := range tests {
+		var matched []string
+		for _, s := range summaries {
+			if test.filter.match(s) {
+				matched = append(matched, s.dev.Name)
+			}
+		}
+		if !reflect.DeepEqual(matched, test.expected) {
+			t.Errorf("%+v matched %v, expected %v", test.filter, matched, test.expected)
+		}
+	}
+}
+
+func TestParentName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"1-1":       "",
+		"1-1.2":     "1-1",
+		"1-1.2.10":  "1-1.2",
+		"usb1":      "",
+		"snapshot":  "",
+		"my.device": "",
+	} {
+		if parent := parentName(name); parent != expected {
+			t.Errorf("parentName(%q) = %q, expected %q", name, parent, expected)
+		}
+	}
+}
+
+func TestSortTree(t *testing.T) {
+	node := func(name string, children ...*treeNode) *treeNode {
+		return &treeNode{summary: &summary{dev: &usb.Device{Name: name}}, listEntry: &listEntry{Name: name}, Children: children}
+	}
+	nodes := []*treeNode{node("snapshot"), node("1-10"), node("other"), node("1-2", node("1-2.3"), node("1-2.1")), node("1-1")}
+	sortTree(nodes)
+	var names []string
+	var walk func(nodes []*treeNode)
+	walk = func(nodes []*treeNode) {
+		for _, node := range nodes {
+			names = append(names, node.Name)
+			walk(node.Children)
+		}
+	}
+	walk(nodes)
+	expected := []string{"1-1", "1-2", "1-2.1", "1-2.3", "1-10", "snapshot", "other"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("sorted %v, expected %v", names, expected)
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	addDevices(t)
+	output, status := captureStdout(t, func() int { return runList([]string{"-s", "91", "-json"}) })
+	if status != 0 {
+		t.Fatalf("list exited with %d", status)
+	}
+	var entries []*listEntry
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		t.Fatalf("%v in %s", err, output)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 devices, got %s", output)
+	}
+	receiver := entries[2]
+	if receiver.Name != "91-1.10" || receiver.Bus != 91 || receiver.Device != 4 || receiver.Port != "1.10" || receiver.Speed != "480" ||
+		receiver.Vendor.ID != "046d" || receiver.Vendor.Name == "" || receiver.Product.ID != "c52b" || receiver.Class != 0 ||
+		!reflect.DeepEqual(receiver.Interfaces, []int{3}) || receiver.ProductString != "Receiver" || receiver.Serial != "K10" {
+		t.Fatalf("unexpected entry %s", output)
+	}
+
+	if output, status := captureStdout(t, func() int { return runList([]string{"-s", "91", "-c", "02", "-json"}) }); status != 1 || output != "[]\n" {
+		t.Fatalf("list without matches = %d, %q", status, output)
+	}
+}
+
+func TestTreeJSON(t *testing.T) {
+	addDevices(t)
+	output, status := captureStdout(t, func() int { return runTree([]string{"-json"}) })
+	if status != 0 {
+		t.Fatalf("tree exited with %d", status)
+	}
+	type node struct {
+		Name     string  `json:"name"`
+		Device   int     `json:"device"`
+		Children []*node `json:"children"`
+	}
+	var buses []struct {
+		Bus     int     `json:"bus"`
+		Devices []*node `json:"devices"`
+	}
+	if err := json.Unmarshal([]byte(output), &buses); err != nil {
+		t.Fatalf("%v in %s", err, output)
+	}
+	tree := make(map[int][]*node)
+	for _, bus := range buses {
+		tree[bus.Bus] = bus.Devices
+	}
+	if hub := tree[91]; len(hub) != 1 || hub[0].Name != "91-1" || len(hub[0].Children) != 2 ||
+		hub[0].Children[0].Name != "91-1.2" || hub[0].Children[1].Name != "91-1.10" || hub[0].Children[1].Device != 4 {
+		t.Fatalf("unexpected bus 91 in %s", output)
+	}
+	if devices := tree[92]; len(devices) != 2 || devices[0].Name != "92-3" || devices[1].Name != "snapshot" {
+		t.Fatalf("unexpected bus 92 in %s", output)
+	}
+}
+
+func TestShowJSON(t *testing.T) {
+	addDevices(t)
+	output, status := captureStdout(t, func() int { return runShow([]string{"-f", "json", "-strings", "91:4"}) })
+	if status != 0 {
+		t.Fatalf("show exited with %d", status)
+	}
+	var doc struct {
+		Sysfs struct {
+			Name string `json:"name"`
+			Bus  int    `json:"bus"`
+		} `json:"sysfs"`
+		Device struct {
+			Vendor struct {
+				ID string `json:"id"`
+			} `json:"vendor"`
+			ProductString struct {
+				Value string `json:"value"`
+			} `json:"product_string"`
+		} `json:"device"`
+		Configurations []struct {
+			Value uint8 `json:"value"`
+		} `json:"configurations"`
+		Strings []*stringEntry `json:"strings"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("%v in %s", err, output)
+	}
+	if doc.Sysfs.Name != "91-1.10" || doc.Sysfs.Bus != 91 || doc.Device.Vendor.ID != "046d" || doc.Device.ProductString.Value != "Receiver" ||
+		len(doc.Configurations) != 1 || doc.Configurations[0].Value != 1 {
+		t.Fatalf("unexpected document %s", output)
+	}
+	if len(doc.Strings) != 2 || doc.Strings[0].Language != "0x0409" || doc.Strings[0].Value != "Receiver" || doc.Strings[1].Value != "K10" {
+		t.Fatalf("unexpected strings %s", output)
+	}
+
+	output, status = captureStdout(t, func() int { return runShow([]string{"-f", "json", "-sysfs", "91:2", "92:2"}) })
+	var docs []json.RawMessage
+	if err := json.Unmarshal([]byte(output), &docs); err != nil || status != 0 || len(docs) != 2 {
+		t.Fatalf("show of two devices = %d, %v: %s", status, err, output)
+	}
+}
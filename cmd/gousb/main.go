@@ -0,0 +1,49 @@
+// Command gousb inspects USB devices, sysfs descriptor dumps and snapshots.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a subcommand, run returns the exit status.
+type command struct {
+	usage string
+	help  string
+	run   func(args []string) int
+}
+
+var commands = map[string]*command{}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: gousb <command> [arguments]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].help)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, exist := commands[os.Args[1]]
+	if !exist {
+		fmt.Fprintf(os.Stderr, "gousb: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+// fail prints an error and returns the exit status for a failed command.
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "gousb: %v\n", err)
+	return 1
+}
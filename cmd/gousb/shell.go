@@ -0,0 +1,519 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/format"
+	"github.com/daedaluz/gousb/hid/item"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	commands["shell"] = &command{
+		usage: "shell [-history FILE] [-transcript FILE] DEVICE",
+		help:  "explore a device interactively, keeping it open",
+		run:   runShell,
+	}
+	shellCommands = map[string]*shellCommand{
+		"help":       {"help", "list the commands", 0, 0, false, (*shell).help},
+		"info":       {"info", "show the device and the claimed interfaces", 0, 0, false, (*shell).info},
+		"claim":      {"claim IFACE [detach]", "claim an interface, detaching its kernel driver if asked to", 1, 2, false, (*shell).claim},
+		"release":    {"release IFACE", "release an interface and attach a detached kernel driver again", 1, 1, false, (*shell).release},
+		"ctrl":       {"ctrl TYPE REQUEST VALUE INDEX [DATA|LENGTH]", "send a control request, eg. ctrl in,vendor,device 1 0 0 64", 4, 5, true, (*shell).ctrl},
+		"desc":       {"desc TYPE [INDEX [LANG]]", "read a standard descriptor, eg. desc config 0", 1, 3, false, (*shell).desc},
+		"read":       {"read EP LENGTH", "read from a bulk endpoint", 2, 2, false, (*shell).read},
+		"iread":      {"iread EP LENGTH", "read from an interrupt endpoint", 2, 2, false, (*shell).read},
+		"write":      {"write EP DATA", "write hex data to a bulk endpoint", 2, 2, true, (*shell).write},
+		"iwrite":     {"iwrite EP DATA", "write hex data to an interrupt endpoint", 2, 2, true, (*shell).write},
+		"clear-halt": {"clear-halt EP", "clear the halt of a stalled endpoint", 1, 1, false, (*shell).clearHalt},
+		"set-config": {"set-config VALUE", "select a configuration", 1, 1, false, (*shell).setConfig},
+		"set-alt":    {"set-alt IFACE ALT", "select an alternate setting", 2, 2, false, (*shell).setAlt},
+		"reset":      {"reset", "reset the port of the device", 0, 0, false, (*shell).reset},
+		"timeout":    {"timeout [MS]", "show or set the transfer timeout", 0, 1, false, (*shell).setTimeout},
+		"decode":     {"decode [DECODER]", "decode the last received data, see decoders", 0, 1, false, (*shell).decode},
+		"decoders":   {"decoders", "list the decoders", 0, 0, false, (*shell).listDecoders},
+		"history":    {"history", "list the commands entered, !N runs command N again", 0, 0, false, (*shell).listHistory},
+		"exit":       {"exit", "release the interfaces and close the device", 0, 0, false, nil},
+	}
+}
+
+// shellCommand is a command of the shell taking min to max arguments.
+// The last argument of commands with data is hex data, which may be written with spaces.
+type shellCommand struct {
+	usage    string
+	help     string
+	min, max int
+	data     bool
+	run      func(sh *shell, args []string) error
+}
+
+var shellCommands map[string]*shellCommand
+
+// shellDecoders decode the data received last, the name is the argument of decode.
+var shellDecoders = map[string]func(w io.Writer, data []byte) error{
+	"hex": func(w io.Writer, data []byte) error {
+		_, err := io.WriteString(w, hex.Dump(data))
+		return err
+	},
+	"descriptors": func(w io.Writer, data []byte) error {
+		var descriptors []usb.Descriptor
+		err := usb.ReadDescriptors(bytes.NewReader(data), func(desc usb.Descriptor) {
+			descriptors = append(descriptors, desc)
+		})
+		if err != nil {
+			return err
+		}
+		if err := format.Descriptors(w, descriptors, nil); err != nil {
+			return err
+		}
+		for _, desc := range descriptors {
+			if str, ok := desc.(*usb.StringDescriptor); ok {
+				fmt.Fprintf(w, "String: %q\n", str.String())
+			}
+		}
+		return nil
+	},
+	"hid": func(w io.Writer, data []byte) error {
+		items, err := item.Parse(data)
+		if err != nil {
+			return err
+		}
+		if err := item.Dump(w, items); err != nil {
+			return err
+		}
+		_, err = item.Evaluate(items)
+		return err
+	},
+}
+
+// descriptorTypes are the names accepted by desc.
+var descriptorTypes = map[string]usb.DescriptorType{
+	"device":      usb.DescriptorTypeDevice,
+	"config":      usb.DescriptorTypeConfig,
+	"string":      usb.DescriptorTypeString,
+	"qualifier":   usb.DescriptorTypeDeviceQualifier,
+	"other-speed": usb.DescriptorTypeOtherSpeedConfiguration,
+	"bos":         usb.DescriptorTypeBOS,
+}
+
+type shell struct {
+	dev     *usb.Device
+	out     io.Writer
+	timeout uint32
+
+	claimed  map[uint8]bool
+	detached map[uint8]bool
+
+	// last is the data received by the last IN transfer.
+	last []byte
+
+	history     []string
+	historyFile *os.File
+	transcript  *os.File
+}
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gousb_history")
+}
+
+func runShell(args []string) int {
+	flags := flag.NewFlagSet("shell", flag.ContinueOnError)
+	historyPath := flags.String("history", defaultHistoryFile(), "history `file`, empty to keep no history")
+	transcriptPath := flags.String("transcript", "", "append the commands and their output to `file`")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n\nDEVICE is a sysfs device name or bus:devnum.\n", commands["shell"].usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	dev, err := findDevice(flags.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+	if err := dev.Open(); err != nil {
+		return fail(fmt.Errorf("%s: %w", dev.Name, err))
+	}
+	sh := &shell{
+		dev:      dev,
+		out:      os.Stdout,
+		timeout:  1000,
+		claimed:  make(map[uint8]bool),
+		detached: make(map[uint8]bool),
+	}
+	defer sh.close()
+	if *historyPath != "" {
+		if data, err := ioutil.ReadFile(*historyPath); err == nil {
+			sh.history = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+		if sh.historyFile, err = os.OpenFile(*historyPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600); err != nil {
+			return fail(err)
+		}
+	}
+	if *transcriptPath != "" {
+		if sh.transcript, err = os.OpenFile(*transcriptPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+			return fail(err)
+		}
+		sh.out = io.MultiWriter(os.Stdout, sh.transcript)
+	}
+	sh.run(os.Stdin)
+	return 0
+}
+
+// run reads commands until exit or the end of the input.
+func (sh *shell) run(r io.Reader) {
+	fmt.Fprintf(sh.out, "%s: %d:%.3d, type help for the commands\n", sh.dev.Name, sh.dev.BusNumber, sh.dev.DeviceNumber)
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(os.Stdout, "gousb> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(os.Stdout)
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			n, err := strconv.Atoi(line[1:])
+			if err != nil || n < 1 || n > len(sh.history) {
+				fmt.Fprintf(sh.out, "no command %s in the history\n", line)
+				continue
+			}
+			line = sh.history[n-1]
+			fmt.Fprintln(os.Stdout, line)
+		}
+		if sh.transcript != nil {
+			fmt.Fprintf(sh.transcript, "gousb> %s\n", line)
+		}
+		sh.remember(line)
+		if line == "exit" || line == "quit" {
+			return
+		}
+		if err := sh.execute(strings.Fields(line)); err != nil {
+			fmt.Fprintf(sh.out, "error: %v\n", err)
+		}
+	}
+}
+
+func (sh *shell) remember(line string) {
+	if len(sh.history) > 0 && sh.history[len(sh.history)-1] == line {
+		return
+	}
+	sh.history = append(sh.history, line)
+	if sh.historyFile != nil {
+		fmt.Fprintln(sh.historyFile, line)
+	}
+}
+
+func (sh *shell) execute(args []string) error {
+	cmd, exist := shellCommands[args[0]]
+	if !exist || cmd.run == nil {
+		return fmt.Errorf("unknown command %q, type help for the commands", args[0])
+	}
+	params := args[1:]
+	if cmd.data && len(params) > cmd.max {
+		params = append(params[:cmd.max-1], strings.Join(params[cmd.max-1:], ""))
+	}
+	if len(params) < cmd.min || len(params) > cmd.max {
+		return fmt.Errorf("usage: %s", cmd.usage)
+	}
+	return cmd.run(sh, append([]string{args[0]}, params...))
+}
+
+// close releases the claimed interfaces, attaches detached kernel drivers and closes the device and files.
+func (sh *shell) close() {
+	for iface := range sh.claimed {
+		sh.release([]string{"release", strconv.Itoa(int(iface))})
+	}
+	sh.dev.Close()
+	if sh.historyFile != nil {
+		sh.historyFile.Close()
+	}
+	if sh.transcript != nil {
+		sh.transcript.Close()
+	}
+}
+
+// received remembers and prints the data of an IN transfer.
+func (sh *shell) received(data []byte) {
+	sh.last = data
+	fmt.Fprintf(sh.out, "%d bytes\n%s", len(data), hex.Dump(data))
+}
+
+func (sh *shell) help(args []string) error {
+	names := make([]string, 0, len(shellCommands))
+	for name := range shellCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := shellCommands[name]
+		fmt.Fprintf(sh.out, "  %-45s %s\n", cmd.usage, cmd.help)
+	}
+	fmt.Fprintf(sh.out, "\nNumbers are decimal or hex with 0x, TYPE is eg. in,vendor,device or 0xc0.\n")
+	return nil
+}
+
+func (sh *shell) info(args []string) error {
+	desc, err := sh.dev.GetDeviceDescriptor()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "%s: %d:%.3d ID %.4x:%.4x\n", sh.dev.Name, sh.dev.BusNumber, sh.dev.DeviceNumber, desc.IDVendor, desc.IDProduct)
+	if cfg, err := sh.dev.GetConfiguration(); err == nil {
+		fmt.Fprintf(sh.out, "configuration %d\n", cfg)
+	}
+	ifaces := make([]int, 0, len(sh.claimed))
+	for iface := range sh.claimed {
+		ifaces = append(ifaces, int(iface))
+	}
+	sort.Ints(ifaces)
+	for _, iface := range ifaces {
+		note := ""
+		if sh.detached[uint8(iface)] {
+			note = ", kernel driver detached"
+		}
+		fmt.Fprintf(sh.out, "interface %d claimed%s\n", iface, note)
+	}
+	fmt.Fprintf(sh.out, "timeout %dms\n", sh.timeout)
+	return nil
+}
+
+func (sh *shell) claim(args []string) error {
+	iface, err := parseUint(args[1], "interface", 8)
+	if err != nil {
+		return err
+	}
+	if len(args) == 3 {
+		if args[2] != "detach" {
+			return fmt.Errorf("usage: %s", shellCommands["claim"].usage)
+		}
+		driver, err := sh.dev.GetDriver(uint32(iface))
+		if err == nil && driver != "" && driver != "usbfs" {
+			if err := sh.dev.DetachKernel(uint32(iface)); err != nil {
+				return fmt.Errorf("detach %s: %w", driver, err)
+			}
+			sh.detached[uint8(iface)] = true
+			fmt.Fprintf(sh.out, "detached %s\n", driver)
+		}
+	}
+	if err := sh.dev.ClaimInterface(uint8(iface)); err != nil {
+		return err
+	}
+	sh.claimed[uint8(iface)] = true
+	return nil
+}
+
+func (sh *shell) release(args []string) error {
+	iface, err := parseUint(args[1], "interface", 8)
+	if err != nil {
+		return err
+	}
+	err = sh.dev.ReleaseInterface(uint8(iface))
+	delete(sh.claimed, uint8(iface))
+	if sh.detached[uint8(iface)] {
+		delete(sh.detached, uint8(iface))
+		if attachErr := sh.dev.AttachKernel(uint32(iface)); attachErr != nil && err == nil {
+			err = fmt.Errorf("attach kernel driver: %w", attachErr)
+		}
+	}
+	return err
+}
+
+func (sh *shell) ctrl(args []string) error {
+	req, err := parseControlRequest(args[1:])
+	if err != nil {
+		return err
+	}
+	data, err := req.do(sh.dev, sh.timeout)
+	if err != nil {
+		return err
+	}
+	if req.typ&usb.RequestDirectionIn != 0 {
+		sh.received(data)
+	}
+	return nil
+}
+
+func (sh *shell) desc(args []string) error {
+	typ, exist := descriptorTypes[strings.ToLower(args[1])]
+	if !exist {
+		value, err := parseUint(args[1], "descriptor type", 8)
+		if err != nil {
+			return err
+		}
+		typ = usb.DescriptorType(value)
+	}
+	var values [2]uint64
+	for i, name := range []string{"index", "language"} {
+		if len(args) <= 2+i {
+			break
+		}
+		var err error
+		if values[i], err = parseUint(args[2+i], name, 8+8*i); err != nil {
+			return err
+		}
+	}
+	if typ == usb.DescriptorTypeString && values[0] != 0 && len(args) < 4 {
+		languages, err := sh.dev.GetLanguages()
+		if err == nil && len(languages) > 0 {
+			values[1] = uint64(languages[0])
+		}
+	}
+	data, err := sh.dev.GetDescriptor(typ, uint8(values[0]), uint16(values[1]))
+	if err != nil {
+		return err
+	}
+	// configurations and the BOS are read in two steps like the kernel does
+	if len(data) >= 4 && (typ == usb.DescriptorTypeConfig || typ == usb.DescriptorTypeBOS || typ == usb.DescriptorTypeOtherSpeedConfiguration) {
+		if total := uint16(data[2]) | uint16(data[3])<<8; int(total) > len(data) {
+			if data, err = sh.dev.GetDescriptorSize(typ, uint8(values[0]), uint16(values[1]), total); err != nil {
+				return err
+			}
+		}
+	}
+	sh.last = data
+	return shellDecoders["descriptors"](sh.out, data)
+}
+
+func (sh *shell) endpointArgs(args []string) (uint8, error) {
+	ep, err := parseUint(args[1], "endpoint", 8)
+	return uint8(ep), err
+}
+
+func (sh *shell) read(args []string) error {
+	ep, err := sh.endpointArgs(args)
+	if err != nil {
+		return err
+	}
+	length, err := parseUint(args[2], "length", 31)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, length)
+	var n int
+	if args[0] == "iread" {
+		n, err = sh.dev.InterruptTimeout(ep|usb.EndpointDirectionIn, data, sh.timeout)
+	} else {
+		n, err = sh.dev.BulkTimeout(ep|usb.EndpointDirectionIn, data, sh.timeout)
+	}
+	if err != nil {
+		return err
+	}
+	sh.received(data[:n])
+	return nil
+}
+
+func (sh *shell) write(args []string) error {
+	ep, err := sh.endpointArgs(args)
+	if err != nil {
+		return err
+	}
+	data, err := parseData(args[2])
+	if err != nil {
+		return err
+	}
+	var n int
+	if args[0] == "iwrite" {
+		n, err = sh.dev.InterruptTimeout(ep&^usb.EndpointDirectionIn, data, sh.timeout)
+	} else {
+		n, err = sh.dev.BulkTimeout(ep&^usb.EndpointDirectionIn, data, sh.timeout)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "wrote %d of %d bytes\n", n, len(data))
+	return nil
+}
+
+func (sh *shell) clearHalt(args []string) error {
+	ep, err := sh.endpointArgs(args)
+	if err != nil {
+		return err
+	}
+	return sh.dev.ClearHalt(ep)
+}
+
+func (sh *shell) setConfig(args []string) error {
+	value, err := strconv.Atoi(args[1])
+	if err != nil || value < -1 || value > 0xFF {
+		return fmt.Errorf("invalid configuration value %q", args[1])
+	}
+	return sh.dev.SetConfiguration(value)
+}
+
+func (sh *shell) setAlt(args []string) error {
+	iface, err := parseUint(args[1], "interface", 8)
+	if err != nil {
+		return err
+	}
+	alt, err := parseUint(args[2], "alternate setting", 8)
+	if err != nil {
+		return err
+	}
+	return sh.dev.SetInterface(uint8(iface), int(alt))
+}
+
+func (sh *shell) reset(args []string) error {
+	return sh.dev.Reset()
+}
+
+func (sh *shell) setTimeout(args []string) error {
+	if len(args) == 2 {
+		timeout, err := parseUint(args[1], "timeout", 32)
+		if err != nil {
+			return err
+		}
+		sh.timeout = uint32(timeout)
+	}
+	fmt.Fprintf(sh.out, "timeout %dms\n", sh.timeout)
+	return nil
+}
+
+func (sh *shell) decode(args []string) error {
+	name := "descriptors"
+	if len(args) == 2 {
+		name = args[1]
+	}
+	decoder, exist := shellDecoders[name]
+	if !exist {
+		return fmt.Errorf("unknown decoder %q, type decoders for the list", name)
+	}
+	if sh.last == nil {
+		return fmt.Errorf("no data received yet")
+	}
+	return decoder(sh.out, sh.last)
+}
+
+func (sh *shell) listDecoders(args []string) error {
+	names := make([]string, 0, len(shellDecoders))
+	for name := range shellDecoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(sh.out, "%s\n", strings.Join(names, " "))
+	return nil
+}
+
+func (sh *shell) listHistory(args []string) error {
+	for i, line := range sh.history {
+		fmt.Fprintf(sh.out, "%5d  %s\n", i+1, line)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// bootMouse is the report descriptor of appendix E.10 of HID 1.11, returned by vendor request 1 of the shell device.
+var bootMouse = []byte{
+	0x05, 0x01, 0x09, 0x02, 0xa1, 0x01, 0x09, 0x01, 0xa1, 0x00, 0x05, 0x09, 0x19, 0x01, 0x29, 0x03,
+	0x15, 0x00, 0x25, 0x01, 0x95, 0x03, 0x75, 0x01, 0x81, 0x02, 0x95, 0x01, 0x75, 0x05, 0x81, 0x01,
+	0x05, 0x01, 0x09, 0x30, 0x09, 0x31, 0x15, 0x81, 0x25, 0x7f, 0x75, 0x08, 0x95, 0x02, 0x81, 0x06,
+	0xc0, 0xc0,
+}
+
+func shellDevice(t *testing.T) *usb.Device {
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Type() == usb.RequestTypeVendor && setup.Request == 1 {
+			return copy(data, bootMouse), nil
+		}
+		return 0, syscall.EPIPE
+	}
+	dev := res.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	return dev
+}
+
+func TestShell(t *testing.T) {
+	transcript, err := os.Create(filepath.Join(t.TempDir(), "transcript"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	sh := &shell{
+		dev:        shellDevice(t),
+		out:        out,
+		timeout:    1000,
+		claimed:    make(map[uint8]bool),
+		detached:   make(map[uint8]bool),
+		transcript: transcript,
+	}
+	sh.out = io.MultiWriter(out, transcript)
+	script := strings.Join([]string{
+		"claim 0",
+		"ctrl in,standard,device 6 0x0100 0 18",
+		"decode",
+		"desc config 0",
+		"ctrl in,vendor,device 1 0 0 64",
+		"decode hid",
+		"ctrl in,vendor,device 2 0 0 1",
+		"!2",
+		"!99",
+		"info",
+		"history",
+		"bogus",
+		"exit",
+		"info",
+	}, "\n")
+	sh.run(strings.NewReader(script))
+	sh.close()
+
+	output := out.String()
+	for _, expected := range []string{
+		"18 bytes\n",
+		"iDVendor             4617",
+		"bNumInterfaces          1",
+		"bEndpointAddress      129",
+		"50 bytes\n",
+		"Usage Page (Generic Desktop)",
+		"Input (Data,Var,Rel)",
+		"error: broken pipe\n",
+		"no command !99 in the history\n",
+		"interface 0 claimed\n",
+		"    8  ctrl in,standard,device 6 0x0100 0 18\n",
+		"error: unknown command \"bogus\"",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output does not contain %q", expected)
+		}
+	}
+	if strings.Count(output, "18 bytes\n") != 2 {
+		t.Errorf("expected !2 to repeat the device descriptor request")
+	}
+	if strings.Count(output, "interface 0 claimed") != 1 {
+		t.Errorf("expected the session to end at exit")
+	}
+	if t.Failed() {
+		t.Log(output)
+	}
+
+	data, err := ioutil.ReadFile(transcript.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "gousb> decode hid\n") || !strings.Contains(string(data), "gousb> ctrl in,standard,device 6 0x0100 0 18\n18 bytes\n") ||
+		!strings.Contains(string(data), "Usage Page (Generic Desktop)") {
+		t.Errorf("unexpected transcript:\n%s", data)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/format"
+	"github.com/daedaluz/gousb/ids"
+	"io"
+	"os"
+	"sort"
+)
+
+func init() {
+	commands["show"] = &command{
+		usage: "show [-f text|json] [-sysfs] [-strings] [DEVICE...]",
+		help:  "dump the descriptors, BOS and strings of devices, like lsusb -v",
+		run:   runShow,
+	}
+}
+
+// stringEntry is a string descriptor in one language.
+type stringEntry struct {
+	Index    uint8  `json:"index"`
+	Language string `json:"language"`
+	Value    string `json:"value"`
+
+	lang usb.LangID
+}
+
+// showDocument is the JSON output of show, the format document with every string in every language.
+type showDocument struct {
+	*format.Document
+	Strings []*stringEntry `json:"strings,omitempty"`
+
+	info *format.Info
+}
+
+// readStrings reads every string referenced by the descriptors in every language of the device.
+// Strings that can not be read are skipped.
+func readStrings(dev *usb.Device, set *usb.DescriptorSet) ([]*stringEntry, error) {
+	languages, err := dev.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+	var res []*stringEntry
+	for _, lang := range languages {
+		for _, idx := range set.StringIndexes() {
+			str, err := dev.GetStringDescriptor(idx, uint16(lang))
+			if err != nil {
+				continue
+			}
+			res = append(res, &stringEntry{Index: idx, Language: fmt.Sprintf("0x%.4x", uint16(lang)), Value: str, lang: lang})
+		}
+	}
+	return res, nil
+}
+
+func writeStrings(w io.Writer, db *ids.Database, strings []*stringEntry) {
+	fmt.Fprintf(w, "String Descriptors:\n")
+	for _, str := range strings {
+		fmt.Fprintf(w, "  %s %-20s %3d %q\n", str.Language, db.Language(str.lang), str.Index, str.Value)
+	}
+}
+
+// allDevices returns every device sorted by bus and device number.
+func allDevices() ([]*usb.Device, error) {
+	devices, err := usb.EnumerateDevices()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].BusNumber != devices[j].BusNumber {
+			return devices[i].BusNumber < devices[j].BusNumber
+		}
+		return devices[i].DeviceNumber < devices[j].DeviceNumber
+	})
+	return devices, nil
+}
+
+// runShow writes a JSON object for a single DEVICE argument and an array otherwise.
+// Devices are opened to read the BOS and strings, devices that can not be opened are shown from sysfs.
+func runShow(args []string) int {
+	flags := flag.NewFlagSet("show", flag.ContinueOnError)
+	outputFormat := flags.String("f", "text", "output `format`, text or json")
+	sysfsOnly := flags.Bool("sysfs", false, "do not open the devices, only show the sysfs descriptors")
+	allStrings := flags.Bool("strings", false, "read every string in every language the device supports")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n\nDEVICE is a sysfs device name or bus:devnum, all devices are shown if omitted.\n", commands["show"].usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || (*outputFormat != "text" && *outputFormat != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	var devices []*usb.Device
+	if flags.NArg() == 0 {
+		var err error
+		if devices, err = allDevices(); err != nil {
+			return fail(err)
+		}
+	}
+	for _, arg := range flags.Args() {
+		dev, err := findDevice(arg)
+		if err != nil {
+			return fail(err)
+		}
+		devices = append(devices, dev)
+	}
+
+	db := ids.Default()
+	opts := &format.Options{IDs: db}
+	documents := make([]*showDocument, 0, len(devices))
+	status := 0
+	for i, dev := range devices {
+		doc, err := show(dev, *sysfsOnly, *allStrings, opts)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", dev.Name, err))
+			status = 1
+			continue
+		}
+		if *outputFormat == "json" {
+			documents = append(documents, doc)
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := format.Text(os.Stdout, doc.info, opts); err != nil {
+			return fail(err)
+		}
+		if len(doc.Strings) > 0 {
+			writeStrings(os.Stdout, db, doc.Strings)
+		}
+	}
+	if *outputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		var err error
+		if flags.NArg() == 1 && len(documents) == 1 {
+			err = encoder.Encode(documents[0])
+		} else {
+			err = encoder.Encode(documents)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	return status
+}
+
+func show(dev *usb.Device, sysfsOnly, allStrings bool, opts *format.Options) (*showDocument, error) {
+	if !sysfsOnly && dev.Open() == nil {
+		defer dev.Close()
+	}
+	info, err := format.FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+	res := &showDocument{Document: format.NewDocument(info, opts), info: info}
+	if allStrings && dev.IsOpen() {
+		if res.Strings, err = readStrings(dev, info.Set); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
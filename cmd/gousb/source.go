@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"io/ioutil"
+	"os"
+)
+
+// findDevice finds a device by its sysfs name, eg. "1-1.2", or its address as "bus:devnum".
+func findDevice(arg string) (*usb.Device, error) {
+	var bus, devnum int
+	_, err := fmt.Sscanf(arg, "%d:%d", &bus, &devnum)
+	byAddress := err == nil
+	devices, err := usb.FindDevices(func(dev *usb.Device) bool {
+		if byAddress {
+			return dev.BusNumber == bus && dev.DeviceNumber == devnum
+		}
+		return dev.Name == arg
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("%s: no such device", arg)
+	}
+	return devices[0], nil
+}
+
+// loadDescriptorSet loads descriptors from a snapshot file, a raw descriptor dump such as the
+// sysfs "descriptors" attribute, or a device. A device is read directly if it can be opened,
+// otherwise its sysfs descriptors are used.
+func loadDescriptorSet(arg string) (*usb.DescriptorSet, error) {
+	if _, err := os.Stat(arg); err == nil {
+		data, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			snapshot, err := usb.ReadSnapshot(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			return snapshot.DescriptorSet()
+		}
+		set, err := usb.ParseDescriptorSet(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		return set, nil
+	}
+	dev, err := findDevice(arg)
+	if err != nil {
+		return nil, err
+	}
+	if err := dev.Open(); err != nil {
+		return dev.GetSysfsDescriptorSet()
+	}
+	defer dev.Close()
+	return dev.GetDescriptorSet()
+}
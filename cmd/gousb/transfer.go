@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	commands["ctrl"] = &command{
+		usage: "ctrl [flags] DEVICE TYPE REQUEST VALUE INDEX [DATA|LENGTH]",
+		help:  "send a control request",
+		run:   runCtrl,
+	}
+	commands["read"] = &command{
+		usage: "read [flags] DEVICE ENDPOINT LENGTH",
+		help:  "read from a bulk or interrupt endpoint",
+		run:   runRead,
+	}
+	commands["write"] = &command{
+		usage: "write [flags] DEVICE ENDPOINT DATA|-",
+		help:  "write to a bulk or interrupt endpoint",
+		run:   runWrite,
+	}
+	commands["reset"] = &command{
+		usage: "reset [flags] DEVICE",
+		help:  "reset the port of a device",
+		run:   runReset,
+	}
+	commands["clear-halt"] = &command{
+		usage: "clear-halt [flags] DEVICE ENDPOINT",
+		help:  "clear the halt of a stalled endpoint",
+		run:   runClearHalt,
+	}
+	commands["set-config"] = &command{
+		usage: "set-config [flags] DEVICE VALUE",
+		help:  "select a configuration, 0 or -1 on usbfs unconfigures the device",
+		run:   runSetConfig,
+	}
+}
+
+// session holds the flags shared by the transfer commands and the device they opened.
+type session struct {
+	flags   *flag.FlagSet
+	claim   int
+	detach  bool
+	timeout uint
+	raw     bool
+
+	dev      *usb.Device
+	detached bool
+}
+
+func newSession(name string, output bool) *session {
+	s := &session{flags: flag.NewFlagSet(name, flag.ContinueOnError)}
+	s.flags.IntVar(&s.claim, "claim", -1, "claim `interface` before the transfer")
+	s.flags.BoolVar(&s.detach, "detach", false, "detach the kernel driver of the claimed interface, it is attached again when done")
+	s.flags.UintVar(&s.timeout, "timeout", 1000, "transfer timeout in `ms`, 0 waits forever")
+	if output {
+		s.flags.BoolVar(&s.raw, "raw", false, "write the received data to stdout as is instead of a hex dump")
+	}
+	s.flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n\nDEVICE is a sysfs device name or bus:devnum. Numbers are decimal or hex with 0x.\n", commands[name].usage)
+		s.flags.PrintDefaults()
+	}
+	return s
+}
+
+// parse parses the flags and checks the number of arguments, the first argument is the device.
+func (s *session) parse(args []string, min, max int) bool {
+	if err := s.flags.Parse(args); err != nil || s.flags.NArg() < min || s.flags.NArg() > max {
+		s.flags.Usage()
+		return false
+	}
+	return true
+}
+
+// open opens the device and claims the interface given with -claim, the device is closed again on errors.
+func (s *session) open() error {
+	if err := s.openDevice(); err != nil {
+		s.close()
+		return err
+	}
+	return nil
+}
+
+func (s *session) openDevice() error {
+	dev, err := findDevice(s.flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := dev.Open(); err != nil {
+		return fmt.Errorf("%s: %w", dev.Name, err)
+	}
+	s.dev = dev
+	if s.claim < 0 {
+		return nil
+	}
+	if s.detach {
+		if driver, err := dev.GetDriver(uint32(s.claim)); err == nil && driver != "" && driver != "usbfs" {
+			if err := dev.DetachKernel(uint32(s.claim)); err != nil {
+				return fmt.Errorf("detach %s from interface %d: %w", driver, s.claim, err)
+			}
+			s.detached = true
+		}
+	}
+	if err := dev.ClaimInterface(uint8(s.claim)); err != nil {
+		return fmt.Errorf("claim interface %d: %w", s.claim, err)
+	}
+	return nil
+}
+
+// close releases the interface, attaches the kernel driver again and closes the device.
+func (s *session) close() {
+	if s.dev == nil {
+		return
+	}
+	if s.claim >= 0 {
+		s.dev.ReleaseInterface(uint8(s.claim))
+	}
+	if s.detached {
+		if err := s.dev.AttachKernel(uint32(s.claim)); err != nil {
+			fail(fmt.Errorf("attach kernel driver to interface %d: %w", s.claim, err))
+		}
+	}
+	s.dev.Close()
+}
+
+// output writes received data as a hex dump or, with -raw, as is.
+func (s *session) output(data []byte) {
+	if s.raw {
+		os.Stdout.Write(data)
+		return
+	}
+	fmt.Print(hex.Dump(data))
+}
+
+func parseUint(arg, name string, bitSize int) (uint64, error) {
+	value, err := strconv.ParseUint(arg, 0, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, arg)
+	}
+	return value, nil
+}
+
+// parseRequestType accepts a bmRequestType number or a comma separated direction, type and recipient,
+// eg. "in,vendor,device". Omitted parts default to out, standard and device.
+func parseRequestType(arg string) (usb.RequestType, error) {
+	if value, err := strconv.ParseUint(arg, 0, 8); err == nil {
+		return usb.RequestType(value), nil
+	}
+	names := map[string]usb.RequestType{
+		"in":        usb.RequestDirectionIn,
+		"out":       usb.RequestDirectionOut,
+		"standard":  usb.RequestTypeStandard,
+		"class":     usb.RequestTypeClass,
+		"vendor":    usb.RequestTypeVendor,
+		"device":    usb.RequestRecipientDevice,
+		"interface": usb.RequestRecipientInterface,
+		"endpoint":  usb.RequestRecipientEndpoint,
+		"other":     usb.RequestRecipientOther,
+	}
+	var res usb.RequestType
+	for _, part := range strings.Split(arg, ",") {
+		bits, exist := names[strings.ToLower(strings.TrimSpace(part))]
+		if !exist {
+			return 0, fmt.Errorf("invalid request type %q, expected eg. in,vendor,device or 0xc0", arg)
+		}
+		res |= bits
+	}
+	return res, nil
+}
+
+// parseData accepts hex data with optional spaces, colons or a 0x prefix, or "-" to read stdin.
+func parseData(arg string) ([]byte, error) {
+	if arg == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	clean := strings.NewReplacer(" ", "", ":", "", "0x", "").Replace(arg)
+	data, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex data %q", arg)
+	}
+	return data, nil
+}
+
+// controlRequest is a control request parsed from the arguments TYPE REQUEST VALUE INDEX [DATA|LENGTH].
+// IN requests take a length and OUT requests optional hex data.
+type controlRequest struct {
+	typ     usb.RequestType
+	request uint8
+	value   uint16
+	index   uint16
+	data    []byte
+}
+
+func parseControlRequest(args []string) (*controlRequest, error) {
+	if len(args) < 4 || len(args) > 5 {
+		return nil, fmt.Errorf("expected TYPE REQUEST VALUE INDEX [DATA|LENGTH]")
+	}
+	typ, err := parseRequestType(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var values [3]uint64
+	for i, name := range []string{"request", "value", "index"} {
+		bitSize := 16
+		if i == 0 {
+			bitSize = 8
+		}
+		if values[i], err = parseUint(args[1+i], name, bitSize); err != nil {
+			return nil, err
+		}
+	}
+	res := &controlRequest{typ: typ, request: uint8(values[0]), value: uint16(values[1]), index: uint16(values[2])}
+	if typ&usb.RequestDirectionIn != 0 {
+		length := uint64(0)
+		if len(args) == 5 {
+			if length, err = parseUint(args[4], "length", 16); err != nil {
+				return nil, err
+			}
+		}
+		res.data = make([]byte, length)
+	} else if len(args) == 5 {
+		if res.data, err = parseData(args[4]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// do performs the request, returning the received data of IN requests.
+func (r *controlRequest) do(dev *usb.Device, timeout uint32) ([]byte, error) {
+	n, err := dev.CtrlTimeout(r.typ, r.request, r.value, r.index, r.data, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if r.typ&usb.RequestDirectionIn != 0 {
+		return r.data[:n], nil
+	}
+	return nil, nil
+}
+
+func runCtrl(args []string) int {
+	s := newSession("ctrl", true)
+	if !s.parse(args, 5, 6) {
+		return 2
+	}
+	req, err := parseControlRequest(s.flags.Args()[1:])
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	data, err := req.do(s.dev, uint32(s.timeout))
+	if err != nil {
+		return fail(err)
+	}
+	if req.typ&usb.RequestDirectionIn != 0 {
+		s.output(data)
+	}
+	return 0
+}
+
+// transfer performs a bulk or an interrupt transfer, depending on the flag -interrupt.
+func (s *session) transfer(interrupt bool, ep uint8, data []byte) (int, error) {
+	if interrupt {
+		return s.dev.InterruptTimeout(ep, data, uint32(s.timeout))
+	}
+	return s.dev.BulkTimeout(ep, data, uint32(s.timeout))
+}
+
+func runRead(args []string) int {
+	s := newSession("read", true)
+	interrupt := s.flags.Bool("interrupt", false, "read from an interrupt endpoint instead of a bulk endpoint")
+	count := s.flags.Int("count", 1, "number of reads, 0 reads until an error")
+	if !s.parse(args, 3, 3) {
+		return 2
+	}
+	ep, err := parseUint(s.flags.Arg(1), "endpoint", 8)
+	if err != nil {
+		return fail(err)
+	}
+	length, err := parseUint(s.flags.Arg(2), "length", 31)
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	buf := make([]byte, length)
+	for i := 0; *count == 0 || i < *count; i++ {
+		n, err := s.transfer(*interrupt, uint8(ep)|usb.EndpointDirectionIn, buf)
+		if err != nil {
+			return fail(err)
+		}
+		s.output(buf[:n])
+	}
+	return 0
+}
+
+func runWrite(args []string) int {
+	s := newSession("write", false)
+	interrupt := s.flags.Bool("interrupt", false, "write to an interrupt endpoint instead of a bulk endpoint")
+	if !s.parse(args, 3, 3) {
+		return 2
+	}
+	ep, err := parseUint(s.flags.Arg(1), "endpoint", 8)
+	if err != nil {
+		return fail(err)
+	}
+	data, err := parseData(s.flags.Arg(2))
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	n, err := s.transfer(*interrupt, uint8(ep)&^usb.EndpointDirectionIn, data)
+	if err != nil {
+		return fail(err)
+	}
+	if n != len(data) {
+		return fail(fmt.Errorf("short write: %d of %d bytes", n, len(data)))
+	}
+	return 0
+}
+
+func runReset(args []string) int {
+	s := newSession("reset", false)
+	if !s.parse(args, 1, 1) {
+		return 2
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	if err := s.dev.Reset(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func runClearHalt(args []string) int {
+	s := newSession("clear-halt", false)
+	if !s.parse(args, 2, 2) {
+		return 2
+	}
+	ep, err := parseUint(s.flags.Arg(1), "endpoint", 8)
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	if err := s.dev.ClearHalt(uint8(ep)); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func runSetConfig(args []string) int {
+	s := newSession("set-config", false)
+	if !s.parse(args, 2, 2) {
+		return 2
+	}
+	value, err := strconv.Atoi(s.flags.Arg(1))
+	if err != nil || value < -1 || value > 0xFF {
+		return fail(fmt.Errorf("invalid configuration value %q", s.flags.Arg(1)))
+	}
+	if err := s.open(); err != nil {
+		return fail(err)
+	}
+	defer s.close()
+	if err := s.dev.SetConfiguration(value); err != nil {
+		return fail(err)
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	usb "github.com/daedaluz/gousb"
+	"testing"
+)
+
+func TestParseRequestType(t *testing.T) {
+	for _, test := range []struct {
+		arg      string
+		expected usb.RequestType
+		ok       bool
+	}{
+		{"0xc0", 0xC0, true},
+		{"33", 0x21, true},
+		{"in,vendor,device", usb.RequestDirectionIn | usb.RequestTypeVendor | usb.RequestRecipientDevice, true},
+		{"IN, Class, Interface", usb.RequestDirectionIn | usb.RequestTypeClass | usb.RequestRecipientInterface, true},
+		{"endpoint", usb.RequestRecipientEndpoint, true},
+		{"out,standard,other", usb.RequestRecipientOther, true},
+		{"0x100", 0, false},
+		{"in,bogus", 0, false},
+		{"", 0, false},
+	} {
+		typ, err := parseRequestType(test.arg)
+		if (err == nil) != test.ok || typ != test.expected {
+			t.Errorf("parseRequestType(%q) = 0x%.2x, %v", test.arg, uint8(typ), err)
+		}
+	}
+}
+
+func TestParseData(t *testing.T) {
+	for _, test := range []struct {
+		arg      string
+		expected []byte
+		ok       bool
+	}{
+		{"", []byte{}, true},
+		{"0102ff", []byte{0x01, 0x02, 0xFF}, true},
+		{"01 02 FF", []byte{0x01, 0x02, 0xFF}, true},
+		{"01:02:ff", []byte{0x01, 0x02, 0xFF}, true},
+		{"0x0102", []byte{0x01, 0x02}, true},
+		{"012", nil, false},
+		{"zz", nil, false},
+	} {
+		data, err := parseData(test.arg)
+		if (err == nil) != test.ok || !bytes.Equal(data, test.expected) {
+			t.Errorf("parseData(%q) = % x, %v", test.arg, data, err)
+		}
+	}
+}
+
+func TestParseControlRequest(t *testing.T) {
+	for _, test := range []struct {
+		args     []string
+		expected *controlRequest
+	}{
+		{[]string{"in,standard,device", "6", "0x0100", "0", "18"},
+			&controlRequest{typ: 0x80, request: 6, value: 0x0100, data: make([]byte, 18)}},
+		{[]string{"0xc0", "1", "0", "0"},
+			&controlRequest{typ: 0xC0, request: 1, data: []byte{}}},
+		{[]string{"out,class,interface", "0x22", "3", "1"},
+			&controlRequest{typ: 0x21, request: 0x22, value: 3, index: 1}},
+		{[]string{"0x21", "0x20", "0", "0", "00c2010000 0008"},
+			&controlRequest{typ: 0x21, request: 0x20, data: []byte{0x00, 0xC2, 0x01, 0x00, 0x00, 0x00, 0x08}}},
+		{[]string{"in", "6", "0"}, nil},
+		{[]string{"in", "6", "0", "0", "1", "2"}, nil},
+		{[]string{"in", "0x100", "0", "0"}, nil},
+		{[]string{"in", "6", "0x10000", "0"}, nil},
+		{[]string{"in", "6", "0", "-1"}, nil},
+		{[]string{"in", "6", "0", "0", "0x10000"}, nil},
+		{[]string{"out", "9", "1", "0", "xyz"}, nil},
+		{[]string{"sideways", "6", "0", "0"}, nil},
+	} {
+		req, err := parseControlRequest(test.args)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("parseControlRequest(%q): expected an error, got %+v", test.args, req)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseControlRequest(%q): %v", test.args, err)
+			continue
+		}
+		if req.typ != test.expected.typ || req.request != test.expected.request || req.value != test.expected.value ||
+			req.index != test.expected.index || !bytes.Equal(req.data, test.expected.data) || (req.data == nil) != (test.expected.data == nil) {
+			t.Errorf("parseControlRequest(%q) = %+v, expected %+v", test.args, req, test.expected)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/daedaluz/gousb/ids"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	commands["tree"] = &command{
+		usage: "tree [-v] [-json]",
+		help:  "show the device topology, like lsusb -t",
+		run:   runTree,
+	}
+}
+
+// treeNode is a device with the devices connected to its downstream ports.
+type treeNode struct {
+	*listEntry
+	Children []*treeNode `json:"children,omitempty"`
+
+	summary *summary
+}
+
+// parentName returns the sysfs name of the hub a device is connected to, "" for devices on a root hub.
+// The parent of "1-1.2" is "1-1", the parent of "1-1" is the root hub.
+func parentName(name string) string {
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 && strings.IndexByte(name, '-') >= 0 {
+		return name[:idx]
+	}
+	return ""
+}
+
+// portNumber returns the number of the port a device is connected to, the last element of its port path.
+func (s *summary) portNumber() string {
+	port := s.port()
+	return port[strings.LastIndexByte(port, '.')+1:]
+}
+
+// buildTree returns the devices connected to the root hubs by bus number.
+// Devices whose hub is missing, such as virtual devices, are shown on the root hub.
+func buildTree(summaries []*summary, db *ids.Database) map[int][]*treeNode {
+	nodes := make(map[string]*treeNode, len(summaries))
+	for _, s := range summaries {
+		nodes[s.dev.Name] = &treeNode{listEntry: s.entry(db), summary: s}
+	}
+	roots := make(map[int][]*treeNode)
+	for _, s := range summaries {
+		node := nodes[s.dev.Name]
+		if parent, exist := nodes[parentName(s.dev.Name)]; exist {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots[s.dev.BusNumber] = append(roots[s.dev.BusNumber], node)
+	}
+	return roots
+}
+
+// sortTree sorts the devices by port number, devices without a port are kept in order at the end.
+func sortTree(nodes []*treeNode) {
+	port := func(node *treeNode) int {
+		if node.summary.port() == "" {
+			return 1 << 16
+		}
+		n, _ := strconv.Atoi(node.summary.portNumber())
+		return n
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return port(nodes[i]) < port(nodes[j])
+	})
+	for _, node := range nodes {
+		sortTree(node.Children)
+	}
+}
+
+func writeTree(nodes []*treeNode, indent string, verbose bool, db *ids.Database) {
+	for _, node := range nodes {
+		s := node.summary
+		position := "Port " + s.portNumber()
+		if s.port() == "" {
+			position = s.dev.Name
+		}
+		class := "Class=" + className(db, s.desc.DeviceDescriptor.BDeviceClass)
+		fmt.Printf("%s|__ %s: Dev %d, %s, %s, %s\n", indent, position, s.dev.DeviceNumber, s.describe(db), class, formatSpeed(s.speed))
+		if verbose {
+			for _, iface := range s.desc.Interfaces {
+				fmt.Printf("%s    If %d Alt %d: Class=%s\n", indent, iface.BInterfaceNumber, iface.BAlternateSetting, className(db, iface.BInterfaceClass))
+			}
+		}
+		writeTree(node.Children, indent+"    ", verbose, db)
+	}
+}
+
+func runTree(args []string) int {
+	flags := flag.NewFlagSet("tree", flag.ContinueOnError)
+	verbose := flags.Bool("v", false, "show the interfaces of every device")
+	asJSON := flags.Bool("json", false, "write a JSON array of buses")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gousb %s\n", commands["tree"].usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	db := ids.Default()
+	summaries, err := summarize()
+	if err != nil {
+		return fail(err)
+	}
+	roots := buildTree(summaries, db)
+	buses := make([]int, 0, len(roots))
+	for bus := range roots {
+		buses = append(buses, bus)
+	}
+	sort.Ints(buses)
+	for _, nodes := range roots {
+		sortTree(nodes)
+	}
+
+	if *asJSON {
+		type busJSON struct {
+			Bus     int         `json:"bus"`
+			Devices []*treeNode `json:"devices"`
+		}
+		res := make([]*busJSON, 0, len(buses))
+		for _, bus := range buses {
+			res = append(res, &busJSON{Bus: bus, Devices: roots[bus]})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(res); err != nil {
+			return fail(err)
+		}
+		return 0
+	}
+	for _, bus := range buses {
+		fmt.Printf("/:  Bus %.3d\n", bus)
+		writeTree(roots[bus], "    ", *verbose, db)
+	}
+	return 0
+}
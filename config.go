@@ -0,0 +1,338 @@
+package usb
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type (
+	// Configuration is a ConfigurationDescriptor together with all interface, endpoint,
+	// endpoint companion and class- or vendor-specific descriptors returned with it.
+	Configuration struct {
+		*ConfigurationDescriptor
+
+		// Interfaces in the order they first appear in the configuration.
+		Interfaces []*Interface
+
+		// Extra holds descriptors following the configuration descriptor but preceding the first interface.
+		Extra []Descriptor
+	}
+
+	// Interface groups all alternate settings sharing the same BInterfaceNumber.
+	Interface struct {
+		Number      uint8
+		AltSettings []*AltSetting
+
+		// Association is the interface association descriptor located immediately before this interface.
+		Association *InterfaceAssociationDescriptor
+	}
+
+	// AltSetting is one InterfaceDescriptor with the endpoints and descriptors following it.
+	AltSetting struct {
+		*InterfaceDescriptor
+		Endpoints []*Endpoint
+
+		// Extra holds class- or vendor-specific descriptors located between the
+		// InterfaceDescriptor and the first EndpointDescriptor. eg. HID or CDC functional descriptors.
+		Extra []Descriptor
+	}
+
+	// Endpoint is an EndpointDescriptor with its companion descriptors.
+	Endpoint struct {
+		*EndpointDescriptor
+		SSCompanion     *SSEndpointCompanionDescriptor
+		SSPIsoCompanion *SSPIsochronousEndpointCompanionDescriptor
+
+		// Extra holds class- or vendor-specific descriptors following the endpoint.
+		Extra []Descriptor
+	}
+
+	// ConfigBuilder assembles a Configuration from a sequence of descriptors.
+	// Descriptors are attached the same way they are when parsed from a device:
+	// interface descriptors start a new alternate setting, endpoint descriptors belong to the
+	// last alternate setting and other descriptors belong to whatever they follow.
+	//
+	// The WTotalLength, BNumInterfaces and BNumEndpoints fields are computed when marshaling.
+	ConfigBuilder struct {
+		config      *Configuration
+		alt         *AltSetting
+		ep          *Endpoint
+		association *InterfaceAssociationDescriptor
+	}
+
+	// BOS is a BOSDescriptor together with its device capability descriptors.
+	BOS struct {
+		*BOSDescriptor
+		Capabilities []Descriptor
+	}
+)
+
+// NewConfigBuilder returns a builder for a configuration described by desc.
+func NewConfigBuilder(desc *ConfigurationDescriptor) *ConfigBuilder {
+	return &ConfigBuilder{
+		config: &Configuration{
+			ConfigurationDescriptor: desc,
+			Interfaces:              make([]*Interface, 0, 4),
+		},
+	}
+}
+
+// Add appends descriptors to the configuration.
+func (b *ConfigBuilder) Add(descriptors ...Descriptor) *ConfigBuilder {
+	for _, desc := range descriptors {
+		b.add(desc)
+	}
+	return b
+}
+
+func (b *ConfigBuilder) add(desc Descriptor) {
+	switch x := desc.(type) {
+	case *ConfigurationDescriptor:
+		b.config.ConfigurationDescriptor = x
+	case *OtherSpeedConfigurationDescriptor:
+		if x.DescriptorType == 0 {
+			x.DescriptorType = DescriptorTypeOtherSpeedConfiguration
+		}
+		b.config.ConfigurationDescriptor = (*ConfigurationDescriptor)(x)
+	case *InterfaceDescriptor:
+		b.alt = &AltSetting{InterfaceDescriptor: x}
+		b.ep = nil
+		iface := b.config.Interface(x.BInterfaceNumber)
+		if iface == nil {
+			iface = &Interface{Number: x.BInterfaceNumber}
+			b.config.Interfaces = append(b.config.Interfaces, iface)
+		}
+		if b.association != nil && len(iface.AltSettings) == 0 {
+			iface.Association = b.association
+			b.association = nil
+		}
+		iface.AltSettings = append(iface.AltSettings, b.alt)
+	case *InterfaceAssociationDescriptor:
+		if b.association != nil {
+			b.addExtra(b.association)
+		}
+		b.association = x
+	case *EndpointDescriptor:
+		if b.alt == nil {
+			b.config.Extra = append(b.config.Extra, x)
+			return
+		}
+		b.ep = &Endpoint{EndpointDescriptor: x}
+		b.alt.Endpoints = append(b.alt.Endpoints, b.ep)
+	case *SSEndpointCompanionDescriptor:
+		if b.ep == nil || b.ep.SSCompanion != nil {
+			b.addExtra(x)
+			return
+		}
+		b.ep.SSCompanion = x
+	case *SSPIsochronousEndpointCompanionDescriptor:
+		if b.ep == nil || b.ep.SSPIsoCompanion != nil {
+			b.addExtra(x)
+			return
+		}
+		b.ep.SSPIsoCompanion = x
+	default:
+		b.addExtra(x)
+	}
+}
+
+func (b *ConfigBuilder) addExtra(desc Descriptor) {
+	switch {
+	case b.ep != nil:
+		b.ep.Extra = append(b.ep.Extra, desc)
+	case b.alt != nil:
+		b.alt.Extra = append(b.alt.Extra, desc)
+	default:
+		b.config.Extra = append(b.config.Extra, desc)
+	}
+}
+
+// Interface starts a new alternate setting, see Add.
+func (b *ConfigBuilder) Interface(desc *InterfaceDescriptor, extra ...Descriptor) *ConfigBuilder {
+	return b.Add(desc).Add(extra...)
+}
+
+// Endpoint adds an endpoint to the last alternate setting, see Add.
+// Companion descriptors may be given in extra.
+func (b *ConfigBuilder) Endpoint(desc *EndpointDescriptor, extra ...Descriptor) *ConfigBuilder {
+	return b.Add(desc).Add(extra...)
+}
+
+// Association adds an interface association descriptor, it is attached to the next new interface.
+func (b *ConfigBuilder) Association(desc *InterfaceAssociationDescriptor) *ConfigBuilder {
+	return b.Add(desc)
+}
+
+// Configuration returns the configuration built so far.
+// An interface association descriptor not followed by a new interface is kept as an extra descriptor.
+func (b *ConfigBuilder) Configuration() *Configuration {
+	if b.association != nil {
+		b.addExtra(b.association)
+		b.association = nil
+	}
+	return b.config
+}
+
+// MarshalBinary returns the encoded configuration, see Configuration.MarshalBinary.
+func (b *ConfigBuilder) MarshalBinary() ([]byte, error) {
+	return b.Configuration().MarshalBinary()
+}
+
+// Interface returns the interface with the specified number or nil if it does not exist.
+func (c *Configuration) Interface(number uint8) *Interface {
+	for _, iface := range c.Interfaces {
+		if iface.Number == number {
+			return iface
+		}
+	}
+	return nil
+}
+
+// AltSetting returns the specified alternate setting or nil if it does not exist.
+func (i *Interface) AltSetting(setting uint8) *AltSetting {
+	for _, alt := range i.AltSettings {
+		if alt.BAlternateSetting == setting {
+			return alt
+		}
+	}
+	return nil
+}
+
+// Descriptors returns the configuration as a flat list of descriptors in wire order.
+func (c *Configuration) Descriptors() []Descriptor {
+	res := make([]Descriptor, 0, 16)
+	if c.ConfigurationDescriptor != nil {
+		res = append(res, c.ConfigurationDescriptor)
+	}
+	res = append(res, c.Extra...)
+	for _, iface := range c.Interfaces {
+		if iface.Association != nil {
+			res = append(res, iface.Association)
+		}
+		for _, alt := range iface.AltSettings {
+			res = append(res, alt.InterfaceDescriptor)
+			res = append(res, alt.Extra...)
+			for _, ep := range alt.Endpoints {
+				res = append(res, ep.EndpointDescriptor)
+				if ep.SSCompanion != nil {
+					res = append(res, ep.SSCompanion)
+				}
+				if ep.SSPIsoCompanion != nil {
+					res = append(res, ep.SSPIsoCompanion)
+				}
+				res = append(res, ep.Extra...)
+			}
+		}
+	}
+	return res
+}
+
+// MarshalBinary encodes the configuration in wire order.
+// WTotalLength, BNumInterfaces and BNumEndpoints are computed from the tree,
+// the descriptors in the tree are left untouched.
+func (c *Configuration) MarshalBinary() ([]byte, error) {
+	if c.ConfigurationDescriptor == nil {
+		return nil, fmt.Errorf("configuration has no configuration descriptor")
+	}
+	numEndpoints := make(map[*InterfaceDescriptor]int)
+	for _, iface := range c.Interfaces {
+		for _, alt := range iface.AltSettings {
+			numEndpoints[alt.InterfaceDescriptor] = len(alt.Endpoints)
+		}
+	}
+	buff := &bytes.Buffer{}
+	for _, desc := range c.Descriptors() {
+		switch x := desc.(type) {
+		case *ConfigurationDescriptor:
+			cfg := *x
+			cfg.BNumInterfaces = uint8(len(c.Interfaces))
+			desc = &cfg
+		case *InterfaceDescriptor:
+			alt := *x
+			alt.BNumEndpoints = uint8(numEndpoints[x])
+			desc = &alt
+		}
+		data, err := MarshalDescriptor(desc)
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(data)
+	}
+	data := buff.Bytes()
+	if len(data) > 0xFFFF {
+		return nil, fmt.Errorf("configuration too long: %d bytes", len(data))
+	}
+	data[2] = uint8(len(data))
+	data[3] = uint8(len(data) >> 8)
+	return data, nil
+}
+
+// ParseConfiguration parses a complete configuration as returned by GetDescriptor(DescriptorTypeConfig)
+// or GetDescriptor(DescriptorTypeOtherSpeedConfiguration).
+// An other speed configuration keeps its descriptor type, see Configuration.IsOtherSpeed.
+func ParseConfiguration(data []byte) (*Configuration, error) {
+	descriptors, err := ParseDescriptors(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("empty configuration")
+	}
+	switch descriptors[0].(type) {
+	case *ConfigurationDescriptor, *OtherSpeedConfigurationDescriptor:
+	default:
+		return nil, fmt.Errorf("expected configuration descriptor, got %v", descriptors[0].Type())
+	}
+	return NewConfigBuilder(nil).Add(descriptors...).Configuration(), nil
+}
+
+// IsOtherSpeed reports whether the configuration was read as an other speed configuration.
+func (c *Configuration) IsOtherSpeed() bool {
+	return c.ConfigurationDescriptor != nil && c.DescriptorType == DescriptorTypeOtherSpeedConfiguration
+}
+
+// MarshalBinary encodes the BOS descriptor and its capabilities.
+// WTotalLength and BNumDeviceCaps are computed from the capability list.
+func (b *BOS) MarshalBinary() ([]byte, error) {
+	hdr := BOSDescriptor{}
+	if b.BOSDescriptor != nil {
+		hdr = *b.BOSDescriptor
+	}
+	hdr.BNumDeviceCaps = uint8(len(b.Capabilities))
+	data, err := hdr.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	for _, capability := range b.Capabilities {
+		capData, err := MarshalDescriptor(capability)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, capData...)
+	}
+	if len(data) > 0xFFFF {
+		return nil, fmt.Errorf("BOS too long: %d bytes", len(data))
+	}
+	data[2] = uint8(len(data))
+	data[3] = uint8(len(data) >> 8)
+	return data, nil
+}
+
+// ParseBOS parses a complete BOS descriptor set as returned by GetDescriptor(DescriptorTypeBOS).
+func ParseBOS(data []byte) (*BOS, error) {
+	descriptors, err := ParseDescriptors(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("empty BOS")
+	}
+	bos, ok := descriptors[0].(*BOSDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("expected BOS descriptor, got %v", descriptors[0].Type())
+	}
+	return &BOS{
+		BOSDescriptor: bos,
+		Capabilities:  descriptors[1:],
+	}, nil
+}
@@ -41,8 +41,9 @@ const (
 	DescriptorTypeString
 	DescriptorTypeInterface
 	DescriptorTypeEndpoint
-
-	DescriptorTypeInterfacePower = DescriptorType(iota + 8)
+	DescriptorTypeDeviceQualifier
+	DescriptorTypeOtherSpeedConfiguration
+	DescriptorTypeInterfacePower
 	DescriptorTypeOTG
 	DescriptorTypeDebug
 	DescriptorTypeInterfaceAssociation
@@ -60,6 +61,15 @@ var (
 		DescriptorTypeInterface: reflect.TypeOf(InterfaceDescriptor{}),
 		DescriptorTypeEndpoint:  reflect.TypeOf(EndpointDescriptor{}),
 		DescriptorTypeString:    reflect.TypeOf(StringDescriptor{}),
+		DescriptorTypeBOS:       reflect.TypeOf(BOSDescriptor{}),
+
+		DescriptorTypeInterfaceAssociation:    reflect.TypeOf(InterfaceAssociationDescriptor{}),
+		DescriptorTypeDeviceQualifier:         reflect.TypeOf(DeviceQualifierDescriptor{}),
+		DescriptorTypeOtherSpeedConfiguration: reflect.TypeOf(OtherSpeedConfigurationDescriptor{}),
+
+		DescriptorTypeDeviceCapability:                           reflect.TypeOf(DeviceCapabilityDescriptor{}),
+		DescriptorTypeSuperSpeedUSBEndprointCompanion:            reflect.TypeOf(SSEndpointCompanionDescriptor{}),
+		DescriptorTypeSuperSpeedPlusIsochronousEndpointCompanion: reflect.TypeOf(SSPIsochronousEndpointCompanionDescriptor{}),
 	}
 )
 
@@ -157,6 +167,46 @@ type (
 		BNumConfigurations uint8
 	}
 
+	// DeviceQualifierDescriptor describes information about a high-speed capable device that would
+	// change if the device were operating at the other speed.
+	// For example, if the device is currently operating at full-speed, the device qualifier returns
+	// information about how it would operate at high-speed and vice-versa.
+	//
+	// The vendor and product ID, device release number and string indices are not included
+	// since they do not change with the speed.
+	//
+	// If a full-speed only device receives a GetDescriptor() request for a device qualifier, it must
+	// respond with a request error. A device operating at Gen X speed shall not support this descriptor.
+	DeviceQualifierDescriptor struct {
+		DescriptorHeader
+		// BcdUSB USB specification version number, at least 0200H.
+		BcdUSB uint16
+
+		// BDeviceClass class code at the other speed.
+		BDeviceClass ClassCode
+
+		// BDeviceSubClass subclass code at the other speed.
+		BDeviceSubClass SubClass
+
+		// BDeviceProtocol protocol code at the other speed.
+		BDeviceProtocol uint8
+
+		// BMaxPacketSize0 maximum packet size for endpoint zero at the other speed.
+		BMaxPacketSize0 uint8
+
+		// BNumConfigurations number of other-speed configurations.
+		BNumConfigurations uint8
+
+		// BReserved reserved for future use, must be zero.
+		BReserved uint8
+	}
+
+	// OtherSpeedConfigurationDescriptor describes a configuration of a high-speed capable device
+	// if it were operating at its other possible speed.
+	// The structure of the other speed configuration is identical to a ConfigurationDescriptor
+	// and is returned together with the interface and endpoint descriptors for the other speed.
+	OtherSpeedConfigurationDescriptor ConfigurationDescriptor
+
 	// BOSDescriptor defines a root descriptor that is similar to the configuration descriptor,
 	// and is the base descriptor for accessing a family of related descriptors.
 	// A host can read a BOS descriptor and learn from the wTotalLength field the entire size
@@ -215,7 +265,7 @@ type (
 		// +----------------------------------------------------------------+
 		// | Bit   | Encoding                                               |
 		// +----------------------------------------------------------------+
-		// | 7     | Reserved. Shall be set to 0 for historical reasons.    |
+		// | 7     | Reserved. Shall be set to 1 for historical reasons.    |
 		// +----------------------------------------------------------------+
 		// | 6     | Self-powered. A device configuration that uses power   |
 		// |       | from the bus and a local source reports a non-zero     |
@@ -275,7 +325,7 @@ type (
 	//        operating systems, installation of an upgrade driver that can parse and enumerate
 	//        configurations that include the Interface Association Descriptor. The Multi-interface Function.
 	//        Class code is documented at http://www.usb.org/developers/docs.
-	//        TODO: enumerate described class codes.
+	//        The Multi-interface Function Class codes are ClassTripleIAD.
 	InterfaceAssociationDescriptor struct {
 		DescriptorHeader
 		// BFirstInterface is the number of the first interface that is associated with this function.
@@ -436,6 +486,10 @@ type (
 		//
 		// This field is reserved and shall not be used for Enhanced SuperSpeed bulk or control endpoints.
 		BInterval uint8
+
+		// Trailing holds the bytes of an endpoint descriptor longer than 7 bytes, such as bRefresh and
+		// bSynchAddress of an audio class 1.0 endpoint. It is empty for a standard endpoint.
+		Trailing []byte
 	}
 
 	// StringDescriptor are optional.
@@ -444,7 +498,7 @@ type (
 	// Publishing Company, Reading, Massachusetts (http://www.unicode.org).
 	// The strings in a device may support multiple languages.
 	// When requesting a string descr iptor, the requester specifies the desired language using a 16-bit
-	// language ID (LANGID) defined by the USB-IF. TODO: Lookup and enumerate langIDs.
+	// language ID (LANGID) defined by the USB-IF, see LangID.
 	// String index zero for all languages returns a string descriptor that contains an array of
 	// 2-byte LANGID codes supported by the device.
 	// A device may omit all string descriptors.
@@ -586,21 +640,37 @@ func readDescriptorHeader(i io.Reader) (*DescriptorHeader, error) {
 	return &header, err
 }
 
-func newDescriptor(hdr DescriptorHeader) (any, reflect.Value) {
-	if descriptor, exist := descriptorMap[hdr.DescriptorType]; exist {
-		x := reflect.New(descriptor)
-		x.Elem().Field(0).Set(reflect.ValueOf(hdr))
-		return x.Interface(), x
+func newDescriptor(hdr DescriptorHeader, body []byte) (any, reflect.Value) {
+	descriptor, exist := descriptorMap[hdr.DescriptorType]
+	if hdr.DescriptorType == DescriptorTypeDeviceCapability && len(body) > 0 {
+		if capDescriptor, capExist := capabilityMap[Capability(body[0])]; capExist {
+			descriptor = capDescriptor
+		}
 	}
-	x := reflect.New(reflect.TypeOf(UnknownDescriptor{}))
+	if !exist {
+		descriptor = reflect.TypeOf(UnknownDescriptor{})
+	}
+	x := reflect.New(descriptor)
 	x.Elem().Field(0).Set(reflect.ValueOf(hdr))
 	return x.Interface(), x
 }
 
+// readDescriptor reads the body of the descriptor described by header.
+// The descriptor is confined to header.Length bytes, any trailing bytes not covered by
+// the descriptor struct are skipped. Descriptors that can be longer, like EndpointDescriptor,
+// end in a slice field that keeps them.
 func readDescriptor(header *DescriptorHeader, i io.Reader) (Descriptor, error) {
-	descriptor, ptrVal := newDescriptor(*header)
+	if header.Length < 2 {
+		return nil, fmt.Errorf("invalid descriptor length %d for %v", header.Length, header.DescriptorType)
+	}
+	body := make([]byte, header.Length-2)
+	if _, err := io.ReadFull(i, body); err != nil {
+		return nil, fmt.Errorf("truncated %v: %w", header.DescriptorType, err)
+	}
+	r := bytes.NewReader(body)
+	descriptor, ptrVal := newDescriptor(*header, body)
 	if customReader, implements := descriptor.(DescriptorParser); implements {
-		if err := customReader.ReadUSBDescriptor(*header, i); err != nil {
+		if err := customReader.ReadUSBDescriptor(*header, r); err != nil {
 			return nil, err
 		}
 		return descriptor.(Descriptor), nil
@@ -614,20 +684,22 @@ loop:
 
 		switch field.Kind() {
 		case reflect.Slice:
+			excessiveData, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
 			switch field.Type() {
 			case reflect.TypeOf([]uint8{}):
-				excessiveData, err := ioutil.ReadAll(i)
 				field.Set(reflect.ValueOf(excessiveData))
-				if err != nil {
-					return nil, err
-				}
 			default:
-				if err := binary.Read(i, binary.LittleEndian, dest); err != nil {
+				elemSize := int(field.Type().Elem().Size())
+				field.Set(reflect.MakeSlice(field.Type(), len(excessiveData)/elemSize, len(excessiveData)/elemSize))
+				if err := binary.Read(bytes.NewReader(excessiveData), binary.LittleEndian, dest); err != nil {
 					break loop
 				}
 			}
 		default:
-			if err := binary.Read(i, binary.LittleEndian, dest); err != nil {
+			if err := binary.Read(r, binary.LittleEndian, dest); err != nil {
 				break loop
 			}
 		}
@@ -659,3 +731,12 @@ func ParseDescriptor(data []byte) (Descriptor, error) {
 	}
 	return readDescriptor(hdr, reader)
 }
+
+// ParseDescriptors parses all descriptors in data, in the order they appear.
+func ParseDescriptors(data []byte) ([]Descriptor, error) {
+	res := make([]Descriptor, 0, 8)
+	err := ReadDescriptors(bytes.NewReader(data), func(d Descriptor) {
+		res = append(res, d)
+	})
+	return res, err
+}
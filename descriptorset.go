@@ -0,0 +1,266 @@
+package usb
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DescriptorSet is the complete set of standard descriptors of a device:
+// the device descriptor, every configuration and the BOS if the device provides one.
+// High-speed capable devices also provide a device qualifier and the other speed configurations.
+type DescriptorSet struct {
+	Device         *DeviceDescriptor
+	Configurations []*Configuration
+	BOS            *BOS
+
+	Qualifier                *DeviceQualifierDescriptor
+	OtherSpeedConfigurations []*Configuration
+}
+
+// GetDescriptorSet reads the device descriptor, all configurations and the BOS from an open device.
+// A device that does not support the BOS descriptor returns a set with a nil BOS.
+// The device qualifier and other speed configurations are read from USB 2.x devices supporting them.
+func (d *Device) GetDescriptorSet() (*DescriptorSet, error) {
+	dev, err := d.GetDeviceDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	res := &DescriptorSet{
+		Device:         dev,
+		Configurations: make([]*Configuration, 0, dev.BNumConfigurations),
+	}
+	for idx := uint8(0); idx < dev.BNumConfigurations; idx++ {
+		cfg, err := d.GetConfigurationDescriptor(idx)
+		if err != nil {
+			return nil, fmt.Errorf("configuration %d: %w", idx, err)
+		}
+		res.Configurations = append(res.Configurations, cfg)
+	}
+	if dev.BcdUSB >= 0x0201 {
+		if bos, err := d.GetBOSDescriptor(); err == nil {
+			res.BOS = bos
+		}
+	}
+	if dev.BcdUSB >= 0x0200 && dev.BcdUSB < 0x0300 {
+		if qualifier, err := d.GetDeviceQualifier(); err == nil {
+			res.Qualifier = qualifier
+			for idx := uint8(0); idx < qualifier.BNumConfigurations; idx++ {
+				cfg, err := d.GetOtherSpeedConfiguration(idx)
+				if err != nil {
+					return nil, fmt.Errorf("other speed configuration %d: %w", idx, err)
+				}
+				res.OtherSpeedConfigurations = append(res.OtherSpeedConfigurations, cfg)
+			}
+		}
+	}
+	return res, nil
+}
+
+// GetOtherSpeedDescriptorSet returns a view of how the device would look operating at its other speed.
+// See DescriptorSet.OtherSpeed.
+func (d *Device) GetOtherSpeedDescriptorSet() (*DescriptorSet, error) {
+	set, err := d.GetDescriptorSet()
+	if err != nil {
+		return nil, err
+	}
+	if set.Qualifier == nil {
+		return nil, fmt.Errorf("device has no device qualifier")
+	}
+	return set.OtherSpeed(), nil
+}
+
+// GetSysfsDescriptorSet parses the sysfs "descriptors" attribute of the device.
+// This does not require the device to be opened, but the set never contains a BOS.
+func (d *Device) GetSysfsDescriptorSet() (*DescriptorSet, error) {
+	data, err := d.readSysfsFile("descriptors")
+	if err != nil {
+		return nil, err
+	}
+	return ParseDescriptorSet(data)
+}
+
+// BuildDescriptorSet encodes a device descriptor and the configurations of builders and parses
+// the result, so every descriptor of the set has its bLength and wTotalLength filled in like a set
+// read from a device. It is mainly used to write fixtures for simulated devices and tests.
+func BuildDescriptorSet(device *DeviceDescriptor, configs ...*ConfigBuilder) (*DescriptorSet, error) {
+	data, err := device.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	for i, builder := range configs {
+		cfg, err := builder.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("configuration %d: %w", i, err)
+		}
+		data = append(data, cfg...)
+	}
+	return ParseDescriptorSet(data)
+}
+
+// ParseDescriptorSet parses a stream of concatenated descriptors, such as the sysfs "descriptors" attribute:
+// a device descriptor followed by complete configurations and optionally a BOS with its capabilities.
+func ParseDescriptorSet(data []byte) (*DescriptorSet, error) {
+	res := &DescriptorSet{
+		Configurations: make([]*Configuration, 0, 1),
+	}
+	var builder *ConfigBuilder
+	inBOS := false
+	finishConfig := func() {
+		if builder != nil {
+			builder.Configuration()
+		}
+	}
+	err := ReadDescriptors(bytes.NewReader(data), func(desc Descriptor) {
+		switch desc.(type) {
+		case *DeviceDescriptor, *ConfigurationDescriptor, *OtherSpeedConfigurationDescriptor,
+			*DeviceQualifierDescriptor, *BOSDescriptor:
+			finishConfig()
+		}
+		switch x := desc.(type) {
+		case *DeviceDescriptor:
+			res.Device = x
+			builder = nil
+			inBOS = false
+		case *ConfigurationDescriptor:
+			builder = NewConfigBuilder(x)
+			res.Configurations = append(res.Configurations, builder.Configuration())
+			inBOS = false
+		case *OtherSpeedConfigurationDescriptor:
+			builder = NewConfigBuilder(nil).Add(x)
+			res.OtherSpeedConfigurations = append(res.OtherSpeedConfigurations, builder.Configuration())
+			inBOS = false
+		case *DeviceQualifierDescriptor:
+			res.Qualifier = x
+			builder = nil
+			inBOS = false
+		case *BOSDescriptor:
+			res.BOS = &BOS{BOSDescriptor: x}
+			builder = nil
+			inBOS = true
+		default:
+			switch {
+			case inBOS:
+				res.BOS.Capabilities = append(res.BOS.Capabilities, x)
+			case builder != nil:
+				builder.Add(x)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	finishConfig()
+	if res.Device == nil {
+		return nil, fmt.Errorf("no device descriptor")
+	}
+	return res, nil
+}
+
+// MarshalBinary encodes the set in the same layout ParseDescriptorSet accepts.
+func (s *DescriptorSet) MarshalBinary() ([]byte, error) {
+	if s.Device == nil {
+		return nil, fmt.Errorf("descriptor set has no device descriptor")
+	}
+	data, err := s.Device.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range s.Configurations {
+		cfgData, err := cfg.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, cfgData...)
+	}
+	if s.BOS != nil {
+		bosData, err := s.BOS.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, bosData...)
+	}
+	if s.Qualifier != nil {
+		qualifierData, err := s.Qualifier.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, qualifierData...)
+	}
+	for _, cfg := range s.OtherSpeedConfigurations {
+		cfgData, err := cfg.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, cfgData...)
+	}
+	return data, nil
+}
+
+// OtherSpeed returns a view of the device as it would look operating at its other speed:
+// the device descriptor with the fields of the device qualifier applied and the other speed
+// configurations presented as regular configurations.
+// Returns nil if the set has no device qualifier.
+func (s *DescriptorSet) OtherSpeed() *DescriptorSet {
+	if s.Device == nil || s.Qualifier == nil {
+		return nil
+	}
+	dev := *s.Device
+	dev.BcdUSB = s.Qualifier.BcdUSB
+	dev.BDeviceClass = s.Qualifier.BDeviceClass
+	dev.BDeviceSubClass = s.Qualifier.BDeviceSubClass
+	dev.BDeviceProtocol = s.Qualifier.BDeviceProtocol
+	dev.BMaxPacketSize0 = s.Qualifier.BMaxPacketSize0
+	dev.BNumConfigurations = s.Qualifier.BNumConfigurations
+	res := &DescriptorSet{
+		Device:                   &dev,
+		Configurations:           make([]*Configuration, 0, len(s.OtherSpeedConfigurations)),
+		BOS:                      s.BOS,
+		Qualifier:                nil,
+		OtherSpeedConfigurations: nil,
+	}
+	for _, cfg := range s.OtherSpeedConfigurations {
+		cfgDesc := *cfg.ConfigurationDescriptor
+		cfgDesc.DescriptorType = DescriptorTypeConfig
+		view := *cfg
+		view.ConfigurationDescriptor = &cfgDesc
+		res.Configurations = append(res.Configurations, &view)
+	}
+	return res
+}
+
+// EnhancedSuperSpeed reports whether the device descriptor claims USB 3.0 or later.
+func (s *DescriptorSet) EnhancedSuperSpeed() bool {
+	return s.Device != nil && s.Device.BcdUSB >= 0x0300
+}
+
+// StringIndexes returns every non-zero string index referenced by the device descriptor,
+// configurations, interface associations and interfaces, in order of first reference.
+func (s *DescriptorSet) StringIndexes() []uint8 {
+	seen := make(map[uint8]bool)
+	res := make([]uint8, 0, 8)
+	add := func(idx uint8) {
+		if idx != 0 && !seen[idx] {
+			seen[idx] = true
+			res = append(res, idx)
+		}
+	}
+	if s.Device != nil {
+		add(s.Device.IManufacturer)
+		add(s.Device.IProduct)
+		add(s.Device.ISerialNumber)
+	}
+	for _, configurations := range [][]*Configuration{s.Configurations, s.OtherSpeedConfigurations} {
+		for _, cfg := range configurations {
+			add(cfg.IConfiguration)
+			for _, iface := range cfg.Interfaces {
+				if iface.Association != nil {
+					add(iface.Association.IFunction)
+				}
+				for _, alt := range iface.AltSettings {
+					add(alt.IInterface)
+				}
+			}
+		}
+	}
+	return res
+}
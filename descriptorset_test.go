@@ -0,0 +1,127 @@
+package usb_test
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+// highSpeed returns a high-speed bulk device with 512 byte endpoints, and at full speed a device
+// qualifier and an other speed configuration with 64 byte endpoints.
+func highSpeed(t *testing.T) *sim.Device {
+	config := func(maxPacketSize uint16) *usb.ConfigBuilder {
+		return usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: maxPacketSize}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x02, BmAttributes: 2, WMaxPacketSize: maxPacketSize})
+	}
+	device := &usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1}
+	data, err := device.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, builder := range []*usb.ConfigBuilder{config(512), config(64)} {
+		cfg, err := builder.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The second configuration is the other speed configuration.
+		if i == 1 {
+			cfg[1] = byte(usb.DescriptorTypeOtherSpeedConfiguration)
+		}
+		data = append(data, cfg...)
+	}
+	qualifier, err := (&usb.DeviceQualifierDescriptor{BcdUSB: 0x0200, BDeviceClass: usb.ClassCodeVendorSpecific, BMaxPacketSize0: 8, BNumConfigurations: 1}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := usb.ParseDescriptorSet(append(data, qualifier...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Configurations) != 1 || len(set.OtherSpeedConfigurations) != 1 || set.Qualifier == nil {
+		t.Fatalf("unexpected fixture %+v", set)
+	}
+	return sim.New(set)
+}
+
+func openSim(t *testing.T, simDev *sim.Device) *usb.Device {
+	dev := simDev.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dev.Close() })
+	return dev
+}
+
+func TestOtherSpeed(t *testing.T) {
+	dev := openSim(t, highSpeed(t))
+	qualifier, err := dev.GetDeviceQualifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qualifier.Length != 10 || qualifier.DescriptorType != usb.DescriptorTypeDeviceQualifier || qualifier.BcdUSB != 0x0200 ||
+		qualifier.BDeviceClass != usb.ClassCodeVendorSpecific || qualifier.BMaxPacketSize0 != 8 || qualifier.BNumConfigurations != 1 {
+		t.Fatalf("unexpected qualifier %+v", qualifier)
+	}
+	cfg, err := dev.GetOtherSpeedConfiguration(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsOtherSpeed() || cfg.WTotalLength != 32 || len(cfg.Interfaces) != 1 ||
+		cfg.Interfaces[0].AltSettings[0].Endpoints[0].WMaxPacketSize != 64 {
+		t.Fatalf("unexpected other speed configuration %+v", cfg)
+	}
+	if _, err := dev.GetOtherSpeedConfiguration(1); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for a missing other speed configuration, got %v", err)
+	}
+
+	set, err := dev.GetDescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Qualifier == nil || len(set.OtherSpeedConfigurations) != 1 || set.Configurations[0].IsOtherSpeed() {
+		t.Fatalf("unexpected descriptor set %+v", set)
+	}
+	other, err := dev.GetOtherSpeedDescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Device.BMaxPacketSize0 != 8 || other.Device.BDeviceClass != usb.ClassCodeVendorSpecific || other.Device.IDVendor != 0x1209 ||
+		other.Qualifier != nil || other.OtherSpeedConfigurations != nil || len(other.Configurations) != 1 {
+		t.Fatalf("unexpected other speed view %+v", other)
+	}
+	view := other.Configurations[0]
+	if view.IsOtherSpeed() || view.DescriptorType != usb.DescriptorTypeConfig || view.Interfaces[0].AltSettings[0].Endpoints[1].WMaxPacketSize != 64 {
+		t.Fatalf("unexpected configuration in the other speed view %+v", view)
+	}
+	if set.Device.BMaxPacketSize0 != 64 || !set.OtherSpeedConfigurations[0].IsOtherSpeed() {
+		t.Fatal("OtherSpeed modified the original set")
+	}
+}
+
+func TestNoQualifier(t *testing.T) {
+	simDev, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := openSim(t, simDev)
+	if _, err := dev.GetDeviceQualifier(); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for a full-speed only device, got %v", err)
+	}
+	if _, err := dev.GetOtherSpeedConfiguration(0); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for the other speed configuration, got %v", err)
+	}
+	set, err := dev.GetDescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Qualifier != nil || set.OtherSpeedConfigurations != nil || set.OtherSpeed() != nil {
+		t.Fatalf("unexpected descriptor set %+v", set)
+	}
+	if _, err := dev.GetOtherSpeedDescriptorSet(); err == nil {
+		t.Fatal("expected an error for a device without device qualifier")
+	}
+}
@@ -3,7 +3,8 @@ package usb
 import (
 	"fmt"
 	"github.com/daedaluz/gousb/usbfs"
-	"syscall"
+	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -12,59 +13,217 @@ const (
 
 type (
 	Device struct {
-		fd           int
 		BusNumber    int
 		DeviceNumber int
 		Name         string
+
+		// backend is nil while the device is closed.
+		backend Backend
+
+		// open and sysfs are set for virtual devices, see NewVirtualDevice.
+		open  func() (Backend, error)
+		sysfs func(attr string) ([]byte, error)
+
+		// capture holds the captureHook seeing every transfer, see SetCapture.
+		capture atomic.Value
+
+		stringLock  sync.Mutex
+		stringCache map[stringKey]string
+		languages   []LangID
+
+		// detached are the interfaces whose kernel driver was detached by ClaimFunction.
+		detachLock sync.Mutex
+		detached   map[uint8]bool
+	}
+
+	// usbfsBackend is the Backend of devices opened through /dev/bus/usb.
+	usbfsBackend struct {
+		fd int
 	}
 )
 
 func (d *Device) Open() error {
-	if d.fd != -1 {
+	if d.backend != nil {
 		return fmt.Errorf("device already open")
 	}
+	if d.open != nil {
+		backend, err := d.open()
+		if err != nil {
+			return err
+		}
+		d.backend = backend
+		return nil
+	}
 	fd, err := usbfs.OpenDevice(d.BusNumber, d.DeviceNumber)
 	if err != nil {
 		return err
 	}
-	d.fd = fd
+	d.backend = &usbfsBackend{fd: fd}
 	return nil
 }
 
 func (d *Device) IsOpen() bool {
-	return d.fd != -1
+	return d.backend != nil
+}
+
+// Backend returns the backend of an open device, nil if the device is closed.
+func (d *Device) Backend() Backend {
+	return d.backend
+}
+
+func (d *Device) kernelDriver() (KernelDriverBackend, error) {
+	if d.backend == nil {
+		return nil, ErrNotOpen
+	}
+	if kernel, ok := d.backend.(KernelDriverBackend); ok {
+		return kernel, nil
+	}
+	return nil, ErrNotSupported
 }
 
 func (d *Device) GetDriver(iface uint32) (string, error) {
-	return usbfs.GetDriver(d.fd, iface)
+	kernel, err := d.kernelDriver()
+	if err != nil {
+		return "", err
+	}
+	return kernel.GetDriver(iface)
 }
 
 func (d *Device) DetachKernel(iface uint32) error {
-	return usbfs.Disconnect(d.fd, iface)
+	kernel, err := d.kernelDriver()
+	if err != nil {
+		return err
+	}
+	return kernel.DetachKernel(iface)
 }
 
 func (d *Device) AttachKernel(iface uint32) error {
-	return usbfs.Connect(d.fd, iface)
+	kernel, err := d.kernelDriver()
+	if err != nil {
+		return err
+	}
+	return kernel.AttachKernel(iface)
+}
+
+// ClaimInterface claims the interface for this file handle.
+// An interface bound to a kernel driver must be detached first, see DetachKernel.
+func (d *Device) ClaimInterface(iface uint8) error {
+	if d.backend == nil {
+		return ErrNotOpen
+	}
+	return d.backend.ClaimInterface(iface)
+}
+
+// ReleaseInterface releases an interface claimed with ClaimInterface.
+func (d *Device) ReleaseInterface(iface uint8) error {
+	if d.backend == nil {
+		return ErrNotOpen
+	}
+	return d.backend.ReleaseInterface(iface)
 }
 
 func (d *Device) Ctrl(typ RequestType, req uint8, value uint16, index uint16, payload []byte) (int, error) {
-	return usbfs.ControlTransfer(d.fd, uint8(typ), req, value, index, 1000, payload)
+	return d.CtrlTimeout(typ, req, value, index, payload, 1000)
 }
 
 func (d *Device) CtrlTimeout(typ RequestType, req uint8, value uint16, index uint16, payload []byte, timeout uint32) (int, error) {
-	return usbfs.ControlTransfer(d.fd, uint8(typ), req, value, index, timeout, payload)
+	if d.backend == nil {
+		return 0, ErrNotOpen
+	}
+	setup := SetupPacket{RequestType: typ, Request: req, Value: value, Index: index, Length: uint16(len(payload))}
+	return d.captureTransfer(TransferTypeControl, uint8(typ&RequestDirectionIn), setup, payload, func() (int, error) {
+		return d.backend.Control(setup, payload, timeout)
+	})
 }
 
 func (d *Device) Bulk(ep uint8, data []byte) (int, error) {
-	return usbfs.BulkTransfer(d.fd, uint32(ep)&0xFF, 1000, data)
+	return d.BulkTimeout(ep, data, 1000)
 }
 
 func (d *Device) BulkTimeout(ep uint8, data []byte, timeout uint32) (int, error) {
-	return usbfs.BulkTransfer(d.fd, uint32(ep)&0xFF, timeout, data)
+	if d.backend == nil {
+		return 0, ErrNotOpen
+	}
+	return d.captureTransfer(TransferTypeBulk, ep, SetupPacket{}, data, func() (int, error) {
+		return d.backend.Bulk(ep, data, timeout)
+	})
+}
+
+func (d *Device) Interrupt(ep uint8, data []byte) (int, error) {
+	return d.InterruptTimeout(ep, data, 1000)
+}
+
+func (d *Device) InterruptTimeout(ep uint8, data []byte, timeout uint32) (int, error) {
+	if d.backend == nil {
+		return 0, ErrNotOpen
+	}
+	return d.captureTransfer(TransferTypeInterrupt, ep, SetupPacket{}, data, func() (int, error) {
+		return d.backend.Interrupt(ep, data, timeout)
+	})
+}
+
+// Reset performs a port reset, the device keeps its address but has to be configured again.
+func (d *Device) Reset() error {
+	if d.backend == nil {
+		return ErrNotOpen
+	}
+	return d.backend.Reset()
 }
 
 func (d *Device) Close() error {
-	e := syscall.Close(d.fd)
-	d.fd = -1
+	if d.backend == nil {
+		return ErrNotOpen
+	}
+	e := d.backend.Close()
+	d.backend = nil
 	return e
 }
+
+func (b *usbfsBackend) Control(setup SetupPacket, data []byte, timeout uint32) (int, error) {
+	return usbfs.ControlTransfer(b.fd, uint8(setup.RequestType), setup.Request, setup.Value, setup.Index, timeout, data)
+}
+
+func (b *usbfsBackend) Bulk(ep uint8, data []byte, timeout uint32) (int, error) {
+	return usbfs.BulkTransfer(b.fd, uint32(ep), timeout, data)
+}
+
+// Interrupt uses the bulk ioctl, usbfs performs the transfer type of the endpoint.
+func (b *usbfsBackend) Interrupt(ep uint8, data []byte, timeout uint32) (int, error) {
+	return usbfs.BulkTransfer(b.fd, uint32(ep), timeout, data)
+}
+
+func (b *usbfsBackend) ClaimInterface(iface uint8) error {
+	return usbfs.ClaimInterface(b.fd, uint32(iface))
+}
+
+func (b *usbfsBackend) ReleaseInterface(iface uint8) error {
+	return usbfs.ReleaseInterface(b.fd, uint32(iface))
+}
+
+func (b *usbfsBackend) Reset() error {
+	return usbfs.ResetDevice(b.fd)
+}
+
+func (b *usbfsBackend) Close() error {
+	return usbfs.CloseDevice(b.fd)
+}
+
+func (b *usbfsBackend) SetConfiguration(value int) error {
+	return usbfs.SetConfiguration(b.fd, value)
+}
+
+func (b *usbfsBackend) ClearHalt(ep uint8) error {
+	return usbfs.ClearHalt(b.fd, uint32(ep))
+}
+
+func (b *usbfsBackend) GetDriver(iface uint32) (string, error) {
+	return usbfs.GetDriver(b.fd, iface)
+}
+
+func (b *usbfsBackend) DetachKernel(iface uint32) error {
+	return usbfs.Disconnect(b.fd, iface)
+}
+
+func (b *usbfsBackend) AttachKernel(iface uint32) error {
+	return usbfs.Connect(b.fd, iface)
+}
@@ -0,0 +1,348 @@
+// Package diff compares two descriptor sets and reports semantic differences,
+// such as an endpoint changing its wMaxPacketSize or an interface gaining an alternate setting.
+//
+// Elements of the descriptor tree are matched by their identity rather than their position:
+// configurations by bConfigurationValue, interfaces by number, alternate settings by
+// bAlternateSetting, endpoints by address and capabilities by their type.
+package diff
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Kind is the kind of a difference.
+type Kind uint8
+
+const (
+	Changed = Kind(iota)
+	Added
+	Removed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Difference is a single difference between two descriptor sets.
+type Difference struct {
+	// Path locates the element in the descriptor tree, eg. ["configuration 1", "interface 0", "endpoint 0x81"].
+	Path []string
+	Kind Kind
+
+	// Field, Old and New describe a changed field, they are empty for added and removed elements.
+	Field string
+	Old   string
+	New   string
+}
+
+func (d Difference) String() string {
+	path := strings.Join(d.Path, " / ")
+	if d.Kind == Changed {
+		return fmt.Sprintf("%s: %s %s -> %s", path, d.Field, d.Old, d.New)
+	}
+	return fmt.Sprintf("%s: %v", path, d.Kind)
+}
+
+// skipFields are fields derived from the shape of the tree, changing them is reported as added or removed elements.
+var skipFields = map[string]bool{
+	"WTotalLength":   true,
+	"BNumInterfaces": true,
+	"BNumEndpoints":  true,
+	"BNumDeviceCaps": true,
+}
+
+// Compare returns the differences going from old to new in tree order.
+func Compare(old, new *usb.DescriptorSet) []Difference {
+	d := &differ{}
+	d.descriptor([]string{"device"}, old.Device, new.Device)
+	d.configurations("configuration", nil, old.Configurations, new.Configurations)
+	d.bos(old.BOS, new.BOS)
+	switch path := []string{"device qualifier"}; {
+	case old.Qualifier == nil && new.Qualifier == nil:
+	case old.Qualifier == nil:
+		d.add(path, Added)
+	case new.Qualifier == nil:
+		d.add(path, Removed)
+	default:
+		d.descriptor(path, old.Qualifier, new.Qualifier)
+	}
+	d.configurations("other speed configuration", nil, old.OtherSpeedConfigurations, new.OtherSpeedConfigurations)
+	return d.res
+}
+
+// Write writes one difference per line.
+func Write(w io.Writer, differences []Difference) error {
+	for _, difference := range differences {
+		if _, err := fmt.Fprintln(w, difference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type differ struct {
+	res []Difference
+}
+
+func (d *differ) add(path []string, kind Kind) {
+	d.res = append(d.res, Difference{Path: path, Kind: kind})
+}
+
+// sub returns a copy of path with elem appended.
+func sub(path []string, elem string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), elem)
+}
+
+// match pairs elements by key, keeping the order of old followed by the elements only present in new.
+// Unpaired elements are reported as added or removed, pairs are passed to fn.
+func (d *differ) match(path []string, oldKeys, newKeys []string, fn func(path []string, i, j int)) {
+	newIndex := make(map[string]int, len(newKeys))
+	for j, key := range newKeys {
+		newIndex[key] = j
+	}
+	oldIndex := make(map[string]int, len(oldKeys))
+	for i, key := range oldKeys {
+		oldIndex[key] = i
+		if j, exist := newIndex[key]; exist {
+			fn(sub(path, key), i, j)
+		} else {
+			d.add(sub(path, key), Removed)
+		}
+	}
+	for _, key := range newKeys {
+		if _, exist := oldIndex[key]; !exist {
+			d.add(sub(path, key), Added)
+		}
+	}
+}
+
+func (d *differ) configurations(name string, path []string, old, new []*usb.Configuration) {
+	keys := func(configs []*usb.Configuration) []string {
+		res := make([]string, len(configs))
+		for i, cfg := range configs {
+			res[i] = fmt.Sprintf("%s %d", name, cfg.BConfigurationValue)
+		}
+		return res
+	}
+	d.match(path, keys(old), keys(new), func(path []string, i, j int) {
+		d.configuration(path, old[i], new[j])
+	})
+}
+
+func (d *differ) configuration(path []string, old, new *usb.Configuration) {
+	d.descriptor(path, old.ConfigurationDescriptor, new.ConfigurationDescriptor)
+	d.extra(path, old.Extra, new.Extra)
+	keys := func(ifaces []*usb.Interface) []string {
+		res := make([]string, len(ifaces))
+		for i, iface := range ifaces {
+			res[i] = fmt.Sprintf("interface %d", iface.Number)
+		}
+		return res
+	}
+	d.match(path, keys(old.Interfaces), keys(new.Interfaces), func(path []string, i, j int) {
+		d.iface(path, old.Interfaces[i], new.Interfaces[j])
+	})
+}
+
+func (d *differ) iface(path []string, old, new *usb.Interface) {
+	switch assocPath := sub(path, "interface association"); {
+	case old.Association == nil && new.Association == nil:
+	case old.Association == nil:
+		d.add(assocPath, Added)
+	case new.Association == nil:
+		d.add(assocPath, Removed)
+	default:
+		d.descriptor(assocPath, old.Association, new.Association)
+	}
+	keys := func(alts []*usb.AltSetting) []string {
+		res := make([]string, len(alts))
+		for i, alt := range alts {
+			res[i] = fmt.Sprintf("alt setting %d", alt.BAlternateSetting)
+		}
+		return res
+	}
+	d.match(path, keys(old.AltSettings), keys(new.AltSettings), func(path []string, i, j int) {
+		d.altSetting(path, old.AltSettings[i], new.AltSettings[j])
+	})
+}
+
+func (d *differ) altSetting(path []string, old, new *usb.AltSetting) {
+	d.descriptor(path, old.InterfaceDescriptor, new.InterfaceDescriptor)
+	d.extra(path, old.Extra, new.Extra)
+	keys := func(endpoints []*usb.Endpoint) []string {
+		res := make([]string, len(endpoints))
+		for i, ep := range endpoints {
+			res[i] = fmt.Sprintf("endpoint 0x%.2x", ep.BEndpointAddress)
+		}
+		return res
+	}
+	d.match(path, keys(old.Endpoints), keys(new.Endpoints), func(path []string, i, j int) {
+		d.endpoint(path, old.Endpoints[i], new.Endpoints[j])
+	})
+}
+
+func (d *differ) endpoint(path []string, old, new *usb.Endpoint) {
+	d.descriptor(path, old.EndpointDescriptor, new.EndpointDescriptor)
+	var oldCompanions, newCompanions []usb.Descriptor
+	if old.SSCompanion != nil {
+		oldCompanions = append(oldCompanions, old.SSCompanion)
+	}
+	if old.SSPIsoCompanion != nil {
+		oldCompanions = append(oldCompanions, old.SSPIsoCompanion)
+	}
+	if new.SSCompanion != nil {
+		newCompanions = append(newCompanions, new.SSCompanion)
+	}
+	if new.SSPIsoCompanion != nil {
+		newCompanions = append(newCompanions, new.SSPIsoCompanion)
+	}
+	d.extra(path, oldCompanions, newCompanions)
+	d.extra(path, old.Extra, new.Extra)
+}
+
+func (d *differ) bos(old, new *usb.BOS) {
+	path := []string{"BOS"}
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		d.add(path, Added)
+		return
+	case new == nil:
+		d.add(path, Removed)
+		return
+	}
+	d.descriptor(path, old.BOSDescriptor, new.BOSDescriptor)
+	d.list(path, old.Capabilities, new.Capabilities, capabilityName)
+}
+
+// extra compares class- and vendor-specific descriptors, matched by type and order of appearance.
+func (d *differ) extra(path []string, old, new []usb.Descriptor) {
+	d.list(path, old, new, func(desc usb.Descriptor) string {
+		return fmt.Sprintf("%v descriptor", desc.Type())
+	})
+}
+
+// list compares descriptors named by name, the n:th occurrence of a name is matched with the n:th in the other list.
+func (d *differ) list(path []string, old, new []usb.Descriptor, name func(usb.Descriptor) string) {
+	keys := func(descriptors []usb.Descriptor) []string {
+		count := make(map[string]int)
+		res := make([]string, len(descriptors))
+		for i, desc := range descriptors {
+			key := name(desc)
+			if n := count[key]; n > 0 {
+				res[i] = fmt.Sprintf("%s #%d", key, n+1)
+			} else {
+				res[i] = key
+			}
+			count[key]++
+		}
+		return res
+	}
+	d.match(path, keys(old), keys(new), func(path []string, i, j int) {
+		d.descriptor(path, old[i], new[j])
+	})
+}
+
+func capabilityName(desc usb.Descriptor) string {
+	if platform, ok := desc.(*usb.CapPlatformDescriptor); ok {
+		return fmt.Sprintf("platform capability %s", platform.UUIDString())
+	}
+	if f := reflect.Indirect(reflect.ValueOf(desc)).FieldByName("BDevCapabilityType"); f.IsValid() {
+		return fmt.Sprintf("%v capability", f.Interface())
+	}
+	return fmt.Sprintf("%v descriptor", desc.Type())
+}
+
+// descriptor compares two descriptors field by field. Descriptors of different types are
+// reported as the old one being removed and the new one added.
+func (d *differ) descriptor(path []string, old, new usb.Descriptor) {
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	if oldValue.Type() != newValue.Type() {
+		d.add(path, Removed)
+		d.add(path, Added)
+		return
+	}
+	d.fields(path, oldValue, newValue)
+}
+
+func (d *differ) fields(path []string, old, new reflect.Value) {
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || skipFields[field.Name] {
+			continue
+		}
+		oldField, newField := old.Field(i), new.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			d.fields(path, oldField, newField)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		name := fieldName(field.Name)
+		d.res = append(d.res, Difference{
+			Path:  path,
+			Kind:  Changed,
+			Field: name,
+			Old:   formatValue(name, oldField),
+			New:   formatValue(name, newField),
+		})
+	}
+}
+
+// fieldName converts a Go field name to the descriptor field name, WMaxPacketSize -> wMaxPacketSize.
+func fieldName(name string) string {
+	switch name {
+	case "Length":
+		return "bLength"
+	case "DescriptorType":
+		return "bDescriptorType"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// formatValue formats a field the way the USB specification does, BCD as version numbers,
+// IDs and bitmaps in hex and everything else in decimal unless the type names its values.
+func formatValue(name string, v reflect.Value) string {
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value := v.Uint()
+		switch {
+		case strings.HasPrefix(name, "bcd"):
+			return fmt.Sprintf("%x.%.2x", value>>8, value&0xFF)
+		case strings.HasPrefix(name, "id"):
+			return fmt.Sprintf("%.4x", value)
+		case strings.HasPrefix(name, "bm"), name == "bEndpointAddress":
+			return fmt.Sprintf("0x%.2x", value)
+		}
+		return fmt.Sprint(value)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(data), v)
+			return fmt.Sprintf("[% x]", data)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,39 @@
+package diff
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"testing"
+)
+
+func testSet(t *testing.T, packetSize uint16, alts int) *usb.DescriptorSet {
+	builder := usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+		Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeInterfaceHID}).
+		Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: packetSize, BInterval: 4})
+	for alt := 1; alt < alts; alt++ {
+		builder.Interface(&usb.InterfaceDescriptor{BAlternateSetting: uint8(alt), BInterfaceClass: usb.ClassCodeInterfaceHID})
+	}
+	set, err := usb.BuildDescriptorSet(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1d6b, IDProduct: 0x0104, BNumConfigurations: 1}, builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestCompare(t *testing.T) {
+	if differences := Compare(testSet(t, 8, 1), testSet(t, 8, 1)); len(differences) != 0 {
+		t.Fatalf("expected no differences, got %v", differences)
+	}
+	expected := []string{
+		"configuration 1 / interface 0 / alt setting 0 / endpoint 0x81: wMaxPacketSize 8 -> 64",
+		"configuration 1 / interface 0 / alt setting 1: added",
+	}
+	differences := Compare(testSet(t, 8, 1), testSet(t, 64, 2))
+	if len(differences) != len(expected) {
+		t.Fatalf("expected %d differences, got %v", len(expected), differences)
+	}
+	for i, difference := range differences {
+		if difference.String() != expected[i] {
+			t.Errorf("difference %d: expected %q, got %q", i, expected[i], difference.String())
+		}
+	}
+}
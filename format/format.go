@@ -0,0 +1,212 @@
+// Package format renders descriptor sets as lsusb -v style text, JSON and YAML.
+//
+// The input is an Info, which is collected from a live Device with FromDevice
+// or parsed from raw descriptor bytes with FromBytes.
+package format
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/ids"
+)
+
+// Speed is the speed a device operates at.
+type Speed uint8
+
+const (
+	SpeedUnknown = Speed(iota)
+	SpeedLow
+	SpeedFull
+	SpeedHigh
+	SpeedSuper
+	SpeedSuperPlus
+)
+
+func (s Speed) String() string {
+	switch s {
+	case SpeedLow:
+		return "low"
+	case SpeedFull:
+		return "full"
+	case SpeedHigh:
+		return "high"
+	case SpeedSuper:
+		return "super"
+	case SpeedSuperPlus:
+		return "super-plus"
+	}
+	return "unknown"
+}
+
+// ParseSysfsSpeed converts the sysfs "speed" attribute (in Mbit/s) to a Speed.
+func ParseSysfsSpeed(speed string) Speed {
+	switch speed {
+	case "1.5":
+		return SpeedLow
+	case "12":
+		return SpeedFull
+	case "480":
+		return SpeedHigh
+	case "5000":
+		return SpeedSuper
+	case "10000", "20000":
+		return SpeedSuperPlus
+	}
+	return SpeedUnknown
+}
+
+// Sysfs is the location of a device as seen in sysfs.
+type Sysfs struct {
+	Name         string `json:"name"`
+	BusNumber    int    `json:"bus"`
+	DeviceNumber int    `json:"device"`
+}
+
+// Info is everything rendered by the formatters.
+type Info struct {
+	Set *usb.DescriptorSet
+
+	// Strings holds the string descriptors by index, missing strings are rendered empty.
+	Strings map[uint8]string
+
+	// Speed is the operating speed, if unknown it is guessed from bcdUSB.
+	Speed Speed
+
+	// Sysfs is nil when the info was not collected from a device.
+	Sysfs *Sysfs
+}
+
+// FromBytes parses raw descriptors as accepted by usb.ParseDescriptorSet.
+func FromBytes(data []byte) (*Info, error) {
+	set, err := usb.ParseDescriptorSet(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{Set: set, Strings: make(map[uint8]string)}, nil
+}
+
+// FromDevice collects the descriptors of a device.
+// An open device is queried directly, including its BOS and strings, otherwise the
+// sysfs descriptors and string attributes are used.
+func FromDevice(dev *usb.Device) (*Info, error) {
+	res := &Info{
+		Strings: make(map[uint8]string),
+		Sysfs: &Sysfs{
+			Name:         dev.Name,
+			BusNumber:    dev.BusNumber,
+			DeviceNumber: dev.DeviceNumber,
+		},
+	}
+	if speed, err := dev.ReadSysfsString("speed"); err == nil {
+		res.Speed = ParseSysfsSpeed(speed)
+	}
+	if dev.IsOpen() {
+		set, err := dev.GetDescriptorSet()
+		if err != nil {
+			return nil, err
+		}
+		res.Set = set
+		for _, idx := range set.StringIndexes() {
+			if str, err := dev.GetString(idx); err == nil {
+				res.Strings[idx] = str
+			}
+		}
+		return res, nil
+	}
+	set, err := dev.GetSysfsDescriptorSet()
+	if err != nil {
+		return nil, err
+	}
+	res.Set = set
+	for attr, idx := range map[string]uint8{
+		"manufacturer": set.Device.IManufacturer,
+		"product":      set.Device.IProduct,
+		"serial":       set.Device.ISerialNumber,
+	} {
+		if str, err := dev.ReadSysfsString(attr); err == nil && idx != 0 {
+			res.Strings[idx] = str
+		}
+	}
+	return res, nil
+}
+
+// speed returns the operating speed, guessing from bcdUSB if unknown.
+// A USB 2.x device is assumed to run at high speed.
+func (info *Info) speed() Speed {
+	if info.Speed != SpeedUnknown {
+		return info.Speed
+	}
+	switch bcd := info.Set.Device.BcdUSB; {
+	case bcd >= 0x0320:
+		return SpeedSuperPlus
+	case bcd >= 0x0300:
+		return SpeedSuper
+	case bcd >= 0x0200:
+		return SpeedHigh
+	}
+	return SpeedFull
+}
+
+// Options control the names used by the formatters.
+type Options struct {
+	// IDs is used to name vendors, products and classes, ids.Default() if nil.
+	IDs *ids.Database
+}
+
+func (o *Options) ids() *ids.Database {
+	if o == nil || o.IDs == nil {
+		return ids.Default()
+	}
+	return o.IDs
+}
+
+// className names a class triple level by level, preferring usb.ids and falling back to
+// the USB-IF defined class codes.
+func className(db *ids.Database, triple usb.ClassTriple) (class, subClass, protocol string) {
+	class = db.Class(triple.Class)
+	if class == "" {
+		class = usb.ClassTriple{Class: triple.Class}.Name()
+	}
+	return class, db.SubClass(triple.Class, triple.SubClass), db.Protocol(triple.Class, triple.SubClass, triple.Protocol)
+}
+
+func formatBCD(bcd uint16) string {
+	return fmt.Sprintf("%x.%.2x", bcd>>8, bcd&0xFF)
+}
+
+// maxPowerMilliAmps returns bMaxPower in mA, the unit is 8mA when operating at SuperSpeed and 2mA otherwise.
+func maxPowerMilliAmps(maxPower uint8, speed Speed) int {
+	if speed >= SpeedSuper {
+		return int(maxPower) * 8
+	}
+	return int(maxPower) * 2
+}
+
+// packetSize splits wMaxPacketSize in the number of transactions per microframe and the packet size.
+func packetSize(ep *usb.EndpointDescriptor) (int, int) {
+	return int(ep.WMaxPacketSize>>11&0x03) + 1, int(ep.WMaxPacketSize & 0x7FF)
+}
+
+// intervalMicroseconds decodes bInterval, 0 if the interval is not meaningful for the endpoint.
+func intervalMicroseconds(ep *usb.EndpointDescriptor, speed Speed) int {
+	if ep.BInterval == 0 {
+		return 0
+	}
+	switch ep.TransferType() {
+	case usb.TransferTypeInterrupt:
+		if speed <= SpeedFull {
+			return int(ep.BInterval) * 1000
+		}
+		fallthrough
+	case usb.TransferTypeIsochronous:
+		if ep.BInterval > 16 {
+			return 0
+		}
+		unit := 125
+		if speed <= SpeedFull {
+			unit = 1000
+		}
+		return unit << (ep.BInterval - 1)
+	}
+	return 0
+}
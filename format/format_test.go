@@ -0,0 +1,98 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/ids"
+	"strings"
+	"testing"
+)
+
+func testInfo(t *testing.T) *Info {
+	set, err := usb.BuildDescriptorSet(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1d6b, IDProduct: 0x0104, IProduct: 1, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0xA0, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeInterfaceHID, BInterfaceSubClass: 1, BInterfaceProtocol: 1}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := set.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Strings[1] = "Gadget"
+	return info
+}
+
+func TestText(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := Text(out, testInfo(t), &Options{IDs: ids.Embedded()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"1d6b:0104 Linux Foundation Multifunction Composite Gadget\n",
+		"  idVendor           0x1d6b Linux Foundation\n",
+		"  iProduct                1 Gadget\n",
+		"    MaxPower              100mA\n",
+		"      Remote Wakeup\n",
+		"      bInterfaceProtocol      1 Keyboard\n",
+		"        bEndpointAddress     0x81  EP 1 IN\n",
+		"        wMaxPacketSize     0x0008  1x 8 bytes\n",
+		"        bInterval               4 1ms\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("missing %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestJSONAndYAML(t *testing.T) {
+	info := testInfo(t)
+	out := &bytes.Buffer{}
+	if err := JSON(out, info, &Options{IDs: ids.Embedded()}); err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Schema != SchemaVersion || doc.Configurations[0].Interfaces[0].AltSettings[0].Endpoints[0].IntervalUS != 1000 {
+		t.Fatalf("unexpected document %s", out)
+	}
+	out.Reset()
+	if err := YAML(out, info, &Options{IDs: ids.Embedded()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"schema: 1\n",
+		"configurations:\n  - value: 1\n",
+		"endpoints:\n" + strings.Repeat(" ", 14) + "- address: \"0x81\"\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("missing %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestDescriptors(t *testing.T) {
+	out := &bytes.Buffer{}
+	ep := &usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 4}
+	ep.Length, ep.DescriptorType = 7, usb.DescriptorTypeEndpoint
+	if err := Descriptors(out, []usb.Descriptor{ep}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"usb.EndpointDescriptor Descriptor:\n",
+		"  bEndpointAddress      129\n",
+		"  wMaxPacketSize          8\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("missing %q in\n%s", expected, out)
+		}
+	}
+}
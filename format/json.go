@@ -0,0 +1,333 @@
+package format
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/ids"
+	"io"
+)
+
+// SchemaVersion is incremented whenever a field of Document is renamed or removed.
+// Fields are only added within a version.
+const SchemaVersion = 1
+
+type (
+	// Document is the JSON representation of an Info.
+	Document struct {
+		Schema                   int            `json:"schema"`
+		Sysfs                    *Sysfs         `json:"sysfs,omitempty"`
+		Speed                    string         `json:"speed"`
+		Device                   *DeviceJSON    `json:"device"`
+		Configurations           []*ConfigJSON  `json:"configurations"`
+		BOS                      *BOSJSON       `json:"bos,omitempty"`
+		Qualifier                *QualifierJSON `json:"qualifier,omitempty"`
+		OtherSpeedConfigurations []*ConfigJSON  `json:"other_speed_configurations,omitempty"`
+	}
+
+	// IDJSON is a numeric id in hex with its name.
+	IDJSON struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+	}
+
+	// StringJSON is a string descriptor index with its value.
+	StringJSON struct {
+		Index uint8  `json:"index"`
+		Value string `json:"value,omitempty"`
+	}
+
+	// ClassJSON is a class triple with the names of each level and the USB-IF name of the triple.
+	ClassJSON struct {
+		Class        uint8  `json:"class"`
+		SubClass     uint8  `json:"subclass"`
+		Protocol     uint8  `json:"protocol"`
+		ClassName    string `json:"class_name,omitempty"`
+		SubClassName string `json:"subclass_name,omitempty"`
+		ProtocolName string `json:"protocol_name,omitempty"`
+		Defined      string `json:"defined,omitempty"`
+	}
+
+	DeviceJSON struct {
+		USBVersion        string      `json:"usb_version"`
+		Class             *ClassJSON  `json:"class"`
+		MaxPacketSize0    uint8       `json:"max_packet_size0"`
+		Vendor            *IDJSON     `json:"vendor"`
+		Product           *IDJSON     `json:"product"`
+		DeviceVersion     string      `json:"device_version"`
+		Manufacturer      *StringJSON `json:"manufacturer"`
+		ProductString     *StringJSON `json:"product_string"`
+		SerialNumber      *StringJSON `json:"serial_number"`
+		NumConfigurations uint8       `json:"num_configurations"`
+	}
+
+	QualifierJSON struct {
+		USBVersion        string     `json:"usb_version"`
+		Class             *ClassJSON `json:"class"`
+		MaxPacketSize0    uint8      `json:"max_packet_size0"`
+		NumConfigurations uint8      `json:"num_configurations"`
+	}
+
+	ConfigJSON struct {
+		Value         uint8            `json:"value"`
+		String        *StringJSON      `json:"string"`
+		TotalLength   uint16           `json:"total_length"`
+		NumInterfaces uint8            `json:"num_interfaces"`
+		Attributes    uint8            `json:"attributes"`
+		SelfPowered   bool             `json:"self_powered"`
+		RemoteWakeup  bool             `json:"remote_wakeup"`
+		MaxPowerMA    int              `json:"max_power_ma"`
+		Interfaces    []*InterfaceJSON `json:"interfaces"`
+		Extra         []*UnknownJSON   `json:"extra,omitempty"`
+	}
+
+	AssociationJSON struct {
+		FirstInterface uint8       `json:"first_interface"`
+		InterfaceCount uint8       `json:"interface_count"`
+		Class          *ClassJSON  `json:"class"`
+		String         *StringJSON `json:"string"`
+	}
+
+	InterfaceJSON struct {
+		Number      uint8             `json:"number"`
+		Association *AssociationJSON  `json:"association,omitempty"`
+		AltSettings []*AltSettingJSON `json:"alt_settings"`
+	}
+
+	AltSettingJSON struct {
+		AlternateSetting uint8           `json:"alternate_setting"`
+		NumEndpoints     uint8           `json:"num_endpoints"`
+		Class            *ClassJSON      `json:"class"`
+		String           *StringJSON     `json:"string"`
+		Endpoints        []*EndpointJSON `json:"endpoints"`
+		Extra            []*UnknownJSON  `json:"extra,omitempty"`
+	}
+
+	SSCompanionJSON struct {
+		MaxBurst         uint8  `json:"max_burst"`
+		Attributes       uint8  `json:"attributes"`
+		BytesPerInterval uint16 `json:"bytes_per_interval"`
+	}
+
+	EndpointJSON struct {
+		Address              string           `json:"address"`
+		Number               uint8            `json:"number"`
+		Direction            string           `json:"direction"`
+		Attributes           uint8            `json:"attributes"`
+		TransferType         string           `json:"transfer_type"`
+		SyncType             string           `json:"sync_type"`
+		UsageType            string           `json:"usage_type"`
+		MaxPacketSize        int              `json:"max_packet_size"`
+		TransactionsPerFrame int              `json:"transactions_per_microframe"`
+		Interval             uint8            `json:"interval"`
+		IntervalUS           int              `json:"interval_us,omitempty"`
+		SSCompanion          *SSCompanionJSON `json:"ss_companion,omitempty"`
+		Extra                []*UnknownJSON   `json:"extra,omitempty"`
+	}
+
+	// UnknownJSON is any other descriptor, as hex encoded raw bytes.
+	UnknownJSON struct {
+		Type uint8  `json:"type"`
+		Name string `json:"name"`
+		Data string `json:"data"`
+	}
+
+	BOSJSON struct {
+		TotalLength  uint16            `json:"total_length"`
+		Capabilities []*CapabilityJSON `json:"capabilities"`
+	}
+
+	// CapabilityJSON is a device capability as raw bytes after bDevCapabilityType,
+	// with the UUID of platform and container ID capabilities.
+	CapabilityJSON struct {
+		Type uint8  `json:"type"`
+		Name string `json:"name"`
+		UUID string `json:"uuid,omitempty"`
+		Data string `json:"data"`
+	}
+)
+
+// NewDocument converts an Info to the JSON model.
+func NewDocument(info *Info, opts *Options) *Document {
+	db := opts.ids()
+	set := info.Set
+	dev := set.Device
+	str := func(idx uint8) *StringJSON {
+		return &StringJSON{Index: idx, Value: info.Strings[idx]}
+	}
+	doc := &Document{
+		Schema: SchemaVersion,
+		Sysfs:  info.Sysfs,
+		Speed:  info.speed().String(),
+		Device: &DeviceJSON{
+			USBVersion:        formatBCD(dev.BcdUSB),
+			Class:             classJSON(db, dev.ClassTriple()),
+			MaxPacketSize0:    dev.BMaxPacketSize0,
+			Vendor:            &IDJSON{ID: fmt.Sprintf("%.4x", dev.IDVendor), Name: db.Vendor(dev.IDVendor)},
+			Product:           &IDJSON{ID: fmt.Sprintf("%.4x", dev.IDProduct), Name: db.Product(dev.IDVendor, dev.IDProduct)},
+			DeviceVersion:     formatBCD(dev.BcdDevice),
+			Manufacturer:      str(dev.IManufacturer),
+			ProductString:     str(dev.IProduct),
+			SerialNumber:      str(dev.ISerialNumber),
+			NumConfigurations: dev.BNumConfigurations,
+		},
+		Configurations: make([]*ConfigJSON, 0, len(set.Configurations)),
+	}
+	for _, cfg := range set.Configurations {
+		doc.Configurations = append(doc.Configurations, configJSON(db, cfg, info.speed(), str))
+	}
+	if bos := set.BOS; bos != nil {
+		doc.BOS = &BOSJSON{TotalLength: bos.WTotalLength, Capabilities: make([]*CapabilityJSON, 0, len(bos.Capabilities))}
+		for _, capability := range bos.Capabilities {
+			doc.BOS.Capabilities = append(doc.BOS.Capabilities, capabilityJSON(capability))
+		}
+	}
+	if q := set.Qualifier; q != nil {
+		doc.Qualifier = &QualifierJSON{
+			USBVersion:        formatBCD(q.BcdUSB),
+			Class:             classJSON(db, usb.ClassTriple{Class: q.BDeviceClass, SubClass: q.BDeviceSubClass, Protocol: q.BDeviceProtocol}),
+			MaxPacketSize0:    q.BMaxPacketSize0,
+			NumConfigurations: q.BNumConfigurations,
+		}
+	}
+	otherSpeed := SpeedFull
+	if info.speed() == SpeedFull {
+		otherSpeed = SpeedHigh
+	}
+	for _, cfg := range set.OtherSpeedConfigurations {
+		doc.OtherSpeedConfigurations = append(doc.OtherSpeedConfigurations, configJSON(db, cfg, otherSpeed, str))
+	}
+	return doc
+}
+
+func classJSON(db *ids.Database, triple usb.ClassTriple) *ClassJSON {
+	class, subClass, protocol := className(db, triple)
+	return &ClassJSON{
+		Class:        uint8(triple.Class),
+		SubClass:     uint8(triple.SubClass),
+		Protocol:     triple.Protocol,
+		ClassName:    class,
+		SubClassName: subClass,
+		ProtocolName: protocol,
+		Defined:      triple.Name(),
+	}
+}
+
+func configJSON(db *ids.Database, cfg *usb.Configuration, speed Speed, str func(uint8) *StringJSON) *ConfigJSON {
+	res := &ConfigJSON{
+		Value:         cfg.BConfigurationValue,
+		String:        str(cfg.IConfiguration),
+		TotalLength:   cfg.WTotalLength,
+		NumInterfaces: cfg.BNumInterfaces,
+		Attributes:    cfg.BmAttributes,
+		SelfPowered:   cfg.BmAttributes&0x40 != 0,
+		RemoteWakeup:  cfg.BmAttributes&0x20 != 0,
+		MaxPowerMA:    maxPowerMilliAmps(cfg.BMaxPower, speed),
+		Interfaces:    make([]*InterfaceJSON, 0, len(cfg.Interfaces)),
+		Extra:         unknownJSON(cfg.Extra),
+	}
+	for _, iface := range cfg.Interfaces {
+		ifaceJSON := &InterfaceJSON{
+			Number:      iface.Number,
+			AltSettings: make([]*AltSettingJSON, 0, len(iface.AltSettings)),
+		}
+		if iad := iface.Association; iad != nil {
+			ifaceJSON.Association = &AssociationJSON{
+				FirstInterface: iad.BFirstInterface,
+				InterfaceCount: iad.BInterfaceCount,
+				Class:          classJSON(db, iad.ClassTriple()),
+				String:         str(iad.IFunction),
+			}
+		}
+		for _, alt := range iface.AltSettings {
+			altJSON := &AltSettingJSON{
+				AlternateSetting: alt.BAlternateSetting,
+				NumEndpoints:     alt.BNumEndpoints,
+				Class:            classJSON(db, alt.ClassTriple()),
+				String:           str(alt.IInterface),
+				Endpoints:        make([]*EndpointJSON, 0, len(alt.Endpoints)),
+				Extra:            unknownJSON(alt.Extra),
+			}
+			for _, ep := range alt.Endpoints {
+				altJSON.Endpoints = append(altJSON.Endpoints, endpointJSON(ep, speed))
+			}
+			ifaceJSON.AltSettings = append(ifaceJSON.AltSettings, altJSON)
+		}
+		res.Interfaces = append(res.Interfaces, ifaceJSON)
+	}
+	return res
+}
+
+func endpointJSON(ep *usb.Endpoint, speed Speed) *EndpointJSON {
+	transactions, size := packetSize(ep.EndpointDescriptor)
+	direction := "out"
+	if ep.BEndpointAddress&usb.EndpointDirectionIn != 0 {
+		direction = "in"
+	}
+	res := &EndpointJSON{
+		Address:              fmt.Sprintf("0x%.2x", ep.BEndpointAddress),
+		Number:               ep.BEndpointAddress & 0x0F,
+		Direction:            direction,
+		Attributes:           ep.BmAttributes,
+		TransferType:         transferTypeNames[ep.TransferType()],
+		SyncType:             syncTypeNames[ep.SynchronizationType()],
+		UsageType:            usageTypeNames[ep.UsageType()],
+		MaxPacketSize:        size,
+		TransactionsPerFrame: transactions,
+		Interval:             ep.BInterval,
+		IntervalUS:           intervalMicroseconds(ep.EndpointDescriptor, speed),
+		Extra:                unknownJSON(ep.Extra),
+	}
+	if c := ep.SSCompanion; c != nil {
+		res.SSCompanion = &SSCompanionJSON{
+			MaxBurst:         c.BMaxBurst,
+			Attributes:       c.BmAttributes,
+			BytesPerInterval: c.WBytesPerInterval,
+		}
+	}
+	if c := ep.SSPIsoCompanion; c != nil {
+		res.Extra = append(res.Extra, unknownJSON([]usb.Descriptor{c})...)
+	}
+	return res
+}
+
+func unknownJSON(descriptors []usb.Descriptor) []*UnknownJSON {
+	var res []*UnknownJSON
+	for _, desc := range descriptors {
+		data, err := usb.MarshalDescriptor(desc)
+		if err != nil {
+			continue
+		}
+		res = append(res, &UnknownJSON{
+			Type: uint8(desc.Type()),
+			Name: desc.Type().String(),
+			Data: hex.EncodeToString(data),
+		})
+	}
+	return res
+}
+
+func capabilityJSON(desc usb.Descriptor) *CapabilityJSON {
+	data, _ := usb.MarshalDescriptor(desc)
+	res := &CapabilityJSON{}
+	if len(data) >= 3 {
+		res.Type = data[2]
+		res.Name = usb.Capability(data[2]).String()
+		res.Data = hex.EncodeToString(data[3:])
+	}
+	switch x := desc.(type) {
+	case *usb.CapPlatformDescriptor:
+		res.UUID = x.UUIDString()
+	case *usb.CapContainerIDDescriptor:
+		res.UUID = x.UUIDString()
+	}
+	return res
+}
+
+// JSON writes the info as indented JSON following Document.
+func JSON(w io.Writer, info *Info, opts *Options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewDocument(info, opts))
+}
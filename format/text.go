@@ -0,0 +1,377 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/ids"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// valueColumn is the column values are right aligned to, relative to the indentation, as in lsusb.
+const valueColumn = 25
+
+type textPrinter struct {
+	w      *bufio.Writer
+	info   *Info
+	db     *ids.Database
+	indent string
+}
+
+// Text writes the info in the style of lsusb -v.
+func Text(w io.Writer, info *Info, opts *Options) error {
+	p := &textPrinter{
+		w:    bufio.NewWriter(w),
+		info: info,
+		db:   opts.ids(),
+	}
+	p.all()
+	return p.w.Flush()
+}
+
+// Descriptors writes loose descriptors field by field, eg. the response to a GET_DESCRIPTOR request.
+func Descriptors(w io.Writer, descriptors []usb.Descriptor, opts *Options) error {
+	p := &textPrinter{
+		w:    bufio.NewWriter(w),
+		info: &Info{Strings: make(map[uint8]string)},
+		db:   opts.ids(),
+	}
+	p.extra(descriptors)
+	return p.w.Flush()
+}
+
+func (p *textPrinter) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, "%s%s\n", p.indent, fmt.Sprintf(format, args...))
+}
+
+// field writes a name with its value right aligned to valueColumn, followed by an optional comment.
+func (p *textPrinter) field(name, value, comment string) {
+	width := len(value)
+	if width < 5 {
+		width = 5
+	}
+	pad := valueColumn - width - len(name)
+	if pad < 1 {
+		pad = 1
+	}
+	line := name + strings.Repeat(" ", pad) + strings.Repeat(" ", width-len(value)) + value
+	if comment != "" {
+		line += " " + comment
+	}
+	p.line("%s", line)
+}
+
+func (p *textPrinter) uint(name string, value interface{}, comment string) {
+	p.field(name, fmt.Sprintf("%d", value), comment)
+}
+
+func (p *textPrinter) hex(name string, value interface{}, digits int, comment string) {
+	p.field(name, fmt.Sprintf("0x%.*x", digits, value), comment)
+}
+
+func (p *textPrinter) str(name string, idx uint8) {
+	p.field(name, fmt.Sprintf("%d", idx), p.info.Strings[idx])
+}
+
+func (p *textPrinter) header(h usb.DescriptorHeader) {
+	p.uint("bLength", h.Length, "")
+	p.uint("bDescriptorType", uint8(h.DescriptorType), "")
+}
+
+func (p *textPrinter) block(title string, fn func()) {
+	p.line("%s:", title)
+	saved := p.indent
+	p.indent += "  "
+	fn()
+	p.indent = saved
+}
+
+func (p *textPrinter) all() {
+	set := p.info.Set
+	dev := set.Device
+	if sysfs := p.info.Sysfs; sysfs != nil {
+		p.line("Bus %.3d Device %.3d: %s", sysfs.BusNumber, sysfs.DeviceNumber, p.db.DeviceInfo(dev))
+	} else {
+		p.line("%s", p.db.DeviceInfo(dev))
+	}
+	p.block("Device Descriptor", func() {
+		p.header(dev.DescriptorHeader)
+		p.field("bcdUSB", formatBCD(dev.BcdUSB), "")
+		p.classTriple("bDevice", dev.ClassTriple())
+		p.uint("bMaxPacketSize0", dev.BMaxPacketSize0, "")
+		p.hex("idVendor", dev.IDVendor, 4, p.db.Vendor(dev.IDVendor))
+		p.hex("idProduct", dev.IDProduct, 4, p.db.Product(dev.IDVendor, dev.IDProduct))
+		p.field("bcdDevice", formatBCD(dev.BcdDevice), "")
+		p.str("iManufacturer", dev.IManufacturer)
+		p.str("iProduct", dev.IProduct)
+		p.str("iSerial", dev.ISerialNumber)
+		p.uint("bNumConfigurations", dev.BNumConfigurations, "")
+		for _, cfg := range set.Configurations {
+			p.configuration("Configuration Descriptor", cfg, p.info.speed())
+		}
+	})
+	if bos := set.BOS; bos != nil {
+		p.bos(bos)
+	}
+	if q := set.Qualifier; q != nil {
+		p.block("Device Qualifier (for other device speed)", func() {
+			p.header(q.DescriptorHeader)
+			p.field("bcdUSB", formatBCD(q.BcdUSB), "")
+			p.classTriple("bDevice", usb.ClassTriple{Class: q.BDeviceClass, SubClass: q.BDeviceSubClass, Protocol: q.BDeviceProtocol})
+			p.uint("bMaxPacketSize0", q.BMaxPacketSize0, "")
+			p.uint("bNumConfigurations", q.BNumConfigurations, "")
+		})
+	}
+	otherSpeed := SpeedFull
+	if p.info.speed() == SpeedFull {
+		otherSpeed = SpeedHigh
+	}
+	for _, cfg := range set.OtherSpeedConfigurations {
+		p.configuration("Other Speed Configuration Descriptor", cfg, otherSpeed)
+	}
+}
+
+// classTriple writes the class, subclass and protocol fields with the prefix bDevice, bInterface or bFunction.
+func (p *textPrinter) classTriple(prefix string, triple usb.ClassTriple) {
+	class, subClass, protocol := className(p.db, triple)
+	p.uint(prefix+"Class", uint8(triple.Class), class)
+	p.uint(prefix+"SubClass", uint8(triple.SubClass), subClass)
+	p.uint(prefix+"Protocol", triple.Protocol, protocol)
+}
+
+func (p *textPrinter) configuration(title string, cfg *usb.Configuration, speed Speed) {
+	p.block(title, func() {
+		p.header(cfg.DescriptorHeader)
+		p.hex("wTotalLength", cfg.WTotalLength, 4, "")
+		p.uint("bNumInterfaces", cfg.BNumInterfaces, "")
+		p.uint("bConfigurationValue", cfg.BConfigurationValue, "")
+		p.str("iConfiguration", cfg.IConfiguration)
+		p.hex("bmAttributes", cfg.BmAttributes, 2, "")
+		p.indent += "  "
+		if cfg.BmAttributes&0x40 != 0 {
+			p.line("Self Powered")
+		} else {
+			p.line("(Bus Powered)")
+		}
+		if cfg.BmAttributes&0x20 != 0 {
+			p.line("Remote Wakeup")
+		}
+		p.indent = p.indent[2:]
+		p.line("%-20s%5dmA", "MaxPower", maxPowerMilliAmps(cfg.BMaxPower, speed))
+		p.extra(cfg.Extra)
+		for _, iface := range cfg.Interfaces {
+			if iad := iface.Association; iad != nil && iad.BFirstInterface == iface.Number {
+				p.block("Interface Association", func() {
+					p.header(iad.DescriptorHeader)
+					p.uint("bFirstInterface", iad.BFirstInterface, "")
+					p.uint("bInterfaceCount", iad.BInterfaceCount, "")
+					p.classTriple("bFunction", iad.ClassTriple())
+					p.str("iFunction", iad.IFunction)
+				})
+			}
+			for _, alt := range iface.AltSettings {
+				p.altSetting(alt, speed)
+			}
+		}
+	})
+}
+
+func (p *textPrinter) altSetting(alt *usb.AltSetting, speed Speed) {
+	p.block("Interface Descriptor", func() {
+		p.header(alt.DescriptorHeader)
+		p.uint("bInterfaceNumber", alt.BInterfaceNumber, "")
+		p.uint("bAlternateSetting", alt.BAlternateSetting, "")
+		p.uint("bNumEndpoints", alt.BNumEndpoints, "")
+		p.classTriple("bInterface", alt.ClassTriple())
+		p.str("iInterface", alt.IInterface)
+		p.extra(alt.Extra)
+		for _, ep := range alt.Endpoints {
+			p.endpoint(ep, speed)
+		}
+	})
+}
+
+var (
+	transferTypeNames = [...]string{"Control", "Isochronous", "Bulk", "Interrupt"}
+	syncTypeNames     = [...]string{"None", "Asynchronous", "Adaptive", "Synchronous"}
+	usageTypeNames    = [...]string{"Data", "Feedback", "Implicit feedback Data", "(reserved)"}
+)
+
+func endpointName(address uint8) string {
+	if address&usb.EndpointDirectionIn != 0 {
+		return fmt.Sprintf("EP %d IN", address&0x0F)
+	}
+	return fmt.Sprintf("EP %d OUT", address&0x0F)
+}
+
+func formatInterval(microseconds int) string {
+	if microseconds == 0 {
+		return ""
+	}
+	if microseconds%1000 == 0 {
+		return fmt.Sprintf("%dms", microseconds/1000)
+	}
+	return fmt.Sprintf("%dus", microseconds)
+}
+
+func (p *textPrinter) endpoint(ep *usb.Endpoint, speed Speed) {
+	p.block("Endpoint Descriptor", func() {
+		p.header(ep.DescriptorHeader)
+		p.hex("bEndpointAddress", ep.BEndpointAddress, 2, " "+endpointName(ep.BEndpointAddress))
+		p.uint("bmAttributes", ep.BmAttributes, "")
+		p.indent += "  "
+		p.line("Transfer Type            %s", transferTypeNames[ep.TransferType()])
+		p.line("Synch Type               %s", syncTypeNames[ep.SynchronizationType()])
+		p.line("Usage Type               %s", usageTypeNames[ep.UsageType()])
+		p.indent = p.indent[2:]
+		transactions, size := packetSize(ep.EndpointDescriptor)
+		p.hex("wMaxPacketSize", ep.WMaxPacketSize, 4, fmt.Sprintf(" %dx %d bytes", transactions, size))
+		p.uint("bInterval", ep.BInterval, formatInterval(intervalMicroseconds(ep.EndpointDescriptor, speed)))
+		if c := ep.SSCompanion; c != nil {
+			p.block("SuperSpeed Endpoint Companion Descriptor", func() {
+				p.header(c.DescriptorHeader)
+				p.uint("bMaxBurst", c.BMaxBurst, "")
+				switch ep.TransferType() {
+				case usb.TransferTypeBulk:
+					p.uint("MaxStreams", 1<<(c.BmAttributes&0x1F)&^1, "")
+				case usb.TransferTypeIsochronous:
+					p.uint("Mult", c.BmAttributes&0x03+1, "")
+				}
+				p.uint("wBytesPerInterval", c.WBytesPerInterval, "")
+			})
+		}
+		if c := ep.SSPIsoCompanion; c != nil {
+			p.descriptor("SuperSpeedPlus Isochronous Endpoint Companion Descriptor", c)
+		}
+		p.extra(ep.Extra)
+	})
+}
+
+func (p *textPrinter) extra(descriptors []usb.Descriptor) {
+	for _, desc := range descriptors {
+		switch x := desc.(type) {
+		case *usb.UnknownDescriptor:
+			data, _ := x.MarshalBinary()
+			p.line("** UNRECOGNIZED: % x", data)
+		default:
+			p.descriptor(fmt.Sprintf("%v Descriptor", desc.Type()), desc)
+		}
+	}
+}
+
+func (p *textPrinter) bos(bos *usb.BOS) {
+	p.block("Binary Object Store Descriptor", func() {
+		p.header(bos.DescriptorHeader)
+		p.hex("wTotalLength", bos.WTotalLength, 4, "")
+		p.uint("bNumDeviceCaps", bos.BNumDeviceCaps, "")
+		for _, capability := range bos.Capabilities {
+			p.capability(capability)
+		}
+	})
+}
+
+var speedNames = [...]string{"Low Speed (1Mbps)", "Full Speed (12Mbps)", "High Speed (480Mbps)", "SuperSpeed (5Gbps)"}
+
+func (p *textPrinter) capability(desc usb.Descriptor) {
+	switch x := desc.(type) {
+	case *usb.CapUSB20ExtensionDescriptor:
+		p.block("USB 2.0 Extension Device Capability", func() {
+			p.header(x.DescriptorHeader)
+			p.uint("bDevCapabilityType", uint8(x.BDevCapabilityType), "")
+			p.hex("bmAttributes", x.BMAttributes, 8, "")
+			p.indent += "  "
+			if x.BMAttributes&0x02 != 0 {
+				p.line("BESL Link Power Management (LPM) Supported")
+			} else {
+				p.line("Link Power Management (LPM) not supported")
+			}
+			p.indent = p.indent[2:]
+		})
+	case *usb.CapSuperSpeedUSBDescriptor:
+		p.block("SuperSpeed USB Device Capability", func() {
+			p.header(x.DescriptorHeader)
+			p.uint("bDevCapabilityType", uint8(x.BDevCapabilityType), "")
+			p.hex("bmAttributes", x.BMAttributes, 2, "")
+			p.indent += "  "
+			if x.BMAttributes&0x02 != 0 {
+				p.line("Latency Tolerance Messages (LTM) Supported")
+			}
+			p.indent = p.indent[2:]
+			p.hex("wSpeedsSupported", x.WSpeedsSupported, 4, "")
+			p.indent += "  "
+			for i, name := range speedNames {
+				if x.WSpeedsSupported&(1<<i) != 0 {
+					p.line("Device can operate at %s", name)
+				}
+			}
+			p.indent = p.indent[2:]
+			p.uint("bFunctionalitySupport", x.BFunctionalitySupport, "")
+			if int(x.BFunctionalitySupport) < len(speedNames) {
+				p.line("  Lowest fully-functional device speed is %s", speedNames[x.BFunctionalitySupport])
+			}
+			p.uint("bU1DevExitLat", x.BU1DevExitLat, "micro seconds")
+			p.uint("bU2DevExitLat", x.WU2DevExitLat, "micro seconds")
+		})
+	case *usb.CapContainerIDDescriptor:
+		p.block("Container ID Device Capability", func() {
+			p.header(x.DescriptorHeader)
+			p.uint("bDevCapabilityType", uint8(x.BDevCapabilityType), "")
+			p.uint("bReserved", x.Reserved, "")
+			p.field("ContainerID", x.UUIDString(), "")
+		})
+	case *usb.CapPlatformDescriptor:
+		p.block("Platform Device Capability", func() {
+			p.header(x.DescriptorHeader)
+			p.uint("bDevCapabilityType", uint8(x.BDevCapabilityType), "")
+			p.uint("bReserved", x.Reserved, "")
+			p.field("PlatformCapabilityUUID", x.UUIDString(), "")
+			p.line("CapabilityData: % x", x.CapabilityData)
+		})
+	case *usb.DeviceCapabilityDescriptor:
+		p.block(fmt.Sprintf("%v Device Capability", x.BDevCapabilityType), func() {
+			p.header(x.DescriptorHeader)
+			p.uint("bDevCapabilityType", uint8(x.BDevCapabilityType), "")
+			p.line("Data: % x", x.Data)
+		})
+	default:
+		title := "Device Capability"
+		if f := reflect.Indirect(reflect.ValueOf(desc)).FieldByName("BDevCapabilityType"); f.IsValid() {
+			title = fmt.Sprintf("%v Device Capability", f.Interface())
+		}
+		p.descriptor(title, desc)
+	}
+}
+
+// descriptor writes any struct descriptor field by field.
+func (p *textPrinter) descriptor(title string, desc usb.Descriptor) {
+	p.block(title, func() {
+		v := reflect.Indirect(reflect.ValueOf(desc))
+		t := v.Type()
+		p.header(v.Field(0).Interface().(usb.DescriptorHeader))
+		for i := 1; i < v.NumField(); i++ {
+			name := fieldName(t.Field(i).Name)
+			switch f := v.Field(i); f.Kind() {
+			case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				p.uint(name, f.Uint(), "")
+			case reflect.Slice, reflect.Array:
+				values := make([]string, f.Len())
+				for j := range values {
+					values[j] = fmt.Sprintf("%.2x", f.Index(j).Uint())
+				}
+				p.line("%s: %s", name, strings.Join(values, " "))
+			default:
+				p.line("%s: %v", name, f.Interface())
+			}
+		}
+	})
+}
+
+// fieldName converts a Go field name to the descriptor field name, BMaxBurst -> bMaxBurst.
+func fieldName(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
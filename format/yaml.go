@@ -0,0 +1,110 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// yamlNode is a JSON value keeping the order of object keys.
+type yamlNode struct {
+	// scalar is the JSON encoding of a string, number, bool or null.
+	scalar string
+	keys   []string
+	values []*yamlNode
+	object bool
+	array  bool
+}
+
+// YAML writes the same document as JSON in YAML block style.
+func YAML(w io.Writer, info *Info, opts *Options) error {
+	data, err := json.Marshal(NewDocument(info, opts))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := readYAMLNode(decoder)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	writeYAMLNode(out, root, "", "")
+	return out.Flush()
+}
+
+func readYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch x := token.(type) {
+	case json.Delim:
+		node := &yamlNode{object: x == '{', array: x == '['}
+		for decoder.More() {
+			if node.object {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			value, err := readYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		// JSON string escapes are valid in YAML double quoted scalars
+		quoted, _ := json.Marshal(x)
+		return &yamlNode{scalar: string(quoted)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	default:
+		return &yamlNode{scalar: fmt.Sprint(x)}, nil
+	}
+}
+
+// inline returns the node written on the same line as its key, or "" for non-empty containers.
+func (n *yamlNode) inline() string {
+	switch {
+	case n.object && len(n.values) == 0:
+		return "{}"
+	case n.array && len(n.values) == 0:
+		return "[]"
+	case n.object || n.array:
+		return ""
+	}
+	return n.scalar
+}
+
+// writeYAMLNode writes the entries of a container, the first line is prefixed with first
+// instead of indent so that a container in a sequence starts on the "- " line.
+func writeYAMLNode(w *bufio.Writer, node *yamlNode, indent, first string) {
+	for i, value := range node.values {
+		prefix := indent
+		if i == 0 {
+			prefix = first
+		}
+		if node.object {
+			prefix += node.keys[i] + ":"
+		} else {
+			prefix += "-"
+		}
+		if inline := value.inline(); inline != "" {
+			fmt.Fprintf(w, "%s %s\n", prefix, inline)
+		} else if node.object {
+			fmt.Fprintf(w, "%s\n", prefix)
+			writeYAMLNode(w, value, indent+"  ", indent+"  ")
+		} else {
+			writeYAMLNode(w, value, indent+"  ", prefix+" ")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package usb
+
+import "fmt"
+
+// Function is a group of interfaces implementing a single device function.
+//
+// Interfaces covered by an InterfaceAssociationDescriptor form one function with the class, subclass
+// and protocol of the association. Every other interface is a function on its own, described by
+// its default alternate setting.
+type Function struct {
+	// Association is nil for a function consisting of a single interface without an association descriptor.
+	Association *InterfaceAssociationDescriptor
+
+	Class    ClassCode
+	SubClass SubClass
+	Protocol uint8
+
+	// IFunction is the index of the string descriptor describing the function.
+	// For a single interface function this is IInterface of the default alternate setting.
+	IFunction uint8
+
+	Interfaces []*Interface
+}
+
+// Functions groups the interfaces of the configuration into functions, in interface order.
+func (c *Configuration) Functions() []*Function {
+	res := make([]*Function, 0, len(c.Interfaces))
+	associated := make(map[uint8]bool)
+	for _, iface := range c.Interfaces {
+		if associated[iface.Number] {
+			continue
+		}
+		if iad := iface.Association; iad != nil {
+			function := &Function{
+				Association: iad,
+				Class:       iad.BFunctionClass,
+				SubClass:    iad.BFunctionSubClass,
+				Protocol:    iad.BFunctionProtocol,
+				IFunction:   iad.IFunction,
+				Interfaces:  make([]*Interface, 0, iad.BInterfaceCount),
+			}
+			for number := iad.BFirstInterface; number < iad.BFirstInterface+iad.BInterfaceCount; number++ {
+				if member := c.Interface(number); member != nil && !associated[number] {
+					associated[number] = true
+					function.Interfaces = append(function.Interfaces, member)
+				}
+			}
+			res = append(res, function)
+			continue
+		}
+		function := &Function{
+			Interfaces: []*Interface{iface},
+		}
+		if alt := iface.AltSetting(0); alt != nil {
+			function.Class = alt.BInterfaceClass
+			function.SubClass = alt.BInterfaceSubClass
+			function.Protocol = alt.BInterfaceProtocol
+			function.IFunction = alt.IInterface
+		}
+		res = append(res, function)
+	}
+	return res
+}
+
+// Function returns the function containing the specified interface, or nil if there is no such interface.
+func (c *Configuration) Function(iface uint8) *Function {
+	for _, function := range c.Functions() {
+		if function.Contains(iface) {
+			return function
+		}
+	}
+	return nil
+}
+
+// Contains reports whether the interface with the specified number is part of the function.
+func (f *Function) Contains(iface uint8) bool {
+	for _, member := range f.Interfaces {
+		if member.Number == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// InterfaceNumbers returns the numbers of all interfaces in the function.
+func (f *Function) InterfaceNumbers() []uint8 {
+	res := make([]uint8, len(f.Interfaces))
+	for i, iface := range f.Interfaces {
+		res[i] = iface.Number
+	}
+	return res
+}
+
+// GetFunctionString returns the string describing the function.
+func (d *Device) GetFunctionString(f *Function) (string, error) {
+	return d.GetString(f.IFunction)
+}
+
+// ClaimFunction claims every interface of the function.
+// If detach is true, kernel drivers bound to the interfaces are detached first and remembered,
+// so ReleaseFunction can reattach them. An interface held by another process through usbfs is
+// not taken over, claiming it fails with EBUSY.
+// If any interface can not be claimed, the interfaces already claimed are released again and
+// the drivers detached are reattached.
+func (d *Device) ClaimFunction(f *Function, detach bool) error {
+	claimed := make([]uint8, 0, len(f.Interfaces))
+	detached := make([]uint8, 0, len(f.Interfaces))
+	undo := func() {
+		d.releaseInterfaces(claimed)
+		for _, number := range detached {
+			d.AttachKernel(uint32(number))
+		}
+	}
+	for _, number := range f.InterfaceNumbers() {
+		if detach {
+			if driver, err := d.GetDriver(uint32(number)); err == nil && driver != "" && driver != "usbfs" {
+				if err := d.DetachKernel(uint32(number)); err != nil {
+					undo()
+					return fmt.Errorf("detach interface %d: %w", number, err)
+				}
+				detached = append(detached, number)
+			}
+		}
+		if err := d.ClaimInterface(number); err != nil {
+			undo()
+			return fmt.Errorf("claim interface %d: %w", number, err)
+		}
+		claimed = append(claimed, number)
+	}
+	d.detachLock.Lock()
+	defer d.detachLock.Unlock()
+	if d.detached == nil {
+		d.detached = make(map[uint8]bool)
+	}
+	for _, number := range detached {
+		d.detached[number] = true
+	}
+	return nil
+}
+
+// ReleaseFunction releases every interface of the function.
+// If attach is true, the kernel drivers ClaimFunction detached are reattached after release,
+// interfaces that had no driver are left alone.
+// The first error is returned, but all interfaces are processed.
+func (d *Device) ReleaseFunction(f *Function, attach bool) error {
+	var firstErr error
+	for _, number := range f.InterfaceNumbers() {
+		if err := d.ReleaseInterface(number); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("release interface %d: %w", number, err)
+		}
+		if d.takeDetached(number) && attach {
+			if err := d.AttachKernel(uint32(number)); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("attach interface %d: %w", number, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// takeDetached reports whether ClaimFunction detached the kernel driver of the interface and forgets it.
+func (d *Device) takeDetached(number uint8) bool {
+	d.detachLock.Lock()
+	defer d.detachLock.Unlock()
+	detached := d.detached[number]
+	delete(d.detached, number)
+	return detached
+}
+
+func (d *Device) releaseInterfaces(numbers []uint8) {
+	for _, number := range numbers {
+		d.ReleaseInterface(number)
+	}
+}
@@ -0,0 +1,71 @@
+package usb_test
+
+import (
+	"errors"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+// cdcACM returns a CDC ACM device with the communication and data interfaces grouped by an association.
+func cdcACM(t *testing.T) *sim.Device {
+	config := usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+		Association(&usb.InterfaceAssociationDescriptor{BFirstInterface: 0, BInterfaceCount: 2, BFunctionClass: usb.ClassCodeCDCControl, BFunctionSubClass: 2}).
+		Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 0, BInterfaceClass: usb.ClassCodeCDCControl, BInterfaceSubClass: 2}).
+		Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x83, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 16}).
+		Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 1, BInterfaceClass: usb.ClassCodeInterfaceCDCData}).
+		Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}).
+		Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x02, BmAttributes: 2, WMaxPacketSize: 64})
+	simDev, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BDeviceClass: usb.ClassCodeMisc, BDeviceSubClass: 2, BDeviceProtocol: 1, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0002, BNumConfigurations: 1}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return simDev
+}
+
+// configured opens the device and selects its configuration.
+func configured(t *testing.T, simDev *sim.Device) (*usb.Device, *usb.Function) {
+	dev := openSim(t, simDev)
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	config, err := dev.GetConfigurationDescriptor(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	function := config.Function(0)
+	if function == nil || len(function.Interfaces) != 2 {
+		t.Fatalf("unexpected function %+v", function)
+	}
+	return dev, function
+}
+
+func TestClaimFunctionKernelDriver(t *testing.T) {
+	simDev := cdcACM(t)
+	simDev.Drivers = map[uint8]string{0: "cdc_acm", 1: "cdc_acm"}
+	dev, function := configured(t, simDev)
+	if err := dev.ClaimFunction(function, true); err != nil {
+		t.Fatal(err)
+	}
+	if !simDev.Detached(0) || !simDev.Detached(1) || !simDev.Claimed(0) || !simDev.Claimed(1) {
+		t.Fatal("expected cdc_acm to be detached and the interfaces claimed")
+	}
+	if err := dev.ReleaseFunction(function, true); err != nil {
+		t.Fatal(err)
+	}
+	if simDev.Detached(0) || simDev.Detached(1) || simDev.Claimed(0) || simDev.Claimed(1) {
+		t.Fatal("expected cdc_acm to be reattached")
+	}
+
+	// An interface claimed by another process is left alone.
+	simDev = cdcACM(t)
+	simDev.Drivers = map[uint8]string{0: "cdc_acm", 1: "usbfs"}
+	dev, function = configured(t, simDev)
+	if err := dev.ClaimFunction(function, true); !errors.Is(err, syscall.EBUSY) {
+		t.Fatalf("expected EBUSY for an interface claimed through usbfs, got %v", err)
+	}
+	if simDev.Detached(0) || simDev.Detached(1) || simDev.Claimed(0) || simDev.Claimed(1) {
+		t.Fatal("the usbfs claim of the other process was taken over")
+	}
+}
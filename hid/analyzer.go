@@ -0,0 +1,98 @@
+package hid
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+)
+
+func init() {
+	analyzer.Register(usb.ClassCodeInterfaceHID, decoder{})
+}
+
+type decoder struct{}
+
+var requestNames = map[uint8]string{
+	GetReport:   "GET_REPORT",
+	GetIdle:     "GET_IDLE",
+	GetProtocol: "GET_PROTOCOL",
+	SetReport:   "SET_REPORT",
+	SetIdle:     "SET_IDLE",
+	SetProtocol: "SET_PROTOCOL",
+}
+
+var reportTypeNames = map[uint8]string{
+	1: "input",
+	2: "output",
+	3: "feature",
+}
+
+var descriptorNames = map[usb.DescriptorType]string{
+	DescriptorTypeHID:      "HID",
+	DescriptorTypeReport:   "REPORT",
+	DescriptorTypePhysical: "PHYSICAL",
+}
+
+func protocolName(protocol uint16) string {
+	switch protocol {
+	case ProtocolBoot:
+		return "boot"
+	case ProtocolReport:
+		return "report"
+	}
+	return fmt.Sprintf("%d", protocol)
+}
+
+func (decoder) DecodeControl(ctx *analyzer.Context, t *analyzer.Transfer) string {
+	setup := t.Setup()
+	if setup.Type() == usb.RequestTypeStandard && setup.Request == usb.ReqGetDescriptor {
+		typ := usb.DescriptorType(setup.Value >> 8)
+		name, exist := descriptorNames[typ]
+		if !exist {
+			return ""
+		}
+		return fmt.Sprintf("HID GET_DESCRIPTOR(%s, idx %d, iface %d, len %d)", name, uint8(setup.Value), setup.Index, setup.Length)
+	}
+	if setup.Type() != usb.RequestTypeClass {
+		return ""
+	}
+	name, exist := requestNames[setup.Request]
+	if !exist {
+		return ""
+	}
+	ok := t.Status() == 0
+	data := t.Data()
+	switch setup.Request {
+	case GetReport, SetReport:
+		typ, exist := reportTypeNames[uint8(setup.Value>>8)]
+		if !exist {
+			typ = fmt.Sprintf("type %d", setup.Value>>8)
+		}
+		return fmt.Sprintf("HID %s(%s, report %d, len %d)", name, typ, uint8(setup.Value), setup.Length)
+	case GetIdle:
+		if ok && len(data) >= 1 {
+			t.Result += fmt.Sprintf(", %dms", int(data[0])*4)
+		}
+		return fmt.Sprintf("HID %s(report %d)", name, uint8(setup.Value))
+	case SetIdle:
+		return fmt.Sprintf("HID %s(report %d, %dms)", name, uint8(setup.Value), int(setup.Value>>8)*4)
+	case GetProtocol:
+		if ok && len(data) >= 1 {
+			t.Result += ", " + protocolName(uint16(data[0]))
+		}
+		return "HID " + name
+	case SetProtocol:
+		return fmt.Sprintf("HID %s(%s)", name, protocolName(setup.Value))
+	}
+	return ""
+}
+
+func (decoder) DecodeData(ctx *analyzer.Context, t *analyzer.Transfer) string {
+	if t.Type() != usb.TransferTypeInterrupt {
+		return ""
+	}
+	if t.Submit.In() {
+		return fmt.Sprintf("HID input report % X", t.Data())
+	}
+	return fmt.Sprintf("HID output report % X", t.Data())
+}
@@ -0,0 +1,68 @@
+package hid
+
+import (
+	"github.com/daedaluz/gousb/analyzer"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	var output [][]byte
+	var transfers []*analyzer.Transfer
+	dev := keyboard(t, &output).USB()
+	dev.SetCapture(analyzer.New().Capture(func(t *analyzer.Transfer) {
+		transfers = append(transfers, t)
+	}))
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	handles, err := NewHIDDevices(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kbd := handles[0]
+	if err := kbd.SetIdle(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kbd.GetIdle(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbd.SetProtocol(ProtocolBoot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kbd.GetProtocol(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kbd.Write([]byte{0x01}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kbd.ReadMax(); err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]string{}
+	for _, transfer := range transfers {
+		found[transfer.Description] = transfer.Result
+	}
+	for description, result := range map[string]string{
+		"HID GET_DESCRIPTOR(REPORT, idx 0, iface 1, len 63)": "63 bytes",
+		"HID SET_IDLE(report 0, 0ms)":                        "0 bytes",
+		"HID GET_IDLE(report 0)":                             "1 byte, 0ms",
+		"HID SET_PROTOCOL(boot)":                             "0 bytes",
+		"HID GET_PROTOCOL":                                   "1 byte, boot",
+		"HID SET_REPORT(output, report 0, len 1)":            "1 byte",
+		"HID input report 02 00 04 00 00 00 00 00":           "8 bytes",
+	} {
+		if got, exist := found[description]; !exist || got != result {
+			t.Errorf("%s -> %q, expected %q", description, got, result)
+		}
+	}
+	if t.Failed() {
+		for _, transfer := range transfers {
+			t.Log(transfer)
+		}
+	}
+}
@@ -1,16 +1,28 @@
 package hid
 
 import (
-	"github.com/daedaluz/gousb"
-	"log"
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/hid/report"
 )
 
 type (
+	// Device is a handle to one claimed HID interface of an open device.
 	Device struct {
 		*usb.Device
+		// Interface is the alternate setting of the HID interface.
+		Interface     *usb.AltSetting
 		HidDescriptor *Descriptor
-		EpIn          *usb.EndpointDescriptor
-		EpOut         *usb.EndpointDescriptor
+		// EpIn is the interrupt IN endpoint. EpOut is nil for interfaces without an interrupt OUT endpoint,
+		// their output reports are sent with SET_REPORT.
+		EpIn  *usb.EndpointDescriptor
+		EpOut *usb.EndpointDescriptor
+		// ReportDescriptor is the report descriptor read when the handle was created.
+		ReportDescriptor []byte
+
+		layout   *report.Layout
+		detached bool
 	}
 
 	Descriptor struct {
@@ -22,6 +34,8 @@ type (
 		DescriptorLength         uint16
 		OptionalDescriptorType   uint8
 		OptionalDescriptorLength uint16
+		// Trailing holds the class descriptor entries past the optional one, 3 bytes each.
+		Trailing []byte
 	}
 )
 
@@ -40,99 +54,388 @@ const (
 	SetProtocol = 0x0B
 )
 
+// Protocols of GET_PROTOCOL and SET_PROTOCOL.
+const (
+	ProtocolBoot   = 0
+	ProtocolReport = 1
+)
+
 func init() {
 	usb.RegisterDescriptorType(DescriptorTypeHID, Descriptor{})
 }
 
-func NewHIDDevice(dev *usb.Device) *Device {
-	var hidDesc *Descriptor
-	var inEp *usb.EndpointDescriptor
-	var outEp *usb.EndpointDescriptor
-
-	for _, d := range dev.Descriptors {
-		switch desc := d.(type) {
-		case *Descriptor:
-			hidDesc = desc
-		case *usb.EndpointDescriptor:
-			if (desc.BEndpointAddress & usb.EndpointDirectionIn) > 0 {
-				inEp = desc
-			} else {
-				outEp = desc
+// MarshalBinary encodes the HID descriptor with one class descriptor entry per NumDescriptors.
+// The second entry is the optional descriptor fields, any further entries are Trailing.
+// A descriptor whose entries do not match NumDescriptors is an error.
+func (d *Descriptor) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 9, 12+len(d.Trailing))
+	data[1] = uint8(DescriptorTypeHID)
+	binary.LittleEndian.PutUint16(data[2:], d.BcdHID)
+	data[4] = d.CountryCode
+	data[5] = d.NumDescriptors
+	data[6] = d.DescriptorType
+	binary.LittleEndian.PutUint16(data[7:], d.DescriptorLength)
+	if d.NumDescriptors > 1 {
+		data = append(data, d.OptionalDescriptorType, 0, 0)
+		binary.LittleEndian.PutUint16(data[10:], d.OptionalDescriptorLength)
+	}
+	data = append(data, d.Trailing...)
+	entries := int(d.NumDescriptors)
+	if entries == 0 {
+		entries = 1
+	}
+	if len(data) != 6+3*entries {
+		return nil, fmt.Errorf("HID descriptor with %d class descriptors has %d bytes of entries", d.NumDescriptors, len(data)-6)
+	}
+	data[0] = uint8(len(data))
+	return data, nil
+}
+
+// NewHIDDevices claims every HID interface of the active configuration of an open device and
+// returns one handle per interface. Kernel drivers bound to the interfaces are detached.
+// If any interface can not be opened, the handles already opened are released again.
+func NewHIDDevices(dev *usb.Device) ([]*Device, error) {
+	cfg, err := activeConfiguration(dev)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Device, 0, len(cfg.Interfaces))
+	for _, alt := range Interfaces(cfg) {
+		hidDev, err := NewHIDDevice(dev, alt)
+		if err != nil {
+			for _, opened := range res {
+				opened.Release()
+			}
+			return nil, err
+		}
+		res = append(res, hidDev)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("configuration %d has no HID interface", cfg.BConfigurationValue)
+	}
+	return res, nil
+}
+
+// NewHIDDevice claims a HID interface of an open device, detaching a bound kernel driver, and
+// reads its report descriptor. alt is the alternate setting of the interface to use.
+// An interface held by another process through usbfs is not taken over, claiming it fails.
+func NewHIDDevice(dev *usb.Device, alt *usb.AltSetting) (*Device, error) {
+	res := &Device{
+		Device:    dev,
+		Interface: alt,
+	}
+	for _, extra := range alt.Extra {
+		if desc, ok := extra.(*Descriptor); ok {
+			res.HidDescriptor = desc
+			break
+		}
+	}
+	if res.HidDescriptor == nil {
+		return nil, fmt.Errorf("interface %d has no HID descriptor", alt.BInterfaceNumber)
+	}
+	for _, ep := range alt.Endpoints {
+		if ep.TransferType() != usb.TransferTypeInterrupt {
+			continue
+		}
+		if ep.BEndpointAddress&usb.EndpointDirectionIn != 0 {
+			if res.EpIn == nil {
+				res.EpIn = ep.EndpointDescriptor
 			}
+		} else if res.EpOut == nil {
+			res.EpOut = ep.EndpointDescriptor
+		}
+	}
+	if res.EpIn == nil {
+		return nil, fmt.Errorf("interface %d has no interrupt IN endpoint", alt.BInterfaceNumber)
+	}
+	if err := res.claim(); err != nil {
+		return nil, err
+	}
+	desc, err := res.GetReportDescriptor()
+	if err != nil {
+		res.Release()
+		return nil, fmt.Errorf("interface %d: report descriptor: %w", alt.BInterfaceNumber, err)
+	}
+	res.ReportDescriptor = desc
+	return res, nil
+}
+
+// Interfaces returns the default alternate settings of the HID interfaces of a configuration.
+func Interfaces(cfg *usb.Configuration) []*usb.AltSetting {
+	res := make([]*usb.AltSetting, 0, len(cfg.Interfaces))
+	for _, iface := range cfg.Interfaces {
+		if alt := iface.AltSetting(0); alt != nil && alt.BInterfaceClass == usb.ClassCodeInterfaceHID {
+			res = append(res, alt)
+		}
+	}
+	return res
+}
+
+func activeConfiguration(dev *usb.Device) (*usb.Configuration, error) {
+	value, err := dev.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if value == 0 {
+		return nil, fmt.Errorf("device is not configured")
+	}
+	set, err := dev.GetDescriptorSet()
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range set.Configurations {
+		if int(cfg.BConfigurationValue) == value {
+			return cfg, nil
+		}
+	}
+	return nil, fmt.Errorf("no descriptor of configuration %d", value)
+}
+
+func (dev *Device) claim() error {
+	number := dev.Number()
+	// A "usbfs" driver is another process holding the interface, it is not detached so the claim fails.
+	if driver, err := dev.Device.GetDriver(uint32(number)); err == nil && driver != "" && driver != "usbfs" {
+		if err := dev.Device.DetachKernel(uint32(number)); err != nil {
+			return fmt.Errorf("detach interface %d: %w", number, err)
+		}
+		dev.detached = true
+	}
+	if err := dev.Device.ClaimInterface(number); err != nil {
+		if dev.detached {
+			dev.Device.AttachKernel(uint32(number))
 		}
+		return fmt.Errorf("claim interface %d: %w", number, err)
+	}
+	return nil
+}
+
+// Release releases the interface and reattaches the kernel driver detached by NewHIDDevice.
+// The usb.Device stays open, it may be shared by the handles of several interfaces.
+func (dev *Device) Release() error {
+	number := dev.Number()
+	err := dev.Device.ReleaseInterface(number)
+	if dev.detached {
+		dev.detached = false
+		if attachErr := dev.Device.AttachKernel(uint32(number)); attachErr != nil && err == nil {
+			err = attachErr
+		}
+	}
+	return err
+}
+
+// Number returns the interface number of the handle.
+func (dev *Device) Number() uint8 {
+	return dev.Interface.BInterfaceNumber
+}
+
+// reportDescriptorLength returns the length of the report descriptor listed in the HID descriptor.
+func (d *Descriptor) reportDescriptorLength() (uint16, bool) {
+	switch {
+	case d.DescriptorType == uint8(DescriptorTypeReport):
+		return d.DescriptorLength, true
+	case d.NumDescriptors > 1 && d.OptionalDescriptorType == uint8(DescriptorTypeReport):
+		return d.OptionalDescriptorLength, true
 	}
-	return &Device{
-		Device:        dev,
-		HidDescriptor: hidDesc,
-		EpIn:          inEp,
-		EpOut:         outEp,
+	return 0, false
+}
+
+// GetReportDescriptor reads the report descriptor of the interface, at the length listed in its HID descriptor.
+func (dev *Device) GetReportDescriptor() ([]byte, error) {
+	length, ok := dev.HidDescriptor.reportDescriptorLength()
+	if !ok {
+		return nil, fmt.Errorf("HID descriptor lists no report descriptor")
+	}
+	data := make([]byte, length)
+	reqType := usb.RequestDirectionIn | usb.RequestTypeStandard | usb.RequestRecipientInterface
+	n, err := dev.Device.Ctrl(reqType, usb.ReqGetDescriptor, uint16(DescriptorTypeReport)<<8, uint16(dev.Number()), data)
+	if err != nil {
+		return nil, err
 	}
+	return data[:n], nil
 }
 
+// Layout returns the reports described by the report descriptor. It is parsed on first use.
+func (dev *Device) Layout() (*report.Layout, error) {
+	if dev.layout == nil {
+		layout, err := report.Parse(dev.ReportDescriptor)
+		if err != nil {
+			return nil, err
+		}
+		dev.layout = layout
+	}
+	return dev.layout, nil
+}
+
+// ReadMax reads one input report into a buffer large enough for the longest input report.
 func (dev *Device) ReadMax() ([]byte, error) {
-	size := dev.EpIn.WMaxPacketSize
+	size := int(dev.EpIn.WMaxPacketSize & 0x7FF)
+	if layout, err := dev.Layout(); err == nil && layout.MaxSize(report.TypeInput) > size {
+		size = layout.MaxSize(report.TypeInput)
+	}
 	buffer := make([]byte, size)
-	x, err := dev.Device.BulkTimeout(dev.EpIn.BEndpointAddress, buffer, 100)
+	x, err := dev.Read(buffer)
 	if err != nil {
 		return nil, err
 	}
 	return buffer[0:x], nil
 }
 
+// Read reads one input report from the interrupt IN endpoint.
 func (dev *Device) Read(buff []byte) (int, error) {
-	x, err := dev.Device.BulkTimeout(dev.EpIn.BEndpointAddress, buff, 100)
+	x, err := dev.Device.InterruptTimeout(dev.EpIn.BEndpointAddress, buff, 100)
 	return x, err
 }
 
+// ReadValues reads one input report and decodes it with the report layout.
+func (dev *Device) ReadValues() ([]report.Value, error) {
+	layout, err := dev.Layout()
+	if err != nil {
+		return nil, err
+	}
+	data, err := dev.ReadMax()
+	if err != nil {
+		return nil, err
+	}
+	return layout.Decode(data)
+}
+
+// Write sends an output report, prefixed by the report ID if the descriptor uses report IDs.
+// Interfaces without an interrupt OUT endpoint receive the report with SET_REPORT, the report must
+// then have the length of the output report in the layout, as for SetReport.
 func (dev *Device) Write(data []byte) (int, error) {
-	x, err := dev.Device.BulkTimeout(dev.EpOut.BEndpointAddress, data, 1000)
-	return x, err
+	if dev.EpOut != nil {
+		x, err := dev.Device.InterruptTimeout(dev.EpOut.BEndpointAddress, data, 1000)
+		return x, err
+	}
+	layout, err := dev.Layout()
+	if err != nil {
+		return 0, err
+	}
+	id := uint8(0)
+	if layout.Descriptor.ReportIDs {
+		if len(data) == 0 {
+			return 0, fmt.Errorf("empty output report")
+		}
+		id = data[0]
+	}
+	if err := dev.checkReport(report.TypeOutput, id, data); err != nil {
+		return 0, err
+	}
+	return dev.setReport(report.TypeOutput, id, data)
 }
 
-// Work in progress....
-func (dev *Device) GetReportDescriptor() {
-	//	buf := make([]byte, dev.HidDescriptor.DescriptorLength)
-	x, err := dev.Device.GetDescriptorData(DescriptorTypeReport, 0, dev.HidDescriptor.DescriptorLength)
-	log.Printf("%X %v\n ", x, err)
+// report returns the report of a type and ID from the layout.
+func (dev *Device) report(typ report.Type, id uint8) (*report.Report, error) {
+	layout, err := dev.Layout()
+	if err != nil {
+		return nil, err
+	}
+	r := layout.Report(typ, id)
+	if r == nil {
+		return nil, fmt.Errorf("interface %d has no %v report %d", dev.Number(), typ, id)
+	}
+	return r, nil
 }
 
-func (dev *Device) GetReport() ([]byte, error) {
-	data := make([]byte, dev.HidDescriptor.DescriptorLength)
+// GetReport reads a report of any type with GET_REPORT. The data is prefixed by the report ID if
+// the descriptor uses report IDs, and can be decoded with the DecodeType method of the layout.
+func (dev *Device) GetReport(typ report.Type, id uint8) ([]byte, error) {
+	r, err := dev.report(typ, id)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, r.Size())
 	reqType := usb.RequestDirectionIn | usb.RequestTypeClass | usb.RequestRecipientInterface
-	value := uint16(0x01)<<8 | uint16(0)
-	idx := uint16(1)
-	_, err := dev.Device.Ctrl(reqType, GetReport, value, idx, data)
+	value := uint16(typ)<<8 | uint16(id)
+	n, err := dev.Device.Ctrl(reqType, GetReport, value, uint16(dev.Number()), data)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return data[:n], nil
 }
 
-func (dev *Device) SetReport() {
-	panic("Implement me")
+// SetReport sends a report of any type with SET_REPORT. The data must be prefixed by the report ID
+// if the descriptor uses report IDs, and have the length of the report in the layout.
+func (dev *Device) SetReport(typ report.Type, id uint8, data []byte) error {
+	if err := dev.checkReport(typ, id, data); err != nil {
+		return err
+	}
+	_, err := dev.setReport(typ, id, data)
+	return err
+}
+
+// checkReport returns an error if data does not have the length of the report in the layout.
+func (dev *Device) checkReport(typ report.Type, id uint8, data []byte) error {
+	r, err := dev.report(typ, id)
+	if err != nil {
+		return err
+	}
+	if len(data) != r.Size() {
+		return fmt.Errorf("%v report %d: %d bytes, expected %d", typ, id, len(data), r.Size())
+	}
+	return nil
+}
+
+func (dev *Device) setReport(typ report.Type, id uint8, data []byte) (int, error) {
+	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
+	value := uint16(typ)<<8 | uint16(id)
+	return dev.Device.Ctrl(reqType, SetReport, value, uint16(dev.Number()), data)
 }
 
-func (dev *Device) GetIdle(interfaceIdx, reportId uint8) (int, error) {
+// GetIdle returns the idle rate of an input report in units of 4 ms, 0 meaning the report is
+// only sent when its data changes. Report ID 0 reads the rate of all input reports.
+func (dev *Device) GetIdle(reportId uint8) (int, error) {
 	data := []byte{0}
 	reqType := usb.RequestDirectionIn | usb.RequestTypeClass | usb.RequestRecipientInterface
-	_, err := dev.Device.Ctrl(reqType, GetIdle, uint16(reportId), uint16(interfaceIdx), data)
+	n, err := dev.Device.Ctrl(reqType, GetIdle, uint16(reportId), uint16(dev.Number()), data)
 	if err != nil {
 		return 0, err
 	}
+	if n != 1 {
+		return 0, fmt.Errorf("GET_IDLE returned %d bytes, expected 1", n)
+	}
 	return int(data[0]), nil
 }
 
-func (dev *Device) SetIdle(interfaceIdx, reportId, duration uint8) error {
+// SetIdle sets the idle rate of an input report in units of 4 ms, see GetIdle.
+// Report ID 0 sets the rate of all input reports.
+func (dev *Device) SetIdle(reportId, duration uint8) error {
 	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
 	value := (uint16(duration) << 8) | uint16(reportId)
-	idx := uint16(interfaceIdx)
-	_, err := dev.Device.Ctrl(reqType, SetIdle, value, idx, nil)
+	_, err := dev.Device.Ctrl(reqType, SetIdle, value, uint16(dev.Number()), nil)
 	return err
 }
 
+// GetProtocol returns the active protocol of a boot interface, ProtocolBoot or ProtocolReport.
+func (dev *Device) GetProtocol() (uint8, error) {
+	data := []byte{0}
+	reqType := usb.RequestDirectionIn | usb.RequestTypeClass | usb.RequestRecipientInterface
+	n, err := dev.Device.Ctrl(reqType, GetProtocol, 0, uint16(dev.Number()), data)
+	if err != nil {
+		return 0, err
+	}
+	if n != 1 {
+		return 0, fmt.Errorf("GET_PROTOCOL returned %d bytes, expected 1", n)
+	}
+	return data[0], nil
+}
+
+// SetProtocol switches a boot interface between the boot protocol and the report protocol.
+// In the boot protocol the reports have the fixed boot layout instead of the report descriptor layout.
+func (dev *Device) SetProtocol(protocol uint8) error {
+	reqType := usb.RequestDirectionOut | usb.RequestTypeClass | usb.RequestRecipientInterface
+	_, err := dev.Device.Ctrl(reqType, SetProtocol, uint16(protocol), uint16(dev.Number()), nil)
+	return err
+}
+
+// hidUSBFilter matches devices with a HID interface in any configuration, from the sysfs descriptors.
 func hidUSBFilter(device *usb.Device) bool {
-	for _, desc := range device.Descriptors {
-		if _, ok := desc.(*Descriptor); ok {
+	set, err := device.GetSysfsDescriptorSet()
+	if err != nil {
+		return false
+	}
+	for _, cfg := range set.Configurations {
+		if len(Interfaces(cfg)) > 0 {
 			return true
 		}
 	}
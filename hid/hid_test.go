@@ -0,0 +1,247 @@
+package hid
+
+import (
+	"bytes"
+	"errors"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/hid/item"
+	"github.com/daedaluz/gousb/hid/report"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+// bootKeyboard is the report descriptor of appendix E.6 of HID 1.11.
+var bootKeyboard = []byte{
+	0x05, 0x01, 0x09, 0x06, 0xa1, 0x01, 0x05, 0x07, 0x19, 0xe0, 0x29, 0xe7, 0x15, 0x00, 0x25, 0x01,
+	0x75, 0x01, 0x95, 0x08, 0x81, 0x02, 0x95, 0x01, 0x75, 0x08, 0x81, 0x01, 0x95, 0x05, 0x75, 0x01,
+	0x05, 0x08, 0x19, 0x01, 0x29, 0x05, 0x91, 0x02, 0x95, 0x01, 0x75, 0x03, 0x91, 0x01, 0x95, 0x06,
+	0x75, 0x08, 0x15, 0x00, 0x25, 0x65, 0x05, 0x07, 0x19, 0x00, 0x29, 0x65, 0x81, 0x00, 0xc0,
+}
+
+// keyboard simulates a boot keyboard on interface 1 without an interrupt OUT endpoint,
+// behind a vendor specific interface 0. Output reports received with SET_REPORT are appended to output.
+// It answers GET_REPORT with the input report and stores the idle rate and protocol.
+func keyboard(t *testing.T, output *[][]byte) *sim.Device {
+	hidDesc := &Descriptor{BcdHID: 0x0111, NumDescriptors: 1, DescriptorType: uint8(DescriptorTypeReport), DescriptorLength: uint16(len(bootKeyboard))}
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0110, BMaxPacketSize0: 8, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0xA0, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 0, BInterfaceClass: usb.ClassCodeVendorSpecific}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceNumber: 1, BNumEndpoints: 1, BInterfaceClass: usb.ClassCodeInterfaceHID, BInterfaceSubClass: 1, BInterfaceProtocol: 1}, hidDesc).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, protocol := uint8(125), uint8(ProtocolReport)
+	res.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		switch {
+		case setup.Type() == usb.RequestTypeStandard && setup.Request == usb.ReqGetDescriptor &&
+			setup.Value == uint16(DescriptorTypeReport)<<8 && setup.Index == 1:
+			return copy(data, bootKeyboard), nil
+		case setup.Type() == usb.RequestTypeClass && setup.Request == SetReport && setup.Value == 0x0200 && setup.Index == 1:
+			*output = append(*output, append([]byte(nil), data...))
+			return len(data), nil
+		case setup.Type() != usb.RequestTypeClass || setup.Index != 1:
+		case setup.Request == GetReport && setup.Value == 0x0100:
+			return copy(data, []byte{0x02, 0, 0x04, 0, 0, 0, 0, 0}), nil
+		case setup.Request == GetIdle && setup.Value == 0:
+			data[0] = idle
+			return 1, nil
+		case setup.Request == SetIdle && uint8(setup.Value) == 0:
+			idle = uint8(setup.Value >> 8)
+			return 0, nil
+		case setup.Request == GetProtocol:
+			data[0] = protocol
+			return 1, nil
+		case setup.Request == SetProtocol && setup.Value <= ProtocolReport:
+			protocol = uint8(setup.Value)
+			return 0, nil
+		}
+		return 0, syscall.EPIPE
+	}
+	res.Interrupt = func(ep uint8, data []byte) (int, error) {
+		return copy(data, []byte{0x02, 0, 0x04, 0, 0, 0, 0, 0}), nil
+	}
+	return res
+}
+
+// open opens the simulated keyboard and returns the handle of its HID interface.
+func open(t *testing.T, simDev *sim.Device) *Device {
+	dev := simDev.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHIDDevices(dev); err == nil {
+		t.Fatal("expected an error for an unconfigured device")
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	handles, err := NewHIDDevices(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handles) != 1 {
+		t.Fatalf("expected 1 HID interface, got %d", len(handles))
+	}
+	return handles[0]
+}
+
+func TestDevice(t *testing.T) {
+	var output [][]byte
+	simDev := keyboard(t, &output)
+	kbd := open(t, simDev)
+	defer kbd.Device.Close()
+	if kbd.Number() != 1 || kbd.EpIn.BEndpointAddress != 0x81 || kbd.EpOut != nil || !simDev.Claimed(1) {
+		t.Fatalf("unexpected handle %+v", kbd)
+	}
+	if !bytes.Equal(kbd.ReportDescriptor, bootKeyboard) {
+		t.Fatalf("unexpected report descriptor % x", kbd.ReportDescriptor)
+	}
+
+	values, err := kbd.ReadValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 9 || values[1].Value != 1 || values[8].Usage != item.NewUsage(0x07, 0x04) {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if n, err := kbd.Write([]byte{0x01}); err != nil || n != 1 {
+		t.Fatalf("write = %d, %v", n, err)
+	}
+	if len(output) != 1 || !bytes.Equal(output[0], []byte{0x01}) {
+		t.Fatalf("unexpected output reports %v", output)
+	}
+
+	if err := kbd.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if simDev.Claimed(1) {
+		t.Fatal("interface still claimed")
+	}
+}
+
+func TestRequests(t *testing.T) {
+	var output [][]byte
+	kbd := open(t, keyboard(t, &output))
+	defer kbd.Device.Close()
+
+	input, err := kbd.GetReport(report.TypeInput, 0)
+	if err != nil || !bytes.Equal(input, []byte{0x02, 0, 0x04, 0, 0, 0, 0, 0}) {
+		t.Fatalf("input report = % x, %v", input, err)
+	}
+	if _, err := kbd.GetReport(report.TypeFeature, 0); err == nil {
+		t.Fatal("expected an error for a report not in the layout")
+	}
+	if err := kbd.SetReport(report.TypeOutput, 0, []byte{0x04}); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbd.SetReport(report.TypeOutput, 0, []byte{0x04, 0}); err == nil {
+		t.Fatal("expected an error for a report of the wrong length")
+	}
+	if len(output) != 1 || !bytes.Equal(output[0], []byte{0x04}) {
+		t.Fatalf("unexpected output reports %v", output)
+	}
+
+	if idle, err := kbd.GetIdle(0); err != nil || idle != 125 {
+		t.Fatalf("idle = %d, %v", idle, err)
+	}
+	if err := kbd.SetIdle(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if idle, err := kbd.GetIdle(0); err != nil || idle != 0 {
+		t.Fatalf("idle after SET_IDLE = %d, %v", idle, err)
+	}
+	if err := kbd.SetProtocol(ProtocolBoot); err != nil {
+		t.Fatal(err)
+	}
+	if protocol, err := kbd.GetProtocol(); err != nil || protocol != ProtocolBoot {
+		t.Fatalf("protocol = %d, %v", protocol, err)
+	}
+}
+
+func TestDescriptorMarshal(t *testing.T) {
+	// A HID descriptor listing a report and two physical descriptors.
+	data := []byte{0x0F, 0x21, 0x11, 0x01, 0x00, 0x03, 0x22, 0x3F, 0x00, 0x23, 0x10, 0x00, 0x23, 0x08, 0x00}
+	desc, err := usb.ParseDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hidDesc, ok := desc.(*Descriptor)
+	if !ok || hidDesc.OptionalDescriptorLength != 0x10 {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	encoded, err := hidDesc.MarshalBinary()
+	if err != nil || !bytes.Equal(encoded, data) {
+		t.Fatalf("round trip mismatch: % x, %v", encoded, err)
+	}
+	hidDesc.Trailing = nil
+	if _, err := hidDesc.MarshalBinary(); err == nil {
+		t.Fatal("expected an error for a missing class descriptor entry")
+	}
+}
+
+func TestKernelDriver(t *testing.T) {
+	var output [][]byte
+	simDev := keyboard(t, &output)
+	simDev.Drivers = map[uint8]string{1: "usbhid"}
+	kbd := open(t, simDev)
+	defer kbd.Device.Close()
+	if !simDev.Detached(1) || !simDev.Claimed(1) {
+		t.Fatal("expected usbhid to be detached and the interface claimed")
+	}
+	if err := kbd.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if simDev.Detached(1) || simDev.Claimed(1) {
+		t.Fatal("expected usbhid to be reattached")
+	}
+
+	// An interface claimed by another process is left alone.
+	simDev = keyboard(t, &output)
+	simDev.Drivers = map[uint8]string{1: "usbfs"}
+	dev := simDev.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHIDDevices(dev); !errors.Is(err, syscall.EBUSY) {
+		t.Fatalf("expected EBUSY for an interface claimed through usbfs, got %v", err)
+	}
+	if simDev.Detached(1) || simDev.Claimed(1) {
+		t.Fatal("the usbfs claim of the other process was taken over")
+	}
+}
+
+func TestReplyLength(t *testing.T) {
+	var output [][]byte
+	simDev := keyboard(t, &output)
+	control := simDev.Control
+	simDev.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Type() == usb.RequestTypeClass && (setup.Request == GetIdle || setup.Request == GetProtocol) {
+			return 0, nil
+		}
+		return control(setup, data)
+	}
+	kbd := open(t, simDev)
+	defer kbd.Device.Close()
+	if idle, err := kbd.GetIdle(0); err == nil {
+		t.Fatalf("expected an error for an empty GET_IDLE reply, got %d", idle)
+	}
+	if protocol, err := kbd.GetProtocol(); err == nil {
+		t.Fatalf("expected an error for an empty GET_PROTOCOL reply, got %d", protocol)
+	}
+
+	// Without an interrupt OUT endpoint, Write checks the length like SetReport.
+	for _, data := range [][]byte{{}, {0x01, 0x00}} {
+		if n, err := kbd.Write(data); err == nil {
+			t.Fatalf("expected an error writing % x, wrote %d bytes", data, n)
+		}
+	}
+	if len(output) != 0 {
+		t.Fatalf("unexpected output reports %v", output)
+	}
+}
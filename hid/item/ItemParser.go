@@ -1,3 +1,554 @@
 package item
 
+import (
+	"fmt"
+	"github.com/daedaluz/gousb/hid/usage"
+)
 
+// Usage is an extended usage: the usage page in the high 16 bits and the usage ID in the low 16 bits.
+type Usage uint32
+
+// NewUsage returns the extended usage of a usage ID on a page.
+func NewUsage(page, id uint16) Usage {
+	return Usage(page)<<16 | Usage(id)
+}
+
+// Page returns the usage page.
+func (u Usage) Page() uint16 {
+	return uint16(u >> 16)
+}
+
+// ID returns the usage ID.
+func (u Usage) ID() uint16 {
+	return uint16(u)
+}
+
+// String returns the page and usage names, eg. "Generic Desktop/X".
+func (u Usage) String() string {
+	return usage.String(u.Page(), u.ID())
+}
+
+// UsageRange is a Usage Minimum and Usage Maximum pair, a single Usage has Minimum == Maximum.
+type UsageRange struct {
+	Minimum Usage
+	Maximum Usage
+}
+
+// Len returns the number of usages in the range.
+func (r UsageRange) Len() int {
+	return int(r.Maximum.ID()) - int(r.Minimum.ID()) + 1
+}
+
+// Contains reports whether u is in the range.
+func (r UsageRange) Contains(u Usage) bool {
+	return u.Page() == r.Minimum.Page() && u.ID() >= r.Minimum.ID() && u.ID() <= r.Maximum.ID()
+}
+
+// Range is an index range of designator or string local items, a single index has Minimum == Maximum.
+type Range struct {
+	Minimum uint32
+	Maximum uint32
+}
+
+// Global is the global item state in effect for a main item.
+type Global struct {
+	UsagePage       uint16
+	LogicalMinimum  int32
+	LogicalMaximum  int32
+	PhysicalMinimum int32
+	PhysicalMaximum int32
+	UnitExponent    int32
+	Unit            uint32
+	ReportSize      uint32
+	ReportID        uint8
+	ReportCount     uint32
+}
+
+// Local is the local item state of a main item.
+type Local struct {
+	// Usages are the usages and usage ranges in the order they were declared. Within a delimiter
+	// set only the first usage, the preferred one, is included.
+	Usages []UsageRange
+	// Alternatives holds the usages of every delimiter set, in the order of the sets. Each entry
+	// lists the preferred usage first followed by its alternates.
+	Alternatives [][]UsageRange
+	Designators  []Range
+	Strings      []Range
+}
+
+// Usage returns the usage with an index into the usages of a main item. Usages past the end
+// repeat the last usage, as a report count larger than the number of usages does in HID 1.11.
+func (l *Local) Usage(index int) (Usage, bool) {
+	for _, r := range l.Usages {
+		if index < r.Len() {
+			return r.Minimum + Usage(index), true
+		}
+		index -= r.Len()
+	}
+	if len(l.Usages) == 0 {
+		return 0, false
+	}
+	return l.Usages[len(l.Usages)-1].Maximum, true
+}
+
+// Count returns the number of usages, ranges counted by their length.
+func (l *Local) Count() int {
+	n := 0
+	for _, r := range l.Usages {
+		n += r.Len()
+	}
+	return n
+}
+
+// Flags are the data bits of Input, Output and Feature items.
+type Flags uint32
+
+const (
+	FlagConstant      = Flags(1 << 0)
+	FlagVariable      = Flags(1 << 1)
+	FlagRelative      = Flags(1 << 2)
+	FlagWrap          = Flags(1 << 3)
+	FlagNonLinear     = Flags(1 << 4)
+	FlagNoPreferred   = Flags(1 << 5)
+	FlagNullState     = Flags(1 << 6)
+	FlagVolatile      = Flags(1 << 7)
+	FlagBufferedBytes = Flags(1 << 8)
+)
+
+var flagNames = []struct {
+	flag     Flags
+	set, clr string
+}{
+	{FlagConstant, "Cnst", "Data"},
+	{FlagVariable, "Var", "Ary"},
+	{FlagRelative, "Rel", "Abs"},
+	{FlagWrap, "Wrap", ""},
+	{FlagNonLinear, "NLin", ""},
+	{FlagNoPreferred, "NPrf", ""},
+	{FlagNullState, "Null", ""},
+	{FlagVolatile, "Vol", ""},
+	{FlagBufferedBytes, "Buf", ""},
+}
+
+func (f Flags) Constant() bool {
+	return f&FlagConstant != 0
+}
+
+func (f Flags) Variable() bool {
+	return f&FlagVariable != 0
+}
+
+func (f Flags) Relative() bool {
+	return f&FlagRelative != 0
+}
+
+func (f Flags) NullState() bool {
+	return f&FlagNullState != 0
+}
+
+// String returns the abbreviations of the HID specification, eg. "Data,Var,Abs".
+func (f Flags) String() string {
+	res := ""
+	for _, name := range flagNames {
+		s := name.clr
+		if f&name.flag != 0 {
+			s = name.set
+		}
+		if s == "" {
+			continue
+		}
+		if res != "" {
+			res += ","
+		}
+		res += s
+	}
+	return res
+}
+
+// CollectionType is the data of a Collection item.
+type CollectionType uint8
+
+const (
+	CollectionPhysical      = CollectionType(0x00)
+	CollectionApplication   = CollectionType(0x01)
+	CollectionLogical       = CollectionType(0x02)
+	CollectionReport        = CollectionType(0x03)
+	CollectionNamedArray    = CollectionType(0x04)
+	CollectionUsageSwitch   = CollectionType(0x05)
+	CollectionUsageModifier = CollectionType(0x06)
+)
+
+func (t CollectionType) String() string {
+	switch t {
+	case CollectionPhysical:
+		return "Physical"
+	case CollectionApplication:
+		return "Application"
+	case CollectionLogical:
+		return "Logical"
+	case CollectionReport:
+		return "Report"
+	case CollectionNamedArray:
+		return "Named Array"
+	case CollectionUsageSwitch:
+		return "Usage Switch"
+	case CollectionUsageModifier:
+		return "Usage Modifier"
+	}
+	if t >= 0x80 {
+		return fmt.Sprintf("Vendor Defined 0x%.2x", uint8(t))
+	}
+	return fmt.Sprintf("Reserved 0x%.2x", uint8(t))
+}
+
+// Collection is a Collection item with the main items and collections up to its End Collection.
+type Collection struct {
+	Item *Item
+	Type CollectionType
+	// Usage is the first usage of the local state, 0 if the collection has none.
+	Usage  Usage
+	Global Global
+	Local  Local
+
+	Parent      *Collection
+	Collections []*Collection
+	Mains       []*Main
+}
+
+// Depth returns the nesting level, 0 for top level collections.
+func (c *Collection) Depth() int {
+	n := 0
+	for p := c.Parent; p != nil; p = p.Parent {
+		n++
+	}
+	return n
+}
+
+// Walk calls fn for the collection and all its descendants, depth first.
+func (c *Collection) Walk(fn func(c *Collection)) {
+	fn(c)
+	for _, child := range c.Collections {
+		child.Walk(fn)
+	}
+}
+
+// Main is an Input, Output or Feature item with the state it was declared in.
+type Main struct {
+	Item  *Item
+	Flags Flags
+	// Global and Local are the item states in effect for the main item.
+	Global Global
+	Local  Local
+	// Collection is the innermost enclosing collection, nil outside of any collection.
+	Collection *Collection
+}
+
+// Tag returns TagInput, TagOutput or TagFeature.
+func (m *Main) Tag() Tag {
+	return m.Item.Tag
+}
+
+// Descriptor is a parsed report descriptor.
+type Descriptor struct {
+	// Items are all items in descriptor order.
+	Items []*Item
+	// Collections are the top level collections.
+	Collections []*Collection
+	// Mains are all Input, Output and Feature items in descriptor order.
+	Mains []*Main
+	// ReportIDs reports whether the descriptor declares report IDs, in which case every report is prefixed by its ID.
+	ReportIDs bool
+}
+
+// Walk calls fn for every collection, depth first.
+func (d *Descriptor) Walk(fn func(c *Collection)) {
+	for _, c := range d.Collections {
+		c.Walk(fn)
+	}
+}
+
+// parser holds the item state while evaluating a descriptor.
+type parser struct {
+	desc   *Descriptor
+	global Global
+	stack  []Global
+	local  Local
+
+	// usageMinimum is set between a Usage Minimum and its Usage Maximum.
+	usageMinimum *Item
+	// designatorMinimum and stringMinimum likewise for their ranges.
+	designatorMinimum *Item
+	stringMinimum     *Item
+	// delimiter is the open delimiter set, nil outside of a delimiter.
+	delimiter *Item
+	set       []UsageRange
+
+	collection *Collection
+}
+
+// ParseDescriptor parses and evaluates a report descriptor.
+//
+// Usage IDs of 1 or 2 bytes are extended with the usage page in effect when the usage is declared,
+// 4 byte usages carry their own page. Unknown tags, Report ID 0, a Pop without Push, unbalanced
+// collections and delimiters, and incomplete ranges are errors.
+func ParseDescriptor(data []byte) (*Descriptor, error) {
+	items, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(items)
+}
+
+// Evaluate builds the collection tree and main items of a list of items, see ParseDescriptor.
+func Evaluate(items []*Item) (*Descriptor, error) {
+	p := &parser{desc: &Descriptor{Items: items}}
+	for _, item := range items {
+		if err := p.item(item); err != nil {
+			return nil, err
+		}
+	}
+	if p.delimiter != nil {
+		return nil, &Error{Offset: p.delimiter.Offset, Item: p.delimiter, Reason: "delimiter not closed"}
+	}
+	if p.collection != nil {
+		return nil, &Error{Offset: p.collection.Item.Offset, Item: p.collection.Item, Reason: "collection not closed"}
+	}
+	for _, m := range p.desc.Mains {
+		if p.desc.ReportIDs && m.Global.ReportID == 0 && m.Global.ReportSize*m.Global.ReportCount != 0 {
+			return nil, errorf(m.Item, "report data without report ID in a descriptor using report IDs")
+		}
+	}
+	return p.desc, nil
+}
+
+func errorf(item *Item, format string, args ...interface{}) error {
+	return &Error{Offset: item.Offset, Item: item, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) item(item *Item) error {
+	if item.Long {
+		// HID 1.11 defines no long item tags, they are kept in the item list only.
+		return nil
+	}
+	if !item.Tag.Known() {
+		return errorf(item, "unknown %s item tag", item.Type())
+	}
+	switch item.Type() {
+	case TypeMain:
+		return p.main(item)
+	case TypeGlobal:
+		return p.globalItem(item)
+	}
+	return p.localItem(item)
+}
+
+func (p *parser) globalItem(item *Item) error {
+	g := &p.global
+	switch item.Tag {
+	case TagUsagePage:
+		if item.Unsigned() > 0xFFFF {
+			return errorf(item, "usage page out of range")
+		}
+		g.UsagePage = uint16(item.Unsigned())
+	case TagLogicalMinimum:
+		g.LogicalMinimum = item.Signed()
+	case TagLogicalMaximum:
+		g.LogicalMaximum = item.Signed()
+	case TagPhysicalMinimum:
+		g.PhysicalMinimum = item.Signed()
+	case TagPhysicalMaximum:
+		g.PhysicalMaximum = item.Signed()
+	case TagUnitExponent:
+		g.UnitExponent = item.Signed()
+	case TagUnit:
+		g.Unit = item.Unsigned()
+	case TagReportSize:
+		g.ReportSize = item.Unsigned()
+	case TagReportID:
+		if item.Unsigned() == 0 || item.Unsigned() > 0xFF {
+			return errorf(item, "report ID must be 1-255")
+		}
+		g.ReportID = uint8(item.Unsigned())
+		p.desc.ReportIDs = true
+	case TagReportCount:
+		g.ReportCount = item.Unsigned()
+	case TagPush:
+		if len(item.Data) != 0 {
+			return errorf(item, "push has data")
+		}
+		p.stack = append(p.stack, *g)
+	case TagPop:
+		if len(item.Data) != 0 {
+			return errorf(item, "pop has data")
+		}
+		if len(p.stack) == 0 {
+			return errorf(item, "pop without push")
+		}
+		*g = p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	return nil
+}
+
+// usage extends a usage item with the current usage page.
+func (p *parser) usage(item *Item) Usage {
+	if len(item.Data) == 4 {
+		return Usage(item.Unsigned())
+	}
+	return NewUsage(p.global.UsagePage, uint16(item.Unsigned()))
+}
+
+func (p *parser) localItem(item *Item) error {
+	l := &p.local
+	if p.delimiter != nil {
+		switch item.Tag {
+		case TagUsage, TagUsageMinimum, TagUsageMaximum, TagDelimiter:
+		default:
+			return errorf(item, "only usages are allowed in a delimiter set")
+		}
+	}
+	if p.usageMinimum != nil && item.Tag != TagUsageMaximum {
+		return errorf(p.usageMinimum, "usage minimum without usage maximum")
+	}
+	switch item.Tag {
+	case TagUsage:
+		p.addUsage(UsageRange{p.usage(item), p.usage(item)})
+	case TagUsageMinimum:
+		p.usageMinimum = item
+	case TagUsageMaximum:
+		if p.usageMinimum == nil {
+			return errorf(item, "usage maximum without usage minimum")
+		}
+		r := UsageRange{p.usage(p.usageMinimum), p.usage(item)}
+		p.usageMinimum = nil
+		if r.Minimum.Page() != r.Maximum.Page() {
+			return errorf(item, "usage range spans usage pages")
+		}
+		if r.Minimum > r.Maximum {
+			return errorf(item, "usage minimum %v larger than maximum", r.Minimum)
+		}
+		p.addUsage(r)
+	case TagDesignatorIndex:
+		l.Designators = append(l.Designators, Range{item.Unsigned(), item.Unsigned()})
+	case TagDesignatorMinimum:
+		p.designatorMinimum = item
+	case TagDesignatorMaximum:
+		r, err := indexRange(p.designatorMinimum, item)
+		if err != nil {
+			return err
+		}
+		p.designatorMinimum = nil
+		l.Designators = append(l.Designators, r)
+	case TagStringIndex:
+		l.Strings = append(l.Strings, Range{item.Unsigned(), item.Unsigned()})
+	case TagStringMinimum:
+		p.stringMinimum = item
+	case TagStringMaximum:
+		r, err := indexRange(p.stringMinimum, item)
+		if err != nil {
+			return err
+		}
+		p.stringMinimum = nil
+		l.Strings = append(l.Strings, r)
+	case TagDelimiter:
+		switch item.Unsigned() {
+		case 1:
+			if p.delimiter != nil {
+				return errorf(item, "nested delimiter")
+			}
+			p.delimiter = item
+			p.set = nil
+		case 0:
+			if p.delimiter == nil {
+				return errorf(item, "delimiter close without open")
+			}
+			if len(p.set) == 0 {
+				return errorf(item, "empty delimiter set")
+			}
+			l.Usages = append(l.Usages, p.set[0])
+			l.Alternatives = append(l.Alternatives, p.set)
+			p.delimiter = nil
+			p.set = nil
+		default:
+			return errorf(item, "invalid delimiter value")
+		}
+	}
+	return nil
+}
+
+func (p *parser) addUsage(r UsageRange) {
+	if p.delimiter != nil {
+		p.set = append(p.set, r)
+		return
+	}
+	p.local.Usages = append(p.local.Usages, r)
+}
+
+func indexRange(minimum, maximum *Item) (Range, error) {
+	if minimum == nil {
+		return Range{}, errorf(maximum, "maximum without minimum")
+	}
+	r := Range{minimum.Unsigned(), maximum.Unsigned()}
+	if r.Minimum > r.Maximum {
+		return Range{}, errorf(maximum, "minimum %d larger than maximum", r.Minimum)
+	}
+	return r, nil
+}
+
+func (p *parser) main(item *Item) error {
+	if p.delimiter != nil {
+		return errorf(p.delimiter, "delimiter not closed before %v", item.Tag)
+	}
+	for _, open := range []*Item{p.usageMinimum, p.designatorMinimum, p.stringMinimum} {
+		if open != nil {
+			return errorf(open, "minimum without maximum")
+		}
+	}
+	switch item.Tag {
+	case TagCollection:
+		if item.Unsigned() > 0xFF {
+			return errorf(item, "collection type out of range")
+		}
+		c := &Collection{
+			Item:   item,
+			Type:   CollectionType(item.Unsigned()),
+			Global: p.global,
+			Local:  p.local,
+			Parent: p.collection,
+		}
+		if len(p.local.Usages) > 0 {
+			c.Usage = p.local.Usages[0].Minimum
+		}
+		if p.collection != nil {
+			p.collection.Collections = append(p.collection.Collections, c)
+		} else {
+			p.desc.Collections = append(p.desc.Collections, c)
+		}
+		p.collection = c
+	case TagEndCollection:
+		if len(item.Data) != 0 {
+			return errorf(item, "end collection has data")
+		}
+		if p.collection == nil {
+			return errorf(item, "end collection without collection")
+		}
+		p.collection = p.collection.Parent
+	default:
+		if p.global.ReportSize == 0 && p.global.ReportCount != 0 {
+			return errorf(item, "report size is 0")
+		}
+		m := &Main{
+			Item:       item,
+			Flags:      Flags(item.Unsigned()),
+			Global:     p.global,
+			Local:      p.local,
+			Collection: p.collection,
+		}
+		if p.collection != nil {
+			p.collection.Mains = append(p.collection.Mains, m)
+		}
+		p.desc.Mains = append(p.desc.Mains, m)
+	}
+	p.local = Local{}
+	return nil
+}
@@ -0,0 +1,54 @@
+package item
+
+import (
+	"fmt"
+	"github.com/daedaluz/gousb/hid/usage"
+	"io"
+	"strings"
+)
+
+// Dump writes one line per item with its encoding and value, indented by collection nesting.
+// Usages are named with the usage page in effect, eg.
+//
+//	05 01        Usage Page (Generic Desktop)
+//	09 02        Usage (Mouse)
+//	a1 01        Collection (Application)
+//	  09 01        Usage (Pointer)
+func Dump(w io.Writer, items []*Item) error {
+	depth := 0
+	var page uint16
+	var stack []uint16
+	for _, item := range items {
+		if item.Tag == TagEndCollection && !item.Long && depth > 0 {
+			depth--
+		}
+		data, err := item.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		text := item.String()
+		switch {
+		case item.Long:
+		case item.Tag == TagUsagePage:
+			page = uint16(item.Unsigned())
+		case item.Tag == TagPush:
+			stack = append(stack, page)
+		case item.Tag == TagPop && len(stack) > 0:
+			page = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case item.Tag == TagUsage, item.Tag == TagUsageMinimum, item.Tag == TagUsageMaximum:
+			if len(item.Data) == 4 {
+				text = fmt.Sprintf("%v (%v)", item.Tag, Usage(item.Unsigned()))
+			} else {
+				text = fmt.Sprintf("%v (%s)", item.Tag, usage.Name(page, uint16(item.Unsigned())))
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s%-12s %s\n", strings.Repeat("  ", depth), fmt.Sprintf("% x", data), text); err != nil {
+			return err
+		}
+		if item.Tag == TagCollection && !item.Long {
+			depth++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+// Package item parses HID report descriptors as defined by the Device Class Definition for HID 1.11, section 6.2.2.
+//
+// Parse splits a descriptor into short and long items, ParseDescriptor also evaluates the items:
+// it tracks the global state including the Push and Pop stack, the local usages with usage ranges
+// and delimiter sets, and builds the collection tree. Malformed descriptors are rejected with an *Error.
+//
+// Documentation: https://www.usb.org/document-library/device-class-definition-hid-111
+package item
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/daedaluz/gousb/hid/usage"
+)
+
+// Type is the type of a short item.
+type Type uint8
+
+const (
+	TypeMain     = Type(0)
+	TypeGlobal   = Type(1)
+	TypeLocal    = Type(2)
+	TypeReserved = Type(3)
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeMain:
+		return "Main"
+	case TypeGlobal:
+		return "Global"
+	case TypeLocal:
+		return "Local"
+	}
+	return "Reserved"
+}
+
+// Tag identifies a short item, it is the item prefix without the size bits: bTag<<4 | bType<<2.
+type Tag uint8
+
+// Main item tags.
+const (
+	TagInput         = Tag(0x80)
+	TagOutput        = Tag(0x90)
+	TagCollection    = Tag(0xA0)
+	TagFeature       = Tag(0xB0)
+	TagEndCollection = Tag(0xC0)
+)
+
+// Global item tags.
+const (
+	TagUsagePage       = Tag(0x04)
+	TagLogicalMinimum  = Tag(0x14)
+	TagLogicalMaximum  = Tag(0x24)
+	TagPhysicalMinimum = Tag(0x34)
+	TagPhysicalMaximum = Tag(0x44)
+	TagUnitExponent    = Tag(0x54)
+	TagUnit            = Tag(0x64)
+	TagReportSize      = Tag(0x74)
+	TagReportID        = Tag(0x84)
+	TagReportCount     = Tag(0x94)
+	TagPush            = Tag(0xA4)
+	TagPop             = Tag(0xB4)
+)
+
+// Local item tags.
+const (
+	TagUsage             = Tag(0x08)
+	TagUsageMinimum      = Tag(0x18)
+	TagUsageMaximum      = Tag(0x28)
+	TagDesignatorIndex   = Tag(0x38)
+	TagDesignatorMinimum = Tag(0x48)
+	TagDesignatorMaximum = Tag(0x58)
+	TagStringIndex       = Tag(0x78)
+	TagStringMinimum     = Tag(0x88)
+	TagStringMaximum     = Tag(0x98)
+	TagDelimiter         = Tag(0xA8)
+)
+
+// prefixLong is the prefix of long items.
+const prefixLong = 0xFE
+
+var tagNames = map[Tag]string{
+	TagInput:             "Input",
+	TagOutput:            "Output",
+	TagCollection:        "Collection",
+	TagFeature:           "Feature",
+	TagEndCollection:     "End Collection",
+	TagUsagePage:         "Usage Page",
+	TagLogicalMinimum:    "Logical Minimum",
+	TagLogicalMaximum:    "Logical Maximum",
+	TagPhysicalMinimum:   "Physical Minimum",
+	TagPhysicalMaximum:   "Physical Maximum",
+	TagUnitExponent:      "Unit Exponent",
+	TagUnit:              "Unit",
+	TagReportSize:        "Report Size",
+	TagReportID:          "Report ID",
+	TagReportCount:       "Report Count",
+	TagPush:              "Push",
+	TagPop:               "Pop",
+	TagUsage:             "Usage",
+	TagUsageMinimum:      "Usage Minimum",
+	TagUsageMaximum:      "Usage Maximum",
+	TagDesignatorIndex:   "Designator Index",
+	TagDesignatorMinimum: "Designator Minimum",
+	TagDesignatorMaximum: "Designator Maximum",
+	TagStringIndex:       "String Index",
+	TagStringMinimum:     "String Minimum",
+	TagStringMaximum:     "String Maximum",
+	TagDelimiter:         "Delimiter",
+}
+
+// Type returns the item type encoded in the tag.
+func (t Tag) Type() Type {
+	return Type(t>>2) & 3
+}
+
+// Known reports whether the tag is defined by HID 1.11.
+func (t Tag) Known() bool {
+	_, exist := tagNames[t]
+	return exist
+}
+
+func (t Tag) String() string {
+	if name, exist := tagNames[t]; exist {
+		return name
+	}
+	return fmt.Sprintf("%v Tag(0x%.2x)", t.Type(), uint8(t)>>4)
+}
+
+// Item is a short or long item of a report descriptor.
+type Item struct {
+	// Tag is the tag of a short item, zero for long items.
+	Tag Tag
+	// Long is set for long items, LongTag is their bLongItemTag.
+	Long    bool
+	LongTag uint8
+	// Data holds 0, 1, 2 or 4 bytes for short items, up to 255 bytes for long items.
+	Data []byte
+	// Offset is the position of the item prefix in the descriptor.
+	Offset int
+}
+
+// Type returns the type of a short item, TypeReserved for long items.
+func (i *Item) Type() Type {
+	if i.Long {
+		return TypeReserved
+	}
+	return i.Tag.Type()
+}
+
+// Unsigned returns the data as an unsigned little endian value.
+func (i *Item) Unsigned() uint32 {
+	switch len(i.Data) {
+	case 1:
+		return uint32(i.Data[0])
+	case 2:
+		return uint32(binary.LittleEndian.Uint16(i.Data))
+	case 4:
+		return binary.LittleEndian.Uint32(i.Data)
+	}
+	return 0
+}
+
+// Signed returns the data as a two's complement little endian value.
+func (i *Item) Signed() int32 {
+	switch len(i.Data) {
+	case 1:
+		return int32(int8(i.Data[0]))
+	case 2:
+		return int32(int16(binary.LittleEndian.Uint16(i.Data)))
+	case 4:
+		return int32(binary.LittleEndian.Uint32(i.Data))
+	}
+	return 0
+}
+
+// Size returns the encoded size of the item including its prefix.
+func (i *Item) Size() int {
+	if i.Long {
+		return 3 + len(i.Data)
+	}
+	return 1 + len(i.Data)
+}
+
+func (i *Item) String() string {
+	if i.Long {
+		return fmt.Sprintf("Long Item (tag 0x%.2x, % X)", i.LongTag, i.Data)
+	}
+	switch i.Tag {
+	case TagLogicalMinimum, TagLogicalMaximum, TagPhysicalMinimum, TagPhysicalMaximum, TagUnitExponent:
+		return fmt.Sprintf("%v (%d)", i.Tag, i.Signed())
+	case TagReportSize, TagReportID, TagReportCount, TagDesignatorIndex, TagDesignatorMinimum, TagDesignatorMaximum,
+		TagStringIndex, TagStringMinimum, TagStringMaximum, TagDelimiter:
+		return fmt.Sprintf("%v (%d)", i.Tag, i.Unsigned())
+	case TagUsagePage:
+		return fmt.Sprintf("%v (%s)", i.Tag, usage.PageName(uint16(i.Unsigned())))
+	case TagEndCollection, TagPush, TagPop:
+		return i.Tag.String()
+	case TagInput, TagOutput, TagFeature:
+		return fmt.Sprintf("%v (%v)", i.Tag, Flags(i.Unsigned()))
+	case TagCollection:
+		return fmt.Sprintf("%v (%v)", i.Tag, CollectionType(i.Unsigned()))
+	}
+	return fmt.Sprintf("%v (0x%.*x)", i.Tag, len(i.Data)*2, i.Unsigned())
+}
+
+// MarshalBinary encodes the item.
+func (i *Item) MarshalBinary() ([]byte, error) {
+	if i.Long {
+		if len(i.Data) > 0xFF {
+			return nil, fmt.Errorf("long item data too long: %d bytes", len(i.Data))
+		}
+		return append([]byte{prefixLong, uint8(len(i.Data)), i.LongTag}, i.Data...), nil
+	}
+	var size uint8
+	switch len(i.Data) {
+	case 0, 1, 2:
+		size = uint8(len(i.Data))
+	case 4:
+		size = 3
+	default:
+		return nil, fmt.Errorf("invalid short item data size %d", len(i.Data))
+	}
+	return append([]byte{uint8(i.Tag&0xFC) | size}, i.Data...), nil
+}
+
+// New returns a short item with the smallest encoding of an unsigned value, no data for zero.
+func New(tag Tag, value uint32) *Item {
+	var data []byte
+	switch {
+	case value == 0:
+	case value <= 0xFF:
+		data = []byte{uint8(value)}
+	case value <= 0xFFFF:
+		data = make([]byte, 2)
+		binary.LittleEndian.PutUint16(data, uint16(value))
+	default:
+		data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, value)
+	}
+	return &Item{Tag: tag, Data: data}
+}
+
+// NewSigned returns a short item with the smallest encoding of a signed value, eg. a logical minimum.
+func NewSigned(tag Tag, value int32) *Item {
+	var data []byte
+	switch {
+	case value == 0:
+	case value >= -0x80 && value <= 0x7F:
+		data = []byte{uint8(value)}
+	case value >= -0x8000 && value <= 0x7FFF:
+		data = make([]byte, 2)
+		binary.LittleEndian.PutUint16(data, uint16(value))
+	default:
+		data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(value))
+	}
+	return &Item{Tag: tag, Data: data}
+}
+
+// Marshal encodes a list of items as a report descriptor.
+func Marshal(items []*Item) ([]byte, error) {
+	var res []byte
+	for _, item := range items {
+		data, err := item.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data...)
+	}
+	return res, nil
+}
+
+// Error is a malformed report descriptor.
+type Error struct {
+	Offset int
+	// Item is the offending item, nil if the descriptor could not be split into items.
+	Item   *Item
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Item != nil {
+		return fmt.Sprintf("hid report descriptor: offset %d: %v: %s", e.Offset, e.Item, e.Reason)
+	}
+	return fmt.Sprintf("hid report descriptor: offset %d: %s", e.Offset, e.Reason)
+}
+
+// Parse splits a report descriptor into items. Only the encoding is checked, see ParseDescriptor.
+func Parse(data []byte) ([]*Item, error) {
+	var res []*Item
+	for offset := 0; offset < len(data); {
+		prefix := data[offset]
+		item := &Item{Offset: offset}
+		if prefix == prefixLong {
+			if offset+3 > len(data) {
+				return nil, &Error{Offset: offset, Reason: "truncated long item"}
+			}
+			size := int(data[offset+1])
+			item.Long = true
+			item.LongTag = data[offset+2]
+			if offset+3+size > len(data) {
+				return nil, &Error{Offset: offset, Reason: fmt.Sprintf("truncated long item, %d data bytes missing", offset+3+size-len(data))}
+			}
+			item.Data = data[offset+3 : offset+3+size]
+		} else {
+			size := int(prefix & 3)
+			if size == 3 {
+				size = 4
+			}
+			item.Tag = Tag(prefix & 0xFC)
+			if offset+1+size > len(data) {
+				return nil, &Error{Offset: offset, Reason: fmt.Sprintf("truncated %v item, %d data bytes missing", item.Tag, offset+1+size-len(data))}
+			}
+			item.Data = data[offset+1 : offset+1+size]
+		}
+		res = append(res, item)
+		offset += item.Size()
+	}
+	return res, nil
+}
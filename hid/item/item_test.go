@@ -0,0 +1,407 @@
+package item
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readDescriptor(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.bin")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no descriptors in testdata: %v", err)
+	}
+	for _, file := range files {
+		data := readDescriptor(t, filepath.Base(file))
+		desc, err := ParseDescriptor(data)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		encoded, err := Marshal(desc.Items)
+		if err != nil || !bytes.Equal(encoded, data) {
+			t.Fatalf("%s: round trip mismatch: % x, %v", file, encoded, err)
+		}
+		if len(desc.Collections) == 0 || len(desc.Mains) == 0 {
+			t.Fatalf("%s: no collections or main items", file)
+		}
+	}
+}
+
+func TestKeyboard(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "keyboard.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Collections) != 1 || desc.ReportIDs {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	app := desc.Collections[0]
+	if app.Type != CollectionApplication || app.Usage != NewUsage(0x01, 0x06) || len(app.Mains) != 5 {
+		t.Fatalf("unexpected application collection %+v", app)
+	}
+	modifiers := app.Mains[0]
+	if modifiers.Tag() != TagInput || modifiers.Flags != FlagVariable || modifiers.Global.ReportSize != 1 ||
+		modifiers.Global.ReportCount != 8 || modifiers.Local.Count() != 8 {
+		t.Fatalf("unexpected modifier input %+v", modifiers)
+	}
+	if u, _ := modifiers.Local.Usage(7); u != NewUsage(0x07, 0xE7) {
+		t.Fatalf("unexpected last modifier usage %v", u)
+	}
+	if reserved := app.Mains[1]; !reserved.Flags.Constant() || len(reserved.Local.Usages) != 0 {
+		t.Fatalf("unexpected reserved byte %+v", reserved)
+	}
+	leds := app.Mains[2]
+	if leds.Tag() != TagOutput || leds.Local.Usages[0] != (UsageRange{NewUsage(0x08, 1), NewUsage(0x08, 5)}) {
+		t.Fatalf("unexpected led output %+v", leds)
+	}
+	keys := app.Mains[4]
+	if keys.Flags.Variable() || keys.Global.LogicalMaximum != 0x65 || keys.Global.ReportCount != 6 || keys.Local.Count() != 0x66 {
+		t.Fatalf("unexpected key array %+v", keys)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "mouse.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := desc.Collections[0]
+	if len(app.Collections) != 1 || len(app.Mains) != 0 {
+		t.Fatalf("unexpected application collection %+v", app)
+	}
+	pointer := app.Collections[0]
+	if pointer.Type != CollectionPhysical || pointer.Usage != NewUsage(0x01, 0x01) || pointer.Parent != app || pointer.Depth() != 1 {
+		t.Fatalf("unexpected pointer collection %+v", pointer)
+	}
+	axes := pointer.Mains[2]
+	if axes.Flags != FlagVariable|FlagRelative || axes.Global.LogicalMinimum != -127 || axes.Global.LogicalMaximum != 127 ||
+		axes.Collection != pointer {
+		t.Fatalf("unexpected axes %+v", axes)
+	}
+	if x, _ := axes.Local.Usage(0); x != NewUsage(0x01, 0x30) {
+		t.Fatalf("unexpected x usage %v", x)
+	}
+	if y, _ := axes.Local.Usage(1); y != NewUsage(0x01, 0x31) {
+		t.Fatalf("unexpected y usage %v", y)
+	}
+}
+
+func TestReportIDs(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "keyboard-consumer.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !desc.ReportIDs || len(desc.Collections) != 2 {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	consumer := desc.Mains[len(desc.Mains)-1]
+	if consumer.Global.ReportID != 2 || consumer.Global.UsagePage != 0x0C || consumer.Global.LogicalMaximum != 0x3FF ||
+		consumer.Local.Usages[0].Maximum != NewUsage(0x0C, 0x3FF) {
+		t.Fatalf("unexpected consumer input %+v", consumer)
+	}
+}
+
+func TestPushPopDelimiter(t *testing.T) {
+	items := []*Item{
+		New(TagUsagePage, 0x01),
+		New(TagUsage, 0x02),
+		New(TagCollection, uint32(CollectionApplication)),
+		New(TagReportSize, 8),
+		New(TagReportCount, 1),
+		NewSigned(TagLogicalMinimum, -127),
+		New(TagLogicalMaximum, 127),
+		New(TagPush, 0),
+		New(TagUsagePage, 0x09),
+		NewSigned(TagLogicalMinimum, 0),
+		New(TagLogicalMaximum, 1),
+		New(TagReportSize, 1),
+		New(TagReportCount, 8),
+		New(TagUsageMinimum, 1),
+		New(TagUsageMaximum, 8),
+		New(TagInput, uint32(FlagVariable)),
+		New(TagPop, 0),
+		New(TagDelimiter, 1),
+		New(TagUsage, 0x38),
+		New(TagUsage, 0x000C0238),
+		New(TagDelimiter, 0),
+		New(TagInput, uint32(FlagVariable|FlagRelative)),
+		New(TagEndCollection, 0),
+	}
+	data, err := Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := ParseDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Mains) != 2 {
+		t.Fatalf("expected 2 main items, got %d", len(desc.Mains))
+	}
+	buttons, wheel := desc.Mains[0], desc.Mains[1]
+	if buttons.Global.UsagePage != 0x09 || buttons.Global.ReportSize != 1 || buttons.Local.Count() != 8 {
+		t.Fatalf("unexpected buttons %+v", buttons)
+	}
+	if wheel.Global.UsagePage != 0x01 || wheel.Global.LogicalMinimum != -127 || wheel.Global.ReportSize != 8 {
+		t.Fatalf("pop did not restore the global state: %+v", wheel.Global)
+	}
+	if len(wheel.Local.Usages) != 1 || wheel.Local.Usages[0].Minimum != NewUsage(0x01, 0x38) ||
+		len(wheel.Local.Alternatives) != 1 || wheel.Local.Alternatives[0][1].Minimum != NewUsage(0x0C, 0x238) {
+		t.Fatalf("unexpected wheel usages %+v", wheel.Local)
+	}
+
+	var dump bytes.Buffer
+	if err := Dump(&dump, items); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dump.String(), "\n  81 06        Input (Data,Var,Rel)\n") ||
+		!strings.Contains(dump.String(), "\n  29 08        Usage Maximum (Button 8)\n") ||
+		!strings.Contains(dump.String(), "\n  09 38        Usage (Wheel)\n") ||
+		!strings.Contains(dump.String(), "\n  0b 38 02 0c 00 Usage (Consumer/AC Pan)\n") {
+		t.Fatalf("unexpected dump:\n%s", dump.String())
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   string
+		offset int
+		reason string
+	}{
+		{"truncated short", "05 01 26 ff", 2, "truncated"},
+		{"truncated long", "fe 04 10 01", 0, "truncated long item"},
+		{"unknown tag", "f5 01", 0, "unknown Global item tag"},
+		{"reserved type", "0c", 0, "unknown Reserved item tag"},
+		{"pop without push", "b4", 0, "pop without push"},
+		{"report id zero", "85 00", 0, "report ID"},
+		{"end without collection", "c0", 0, "end collection without collection"},
+		{"unclosed collection", "a1 01 a1 00 c0", 0, "collection not closed"},
+		{"usage maximum without minimum", "29 05", 0, "usage maximum without usage minimum"},
+		{"usage minimum without maximum", "19 01 81 02", 0, "minimum without maximum"},
+		{"usage range reversed", "19 05 29 01", 2, "larger than maximum"},
+		{"nested delimiter", "a9 01 a9 01", 2, "nested delimiter"},
+		{"unclosed delimiter", "a9 01 09 01 81 02", 0, "delimiter not closed"},
+		{"delimiter close without open", "a9 00", 0, "without open"},
+		{"report size zero", "95 01 81 02", 2, "report size is 0"},
+		{"missing report id", "75 08 95 01 81 02 85 01 81 02", 4, "without report ID"},
+	} {
+		data, err := hex.DecodeString(strings.ReplaceAll(test.data, " ", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ParseDescriptor(data)
+		var parseErr *Error
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%s: expected *Error, got %v", test.name, err)
+		}
+		if parseErr.Offset != test.offset || !strings.Contains(parseErr.Reason, test.reason) {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+	}
+}
+
+func TestLongItem(t *testing.T) {
+	data := []byte{0x05, 0x01, 0xFE, 0x02, 0xF0, 0xAA, 0xBB, 0x09, 0x02}
+	items, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[1].Long || items[1].LongTag != 0xF0 || !bytes.Equal(items[1].Data, []byte{0xAA, 0xBB}) ||
+		items[2].Offset != 7 || items[2].Tag != TagUsage {
+		t.Fatalf("unexpected items %v", items)
+	}
+}
+
+func TestVendorPages(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "gaming-mouse.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !desc.ReportIDs || len(desc.Collections) != 5 {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	axes := desc.Collections[0].Collections[0].Mains[1]
+	if axes.Global.ReportSize != 16 || axes.Global.LogicalMinimum != -32767 || axes.Global.LogicalMaximum != 32767 {
+		t.Fatalf("unexpected axes %+v", axes.Global)
+	}
+	if pan := desc.Collections[0].Collections[0].Mains[3]; pan.Local.Usages[0].Minimum != NewUsage(0x0C, 0x238) {
+		t.Fatalf("unexpected pan usage %+v", pan.Local)
+	}
+	for i, test := range []struct {
+		usage  Usage
+		id     uint8
+		count  uint32
+		output bool
+	}{
+		{NewUsage(0xFF00, 1), 0x10, 6, true},
+		{NewUsage(0xFF00, 2), 0x11, 19, true},
+		{NewUsage(0xFF01, 1), 0xF0, 256, false},
+	} {
+		app := desc.Collections[2+i]
+		if app.Usage != test.usage || app.Global.UsagePage != test.usage.Page() {
+			t.Fatalf("unexpected vendor collection %d %v", i, app.Usage)
+		}
+		first := app.Mains[0]
+		if first.Global.ReportID != test.id || first.Global.ReportCount != test.count || first.Global.LogicalMaximum != 255 {
+			t.Fatalf("unexpected vendor report %+v", first.Global)
+		}
+		if test.output && (len(app.Mains) != 2 || app.Mains[1].Tag() != TagOutput) {
+			t.Fatalf("expected an output report in collection %v", app.Usage)
+		}
+		if !test.output && first.Tag() != TagFeature {
+			t.Fatalf("expected a feature report in collection %v", app.Usage)
+		}
+	}
+}
+
+func TestCompositeKeyboard(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "composite-keyboard.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Collections) != 5 {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	ids := make(map[uint8]Usage)
+	for _, app := range desc.Collections {
+		for _, m := range app.Mains {
+			if prev, ok := ids[m.Global.ReportID]; ok && prev != app.Usage {
+				t.Fatalf("report %d in collections %v and %v", m.Global.ReportID, prev, app.Usage)
+			}
+			ids[m.Global.ReportID] = app.Usage
+		}
+	}
+	if len(ids) != 5 || ids[1] != NewUsage(0x01, 0x06) || ids[2] != NewUsage(0x01, 0x06) || ids[4] != NewUsage(0x01, 0x80) {
+		t.Fatalf("unexpected report IDs %v", ids)
+	}
+	bitmap := desc.Collections[1].Mains[0]
+	if !bitmap.Flags.Variable() || bitmap.Global.ReportCount != 109 || bitmap.Local.Count() != 109 {
+		t.Fatalf("unexpected rollover bitmap %+v", bitmap)
+	}
+	if u, _ := bitmap.Local.Usage(108); u != NewUsage(0x07, 0x70) {
+		t.Fatalf("unexpected last bitmap usage %v", u)
+	}
+	system := desc.Collections[3].Mains[0]
+	if !system.Flags.NullState() || system.Flags.Variable() || system.Global.LogicalMinimum != 1 || system.Local.Count() != 3 {
+		t.Fatalf("unexpected system control %+v", system)
+	}
+	if vendor := desc.Collections[4]; vendor.Usage != NewUsage(0xFF31, 0x74) || vendor.Mains[0].Tag() != TagFeature {
+		t.Fatalf("unexpected vendor collection %+v", vendor)
+	}
+}
+
+func TestDigitizer(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "digitizer.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pen, touch := desc.Collections[0], desc.Collections[1]
+	if pen.Usage != NewUsage(0x0D, 0x02) || touch.Usage != NewUsage(0x0D, 0x04) {
+		t.Fatalf("unexpected applications %v %v", pen.Usage, touch.Usage)
+	}
+	stylus := pen.Collections[0]
+	x := stylus.Mains[2]
+	if x.Global.Unit != 0x11 || x.Global.UnitExponent != 14 || x.Global.PhysicalMaximum != 2350 || x.Global.LogicalMaximum != 9600 {
+		t.Fatalf("unexpected x %+v", x.Global)
+	}
+	if y := stylus.Mains[3]; y.Global.PhysicalMaximum != 1400 || y.Global.LogicalMaximum != 5608 {
+		t.Fatalf("unexpected y %+v", y.Global)
+	}
+	tilt := stylus.Mains[5]
+	if tilt.Global.Unit != 0x14 || tilt.Global.PhysicalMinimum != -60 || tilt.Global.LogicalMinimum != -60 || tilt.Local.Count() != 2 {
+		t.Fatalf("unexpected tilt %+v", tilt.Global)
+	}
+	if len(touch.Collections) != 2 {
+		t.Fatalf("expected two fingers, got %d", len(touch.Collections))
+	}
+	for _, finger := range touch.Collections {
+		if finger.Type != CollectionLogical || finger.Usage != NewUsage(0x0D, 0x22) || len(finger.Mains) != 5 {
+			t.Fatalf("unexpected finger %+v", finger)
+		}
+		if id := finger.Mains[2]; id.Global.ReportCount != 1 || id.Local.Usages[0].Minimum != NewUsage(0x0D, 0x51) {
+			t.Fatalf("unexpected contact identifier %+v", id)
+		}
+	}
+	blob := touch.Mains[len(touch.Mains)-1]
+	if blob.Tag() != TagFeature || blob.Global.ReportID != 4 || blob.Global.ReportCount != 256 ||
+		blob.Local.Usages[0].Minimum != NewUsage(0xFF00, 0xC5) {
+		t.Fatalf("unexpected certification blob %+v", blob)
+	}
+}
+
+func TestPowerDevice(t *testing.T) {
+	desc, err := ParseDescriptor(readDescriptor(t, "ups.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary, status *Collection
+	desc.Walk(func(c *Collection) {
+		switch c.Usage {
+		case NewUsage(0x84, 0x24):
+			summary = c
+		case NewUsage(0x84, 0x02):
+			status = c
+		}
+	})
+	if summary == nil || status == nil || status.Parent != summary || status.Depth() != 2 {
+		t.Fatalf("unexpected collections %+v %+v", summary, status)
+	}
+	runTime := summary.Mains[4]
+	if runTime.Local.Usages[0].Minimum != NewUsage(0x85, 0x68) || runTime.Global.Unit != 0x1001 ||
+		runTime.Global.ReportSize != 32 || runTime.Global.LogicalMaximum != 65535 || runTime.Flags&FlagVolatile == 0 {
+		t.Fatalf("unexpected run time %+v", runTime)
+	}
+	voltage := summary.Mains[6]
+	if voltage.Global.Unit != 0x00F0D121 || voltage.Global.UnitExponent != 7 || voltage.Global.UsagePage != 0x84 {
+		t.Fatalf("unexpected voltage %+v", voltage.Global)
+	}
+	bits := status.Mains[0]
+	if bits.Tag() != TagInput || bits.Global.ReportID != 8 || bits.Local.Count() != 5 {
+		t.Fatalf("unexpected status %+v", bits)
+	}
+	// The last usage is a 4 byte usage on the power page inside the battery system page.
+	if u, _ := bits.Local.Usage(4); u != NewUsage(0x84, 0x62) || bits.Global.UsagePage != 0x85 {
+		t.Fatalf("unexpected extended usage %v", u)
+	}
+}
+
+// TestSysfs parses the report descriptors of the HID devices of the machine running the test
+// when GOUSB_HID_SYSFS is set. A descriptor that fails can be added to the corpus with
+// cp /sys/bus/hid/devices/<device>/report_descriptor testdata/<device>.bin.
+func TestSysfs(t *testing.T) {
+	if os.Getenv("GOUSB_HID_SYSFS") == "" {
+		t.Skip("GOUSB_HID_SYSFS not set")
+	}
+	files, _ := filepath.Glob("/sys/bus/hid/devices/*/report_descriptor")
+	if len(files) == 0 {
+		t.Skip("no HID devices")
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Logf("%s: %v", file, err)
+			continue
+		}
+		desc, err := ParseDescriptor(data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if encoded, err := Marshal(desc.Items); err != nil || !bytes.Equal(encoded, data) {
+			t.Errorf("%s: round trip mismatch: % x, %v", file, encoded, err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package report models the reports described by a HID report descriptor: the input, output and
+// feature reports of every report ID, the bit layout of their fields, and the conversion between
+// report data and usage/value pairs.
+package report
+
+import (
+	"fmt"
+	"github.com/daedaluz/gousb/hid/item"
+	"github.com/daedaluz/gousb/hid/usage"
+	"sort"
+)
+
+// Type is a report type, the values are those of the high byte of wValue in GET_REPORT and SET_REPORT.
+type Type uint8
+
+const (
+	TypeInput   = Type(1)
+	TypeOutput  = Type(2)
+	TypeFeature = Type(3)
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeInput:
+		return "Input"
+	case TypeOutput:
+		return "Output"
+	case TypeFeature:
+		return "Feature"
+	}
+	return fmt.Sprintf("Type(%d)", uint8(t))
+}
+
+// typeOf returns the report type of a main item tag.
+func typeOf(tag item.Tag) Type {
+	switch tag {
+	case item.TagOutput:
+		return TypeOutput
+	case item.TagFeature:
+		return TypeFeature
+	}
+	return TypeInput
+}
+
+// Field is the data of one Input, Output or Feature item: Count values of Size bits each.
+type Field struct {
+	Main     *item.Main
+	Type     Type
+	ReportID uint8
+	// Offset is the bit offset of the first value in the report, not counting the report ID byte.
+	Offset int
+	Size   int
+	Count  int
+	Flags  item.Flags
+	// Usages are the usages of the main item. A variable field assigns them to its values in order,
+	// an array field holds indexes into them.
+	Usages []item.UsageRange
+
+	LogicalMinimum  int32
+	LogicalMaximum  int32
+	PhysicalMinimum int32
+	PhysicalMaximum int32
+	Unit            Unit
+	UnitExponent    int
+	// Collection is the innermost collection of the field, nil outside of any collection.
+	Collection *item.Collection
+}
+
+// Variable reports whether every value of the field has its own usage.
+func (f *Field) Variable() bool {
+	return f.Flags.Variable()
+}
+
+// Constant reports whether the field is padding or otherwise constant.
+func (f *Field) Constant() bool {
+	return f.Flags.Constant()
+}
+
+// Signed reports whether the values are two's complement, which is the case for negative logical minimums.
+func (f *Field) Signed() bool {
+	return f.LogicalMinimum < 0
+}
+
+// Usage returns the usage of a value of a variable field. Values past the declared usages repeat the last usage.
+func (f *Field) Usage(index int) (item.Usage, bool) {
+	local := item.Local{Usages: f.Usages}
+	return local.Usage(index)
+}
+
+// Selected returns the usage an array value selects, false for values outside the logical range or
+// the usages and for usage ID 0, which means no usage is selected.
+func (f *Field) Selected(value int32) (item.Usage, bool) {
+	if value < f.LogicalMinimum || value > f.LogicalMaximum {
+		return 0, false
+	}
+	index := int(value - f.LogicalMinimum)
+	local := item.Local{Usages: f.Usages}
+	if index >= local.Count() {
+		return 0, false
+	}
+	u, _ := local.Usage(index)
+	return u, u.ID() != 0
+}
+
+// index returns the array value selecting a usage.
+func (f *Field) index(u item.Usage) (int32, bool) {
+	n := 0
+	for _, r := range f.Usages {
+		if r.Contains(u) {
+			return f.LogicalMinimum + int32(n+int(u.ID()-r.Minimum.ID())), true
+		}
+		n += r.Len()
+	}
+	return 0, false
+}
+
+func (f *Field) String() string {
+	kind := "array"
+	if f.Variable() {
+		kind = "variable"
+	}
+	if f.Constant() {
+		kind = "constant"
+	}
+	res := fmt.Sprintf("%v report %d bits %d-%d: %d x %d bit %s, logical %d..%d",
+		f.Type, f.ReportID, f.Offset, f.Offset+f.Size*f.Count-1, f.Count, f.Size, kind, f.LogicalMinimum, f.LogicalMaximum)
+	for i, r := range f.Usages {
+		if i == 0 {
+			res += ", "
+		} else {
+			res += " "
+		}
+		if r.Minimum == r.Maximum {
+			res += r.Minimum.String()
+		} else {
+			res += fmt.Sprintf("%v..%s", r.Minimum, usage.Name(r.Maximum.Page(), r.Maximum.ID()))
+		}
+	}
+	return res
+}
+
+// Report is a report of one type and ID.
+type Report struct {
+	Type Type
+	// ID is the report ID, 0 if the descriptor does not use report IDs.
+	ID     uint8
+	Fields []*Field
+	// Bits is the size of the report data without the report ID.
+	Bits int
+}
+
+// Size returns the length of the report in bytes, including the report ID byte if the report has an ID.
+func (r *Report) Size() int {
+	size := (r.Bits + 7) / 8
+	if r.ID != 0 {
+		size++
+	}
+	return size
+}
+
+// Layout holds the reports of a report descriptor.
+type Layout struct {
+	Descriptor *item.Descriptor
+	// Reports are sorted by type and ID.
+	Reports []*Report
+}
+
+// Parse parses a report descriptor and computes its layout.
+func Parse(data []byte) (*Layout, error) {
+	desc, err := item.ParseDescriptor(data)
+	if err != nil {
+		return nil, err
+	}
+	return New(desc)
+}
+
+// New computes the report layout of a parsed descriptor.
+func New(desc *item.Descriptor) (*Layout, error) {
+	type key struct {
+		typ Type
+		id  uint8
+	}
+	l := &Layout{Descriptor: desc}
+	reports := make(map[key]*Report)
+	for _, m := range desc.Mains {
+		g := m.Global
+		k := key{typeOf(m.Tag()), g.ReportID}
+		r, exist := reports[k]
+		if !exist {
+			r = &Report{Type: k.typ, ID: k.id}
+			reports[k] = r
+			l.Reports = append(l.Reports, r)
+		}
+		f := &Field{
+			Main:            m,
+			Type:            k.typ,
+			ReportID:        k.id,
+			Offset:          r.Bits,
+			Size:            int(g.ReportSize),
+			Count:           int(g.ReportCount),
+			Flags:           m.Flags,
+			Usages:          m.Local.Usages,
+			LogicalMinimum:  g.LogicalMinimum,
+			LogicalMaximum:  unsignedMaximum(g.LogicalMinimum, g.LogicalMaximum, g.ReportSize),
+			PhysicalMinimum: g.PhysicalMinimum,
+			PhysicalMaximum: unsignedMaximum(g.PhysicalMinimum, g.PhysicalMaximum, g.ReportSize),
+			Unit:            Unit(g.Unit),
+			UnitExponent:    unitExponent(g.UnitExponent),
+			Collection:      m.Collection,
+		}
+		if f.Size > 32 && !f.Constant() {
+			return nil, &item.Error{Offset: m.Item.Offset, Item: m.Item, Reason: fmt.Sprintf("report size %d larger than 32 bits", f.Size)}
+		}
+		r.Bits += f.Size * f.Count
+		if r.Bits > 8*0xFFFF {
+			return nil, &item.Error{Offset: m.Item.Offset, Item: m.Item, Reason: "report too long"}
+		}
+		r.Fields = append(r.Fields, f)
+	}
+	sort.SliceStable(l.Reports, func(i, j int) bool {
+		if l.Reports[i].Type != l.Reports[j].Type {
+			return l.Reports[i].Type < l.Reports[j].Type
+		}
+		return l.Reports[i].ID < l.Reports[j].ID
+	})
+	return l, nil
+}
+
+// unsignedMaximum reinterprets a maximum that only is negative because a descriptor encoded an
+// unsigned value, such as Logical Maximum (255) in one byte, with a non-negative minimum.
+func unsignedMaximum(minimum, maximum int32, size uint32) int32 {
+	if minimum < 0 || maximum >= 0 || size == 0 || size >= 32 {
+		return maximum
+	}
+	return int32(uint32(maximum) & (1<<size - 1))
+}
+
+// unitExponent decodes the unit exponent, which HID 1.11 encodes as a 4 bit two's complement nibble.
+func unitExponent(exponent int32) int {
+	if exponent > 7 && exponent <= 0xF {
+		return int(exponent) - 16
+	}
+	return int(exponent)
+}
+
+// Report returns the report of a type and ID, nil if the descriptor has no such report.
+func (l *Layout) Report(typ Type, id uint8) *Report {
+	for _, r := range l.Reports {
+		if r.Type == typ && r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// ReportsOf returns the reports of a type.
+func (l *Layout) ReportsOf(typ Type) []*Report {
+	var res []*Report
+	for _, r := range l.Reports {
+		if r.Type == typ {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// MaxSize returns the length of the longest report of a type including the report ID, 0 if there are none.
+func (l *Layout) MaxSize(typ Type) int {
+	size := 0
+	for _, r := range l.ReportsOf(typ) {
+		if r.Size() > size {
+			size = r.Size()
+		}
+	}
+	return size
+}
+
+// Decode decodes an input report as read from the interrupt IN endpoint, prefixed by the report ID
+// if the descriptor uses report IDs.
+func (l *Layout) Decode(report []byte) ([]Value, error) {
+	return l.DecodeType(TypeInput, report)
+}
+
+// DecodeType decodes a report of any type, eg. a feature report returned by GET_REPORT.
+func (l *Layout) DecodeType(typ Type, report []byte) ([]Value, error) {
+	var id uint8
+	if l.Descriptor.ReportIDs {
+		if len(report) == 0 {
+			return nil, fmt.Errorf("empty %v report", typ)
+		}
+		id = report[0]
+	}
+	r := l.Report(typ, id)
+	if r == nil {
+		return nil, fmt.Errorf("unknown %v report %d", typ, id)
+	}
+	return r.Decode(report)
+}
+
+// Encode encodes an output or feature report, see Report.Encode.
+func (l *Layout) Encode(typ Type, id uint8, values []Value) ([]byte, error) {
+	r := l.Report(typ, id)
+	if r == nil {
+		return nil, fmt.Errorf("unknown %v report %d", typ, id)
+	}
+	return r.Encode(values)
+}
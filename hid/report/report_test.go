@@ -0,0 +1,162 @@
+package report
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/daedaluz/gousb/hid/item"
+	"math"
+	"strings"
+	"testing"
+)
+
+const (
+	// bootKeyboard and bootMouse are the report descriptors of appendix E of HID 1.11.
+	bootKeyboard = "05 01 09 06 a1 01 05 07 19 e0 29 e7 15 00 25 01 75 01 95 08 81 02 95 01 75 08 81 01 95 05 75 01 05 08" +
+		"19 01 29 05 91 02 95 01 75 03 91 01 95 06 75 08 15 00 25 65 05 07 19 00 29 65 81 00 c0"
+	bootMouse = "05 01 09 02 a1 01 09 01 a1 00 05 09 19 01 29 03 15 00 25 01 95 03 75 01 81 02 95 01 75 05 81 01 05 01" +
+		"09 30 09 31 15 81 25 7f 75 08 95 02 81 06 c0 c0"
+)
+
+func parseHex(t *testing.T, s string) *Layout {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestKeyboard(t *testing.T) {
+	l := parseHex(t, bootKeyboard)
+	if len(l.Reports) != 2 || l.Report(TypeInput, 0).Size() != 8 || l.Report(TypeOutput, 0).Size() != 1 {
+		t.Fatalf("unexpected reports %+v", l.Reports)
+	}
+	keys := l.Report(TypeInput, 0).Fields[2]
+	if keys.String() != "Input report 0 bits 16-63: 6 x 8 bit array, logical 0..101, Keyboard/Keypad/Reserved (no event indicated)..Keyboard Application" {
+		t.Fatalf("unexpected key field %v", keys)
+	}
+	values, err := l.Decode([]byte{0x02, 0, 0x04, 0x05, 0, 0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 10 {
+		t.Fatalf("expected 8 modifiers and 2 keys, got %v", values)
+	}
+	if shift := values[1]; shift.String() != "Keyboard/Keypad/Keyboard LeftShift = 1" || values[0].Value != 0 {
+		t.Fatalf("unexpected modifiers %v", values[:8])
+	}
+	if a, b := values[8], values[9]; a.Usage != item.NewUsage(0x07, 0x04) || b.Usage != item.NewUsage(0x07, 0x05) || b.Index != 1 {
+		t.Fatalf("unexpected keys %v", values[8:])
+	}
+
+	leds, err := l.Encode(TypeOutput, 0, []Value{{Usage: item.NewUsage(0x08, 1), Value: 1}, {Usage: item.NewUsage(0x08, 2), Value: 1}})
+	if err != nil || !bytes.Equal(leds, []byte{0x03}) {
+		t.Fatalf("unexpected led report % x, %v", leds, err)
+	}
+	if _, err := l.Encode(TypeOutput, 0, []Value{{Usage: item.NewUsage(0x08, 1), Value: 2}}); err == nil {
+		t.Fatal("expected an error for a value outside the logical range")
+	}
+	if _, err := l.Encode(TypeOutput, 0, []Value{{Usage: item.NewUsage(0x08, 6), Value: 1}}); err == nil {
+		t.Fatal("expected an error for a usage not in the report")
+	}
+	input, err := l.Report(TypeInput, 0).Encode([]Value{{Usage: item.NewUsage(0x07, 0xE0), Value: 1}, {Usage: item.NewUsage(0x07, 0x29), Value: 1}})
+	if err != nil || !bytes.Equal(input, []byte{0x01, 0, 0x29, 0, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected input report % x, %v", input, err)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	l := parseHex(t, bootMouse)
+	values, err := l.Decode([]byte{0x01, 0xFF, 0x05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 5 || values[0].Value != 1 || values[1].Value != 0 {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if x, y := values[3], values[4]; x.Usage != item.NewUsage(0x01, 0x30) || x.Value != -1 || y.Value != 5 || !x.Field.Signed() {
+		t.Fatalf("unexpected axes %v %v", x, y)
+	}
+	if _, err := l.Decode([]byte{0x01, 0xFF}); err == nil {
+		t.Fatal("expected an error for a short report")
+	}
+}
+
+func TestFeature(t *testing.T) {
+	data, err := item.Marshal([]*item.Item{
+		item.New(item.TagUsagePage, 0x01),
+		item.New(item.TagUsage, 0x00),
+		item.New(item.TagCollection, uint32(item.CollectionApplication)),
+		item.New(item.TagReportID, 3),
+		item.New(item.TagUsage, 0x30),
+		item.New(item.TagLogicalMinimum, 0),
+		{Tag: item.TagLogicalMaximum, Data: []byte{0xFF}},
+		item.New(item.TagPhysicalMinimum, 0),
+		item.New(item.TagPhysicalMaximum, 100),
+		item.New(item.TagUnit, 0x11),
+		item.New(item.TagUnitExponent, 0x0E),
+		item.New(item.TagReportSize, 8),
+		item.New(item.TagReportCount, 1),
+		item.New(item.TagFeature, uint32(item.FlagVariable)),
+		item.New(item.TagReportID, 4),
+		item.New(item.TagReportSize, 4),
+		item.New(item.TagReportCount, 2),
+		item.New(item.TagLogicalMaximum, 15),
+		item.New(item.TagUsageMinimum, 0x31),
+		item.New(item.TagUsageMaximum, 0x32),
+		item.New(item.TagInput, uint32(item.FlagVariable)),
+		item.New(item.TagEndCollection, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feature := l.Report(TypeFeature, 3)
+	if feature == nil || feature.Size() != 2 || l.MaxSize(TypeInput) != 2 || len(l.ReportsOf(TypeFeature)) != 1 {
+		t.Fatalf("unexpected reports %+v", l.Reports)
+	}
+	f := feature.Fields[0]
+	if f.LogicalMaximum != 255 || f.UnitExponent != -2 || f.Unit.String() != "cm" {
+		t.Fatalf("unexpected field %v, unit %q exponent %d", f, f.Unit, f.UnitExponent)
+	}
+	values, err := l.DecodeType(TypeFeature, []byte{3, 0xFF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0].Value != 255 || math.Abs(values[0].Physical()-1) > 1e-9 {
+		t.Fatalf("unexpected values %v", values)
+	}
+	encoded, err := l.Encode(TypeFeature, 3, []Value{{Usage: item.NewUsage(0x01, 0x30), Value: 128}})
+	if err != nil || !bytes.Equal(encoded, []byte{3, 128}) {
+		t.Fatalf("unexpected feature report % x, %v", encoded, err)
+	}
+
+	values, err = l.Decode([]byte{4, 0x5A})
+	if err != nil || len(values) != 2 || values[0].Value != 0xA || values[1].Usage != item.NewUsage(0x01, 0x32) || values[1].Value != 5 {
+		t.Fatalf("unexpected input values %v, %v", values, err)
+	}
+	if _, err := l.Decode([]byte{5, 0}); err == nil {
+		t.Fatal("expected an error for an unknown report ID")
+	}
+}
+
+func TestUnit(t *testing.T) {
+	for unit, expected := range map[Unit]string{
+		0:          "",
+		0x11:       "cm",
+		0xE011:     "cm*s^-2",
+		0x12:       "rad",
+		0x00010001: "K",
+		0x14:       "deg",
+	} {
+		if s := unit.String(); s != expected {
+			t.Fatalf("unit 0x%x: expected %q, got %q", uint32(unit), expected, s)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit is the Unit global item: a measurement system and exponents of the base units, one nibble each.
+type Unit uint32
+
+// UnitSystem is the lowest nibble of a unit.
+type UnitSystem uint8
+
+const (
+	UnitSystemNone            = UnitSystem(0)
+	UnitSystemSILinear        = UnitSystem(1)
+	UnitSystemSIRotation      = UnitSystem(2)
+	UnitSystemEnglishLinear   = UnitSystem(3)
+	UnitSystemEnglishRotation = UnitSystem(4)
+)
+
+// unitNames are the base unit names of the nibbles 1-6 for the SI linear, SI rotation, English linear
+// and English rotation systems.
+var unitNames = [6][4]string{
+	{"cm", "rad", "in", "deg"},
+	{"g", "g", "slug", "slug"},
+	{"s", "s", "s", "s"},
+	{"K", "K", "F", "F"},
+	{"A", "A", "A", "A"},
+	{"cd", "cd", "cd", "cd"},
+}
+
+// System returns the measurement system.
+func (u Unit) System() UnitSystem {
+	return UnitSystem(u & 0xF)
+}
+
+// Exponent returns the signed exponent of a base unit: 0 length, 1 mass, 2 time, 3 temperature,
+// 4 current and 5 luminous intensity.
+func (u Unit) Exponent(base int) int {
+	e := int(u>>(4*(base+1))) & 0xF
+	if e > 7 {
+		e -= 16
+	}
+	return e
+}
+
+// String returns the unit as a product of base units, eg. "cm*s^-2", "" for no unit.
+func (u Unit) String() string {
+	system := u.System()
+	if system == UnitSystemNone {
+		return ""
+	}
+	if system > UnitSystemEnglishRotation {
+		return fmt.Sprintf("Unit(0x%.8x)", uint32(u))
+	}
+	var parts []string
+	for base, names := range unitNames {
+		switch e := u.Exponent(base); e {
+		case 0:
+		case 1:
+			parts = append(parts, names[system-1])
+		default:
+			parts = append(parts, fmt.Sprintf("%s^%d", names[system-1], e))
+		}
+	}
+	return strings.Join(parts, "*")
+}
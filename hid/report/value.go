@@ -0,0 +1,185 @@
+package report
+
+import (
+	"fmt"
+	"github.com/daedaluz/gousb/hid/item"
+	"math"
+)
+
+// Value is a usage/value pair of a report.
+//
+// Variable fields yield one value per report count with the logical value. Array fields yield one
+// value per selected usage, such as a pressed key, with Value 1. Constant fields and values in the
+// null state are skipped.
+type Value struct {
+	Usage item.Usage
+	Value int32
+	// Field is the field the value was decoded from, it is ignored by Encode.
+	Field *Field
+	// Index is the position of the value in its field.
+	Index int
+}
+
+// Physical returns the value scaled to the physical range and the unit exponent of its field.
+// Fields without a physical range use the logical range.
+func (v Value) Physical() float64 {
+	f := v.Field
+	if f == nil {
+		return float64(v.Value)
+	}
+	res := float64(v.Value)
+	if (f.PhysicalMinimum != 0 || f.PhysicalMaximum != 0) && f.LogicalMaximum != f.LogicalMinimum {
+		res = float64(f.PhysicalMinimum) + (res-float64(f.LogicalMinimum))*
+			float64(f.PhysicalMaximum-f.PhysicalMinimum)/float64(f.LogicalMaximum-f.LogicalMinimum)
+	}
+	return res * math.Pow10(f.UnitExponent)
+}
+
+func (v Value) String() string {
+	return fmt.Sprintf("%v = %d", v.Usage, v.Value)
+}
+
+// bits returns size bits of data at a bit offset, little endian.
+func bits(data []byte, offset, size int) uint32 {
+	var res uint32
+	for i := 0; i < size; i++ {
+		bit := offset + i
+		if data[bit/8]&(1<<(bit%8)) != 0 {
+			res |= 1 << i
+		}
+	}
+	return res
+}
+
+func setBits(data []byte, offset, size int, value uint32) {
+	for i := 0; i < size; i++ {
+		bit := offset + i
+		if value&(1<<i) != 0 {
+			data[bit/8] |= 1 << (bit % 8)
+		} else {
+			data[bit/8] &^= 1 << (bit % 8)
+		}
+	}
+}
+
+// value returns a value of a field from the report data without the report ID.
+func (f *Field) value(data []byte, index int) int32 {
+	raw := bits(data, f.Offset+index*f.Size, f.Size)
+	if f.Signed() && f.Size < 32 && raw&(1<<(f.Size-1)) != 0 {
+		raw |= ^uint32(0) << f.Size
+	}
+	return int32(raw)
+}
+
+// null returns the value of an unused array slot: a value outside the logical range or one
+// selecting usage ID 0, which is reserved on every usage page.
+func (f *Field) null() int32 {
+	if f.LogicalMinimum > 0 {
+		return 0
+	}
+	if u, ok := f.Usage(0); ok && u.ID() == 0 {
+		return f.LogicalMinimum
+	}
+	return f.LogicalMaximum + 1
+}
+
+// Decode decodes the report data, prefixed by the report ID if the report has an ID.
+// Reports shorter than Size are rejected, longer reports are accepted.
+func (r *Report) Decode(report []byte) ([]Value, error) {
+	if len(report) < r.Size() {
+		return nil, fmt.Errorf("%v report %d: %d bytes, expected %d", r.Type, r.ID, len(report), r.Size())
+	}
+	data := report
+	if r.ID != 0 {
+		if report[0] != r.ID {
+			return nil, fmt.Errorf("%v report %d: data has report ID %d", r.Type, r.ID, report[0])
+		}
+		data = report[1:]
+	}
+	var res []Value
+	for _, f := range r.Fields {
+		if f.Constant() || f.Size == 0 {
+			continue
+		}
+		for i := 0; i < f.Count; i++ {
+			v := f.value(data, i)
+			if !f.Variable() {
+				if u, ok := f.Selected(v); ok {
+					res = append(res, Value{Usage: u, Value: 1, Field: f, Index: i})
+				}
+				continue
+			}
+			if f.Flags.NullState() && (v < f.LogicalMinimum || v > f.LogicalMaximum) {
+				continue
+			}
+			u, _ := f.Usage(i)
+			res = append(res, Value{Usage: u, Value: v, Field: f, Index: i})
+		}
+	}
+	return res, nil
+}
+
+// Encode builds the report data from usage/value pairs, prefixed by the report ID if the report has an ID.
+// A value of a variable field is stored in the first value with its usage not yet assigned, values of
+// unassigned variables are 0. A usage of an array field is selected in the next free array slot if
+// its value is non-zero. Usages not in the report and values outside the logical range are errors.
+func (r *Report) Encode(values []Value) ([]byte, error) {
+	report := make([]byte, r.Size())
+	data := report
+	if r.ID != 0 {
+		report[0] = r.ID
+		data = report[1:]
+	}
+	assigned := make(map[*Field]map[int]bool)
+	used := make(map[*Field]int)
+	for _, f := range r.Fields {
+		assigned[f] = make(map[int]bool)
+		if !f.Variable() && !f.Constant() {
+			for i := 0; i < f.Count; i++ {
+				setBits(data, f.Offset+i*f.Size, f.Size, uint32(f.null()))
+			}
+		}
+	}
+	for _, v := range values {
+		if err := r.encode(data, v, assigned, used); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (r *Report) encode(data []byte, v Value, assigned map[*Field]map[int]bool, used map[*Field]int) error {
+	for _, f := range r.Fields {
+		if f.Constant() || f.Size == 0 {
+			continue
+		}
+		if f.Variable() {
+			for i := 0; i < f.Count; i++ {
+				if u, _ := f.Usage(i); u != v.Usage || assigned[f][i] {
+					continue
+				}
+				if v.Value < f.LogicalMinimum || v.Value > f.LogicalMaximum {
+					return fmt.Errorf("%v: value %d outside logical range %d..%d", v.Usage, v.Value, f.LogicalMinimum, f.LogicalMaximum)
+				}
+				assigned[f][i] = true
+				setBits(data, f.Offset+i*f.Size, f.Size, uint32(v.Value))
+				return nil
+			}
+			continue
+		}
+		index, ok := f.index(v.Usage)
+		if !ok {
+			continue
+		}
+		if v.Value == 0 {
+			return nil
+		}
+		if used[f] >= f.Count {
+			return fmt.Errorf("%v: all %d array slots in use", v.Usage, f.Count)
+		}
+		setBits(data, f.Offset+used[f]*f.Size, f.Size, uint32(index))
+		used[f]++
+		return nil
+	}
+	return fmt.Errorf("usage %v not in %v report %d", v.Usage, r.Type, r.ID)
+}
@@ -0,0 +1,83 @@
+//go:build ignore
+
+// gen converts hut.txt to tables.go.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type page struct {
+	id     uint64
+	name   string
+	usages [][2]string
+}
+
+func parse(path string) ([]*page, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var pages []*page
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimSpace(text), "  ", 2)
+		if strings.HasPrefix(text, "HUT ") {
+			fields = strings.SplitN(strings.TrimPrefix(text, "HUT "), "  ", 2)
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: invalid line %q", path, line, text)
+		}
+		id, err := strconv.ParseUint(fields[0], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		switch {
+		case strings.HasPrefix(text, "HUT "):
+			pages = append(pages, &page{id: id, name: fields[1]})
+		case strings.HasPrefix(text, "\t") && len(pages) > 0:
+			p := pages[len(pages)-1]
+			p.usages = append(p.usages, [2]string{fmt.Sprintf("0x%.4x", id), fields[1]})
+		default:
+			return nil, fmt.Errorf("%s:%d: invalid line %q", path, line, text)
+		}
+	}
+	return pages, scanner.Err()
+}
+
+func main() {
+	pages, err := parse("hut.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by gen.go from hut.txt; DO NOT EDIT.\n\npackage usage\n\n")
+	fmt.Fprintf(buf, "var pages = map[uint16]*page{\n")
+	for _, p := range pages {
+		fmt.Fprintf(buf, "0x%.4x: {name: %q, usages: map[uint16]string{\n", p.id, p.name)
+		for _, u := range p.usages {
+			fmt.Fprintf(buf, "%s: %q,\n", u[0], u[1])
+		}
+		fmt.Fprintf(buf, "}},\n")
+	}
+	fmt.Fprintf(buf, "}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("tables.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,2213 @@
+// Code generated by gen.go from hut.txt; DO NOT EDIT.
+
+package usage
+
+var pages = map[uint16]*page{
+	0x0001: {name: "Generic Desktop", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Pointer",
+		0x0002: "Mouse",
+		0x0004: "Joystick",
+		0x0005: "Gamepad",
+		0x0006: "Keyboard",
+		0x0007: "Keypad",
+		0x0008: "Multi-axis Controller",
+		0x0009: "Tablet PC System Controls",
+		0x000a: "Water Cooling Device",
+		0x000b: "Computer Chassis Device",
+		0x000c: "Wireless Radio Controls",
+		0x000d: "Portable Device Control",
+		0x000e: "System Multi-Axis Controller",
+		0x000f: "Spatial Controller",
+		0x0010: "Assistive Control",
+		0x0011: "Device Dock",
+		0x0012: "Dockable Device",
+		0x0013: "Call State Management Control",
+		0x0030: "X",
+		0x0031: "Y",
+		0x0032: "Z",
+		0x0033: "Rx",
+		0x0034: "Ry",
+		0x0035: "Rz",
+		0x0036: "Slider",
+		0x0037: "Dial",
+		0x0038: "Wheel",
+		0x0039: "Hat Switch",
+		0x003a: "Counted Buffer",
+		0x003b: "Byte Count",
+		0x003c: "Motion Wakeup",
+		0x003d: "Start",
+		0x003e: "Select",
+		0x0040: "Vx",
+		0x0041: "Vy",
+		0x0042: "Vz",
+		0x0043: "Vbrx",
+		0x0044: "Vbry",
+		0x0045: "Vbrz",
+		0x0046: "Vno",
+		0x0047: "Feature Notification",
+		0x0048: "Resolution Multiplier",
+		0x0049: "Qx",
+		0x004a: "Qy",
+		0x004b: "Qz",
+		0x004c: "Qw",
+		0x0080: "System Control",
+		0x0081: "System Power Down",
+		0x0082: "System Sleep",
+		0x0083: "System Wake Up",
+		0x0084: "System Context Menu",
+		0x0085: "System Main Menu",
+		0x0086: "System App Menu",
+		0x0087: "System Menu Help",
+		0x0088: "System Menu Exit",
+		0x0089: "System Menu Select",
+		0x008a: "System Menu Right",
+		0x008b: "System Menu Left",
+		0x008c: "System Menu Up",
+		0x008d: "System Menu Down",
+		0x008e: "System Cold Restart",
+		0x008f: "System Warm Restart",
+		0x0090: "D-pad Up",
+		0x0091: "D-pad Down",
+		0x0092: "D-pad Right",
+		0x0093: "D-pad Left",
+		0x0094: "Index Trigger",
+		0x0095: "Palm Trigger",
+		0x0096: "Thumbstick",
+		0x0097: "System Function Shift",
+		0x0098: "System Function Shift Lock",
+		0x0099: "System Function Shift Lock Indicator",
+		0x009a: "System Dismiss Notification",
+		0x009b: "System Do Not Disturb",
+		0x00a0: "System Dock",
+		0x00a1: "System Undock",
+		0x00a2: "System Setup",
+		0x00a3: "System Break",
+		0x00a4: "System Debugger Break",
+		0x00a5: "Application Break",
+		0x00a6: "Application Debugger Break",
+		0x00a7: "System Speaker Mute",
+		0x00a8: "System Hibernate",
+		0x00a9: "System Microphone Mute",
+		0x00b0: "System Display Invert",
+		0x00b1: "System Display Internal",
+		0x00b2: "System Display External",
+		0x00b3: "System Display Both",
+		0x00b4: "System Display Dual",
+		0x00b5: "System Display Toggle Int/Ext Mode",
+		0x00b6: "System Display Swap Primary/Secondary",
+		0x00b7: "System Display Toggle LCD Autoscale",
+		0x00c0: "Sensor Zone",
+		0x00c1: "RPM",
+		0x00c2: "Coolant Level",
+		0x00c3: "Coolant Critical Level",
+		0x00c4: "Coolant Pump",
+		0x00c5: "Chassis Enclosure",
+		0x00c6: "Wireless Radio Button",
+		0x00c7: "Wireless Radio LED",
+		0x00c8: "Wireless Radio Slider Switch",
+		0x00c9: "System Display Rotation Lock Button",
+		0x00ca: "System Display Rotation Lock Slider Switch",
+		0x00cb: "Control Enable",
+		0x00d0: "Dockable Device Unique ID",
+		0x00d1: "Dockable Device Vendor ID",
+		0x00d2: "Dockable Device Primary Usage Page",
+		0x00d3: "Dockable Device Primary Usage ID",
+		0x00d4: "Dockable Device Docking State",
+		0x00d5: "Dockable Device Display Occlusion",
+		0x00d6: "Dockable Device Object Type",
+		0x00e0: "Call Active LED",
+		0x00e1: "Call Mute Toggle",
+		0x00e2: "Call Mute LED",
+	}},
+	0x0002: {name: "Simulation Controls", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Flight Simulation Device",
+		0x0002: "Automobile Simulation Device",
+		0x0003: "Tank Simulation Device",
+		0x0004: "Spaceship Simulation Device",
+		0x0005: "Submarine Simulation Device",
+		0x0006: "Sailing Simulation Device",
+		0x0007: "Motorcycle Simulation Device",
+		0x0008: "Sports Simulation Device",
+		0x0009: "Airplane Simulation Device",
+		0x000a: "Helicopter Simulation Device",
+		0x000b: "Magic Carpet Simulation Device",
+		0x000c: "Bicycle Simulation Device",
+		0x0020: "Flight Control Stick",
+		0x0021: "Flight Stick",
+		0x0022: "Cyclic Control",
+		0x0023: "Cyclic Trim",
+		0x0024: "Flight Yoke",
+		0x0025: "Track Control",
+		0x00b0: "Aileron",
+		0x00b1: "Aileron Trim",
+		0x00b2: "Anti-Torque Control",
+		0x00b3: "Autopilot Enable",
+		0x00b4: "Chaff Release",
+		0x00b5: "Collective Control",
+		0x00b6: "Dive Brake",
+		0x00b7: "Electronic Countermeasures",
+		0x00b8: "Elevator",
+		0x00b9: "Elevator Trim",
+		0x00ba: "Rudder",
+		0x00bb: "Throttle",
+		0x00bc: "Flight Communications",
+		0x00bd: "Flare Release",
+		0x00be: "Landing Gear",
+		0x00bf: "Toe Brake",
+		0x00c0: "Trigger",
+		0x00c1: "Weapons Arm",
+		0x00c2: "Weapons Select",
+		0x00c3: "Wing Flaps",
+		0x00c4: "Accelerator",
+		0x00c5: "Brake",
+		0x00c6: "Clutch",
+		0x00c7: "Shifter",
+		0x00c8: "Steering",
+		0x00c9: "Turret Direction",
+		0x00ca: "Barrel Elevation",
+		0x00cb: "Dive Plane",
+		0x00cc: "Ballast",
+		0x00cd: "Bicycle Crank",
+		0x00ce: "Handle Bars",
+		0x00cf: "Front Brake",
+		0x00d0: "Rear Brake",
+	}},
+	0x0003: {name: "VR Controls", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Belt",
+		0x0002: "Body Suit",
+		0x0003: "Flexor",
+		0x0004: "Glove",
+		0x0005: "Head Tracker",
+		0x0006: "Head Mounted Display",
+		0x0007: "Hand Tracker",
+		0x0008: "Oculometer",
+		0x0009: "Vest",
+		0x000a: "Animatronic Device",
+		0x0020: "Stereo Enable",
+		0x0021: "Display Enable",
+	}},
+	0x0004: {name: "Sport Controls", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Baseball Bat",
+		0x0002: "Golf Club",
+		0x0003: "Rowing Machine",
+		0x0004: "Treadmill",
+		0x0030: "Oar",
+		0x0031: "Slope",
+		0x0032: "Rate",
+		0x0033: "Stick Speed",
+		0x0034: "Stick Face Angle",
+		0x0035: "Stick Heel/Toe",
+		0x0036: "Stick Follow Through",
+		0x0037: "Stick Tempo",
+		0x0038: "Stick Type",
+		0x0039: "Stick Height",
+		0x0050: "Putter",
+		0x0051: "1 Iron",
+		0x0052: "2 Iron",
+		0x0053: "3 Iron",
+		0x0054: "4 Iron",
+		0x0055: "5 Iron",
+		0x0056: "6 Iron",
+		0x0057: "7 Iron",
+		0x0058: "8 Iron",
+		0x0059: "9 Iron",
+		0x005a: "10 Iron",
+		0x005b: "11 Iron",
+		0x005c: "Sand Wedge",
+		0x005d: "Loft Wedge",
+		0x005e: "Power Wedge",
+		0x005f: "1 Wood",
+		0x0060: "3 Wood",
+		0x0061: "5 Wood",
+		0x0062: "7 Wood",
+		0x0063: "9 Wood",
+	}},
+	0x0005: {name: "Game Controls", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "3D Game Controller",
+		0x0002: "Pinball Device",
+		0x0003: "Gun Device",
+		0x0020: "Point of View",
+		0x0021: "Turn Right/Left",
+		0x0022: "Pitch Forward/Backward",
+		0x0023: "Roll Right/Left",
+		0x0024: "Move Right/Left",
+		0x0025: "Move Forward/Backward",
+		0x0026: "Move Up/Down",
+		0x0027: "Lean Right/Left",
+		0x0028: "Lean Forward/Backward",
+		0x0029: "Height of POV",
+		0x002a: "Flipper",
+		0x002b: "Secondary Flipper",
+		0x002c: "Bump",
+		0x002d: "New Game",
+		0x002e: "Shoot Ball",
+		0x002f: "Player",
+		0x0030: "Gun Bolt",
+		0x0031: "Gun Clip",
+		0x0032: "Gun Selector",
+		0x0033: "Gun Single Shot",
+		0x0034: "Gun Burst",
+		0x0035: "Gun Automatic",
+		0x0036: "Gun Safety",
+		0x0037: "Gamepad Fire/Jump",
+		0x0039: "Gamepad Trigger",
+	}},
+	0x0006: {name: "Generic Device Controls", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Background/Nonuser Controls",
+		0x0020: "Battery Strength",
+		0x0021: "Wireless Channel",
+		0x0022: "Wireless ID",
+		0x0023: "Discover Wireless Control",
+		0x0024: "Security Code Character Entered",
+		0x0025: "Security Code Character Erased",
+		0x0026: "Security Code Cleared",
+		0x0027: "Sequence ID",
+		0x0028: "Sequence ID Reset",
+		0x0029: "RF Signal Strength",
+		0x002a: "Software Version",
+		0x002b: "Protocol Version",
+		0x002c: "Hardware Version",
+		0x002d: "Major",
+		0x002e: "Minor",
+		0x002f: "Revision",
+		0x0030: "Handedness",
+		0x0031: "Either Hand",
+		0x0032: "Left Hand",
+		0x0033: "Right Hand",
+		0x0034: "Both Hands",
+		0x0040: "Grip Pose Offset",
+		0x0041: "Pointer Pose Offset",
+	}},
+	0x0007: {name: "Keyboard/Keypad", usages: map[uint16]string{
+		0x0000: "Reserved (no event indicated)",
+		0x0001: "Keyboard ErrorRollOver",
+		0x0002: "Keyboard POSTFail",
+		0x0003: "Keyboard ErrorUndefined",
+		0x0004: "Keyboard a and A",
+		0x0005: "Keyboard b and B",
+		0x0006: "Keyboard c and C",
+		0x0007: "Keyboard d and D",
+		0x0008: "Keyboard e and E",
+		0x0009: "Keyboard f and F",
+		0x000a: "Keyboard g and G",
+		0x000b: "Keyboard h and H",
+		0x000c: "Keyboard i and I",
+		0x000d: "Keyboard j and J",
+		0x000e: "Keyboard k and K",
+		0x000f: "Keyboard l and L",
+		0x0010: "Keyboard m and M",
+		0x0011: "Keyboard n and N",
+		0x0012: "Keyboard o and O",
+		0x0013: "Keyboard p and P",
+		0x0014: "Keyboard q and Q",
+		0x0015: "Keyboard r and R",
+		0x0016: "Keyboard s and S",
+		0x0017: "Keyboard t and T",
+		0x0018: "Keyboard u and U",
+		0x0019: "Keyboard v and V",
+		0x001a: "Keyboard w and W",
+		0x001b: "Keyboard x and X",
+		0x001c: "Keyboard y and Y",
+		0x001d: "Keyboard z and Z",
+		0x001e: "Keyboard 1 and !",
+		0x001f: "Keyboard 2 and @",
+		0x0020: "Keyboard 3 and #",
+		0x0021: "Keyboard 4 and $",
+		0x0022: "Keyboard 5 and %",
+		0x0023: "Keyboard 6 and ^",
+		0x0024: "Keyboard 7 and &",
+		0x0025: "Keyboard 8 and *",
+		0x0026: "Keyboard 9 and (",
+		0x0027: "Keyboard 0 and )",
+		0x0028: "Keyboard Return (ENTER)",
+		0x0029: "Keyboard ESCAPE",
+		0x002a: "Keyboard DELETE (Backspace)",
+		0x002b: "Keyboard Tab",
+		0x002c: "Keyboard Spacebar",
+		0x002d: "Keyboard - and (underscore)",
+		0x002e: "Keyboard = and +",
+		0x002f: "Keyboard [ and {",
+		0x0030: "Keyboard ] and }",
+		0x0031: "Keyboard \\ and |",
+		0x0032: "Keyboard Non-US # and ~",
+		0x0033: "Keyboard ; and :",
+		0x0034: "Keyboard ' and \"",
+		0x0035: "Keyboard Grave Accent and Tilde",
+		0x0036: "Keyboard , and <",
+		0x0037: "Keyboard . and >",
+		0x0038: "Keyboard / and ?",
+		0x0039: "Keyboard Caps Lock",
+		0x003a: "Keyboard F1",
+		0x003b: "Keyboard F2",
+		0x003c: "Keyboard F3",
+		0x003d: "Keyboard F4",
+		0x003e: "Keyboard F5",
+		0x003f: "Keyboard F6",
+		0x0040: "Keyboard F7",
+		0x0041: "Keyboard F8",
+		0x0042: "Keyboard F9",
+		0x0043: "Keyboard F10",
+		0x0044: "Keyboard F11",
+		0x0045: "Keyboard F12",
+		0x0046: "Keyboard PrintScreen",
+		0x0047: "Keyboard Scroll Lock",
+		0x0048: "Keyboard Pause",
+		0x0049: "Keyboard Insert",
+		0x004a: "Keyboard Home",
+		0x004b: "Keyboard PageUp",
+		0x004c: "Keyboard Delete Forward",
+		0x004d: "Keyboard End",
+		0x004e: "Keyboard PageDown",
+		0x004f: "Keyboard RightArrow",
+		0x0050: "Keyboard LeftArrow",
+		0x0051: "Keyboard DownArrow",
+		0x0052: "Keyboard UpArrow",
+		0x0053: "Keypad Num Lock and Clear",
+		0x0054: "Keypad /",
+		0x0055: "Keypad *",
+		0x0056: "Keypad -",
+		0x0057: "Keypad +",
+		0x0058: "Keypad ENTER",
+		0x0059: "Keypad 1 and End",
+		0x005a: "Keypad 2 and Down Arrow",
+		0x005b: "Keypad 3 and PageDn",
+		0x005c: "Keypad 4 and Left Arrow",
+		0x005d: "Keypad 5",
+		0x005e: "Keypad 6 and Right Arrow",
+		0x005f: "Keypad 7 and Home",
+		0x0060: "Keypad 8 and Up Arrow",
+		0x0061: "Keypad 9 and PageUp",
+		0x0062: "Keypad 0 and Insert",
+		0x0063: "Keypad . and Delete",
+		0x0064: "Keyboard Non-US \\ and |",
+		0x0065: "Keyboard Application",
+		0x0066: "Keyboard Power",
+		0x0067: "Keypad =",
+		0x0068: "Keyboard F13",
+		0x0069: "Keyboard F14",
+		0x006a: "Keyboard F15",
+		0x006b: "Keyboard F16",
+		0x006c: "Keyboard F17",
+		0x006d: "Keyboard F18",
+		0x006e: "Keyboard F19",
+		0x006f: "Keyboard F20",
+		0x0070: "Keyboard F21",
+		0x0071: "Keyboard F22",
+		0x0072: "Keyboard F23",
+		0x0073: "Keyboard F24",
+		0x0074: "Keyboard Execute",
+		0x0075: "Keyboard Help",
+		0x0076: "Keyboard Menu",
+		0x0077: "Keyboard Select",
+		0x0078: "Keyboard Stop",
+		0x0079: "Keyboard Again",
+		0x007a: "Keyboard Undo",
+		0x007b: "Keyboard Cut",
+		0x007c: "Keyboard Copy",
+		0x007d: "Keyboard Paste",
+		0x007e: "Keyboard Find",
+		0x007f: "Keyboard Mute",
+		0x0080: "Keyboard Volume Up",
+		0x0081: "Keyboard Volume Down",
+		0x0082: "Keyboard Locking Caps Lock",
+		0x0083: "Keyboard Locking Num Lock",
+		0x0084: "Keyboard Locking Scroll Lock",
+		0x0085: "Keypad Comma",
+		0x0086: "Keypad Equal Sign",
+		0x0087: "Keyboard International1",
+		0x0088: "Keyboard International2",
+		0x0089: "Keyboard International3",
+		0x008a: "Keyboard International4",
+		0x008b: "Keyboard International5",
+		0x008c: "Keyboard International6",
+		0x008d: "Keyboard International7",
+		0x008e: "Keyboard International8",
+		0x008f: "Keyboard International9",
+		0x0090: "Keyboard LANG1",
+		0x0091: "Keyboard LANG2",
+		0x0092: "Keyboard LANG3",
+		0x0093: "Keyboard LANG4",
+		0x0094: "Keyboard LANG5",
+		0x0095: "Keyboard LANG6",
+		0x0096: "Keyboard LANG7",
+		0x0097: "Keyboard LANG8",
+		0x0098: "Keyboard LANG9",
+		0x0099: "Keyboard Alternate Erase",
+		0x009a: "Keyboard SysReq/Attention",
+		0x009b: "Keyboard Cancel",
+		0x009c: "Keyboard Clear",
+		0x009d: "Keyboard Prior",
+		0x009e: "Keyboard Return",
+		0x009f: "Keyboard Separator",
+		0x00a0: "Keyboard Out",
+		0x00a1: "Keyboard Oper",
+		0x00a2: "Keyboard Clear/Again",
+		0x00a3: "Keyboard CrSel/Props",
+		0x00a4: "Keyboard ExSel",
+		0x00b0: "Keypad 00",
+		0x00b1: "Keypad 000",
+		0x00b2: "Thousands Separator",
+		0x00b3: "Decimal Separator",
+		0x00b4: "Currency Unit",
+		0x00b5: "Currency Sub-unit",
+		0x00b6: "Keypad (",
+		0x00b7: "Keypad )",
+		0x00b8: "Keypad {",
+		0x00b9: "Keypad }",
+		0x00ba: "Keypad Tab",
+		0x00bb: "Keypad Backspace",
+		0x00bc: "Keypad A",
+		0x00bd: "Keypad B",
+		0x00be: "Keypad C",
+		0x00bf: "Keypad D",
+		0x00c0: "Keypad E",
+		0x00c1: "Keypad F",
+		0x00c2: "Keypad XOR",
+		0x00c3: "Keypad ^",
+		0x00c4: "Keypad %",
+		0x00c5: "Keypad <",
+		0x00c6: "Keypad >",
+		0x00c7: "Keypad &",
+		0x00c8: "Keypad &&",
+		0x00c9: "Keypad |",
+		0x00ca: "Keypad ||",
+		0x00cb: "Keypad :",
+		0x00cc: "Keypad #",
+		0x00cd: "Keypad Space",
+		0x00ce: "Keypad @",
+		0x00cf: "Keypad !",
+		0x00d0: "Keypad Memory Store",
+		0x00d1: "Keypad Memory Recall",
+		0x00d2: "Keypad Memory Clear",
+		0x00d3: "Keypad Memory Add",
+		0x00d4: "Keypad Memory Subtract",
+		0x00d5: "Keypad Memory Multiply",
+		0x00d6: "Keypad Memory Divide",
+		0x00d7: "Keypad +/-",
+		0x00d8: "Keypad Clear",
+		0x00d9: "Keypad Clear Entry",
+		0x00da: "Keypad Binary",
+		0x00db: "Keypad Octal",
+		0x00dc: "Keypad Decimal",
+		0x00dd: "Keypad Hexadecimal",
+		0x00e0: "Keyboard LeftControl",
+		0x00e1: "Keyboard LeftShift",
+		0x00e2: "Keyboard LeftAlt",
+		0x00e3: "Keyboard Left GUI",
+		0x00e4: "Keyboard RightControl",
+		0x00e5: "Keyboard RightShift",
+		0x00e6: "Keyboard RightAlt",
+		0x00e7: "Keyboard Right GUI",
+	}},
+	0x0008: {name: "LED", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Num Lock",
+		0x0002: "Caps Lock",
+		0x0003: "Scroll Lock",
+		0x0004: "Compose",
+		0x0005: "Kana",
+		0x0006: "Power",
+		0x0007: "Shift",
+		0x0008: "Do Not Disturb",
+		0x0009: "Mute",
+		0x000a: "Tone Enable",
+		0x000b: "High Cut Filter",
+		0x000c: "Low Cut Filter",
+		0x000d: "Equalizer Enable",
+		0x000e: "Sound Field On",
+		0x000f: "Surround On",
+		0x0010: "Repeat",
+		0x0011: "Stereo",
+		0x0012: "Sampling Rate Detect",
+		0x0013: "Spinning",
+		0x0014: "CAV",
+		0x0015: "CLV",
+		0x0016: "Recording Format Detect",
+		0x0017: "Off-Hook",
+		0x0018: "Ring",
+		0x0019: "Message Waiting",
+		0x001a: "Data Mode",
+		0x001b: "Battery Operation",
+		0x001c: "Battery OK",
+		0x001d: "Battery Low",
+		0x001e: "Speaker",
+		0x001f: "Headset",
+		0x0020: "Hold",
+		0x0021: "Microphone",
+		0x0022: "Coverage",
+		0x0023: "Night Mode",
+		0x0024: "Send Calls",
+		0x0025: "Call Pickup",
+		0x0026: "Conference",
+		0x0027: "Stand-by",
+		0x0028: "Camera On",
+		0x0029: "Camera Off",
+		0x002a: "On-Line",
+		0x002b: "Off-Line",
+		0x002c: "Busy",
+		0x002d: "Ready",
+		0x002e: "Paper-Out",
+		0x002f: "Paper-Jam",
+		0x0030: "Remote",
+		0x0031: "Forward",
+		0x0032: "Reverse",
+		0x0033: "Stop",
+		0x0034: "Rewind",
+		0x0035: "Fast Forward",
+		0x0036: "Play",
+		0x0037: "Pause",
+		0x0038: "Record",
+		0x0039: "Error",
+		0x003a: "Usage Selected Indicator",
+		0x003b: "Usage In Use Indicator",
+		0x003c: "Usage Multi Mode Indicator",
+		0x003d: "Indicator On",
+		0x003e: "Indicator Flash",
+		0x003f: "Indicator Slow Blink",
+		0x0040: "Indicator Fast Blink",
+		0x0041: "Indicator Off",
+		0x0042: "Flash On Time",
+		0x0043: "Slow Blink On Time",
+		0x0044: "Slow Blink Off Time",
+		0x0045: "Fast Blink On Time",
+		0x0046: "Fast Blink Off Time",
+		0x0047: "Usage Indicator Color",
+		0x0048: "Indicator Red",
+		0x0049: "Indicator Green",
+		0x004a: "Indicator Amber",
+		0x004b: "Generic Indicator",
+		0x004c: "System Suspend",
+		0x004d: "External Power Connected",
+		0x004e: "Indicator Blue",
+		0x004f: "Indicator Orange",
+		0x0050: "Good Status",
+		0x0051: "Warning Status",
+		0x0052: "RGB LED",
+		0x0053: "Red LED Channel",
+		0x0054: "Blue LED Channel",
+		0x0055: "Green LED Channel",
+		0x0056: "LED Intensity",
+		0x0060: "Player Indicator",
+		0x0061: "Player 1",
+		0x0062: "Player 2",
+		0x0063: "Player 3",
+		0x0064: "Player 4",
+		0x0065: "Player 5",
+		0x0066: "Player 6",
+		0x0067: "Player 7",
+		0x0068: "Player 8",
+	}},
+	0x0009: {name: "Button", usages: map[uint16]string{}},
+	0x000a: {name: "Ordinal", usages: map[uint16]string{}},
+	0x000b: {name: "Telephony Device", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Phone",
+		0x0002: "Answering Machine",
+		0x0003: "Message Controls",
+		0x0004: "Handset",
+		0x0005: "Headset",
+		0x0006: "Telephony Key Pad",
+		0x0007: "Programmable Button",
+		0x0020: "Hook Switch",
+		0x0021: "Flash",
+		0x0022: "Feature",
+		0x0023: "Hold",
+		0x0024: "Redial",
+		0x0025: "Transfer",
+		0x0026: "Drop",
+		0x0027: "Park",
+		0x0028: "Forward Calls",
+		0x0029: "Alternate Function",
+		0x002a: "Line",
+		0x002b: "Speaker Phone",
+		0x002c: "Conference",
+		0x002d: "Ring Enable",
+		0x002e: "Ring Select",
+		0x002f: "Phone Mute",
+		0x0030: "Caller ID",
+		0x0031: "Send",
+		0x0050: "Speed Dial",
+		0x0051: "Store Number",
+		0x0052: "Recall Number",
+		0x0053: "Phone Directory",
+		0x0070: "Voice Mail",
+		0x0071: "Screen Calls",
+		0x0072: "Do Not Disturb",
+		0x0073: "Message",
+		0x0074: "Answer On/Off",
+		0x0090: "Inside Dial Tone",
+		0x0091: "Outside Dial Tone",
+		0x0092: "Inside Ring Tone",
+		0x0093: "Outside Ring Tone",
+		0x0094: "Priority Ring Tone",
+		0x0095: "Inside Ringback",
+		0x0096: "Priority Ringback",
+		0x0097: "Line Busy Tone",
+		0x0098: "Reorder Tone",
+		0x0099: "Call Waiting Tone",
+		0x009a: "Confirmation Tone 1",
+		0x009b: "Confirmation Tone 2",
+		0x009c: "Tones Off",
+		0x009d: "Outside Ringback",
+		0x009e: "Ringer",
+		0x00b0: "Phone Key 0",
+		0x00b1: "Phone Key 1",
+		0x00b2: "Phone Key 2",
+		0x00b3: "Phone Key 3",
+		0x00b4: "Phone Key 4",
+		0x00b5: "Phone Key 5",
+		0x00b6: "Phone Key 6",
+		0x00b7: "Phone Key 7",
+		0x00b8: "Phone Key 8",
+		0x00b9: "Phone Key 9",
+		0x00ba: "Phone Key Star",
+		0x00bb: "Phone Key Pound",
+		0x00bc: "Phone Key A",
+		0x00bd: "Phone Key B",
+		0x00be: "Phone Key C",
+		0x00bf: "Phone Key D",
+		0x00c0: "Phone Call History Key",
+		0x00c1: "Phone Caller ID Key",
+		0x00c2: "Phone Settings Key",
+		0x00f0: "Host Control",
+		0x00f1: "Host Available",
+		0x00f2: "Host Call Active",
+		0x00f3: "Activate Handset Audio",
+		0x00f4: "Ring Type",
+		0x00f5: "Re-dialable Phone Number",
+		0x00f8: "Stop Ring Tone",
+		0x00f9: "PSTN Ring Tone",
+		0x00fa: "Host Ring Tone",
+		0x00fb: "Alert Sound Error",
+		0x00fc: "Alert Sound Confirm",
+		0x00fd: "Alert Sound Notification",
+		0x00fe: "Silent Ring",
+		0x0108: "Email Message Waiting",
+		0x0109: "Voicemail Message Waiting",
+		0x010a: "Host Hold",
+		0x0110: "Incoming Call History Count",
+		0x0111: "Outgoing Call History Count",
+		0x0112: "Incoming Call History",
+		0x0113: "Outgoing Call History",
+		0x0114: "Phone Locale",
+		0x0140: "Phone Time Second",
+		0x0141: "Phone Time Minute",
+		0x0142: "Phone Time Hour",
+		0x0143: "Phone Date Day",
+		0x0144: "Phone Date Month",
+		0x0145: "Phone Date Year",
+		0x0146: "Handset Nickname",
+		0x0147: "Address Book ID",
+		0x014a: "Call Duration",
+		0x014b: "Dual Mode Phone",
+	}},
+	0x000c: {name: "Consumer", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Consumer Control",
+		0x0002: "Numeric Key Pad",
+		0x0003: "Programmable Buttons",
+		0x0004: "Microphone",
+		0x0005: "Headphone",
+		0x0006: "Graphic Equalizer",
+		0x0020: "+10",
+		0x0021: "+100",
+		0x0022: "AM/PM",
+		0x0030: "Power",
+		0x0031: "Reset",
+		0x0032: "Sleep",
+		0x0033: "Sleep After",
+		0x0034: "Sleep Mode",
+		0x0035: "Illumination",
+		0x0036: "Function Buttons",
+		0x0040: "Menu",
+		0x0041: "Menu Pick",
+		0x0042: "Menu Up",
+		0x0043: "Menu Down",
+		0x0044: "Menu Left",
+		0x0045: "Menu Right",
+		0x0046: "Menu Escape",
+		0x0047: "Menu Value Increase",
+		0x0048: "Menu Value Decrease",
+		0x0060: "Data On Screen",
+		0x0061: "Closed Caption",
+		0x0062: "Closed Caption Select",
+		0x0063: "VCR/TV",
+		0x0064: "Broadcast Mode",
+		0x0065: "Snapshot",
+		0x0066: "Still",
+		0x0067: "Picture-in-Picture Toggle",
+		0x0068: "Picture-in-Picture Swap",
+		0x0069: "Red Menu Button",
+		0x006a: "Green Menu Button",
+		0x006b: "Blue Menu Button",
+		0x006c: "Yellow Menu Button",
+		0x006d: "Aspect",
+		0x006e: "3D Mode Select",
+		0x006f: "Display Brightness Increment",
+		0x0070: "Display Brightness Decrement",
+		0x0071: "Display Brightness",
+		0x0072: "Display Backlight Toggle",
+		0x0073: "Display Set Brightness to Minimum",
+		0x0074: "Display Set Brightness to Maximum",
+		0x0075: "Display Set Auto Brightness",
+		0x0076: "Camera Access Enabled",
+		0x0077: "Camera Access Disabled",
+		0x0078: "Camera Access Toggle",
+		0x0079: "Keyboard Brightness Increment",
+		0x007a: "Keyboard Brightness Decrement",
+		0x007b: "Keyboard Backlight Set Level",
+		0x007c: "Keyboard Backlight OOC",
+		0x007d: "Keyboard Backlight Set Minimum",
+		0x007e: "Keyboard Backlight Set Maximum",
+		0x007f: "Keyboard Backlight Auto",
+		0x0080: "Selection",
+		0x0081: "Assign Selection",
+		0x0082: "Mode Step",
+		0x0083: "Recall Last",
+		0x0084: "Enter Channel",
+		0x0085: "Order Movie",
+		0x0086: "Channel",
+		0x0087: "Media Selection",
+		0x0088: "Media Select Computer",
+		0x0089: "Media Select TV",
+		0x008a: "Media Select WWW",
+		0x008b: "Media Select DVD",
+		0x008c: "Media Select Telephone",
+		0x008d: "Media Select Program Guide",
+		0x008e: "Media Select Video Phone",
+		0x008f: "Media Select Games",
+		0x0090: "Media Select Messages",
+		0x0091: "Media Select CD",
+		0x0092: "Media Select VCR",
+		0x0093: "Media Select Tuner",
+		0x0094: "Quit",
+		0x0095: "Help",
+		0x0096: "Media Select Tape",
+		0x0097: "Media Select Cable",
+		0x0098: "Media Select Satellite",
+		0x0099: "Media Select Security",
+		0x009a: "Media Select Home",
+		0x009b: "Media Select Call",
+		0x009c: "Channel Increment",
+		0x009d: "Channel Decrement",
+		0x009e: "Media Select SAP",
+		0x00a0: "VCR Plus",
+		0x00a1: "Once",
+		0x00a2: "Daily",
+		0x00a3: "Weekly",
+		0x00a4: "Monthly",
+		0x00b0: "Play",
+		0x00b1: "Pause",
+		0x00b2: "Record",
+		0x00b3: "Fast Forward",
+		0x00b4: "Rewind",
+		0x00b5: "Scan Next Track",
+		0x00b6: "Scan Previous Track",
+		0x00b7: "Stop",
+		0x00b8: "Eject",
+		0x00b9: "Random Play",
+		0x00ba: "Select Disc",
+		0x00bb: "Enter Disc",
+		0x00bc: "Repeat",
+		0x00bd: "Tracking",
+		0x00be: "Track Normal",
+		0x00bf: "Slow Tracking",
+		0x00c0: "Frame Forward",
+		0x00c1: "Frame Back",
+		0x00c2: "Mark",
+		0x00c3: "Clear Mark",
+		0x00c4: "Repeat From Mark",
+		0x00c5: "Return To Mark",
+		0x00c6: "Search Mark Forward",
+		0x00c7: "Search Mark Backwards",
+		0x00c8: "Counter Reset",
+		0x00c9: "Show Counter",
+		0x00ca: "Tracking Increment",
+		0x00cb: "Tracking Decrement",
+		0x00cc: "Stop/Eject",
+		0x00cd: "Play/Pause",
+		0x00ce: "Play/Skip",
+		0x00cf: "Voice Command",
+		0x00d0: "Invoke Capture Interface",
+		0x00d1: "Start or Stop Game Recording",
+		0x00d2: "Historical Game Capture",
+		0x00d3: "Capture Game Screenshot",
+		0x00d4: "Show or Hide Recording Indicator",
+		0x00d5: "Start or Stop Microphone Capture",
+		0x00d6: "Start or Stop Camera Capture",
+		0x00d7: "Start or Stop Game Broadcast",
+		0x00d8: "Start or Stop Voice Dictation Session",
+		0x00d9: "Invoke/Dismiss Emoji Picker",
+		0x00e0: "Volume",
+		0x00e1: "Balance",
+		0x00e2: "Mute",
+		0x00e3: "Bass",
+		0x00e4: "Treble",
+		0x00e5: "Bass Boost",
+		0x00e6: "Surround Mode",
+		0x00e7: "Loudness",
+		0x00e8: "MPX",
+		0x00e9: "Volume Increment",
+		0x00ea: "Volume Decrement",
+		0x00f0: "Speed Select",
+		0x00f1: "Playback Speed",
+		0x00f2: "Standard Play",
+		0x00f3: "Long Play",
+		0x00f4: "Extended Play",
+		0x00f5: "Slow",
+		0x0100: "Fan Enable",
+		0x0101: "Fan Speed",
+		0x0102: "Light Enable",
+		0x0103: "Light Illumination Level",
+		0x0104: "Climate Control Enable",
+		0x0105: "Room Temperature",
+		0x0106: "Security Enable",
+		0x0107: "Fire Alarm",
+		0x0108: "Police Alarm",
+		0x0109: "Proximity",
+		0x010a: "Motion",
+		0x010b: "Duress Alarm",
+		0x010c: "Holdup Alarm",
+		0x010d: "Medical Alarm",
+		0x0150: "Balance Right",
+		0x0151: "Balance Left",
+		0x0152: "Bass Increment",
+		0x0153: "Bass Decrement",
+		0x0154: "Treble Increment",
+		0x0155: "Treble Decrement",
+		0x0160: "Speaker System",
+		0x0161: "Channel Left",
+		0x0162: "Channel Right",
+		0x0163: "Channel Center",
+		0x0164: "Channel Front",
+		0x0165: "Channel Center Front",
+		0x0166: "Channel Side",
+		0x0167: "Channel Surround",
+		0x0168: "Channel Low Frequency Enhancement",
+		0x0169: "Channel Top",
+		0x016a: "Channel Unknown",
+		0x0170: "Sub-channel",
+		0x0171: "Sub-channel Increment",
+		0x0172: "Sub-channel Decrement",
+		0x0173: "Alternate Audio Increment",
+		0x0174: "Alternate Audio Decrement",
+		0x0180: "Application Launch Buttons",
+		0x0181: "AL Launch Button Configuration Tool",
+		0x0182: "AL Programmable Button Configuration",
+		0x0183: "AL Consumer Control Configuration",
+		0x0184: "AL Word Processor",
+		0x0185: "AL Text Editor",
+		0x0186: "AL Spreadsheet",
+		0x0187: "AL Graphics Editor",
+		0x0188: "AL Presentation App",
+		0x0189: "AL Database App",
+		0x018a: "AL Email Reader",
+		0x018b: "AL Newsreader",
+		0x018c: "AL Voicemail",
+		0x018d: "AL Contacts/Address Book",
+		0x018e: "AL Calendar/Schedule",
+		0x018f: "AL Task/Project Manager",
+		0x0190: "AL Log/Journal/Timecard",
+		0x0191: "AL Checkbook/Finance",
+		0x0192: "AL Calculator",
+		0x0193: "AL A/V Capture/Playback",
+		0x0194: "AL Local Machine Browser",
+		0x0195: "AL LAN/WAN Browser",
+		0x0196: "AL Internet Browser",
+		0x0197: "AL Remote Networking/ISP Connect",
+		0x0198: "AL Network Conference",
+		0x0199: "AL Network Chat",
+		0x019a: "AL Telephony/Dialer",
+		0x019b: "AL Logon",
+		0x019c: "AL Logoff",
+		0x019d: "AL Logon/Logoff",
+		0x019e: "AL Terminal Lock/Screensaver",
+		0x019f: "AL Control Panel",
+		0x01a0: "AL Command Line Processor/Run",
+		0x01a1: "AL Process/Task Manager",
+		0x01a2: "AL Select Task/Application",
+		0x01a3: "AL Next Task/Application",
+		0x01a4: "AL Previous Task/Application",
+		0x01a5: "AL Preemptive Halt Task/Application",
+		0x01a6: "AL Integrated Help Center",
+		0x01a7: "AL Documents",
+		0x01a8: "AL Thesaurus",
+		0x01a9: "AL Dictionary",
+		0x01aa: "AL Desktop",
+		0x01ab: "AL Spell Check",
+		0x01ac: "AL Grammar Check",
+		0x01ad: "AL Wireless Status",
+		0x01ae: "AL Keyboard Layout",
+		0x01af: "AL Virus Protection",
+		0x01b0: "AL Encryption",
+		0x01b1: "AL Screen Saver",
+		0x01b2: "AL Alarms",
+		0x01b3: "AL Clock",
+		0x01b4: "AL File Browser",
+		0x01b5: "AL Power Status",
+		0x01b6: "AL Image Browser",
+		0x01b7: "AL Audio Browser",
+		0x01b8: "AL Movie Browser",
+		0x01b9: "AL Digital Rights Manager",
+		0x01ba: "AL Digital Wallet",
+		0x01bc: "AL Instant Messaging",
+		0x01bd: "AL OEM Features/Tips/Tutorial Browser",
+		0x01be: "AL OEM Help",
+		0x01bf: "AL Online Community",
+		0x01c0: "AL Entertainment Content Browser",
+		0x01c1: "AL Online Shopping Browser",
+		0x01c2: "AL SmartCard Information/Help",
+		0x01c3: "AL Market Monitor/Finance Browser",
+		0x01c4: "AL Customized Corporate News Browser",
+		0x01c5: "AL Online Activity Browser",
+		0x01c6: "AL Research/Search Browser",
+		0x01c7: "AL Audio Player",
+		0x01c8: "AL Message Status",
+		0x01c9: "AL Contact Sync",
+		0x01ca: "AL Navigation",
+		0x01cb: "AL Context-aware Desktop Assistant",
+		0x0200: "Generic GUI Application Controls",
+		0x0201: "AC New",
+		0x0202: "AC Open",
+		0x0203: "AC Close",
+		0x0204: "AC Exit",
+		0x0205: "AC Maximize",
+		0x0206: "AC Minimize",
+		0x0207: "AC Save",
+		0x0208: "AC Print",
+		0x0209: "AC Properties",
+		0x021a: "AC Undo",
+		0x021b: "AC Copy",
+		0x021c: "AC Cut",
+		0x021d: "AC Paste",
+		0x021e: "AC Select All",
+		0x021f: "AC Find",
+		0x0220: "AC Find and Replace",
+		0x0221: "AC Search",
+		0x0222: "AC Go To",
+		0x0223: "AC Home",
+		0x0224: "AC Back",
+		0x0225: "AC Forward",
+		0x0226: "AC Stop",
+		0x0227: "AC Refresh",
+		0x0228: "AC Previous Link",
+		0x0229: "AC Next Link",
+		0x022a: "AC Bookmarks",
+		0x022b: "AC History",
+		0x022c: "AC Subscriptions",
+		0x022d: "AC Zoom In",
+		0x022e: "AC Zoom Out",
+		0x022f: "AC Zoom",
+		0x0230: "AC Full Screen View",
+		0x0231: "AC Normal View",
+		0x0232: "AC View Toggle",
+		0x0233: "AC Scroll Up",
+		0x0234: "AC Scroll Down",
+		0x0235: "AC Scroll",
+		0x0236: "AC Pan Left",
+		0x0237: "AC Pan Right",
+		0x0238: "AC Pan",
+		0x0239: "AC New Window",
+		0x023a: "AC Tile Horizontally",
+		0x023b: "AC Tile Vertically",
+		0x023c: "AC Format",
+		0x023d: "AC Edit",
+		0x023e: "AC Bold",
+		0x023f: "AC Italics",
+		0x0240: "AC Underline",
+		0x0241: "AC Strikethrough",
+		0x0242: "AC Subscript",
+		0x0243: "AC Superscript",
+		0x0244: "AC All Caps",
+		0x0245: "AC Rotate",
+		0x0246: "AC Resize",
+		0x0247: "AC Flip Horizontal",
+		0x0248: "AC Flip Vertical",
+		0x0249: "AC Mirror Horizontal",
+		0x024a: "AC Mirror Vertical",
+		0x024b: "AC Font Select",
+		0x024c: "AC Font Color",
+		0x024d: "AC Font Size",
+		0x024e: "AC Justify Left",
+		0x024f: "AC Justify Center H",
+		0x0250: "AC Justify Right",
+		0x0251: "AC Justify Block H",
+		0x0252: "AC Justify Top",
+		0x0253: "AC Justify Center V",
+		0x0254: "AC Justify Bottom",
+		0x0255: "AC Justify Block V",
+		0x0256: "AC Indent Decrease",
+		0x0257: "AC Indent Increase",
+		0x0258: "AC Numbered List",
+		0x0259: "AC Restart Numbering",
+		0x025a: "AC Bulleted List",
+		0x025b: "AC Promote",
+		0x025c: "AC Demote",
+		0x025d: "AC Yes",
+		0x025e: "AC No",
+		0x025f: "AC Cancel",
+		0x0260: "AC Catalog",
+		0x0261: "AC Buy/Checkout",
+		0x0262: "AC Add to Cart",
+		0x0263: "AC Expand",
+		0x0264: "AC Expand All",
+		0x0265: "AC Collapse",
+		0x0266: "AC Collapse All",
+		0x0267: "AC Print Preview",
+		0x0268: "AC Paste Special",
+		0x0269: "AC Insert Mode",
+		0x026a: "AC Delete",
+		0x026b: "AC Lock",
+		0x026c: "AC Unlock",
+		0x026d: "AC Protect",
+		0x026e: "AC Unprotect",
+		0x026f: "AC Attach Comment",
+		0x0270: "AC Delete Comment",
+		0x0271: "AC View Comment",
+		0x0272: "AC Select Word",
+		0x0273: "AC Select Sentence",
+		0x0274: "AC Select Paragraph",
+		0x0275: "AC Select Column",
+		0x0276: "AC Select Row",
+		0x0277: "AC Select Table",
+		0x0278: "AC Select Object",
+		0x0279: "AC Redo/Repeat",
+		0x027a: "AC Sort",
+		0x027b: "AC Sort Ascending",
+		0x027c: "AC Sort Descending",
+		0x027d: "AC Filter",
+		0x027e: "AC Set Clock",
+		0x027f: "AC View Clock",
+		0x0280: "AC Select Time Zone",
+		0x0281: "AC Edit Time Zones",
+		0x0282: "AC Set Alarm",
+		0x0283: "AC Clear Alarm",
+		0x0284: "AC Snooze Alarm",
+		0x0285: "AC Reset Alarm",
+		0x0286: "AC Synchronize",
+		0x0287: "AC Send/Receive",
+		0x0288: "AC Send To",
+		0x0289: "AC Reply",
+		0x028a: "AC Reply All",
+		0x028b: "AC Forward Msg",
+		0x028c: "AC Send",
+		0x028d: "AC Attach File",
+		0x028e: "AC Upload",
+		0x028f: "AC Download (Save Target As)",
+		0x0290: "AC Set Borders",
+		0x0291: "AC Insert Row",
+		0x0292: "AC Insert Column",
+		0x0293: "AC Insert File",
+		0x0294: "AC Insert Picture",
+		0x0295: "AC Insert Object",
+		0x0296: "AC Insert Symbol",
+		0x0297: "AC Save and Close",
+		0x0298: "AC Rename",
+		0x0299: "AC Merge",
+		0x029a: "AC Split",
+		0x029b: "AC Distribute Horizontally",
+		0x029c: "AC Distribute Vertically",
+		0x029d: "AC Next Keyboard Layout Select",
+		0x029e: "AC Navigation Guidance",
+		0x029f: "AC Desktop Show All Windows",
+		0x02a0: "AC Soft Key Left",
+		0x02a1: "AC Soft Key Right",
+		0x02a2: "AC Desktop Show All Applications",
+		0x02b0: "AC Idle Keep Alive",
+		0x02c0: "Extended Keyboard Attributes Collection",
+		0x02c1: "Keyboard Form Factor",
+		0x02c2: "Keyboard Key Type",
+		0x02c3: "Keyboard Physical Layout",
+		0x02c4: "Vendor-Specific Keyboard Physical Layout",
+		0x02c5: "Keyboard IETF Language Tag Index",
+		0x02c6: "Implemented Keyboard Input Assist Controls",
+		0x02c7: "Keyboard Input Assist Previous",
+		0x02c8: "Keyboard Input Assist Next",
+		0x02c9: "Keyboard Input Assist Previous Group",
+		0x02ca: "Keyboard Input Assist Next Group",
+		0x02cb: "Keyboard Input Assist Accept",
+		0x02cc: "Keyboard Input Assist Cancel",
+		0x02d0: "Privacy Screen Toggle",
+		0x02d1: "Privacy Screen Level Decrement",
+		0x02d2: "Privacy Screen Level Increment",
+		0x02d3: "Privacy Screen Level Minimum",
+		0x02d4: "Privacy Screen Level Maximum",
+		0x0500: "Contact Edited",
+		0x0501: "Contact Added",
+		0x0502: "Contact Record Active",
+		0x0503: "Contact Index",
+		0x0504: "Contact Nickname",
+		0x0505: "Contact First Name",
+		0x0506: "Contact Last Name",
+		0x0507: "Contact Full Name",
+		0x0508: "Contact Phone Number Personal",
+		0x0509: "Contact Phone Number Business",
+		0x050a: "Contact Phone Number Mobile",
+		0x050b: "Contact Phone Number Pager",
+		0x050c: "Contact Phone Number Fax",
+		0x050d: "Contact Phone Number Other",
+		0x050e: "Contact Email Personal",
+		0x050f: "Contact Email Business",
+		0x0510: "Contact Email Other",
+		0x0511: "Contact Email Main",
+		0x0512: "Contact Speed Dial Number",
+		0x0513: "Contact Status Flag",
+		0x0514: "Contact Misc.",
+	}},
+	0x000d: {name: "Digitizers", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Digitizer",
+		0x0002: "Pen",
+		0x0003: "Light Pen",
+		0x0004: "Touch Screen",
+		0x0005: "Touch Pad",
+		0x0006: "Whiteboard",
+		0x0007: "Coordinate Measuring Machine",
+		0x0008: "3D Digitizer",
+		0x0009: "Stereo Plotter",
+		0x000a: "Articulated Arm",
+		0x000b: "Armature",
+		0x000c: "Multiple Point Digitizer",
+		0x000d: "Free Space Wand",
+		0x000e: "Device Configuration",
+		0x000f: "Capacitive Heat Map Digitizer",
+		0x0020: "Stylus",
+		0x0021: "Puck",
+		0x0022: "Finger",
+		0x0023: "Device Settings",
+		0x0024: "Character Gesture",
+		0x0030: "Tip Pressure",
+		0x0031: "Barrel Pressure",
+		0x0032: "In Range",
+		0x0033: "Touch",
+		0x0034: "Untouch",
+		0x0035: "Tap",
+		0x0036: "Quality",
+		0x0037: "Data Valid",
+		0x0038: "Transducer Index",
+		0x0039: "Tablet Function Keys",
+		0x003a: "Program Change Keys",
+		0x003b: "Battery Strength",
+		0x003c: "Invert",
+		0x003d: "X Tilt",
+		0x003e: "Y Tilt",
+		0x003f: "Azimuth",
+		0x0040: "Altitude",
+		0x0041: "Twist",
+		0x0042: "Tip Switch",
+		0x0043: "Secondary Tip Switch",
+		0x0044: "Barrel Switch",
+		0x0045: "Eraser",
+		0x0046: "Tablet Pick",
+		0x0047: "Touch Valid",
+		0x0048: "Width",
+		0x0049: "Height",
+		0x0051: "Contact Identifier",
+		0x0052: "Device Mode",
+		0x0053: "Device Identifier",
+		0x0054: "Contact Count",
+		0x0055: "Contact Count Maximum",
+		0x0056: "Scan Time",
+		0x0057: "Surface Switch",
+		0x0058: "Button Switch",
+		0x0059: "Pad Type",
+		0x005a: "Secondary Barrel Switch",
+		0x005b: "Transducer Serial Number",
+		0x005c: "Preferred Color",
+		0x005d: "Preferred Color is Locked",
+		0x005e: "Preferred Line Width",
+		0x005f: "Preferred Line Width is Locked",
+		0x0060: "Latency Mode",
+		0x0061: "Gesture Character Quality",
+		0x0062: "Character Gesture Data Length",
+		0x0063: "Character Gesture Data",
+		0x0064: "Gesture Character Encoding",
+		0x0065: "UTF8 Character Gesture Encoding",
+		0x0066: "UTF16 Little Endian Character Gesture Encoding",
+		0x0067: "UTF16 Big Endian Character Gesture Encoding",
+		0x0068: "UTF32 Little Endian Character Gesture Encoding",
+		0x0069: "UTF32 Big Endian Character Gesture Encoding",
+		0x006a: "Capacitive Heat Map Protocol Vendor ID",
+		0x006b: "Capacitive Heat Map Protocol Version",
+		0x006c: "Capacitive Heat Map Frame Data",
+		0x006d: "Gesture Character Enable",
+		0x006e: "Transducer Serial Number Part 2",
+		0x006f: "No Preferred Color",
+		0x0070: "Preferred Line Style",
+		0x0071: "Preferred Line Style is Locked",
+		0x0072: "Ink",
+		0x0073: "Pencil",
+		0x0074: "Highlighter",
+		0x0075: "Chisel Marker",
+		0x0076: "Brush",
+		0x0077: "No Preference",
+		0x0080: "Digitizer Diagnostic",
+		0x0081: "Digitizer Error",
+		0x0082: "Err Normal Status",
+		0x0083: "Err Transducers Exceeded",
+		0x0084: "Err Full Trans Features Unavailable",
+		0x0085: "Err Charge Low",
+		0x0090: "Transducer Software Info",
+		0x0091: "Transducer Vendor ID",
+		0x0092: "Transducer Product ID",
+		0x0093: "Device Supported Protocols",
+		0x0094: "Transducer Supported Protocols",
+		0x0095: "No Protocol",
+		0x0096: "Wacom AES Protocol",
+		0x0097: "USI Protocol",
+		0x0098: "Microsoft Pen Protocol",
+		0x00a0: "Supported Report Rates",
+		0x00a1: "Report Rate",
+		0x00a2: "Transducer Connected",
+		0x00a3: "Switch Disabled",
+		0x00a4: "Switch Unimplemented",
+		0x00a5: "Transducer Switches",
+		0x00a6: "Transducer Index Selector",
+		0x00b0: "Button Press Threshold",
+	}},
+	0x000e: {name: "Haptics", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Simple Haptic Controller",
+		0x0010: "Waveform List",
+		0x0011: "Duration List",
+		0x0020: "Auto Trigger",
+		0x0021: "Manual Trigger",
+		0x0022: "Auto Trigger Associated Control",
+		0x0023: "Intensity",
+		0x0024: "Repeat Count",
+		0x0025: "Retrigger Period",
+		0x0026: "Waveform Vendor Page",
+		0x0027: "Waveform Vendor ID",
+		0x0028: "Waveform Cutoff Time",
+		0x1001: "Waveform None",
+		0x1002: "Waveform Stop",
+		0x1003: "Waveform Click",
+		0x1004: "Waveform Buzz Continuous",
+		0x1005: "Waveform Rumble Continuous",
+		0x1006: "Waveform Press",
+		0x1007: "Waveform Release",
+	}},
+	0x000f: {name: "Physical Input Device", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Physical Input Device",
+		0x0020: "Normal",
+		0x0021: "Set Effect Report",
+		0x0022: "Effect Parameter Block Index",
+		0x0023: "Parameter Block Offset",
+		0x0024: "ROM Flag",
+		0x0025: "Effect Type",
+		0x0026: "ET Constant-Force",
+		0x0027: "ET Ramp",
+		0x0028: "ET Custom-Force",
+		0x0030: "ET Square",
+		0x0031: "ET Sine",
+		0x0032: "ET Triangle",
+		0x0033: "ET Sawtooth Up",
+		0x0034: "ET Sawtooth Down",
+		0x0040: "ET Spring",
+		0x0041: "ET Damper",
+		0x0042: "ET Inertia",
+		0x0043: "ET Friction",
+		0x0050: "Duration",
+		0x0051: "Sample Period",
+		0x0052: "Gain",
+		0x0053: "Trigger Button",
+		0x0054: "Trigger Repeat Interval",
+		0x0055: "Axes Enable",
+		0x0056: "Direction Enable",
+		0x0057: "Direction",
+		0x0058: "Type Specific Block Offset",
+		0x0059: "Block Type",
+		0x005a: "Set Envelope Report",
+		0x005b: "Attack Level",
+		0x005c: "Attack Time",
+		0x005d: "Fade Level",
+		0x005e: "Fade Time",
+		0x005f: "Set Condition Report",
+		0x0060: "Center-Point Offset",
+		0x0061: "Positive Coefficient",
+		0x0062: "Negative Coefficient",
+		0x0063: "Positive Saturation",
+		0x0064: "Negative Saturation",
+		0x0065: "Dead Band",
+		0x0066: "Download Force Sample",
+		0x0067: "Isoch Custom-Force Enable",
+		0x0068: "Custom-Force Data Report",
+		0x0069: "Custom-Force Data",
+		0x006a: "Custom-Force Vendor Defined Data",
+		0x006b: "Set Custom-Force Report",
+		0x006c: "Custom-Force Data Offset",
+		0x006d: "Sample Count",
+		0x006e: "Set Periodic Report",
+		0x006f: "Offset",
+		0x0070: "Magnitude",
+		0x0071: "Phase",
+		0x0072: "Period",
+		0x0073: "Set Constant-Force Report",
+		0x0074: "Set Ramp-Force Report",
+		0x0075: "Ramp Start",
+		0x0076: "Ramp End",
+		0x0077: "Effect Operation Report",
+		0x0078: "Effect Operation",
+		0x0079: "Op Effect Start",
+		0x007a: "Op Effect Start Solo",
+		0x007b: "Op Effect Stop",
+		0x007c: "Loop Count",
+		0x007d: "Device Gain Report",
+		0x007e: "Device Gain",
+		0x007f: "PID Pool Report",
+		0x0080: "RAM Pool Size",
+		0x0081: "ROM Pool Size",
+		0x0082: "ROM Effect Block Count",
+		0x0083: "Simultaneous Effects Max",
+		0x0084: "Pool Alignment",
+		0x0085: "PID Pool Move Report",
+		0x0086: "Move Source",
+		0x0087: "Move Destination",
+		0x0088: "Move Length",
+		0x0089: "PID Block Load Report",
+		0x008b: "Block Load Status",
+		0x008c: "Block Load Success",
+		0x008d: "Block Load Full",
+		0x008e: "Block Load Error",
+		0x008f: "Block Handle",
+		0x0090: "PID Block Free Report",
+		0x0091: "Type Specific Block Handle",
+		0x0092: "PID State Report",
+		0x0094: "Effect Playing",
+		0x0095: "PID Device Control Report",
+		0x0096: "PID Device Control",
+		0x0097: "DC Enable Actuators",
+		0x0098: "DC Disable Actuators",
+		0x0099: "DC Stop All Effects",
+		0x009a: "DC Device Reset",
+		0x009b: "DC Device Pause",
+		0x009c: "DC Device Continue",
+		0x009f: "Device Paused",
+		0x00a0: "Actuators Enabled",
+		0x00a4: "Safety Switch",
+		0x00a5: "Actuator Override Switch",
+		0x00a6: "Actuator Power",
+		0x00a7: "Start Delay",
+		0x00a8: "Parameter Block Size",
+		0x00a9: "Device-Managed Pool",
+		0x00aa: "Shared Parameter Blocks",
+		0x00ab: "Create New Effect Report",
+		0x00ac: "RAM Pool Available",
+	}},
+	0x0010: {name: "Unicode", usages: map[uint16]string{}},
+	0x0012: {name: "Eye and Head Trackers", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Eye Tracker",
+		0x0002: "Head Tracker",
+		0x0010: "Tracking Data",
+		0x0011: "Capabilities",
+		0x0012: "Configuration",
+		0x0013: "Status",
+		0x0014: "Control",
+		0x0020: "Sensor Timestamp",
+		0x0021: "Position X",
+		0x0022: "Position Y",
+		0x0023: "Position Z",
+		0x0024: "Gaze Point",
+		0x0025: "Left Eye Position",
+		0x0026: "Right Eye Position",
+		0x0027: "Head Position",
+		0x0028: "Head Direction Point",
+		0x0029: "Rotation about X axis",
+		0x002a: "Rotation about Y axis",
+		0x002b: "Rotation about Z axis",
+	}},
+	0x0014: {name: "Auxiliary Display", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Alphanumeric Display",
+		0x0002: "Auxiliary Display",
+		0x0020: "Display Attributes Report",
+		0x0021: "ASCII Character Set",
+		0x0022: "Data Read Back",
+		0x0023: "Font Read Back",
+		0x0024: "Display Control Report",
+		0x0025: "Clear Display",
+		0x0026: "Display Enable",
+		0x0027: "Screen Saver Delay",
+		0x0028: "Screen Saver Enable",
+		0x0029: "Vertical Scroll",
+		0x002a: "Horizontal Scroll",
+		0x002b: "Character Report",
+		0x002c: "Display Data",
+		0x002d: "Display Status",
+		0x002e: "Stat Not Ready",
+		0x002f: "Stat Ready",
+		0x0030: "Err Not a loadable character",
+		0x0031: "Err Font data cannot be read",
+		0x0032: "Cursor Position Report",
+		0x0033: "Row",
+		0x0034: "Column",
+		0x0035: "Rows",
+		0x0036: "Columns",
+		0x0037: "Cursor Pixel Positioning",
+		0x0038: "Cursor Mode",
+		0x0039: "Cursor Enable",
+		0x003a: "Cursor Blink",
+		0x003b: "Font Report",
+		0x003c: "Font Data",
+		0x003d: "Character Width",
+		0x003e: "Character Height",
+		0x003f: "Character Spacing Horizontal",
+		0x0040: "Character Spacing Vertical",
+		0x0041: "Unicode Character Set",
+		0x0042: "Font 7-Segment",
+		0x0043: "7-Segment Direct Map",
+		0x0044: "Font 14-Segment",
+		0x0045: "14-Segment Direct Map",
+		0x0046: "Display Brightness",
+		0x0047: "Display Contrast",
+		0x0048: "Character Attribute",
+		0x0049: "Attribute Readback",
+		0x004a: "Attribute Data",
+		0x004b: "Char Attr Enhance",
+		0x004c: "Char Attr Underline",
+		0x004d: "Char Attr Blink",
+		0x0080: "Bitmap Size X",
+		0x0081: "Bitmap Size Y",
+		0x0082: "Max Blit Size",
+		0x0083: "Bit Depth Format",
+		0x0084: "Display Orientation",
+		0x0085: "Palette Report",
+		0x0086: "Palette Data Size",
+		0x0087: "Palette Data Offset",
+		0x0088: "Palette Data",
+		0x008a: "Blit Report",
+		0x008b: "Blit Rectangle X1",
+		0x008c: "Blit Rectangle Y1",
+		0x008d: "Blit Rectangle X2",
+		0x008e: "Blit Rectangle Y2",
+		0x008f: "Blit Data",
+		0x0090: "Soft Button",
+		0x0091: "Soft Button ID",
+		0x0092: "Soft Button Side",
+		0x0093: "Soft Button Offset 1",
+		0x0094: "Soft Button Offset 2",
+		0x0095: "Soft Button Report",
+	}},
+	0x0020: {name: "Sensors", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Sensor",
+		0x0010: "Biometric",
+		0x0011: "Biometric: Human Presence",
+		0x0012: "Biometric: Human Proximity",
+		0x0013: "Biometric: Human Touch",
+		0x0014: "Biometric: Blood Pressure",
+		0x0015: "Biometric: Body Temperature",
+		0x0016: "Biometric: Heart Rate",
+		0x0017: "Biometric: Heart Rate Variability",
+		0x0018: "Biometric: Peripheral Oxygen Saturation",
+		0x0019: "Biometric: Respiratory Rate",
+		0x0020: "Electrical",
+		0x0021: "Electrical: Capacitance",
+		0x0022: "Electrical: Current",
+		0x0023: "Electrical: Power",
+		0x0024: "Electrical: Inductance",
+		0x0025: "Electrical: Resistance",
+		0x0026: "Electrical: Voltage",
+		0x0027: "Electrical: Potentiometer",
+		0x0028: "Electrical: Frequency",
+		0x0029: "Electrical: Period",
+		0x0030: "Environmental",
+		0x0031: "Environmental: Atmospheric Pressure",
+		0x0032: "Environmental: Humidity",
+		0x0033: "Environmental: Temperature",
+		0x0034: "Environmental: Wind Direction",
+		0x0035: "Environmental: Wind Speed",
+		0x0036: "Environmental: Air Quality",
+		0x0037: "Environmental: Heat Index",
+		0x0038: "Environmental: Surface Temperature",
+		0x0039: "Environmental: Volatile Organic Compounds",
+		0x003a: "Environmental: Object Presence",
+		0x003b: "Environmental: Object Proximity",
+		0x0040: "Light",
+		0x0041: "Light: Ambient Light",
+		0x0042: "Light: Consumer Infrared",
+		0x0043: "Light: Infrared Light",
+		0x0044: "Light: Visible Light",
+		0x0045: "Light: Ultraviolet Light",
+		0x0050: "Location",
+		0x0051: "Location: Broadcast",
+		0x0052: "Location: Dead Reckoning",
+		0x0053: "Location: GPS",
+		0x0054: "Location: Lookup",
+		0x0055: "Location: Other",
+		0x0056: "Location: Static",
+		0x0057: "Location: Triangulation",
+		0x0060: "Mechanical",
+		0x0061: "Mechanical: Boolean Switch",
+		0x0062: "Mechanical: Boolean Switch Array",
+		0x0063: "Mechanical: Multivalue Switch",
+		0x0064: "Mechanical: Force",
+		0x0065: "Mechanical: Pressure",
+		0x0066: "Mechanical: Strain",
+		0x0067: "Mechanical: Weight",
+		0x0068: "Mechanical: Haptic Vibrator",
+		0x0069: "Mechanical: Hall Effect Switch",
+		0x0070: "Motion",
+		0x0071: "Motion: Accelerometer 1D",
+		0x0072: "Motion: Accelerometer 2D",
+		0x0073: "Motion: Accelerometer 3D",
+		0x0074: "Motion: Gyrometer 1D",
+		0x0075: "Motion: Gyrometer 2D",
+		0x0076: "Motion: Gyrometer 3D",
+		0x0077: "Motion: Motion Detector",
+		0x0078: "Motion: Speedometer",
+		0x0079: "Motion: Accelerometer",
+		0x007a: "Motion: Gyrometer",
+		0x007b: "Motion: Gravity Vector",
+		0x007c: "Motion: Linear Accelerometer",
+		0x0080: "Orientation",
+		0x0081: "Orientation: Compass 1D",
+		0x0082: "Orientation: Compass 2D",
+		0x0083: "Orientation: Compass 3D",
+		0x0084: "Orientation: Inclinometer 1D",
+		0x0085: "Orientation: Inclinometer 2D",
+		0x0086: "Orientation: Inclinometer 3D",
+		0x0087: "Orientation: Distance 1D",
+		0x0088: "Orientation: Distance 2D",
+		0x0089: "Orientation: Distance 3D",
+		0x008a: "Orientation: Device Orientation",
+		0x008b: "Orientation: Compass",
+		0x008c: "Orientation: Inclinometer",
+		0x008d: "Orientation: Distance",
+		0x008e: "Orientation: Relative Orientation",
+		0x008f: "Orientation: Simple Orientation",
+		0x0090: "Scanner",
+		0x0091: "Scanner: Barcode",
+		0x0092: "Scanner: RFID",
+		0x0093: "Scanner: NFC",
+		0x00a0: "Time",
+		0x00a1: "Time: Alarm Timer",
+		0x00a2: "Time: Real Time Clock",
+		0x00b0: "Personal Activity",
+		0x00b1: "Personal Activity: Activity Detection",
+		0x00b2: "Personal Activity: Device Position",
+		0x00b3: "Personal Activity: Floor Tracker",
+		0x00b4: "Personal Activity: Pedometer",
+		0x00b5: "Personal Activity: Step Detection",
+		0x00c0: "Orientation Extended",
+		0x00c1: "Orientation Extended: Geomagnetic Orientation",
+		0x00c2: "Orientation Extended: Magnetometer",
+		0x00d0: "Gesture",
+		0x00d1: "Gesture: Chassis Flip Gesture",
+		0x00d2: "Gesture: Hinge Fold Gesture",
+		0x00e0: "Other",
+		0x00e1: "Other: Custom",
+		0x00e2: "Other: Generic",
+		0x00e3: "Other: Generic Enumerator",
+		0x00e4: "Other: Hinge Angle",
+		0x0200: "Event",
+		0x0201: "Event: Sensor State",
+		0x0202: "Event: Sensor Event",
+		0x0300: "Property",
+		0x0301: "Property: Friendly Name",
+		0x0302: "Property: Persistent Unique ID",
+		0x0303: "Property: Sensor Status",
+		0x0304: "Property: Minimum Report Interval",
+		0x0305: "Property: Sensor Manufacturer",
+		0x0306: "Property: Sensor Model",
+		0x0307: "Property: Sensor Serial Number",
+		0x0308: "Property: Sensor Description",
+		0x0309: "Property: Sensor Connection Type",
+		0x030a: "Property: Sensor Device Path",
+		0x030b: "Property: Hardware Revision",
+		0x030c: "Property: Firmware Version",
+		0x030d: "Property: Release Date",
+		0x030e: "Property: Report Interval",
+		0x030f: "Property: Change Sensitivity Absolute",
+		0x0310: "Property: Change Sensitivity Percent of Range",
+		0x0311: "Property: Change Sensitivity Percent Relative",
+		0x0312: "Property: Accuracy",
+		0x0313: "Property: Resolution",
+		0x0314: "Property: Maximum",
+		0x0315: "Property: Minimum",
+		0x0316: "Property: Reporting State",
+		0x0317: "Property: Sampling Rate",
+		0x0318: "Property: Response Curve",
+		0x0319: "Property: Power State",
+		0x031a: "Property: Maximum FIFO Events",
+		0x031b: "Property: Report Latency",
+		0x031c: "Property: Flush FIFO Events",
+		0x031d: "Property: Maximum Power Consumption",
+		0x031e: "Property: Is Primary",
+		0x031f: "Property: Human Presence Detection Type",
+		0x0400: "Data Field: Location",
+		0x0430: "Data Field: Environmental",
+		0x0431: "Data Field: Atmospheric Pressure",
+		0x0433: "Data Field: Relative Humidity",
+		0x0434: "Data Field: Temperature",
+		0x0450: "Data Field: Motion",
+		0x0451: "Data Field: Motion State",
+		0x0452: "Data Field: Acceleration",
+		0x0453: "Data Field: Acceleration Axis X",
+		0x0454: "Data Field: Acceleration Axis Y",
+		0x0455: "Data Field: Acceleration Axis Z",
+		0x0456: "Data Field: Angular Velocity",
+		0x0457: "Data Field: Angular Velocity about X Axis",
+		0x0458: "Data Field: Angular Velocity about Y Axis",
+		0x0459: "Data Field: Angular Velocity about Z Axis",
+		0x045a: "Data Field: Angular Position",
+		0x045b: "Data Field: Angular Position about X Axis",
+		0x045c: "Data Field: Angular Position about Y Axis",
+		0x045d: "Data Field: Angular Position about Z Axis",
+		0x045e: "Data Field: Motion Speed",
+		0x045f: "Data Field: Motion Intensity",
+		0x0470: "Data Field: Orientation",
+		0x0471: "Data Field: Heading",
+		0x0472: "Data Field: Heading X Axis",
+		0x0473: "Data Field: Heading Y Axis",
+		0x0474: "Data Field: Heading Z Axis",
+		0x0475: "Data Field: Heading Compensated Magnetic North",
+		0x0476: "Data Field: Heading Compensated True North",
+		0x0477: "Data Field: Heading Magnetic North",
+		0x0478: "Data Field: Heading True North",
+		0x0479: "Data Field: Distance",
+		0x047a: "Data Field: Distance X Axis",
+		0x047b: "Data Field: Distance Y Axis",
+		0x047c: "Data Field: Distance Z Axis",
+		0x047d: "Data Field: Distance Out-of-Range",
+		0x047e: "Data Field: Tilt",
+		0x047f: "Data Field: Tilt X Axis",
+		0x0480: "Data Field: Tilt Y Axis",
+		0x0481: "Data Field: Tilt Z Axis",
+		0x0482: "Data Field: Rotation Matrix",
+		0x0483: "Data Field: Quaternion",
+		0x0484: "Data Field: Magnetic Flux",
+		0x0485: "Data Field: Magnetic Flux X Axis",
+		0x0486: "Data Field: Magnetic Flux Y Axis",
+		0x0487: "Data Field: Magnetic Flux Z Axis",
+		0x0488: "Data Field: Magnetometer Accuracy",
+		0x0489: "Data Field: Simple Orientation Direction",
+		0x04b0: "Data Field: Biometric",
+		0x04b1: "Data Field: Human Presence",
+		0x04b2: "Data Field: Human Proximity Range",
+		0x04b3: "Data Field: Human Proximity Out of Range",
+		0x04b4: "Data Field: Human Touch State",
+		0x04d0: "Data Field: Light",
+		0x04d1: "Data Field: Illuminance",
+		0x04d2: "Data Field: Color Temperature",
+		0x04d3: "Data Field: Chromaticity",
+		0x04d4: "Data Field: Chromaticity X",
+		0x04d5: "Data Field: Chromaticity Y",
+		0x0800: "Sensor State: Undefined",
+		0x0801: "Sensor State: Ready",
+		0x0802: "Sensor State: Not Available",
+		0x0803: "Sensor State: No Data",
+		0x0804: "Sensor State: Initializing",
+		0x0805: "Sensor State: Access Denied",
+		0x0806: "Sensor State: Error",
+		0x0810: "Sensor Event: Unknown",
+		0x0811: "Sensor Event: State Changed",
+		0x0812: "Sensor Event: Property Changed",
+		0x0813: "Sensor Event: Data Updated",
+		0x0814: "Sensor Event: Poll Response",
+		0x0815: "Sensor Event: Change Sensitivity",
+		0x0816: "Sensor Event: Range Maximum Reached",
+		0x0817: "Sensor Event: Range Minimum Reached",
+		0x0818: "Sensor Event: High Threshold Cross Upward",
+		0x0819: "Sensor Event: High Threshold Cross Downward",
+		0x081a: "Sensor Event: Low Threshold Cross Upward",
+		0x081b: "Sensor Event: Low Threshold Cross Downward",
+		0x081c: "Sensor Event: Zero Threshold Cross Upward",
+		0x081d: "Sensor Event: Zero Threshold Cross Downward",
+		0x081e: "Sensor Event: Period Exceeded",
+		0x081f: "Sensor Event: Frequency Exceeded",
+		0x0820: "Sensor Event: Complex Trigger",
+		0x0830: "Connection Type: PC Integrated",
+		0x0831: "Connection Type: PC Attached",
+		0x0832: "Connection Type: PC External",
+		0x0840: "Reporting State: Report No Events",
+		0x0841: "Reporting State: Report All Events",
+		0x0842: "Reporting State: Report Threshold Events",
+		0x0843: "Reporting State: Wake On No Events",
+		0x0844: "Reporting State: Wake On All Events",
+		0x0845: "Reporting State: Wake On Threshold Events",
+		0x0850: "Power State: Undefined",
+		0x0851: "Power State: D0 Full Power",
+		0x0852: "Power State: D1 Low Power",
+		0x0853: "Power State: D2 Standby Power with Wakeup",
+		0x0854: "Power State: D3 Sleep with Wakeup",
+		0x0855: "Power State: D4 Power Off",
+	}},
+	0x0040: {name: "Medical Instrument", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Medical Ultrasound",
+		0x0020: "VCR/Acquisition",
+		0x0021: "Freeze/Thaw",
+		0x0022: "Clip Store",
+		0x0023: "Update",
+		0x0024: "Next",
+		0x0025: "Save",
+		0x0026: "Print",
+		0x0027: "Microphone Enable",
+		0x0040: "Cine",
+		0x0041: "Transmit Power",
+		0x0042: "Volume",
+		0x0043: "Focus",
+		0x0044: "Depth",
+		0x0060: "Soft Step - Primary",
+		0x0061: "Soft Step - Secondary",
+		0x0070: "Depth Gain Compensation",
+		0x0080: "Zoom Select",
+		0x0081: "Zoom Adjust",
+		0x0082: "Spectral Doppler Mode Select",
+		0x0083: "Spectral Doppler Adjust",
+		0x0084: "Color Doppler Mode Select",
+		0x0085: "Color Doppler Adjust",
+		0x0086: "Motion Mode Select",
+		0x0087: "Motion Mode Adjust",
+		0x0088: "2-D Mode Select",
+		0x0089: "2-D Mode Adjust",
+		0x00a0: "Soft Control Select",
+		0x00a1: "Soft Control Adjust",
+	}},
+	0x0041: {name: "Braille Display", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Braille Display",
+		0x0002: "Braille Row",
+		0x0003: "8 Dot Braille Cell",
+		0x0004: "6 Dot Braille Cell",
+		0x0005: "Number of Braille Cells",
+		0x0006: "Screen Reader Control",
+		0x0007: "Screen Reader Identifier",
+		0x00fa: "Router Set 1",
+		0x00fb: "Router Set 2",
+		0x00fc: "Router Set 3",
+		0x0100: "Router Key",
+		0x0101: "Row Router Key",
+		0x0200: "Braille Buttons",
+		0x0201: "Braille Keyboard Dot 1",
+		0x0202: "Braille Keyboard Dot 2",
+		0x0203: "Braille Keyboard Dot 3",
+		0x0204: "Braille Keyboard Dot 4",
+		0x0205: "Braille Keyboard Dot 5",
+		0x0206: "Braille Keyboard Dot 6",
+		0x0207: "Braille Keyboard Dot 7",
+		0x0208: "Braille Keyboard Dot 8",
+		0x0209: "Braille Keyboard Space",
+		0x020a: "Braille Keyboard Left Space",
+		0x020b: "Braille Keyboard Right Space",
+		0x020c: "Braille Face Controls",
+		0x020d: "Braille Left Controls",
+		0x020e: "Braille Right Controls",
+		0x020f: "Braille Top Controls",
+		0x0210: "Braille Joystick Center",
+		0x0211: "Braille Joystick Up",
+		0x0212: "Braille Joystick Down",
+		0x0213: "Braille Joystick Left",
+		0x0214: "Braille Joystick Right",
+		0x0215: "Braille D-Pad Center",
+		0x0216: "Braille D-Pad Up",
+		0x0217: "Braille D-Pad Down",
+		0x0218: "Braille D-Pad Left",
+		0x0219: "Braille D-Pad Right",
+		0x021a: "Braille Pan Left",
+		0x021b: "Braille Pan Right",
+		0x021c: "Braille Rocker Up",
+		0x021d: "Braille Rocker Down",
+		0x021e: "Braille Rocker Press",
+	}},
+	0x0059: {name: "Lighting And Illumination", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "LampArray",
+		0x0002: "LampArrayAttributesReport",
+		0x0003: "LampCount",
+		0x0004: "BoundingBoxWidthInMicrometers",
+		0x0005: "BoundingBoxHeightInMicrometers",
+		0x0006: "BoundingBoxDepthInMicrometers",
+		0x0007: "LampArrayKind",
+		0x0008: "MinUpdateIntervalInMicroseconds",
+		0x0020: "LampAttributesRequestReport",
+		0x0021: "LampId",
+		0x0022: "LampAttributesResponseReport",
+		0x0023: "PositionXInMicrometers",
+		0x0024: "PositionYInMicrometers",
+		0x0025: "PositionZInMicrometers",
+		0x0026: "LampPurposes",
+		0x0027: "UpdateLatencyInMicroseconds",
+		0x0028: "RedLevelCount",
+		0x0029: "GreenLevelCount",
+		0x002a: "BlueLevelCount",
+		0x002b: "IntensityLevelCount",
+		0x002c: "IsProgrammable",
+		0x002d: "InputBinding",
+		0x0050: "LampMultiUpdateReport",
+		0x0051: "RedUpdateChannel",
+		0x0052: "GreenUpdateChannel",
+		0x0053: "BlueUpdateChannel",
+		0x0054: "IntensityUpdateChannel",
+		0x0055: "LampUpdateFlags",
+		0x0060: "LampRangeUpdateReport",
+		0x0061: "LampIdStart",
+		0x0062: "LampIdEnd",
+		0x0070: "LampArrayControlReport",
+		0x0071: "AutonomousMode",
+	}},
+	0x0080: {name: "Monitor", usages: map[uint16]string{
+		0x0000: "Reserved",
+		0x0001: "Monitor Control",
+		0x0002: "EDID Information",
+		0x0003: "VDIF Information",
+		0x0004: "VESA Version",
+	}},
+	0x0081: {name: "Monitor Enumerated", usages: map[uint16]string{}},
+	0x0082: {name: "VESA Virtual Controls", usages: map[uint16]string{
+		0x0001: "Degauss",
+		0x0010: "Brightness",
+		0x0012: "Contrast",
+		0x0016: "Red Video Gain",
+		0x0018: "Green Video Gain",
+		0x001a: "Blue Video Gain",
+		0x001c: "Focus",
+		0x0020: "Horizontal Position",
+		0x0022: "Horizontal Size",
+		0x0024: "Horizontal Pincushion",
+		0x0026: "Horizontal Pincushion Balance",
+		0x0028: "Horizontal Misconvergence",
+		0x002a: "Horizontal Linearity",
+		0x002c: "Horizontal Linearity Balance",
+		0x0030: "Vertical Position",
+		0x0032: "Vertical Size",
+		0x0034: "Vertical Pincushion",
+		0x0036: "Vertical Pincushion Balance",
+		0x0038: "Vertical Misconvergence",
+		0x003a: "Vertical Linearity",
+		0x003c: "Vertical Linearity Balance",
+		0x0040: "Parallelogram Distortion (Key Balance)",
+		0x0042: "Trapezoidal Distortion (Key)",
+		0x0044: "Tilt (Rotation)",
+		0x0046: "Top Corner Distortion Control",
+		0x0048: "Top Corner Distortion Balance",
+		0x004a: "Bottom Corner Distortion Control",
+		0x004c: "Bottom Corner Distortion Balance",
+		0x0056: "Horizontal Moire",
+		0x0058: "Vertical Moire",
+		0x005e: "Input Level Select",
+		0x0060: "Input Source Select",
+		0x006c: "Red Video Black Level",
+		0x006e: "Green Video Black Level",
+		0x0070: "Blue Video Black Level",
+		0x00a2: "Auto Size Center",
+		0x00a4: "Polarity Horizontal Synchronization",
+		0x00a6: "Polarity Vertical Synchronization",
+		0x00a8: "Synchronization Type",
+		0x00aa: "Screen Orientation",
+		0x00ac: "Horizontal Frequency",
+		0x00ae: "Vertical Frequency",
+		0x00b0: "Settings",
+		0x00ca: "On Screen Display",
+		0x00d4: "Stereo Mode",
+	}},
+	0x0084: {name: "Power", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "iName",
+		0x0002: "Present Status",
+		0x0003: "Changed Status",
+		0x0004: "UPS",
+		0x0005: "Power Supply",
+		0x0010: "Battery System",
+		0x0011: "Battery System ID",
+		0x0012: "Battery",
+		0x0013: "Battery ID",
+		0x0014: "Charger",
+		0x0015: "Charger ID",
+		0x0016: "Power Converter",
+		0x0017: "Power Converter ID",
+		0x0018: "Outlet System",
+		0x0019: "Outlet System ID",
+		0x001a: "Input",
+		0x001b: "Input ID",
+		0x001c: "Output",
+		0x001d: "Output ID",
+		0x001e: "Flow",
+		0x001f: "Flow ID",
+		0x0020: "Outlet",
+		0x0021: "Outlet ID",
+		0x0022: "Gang",
+		0x0023: "Gang ID",
+		0x0024: "Power Summary",
+		0x0025: "Power Summary ID",
+		0x0030: "Voltage",
+		0x0031: "Current",
+		0x0032: "Frequency",
+		0x0033: "Apparent Power",
+		0x0034: "Active Power",
+		0x0035: "Percent Load",
+		0x0036: "Temperature",
+		0x0037: "Humidity",
+		0x0038: "Bad Count",
+		0x0040: "Config Voltage",
+		0x0041: "Config Current",
+		0x0042: "Config Frequency",
+		0x0043: "Config Apparent Power",
+		0x0044: "Config Active Power",
+		0x0045: "Config Percent Load",
+		0x0046: "Config Temperature",
+		0x0047: "Config Humidity",
+		0x0050: "Switch On Control",
+		0x0051: "Switch Off Control",
+		0x0052: "Toggle Control",
+		0x0053: "Low Voltage Transfer",
+		0x0054: "High Voltage Transfer",
+		0x0055: "Delay Before Reboot",
+		0x0056: "Delay Before Startup",
+		0x0057: "Delay Before Shutdown",
+		0x0058: "Test",
+		0x0059: "Module Reset",
+		0x005a: "Audible Alarm Control",
+		0x0060: "Present",
+		0x0061: "Good",
+		0x0062: "Internal Failure",
+		0x0063: "Voltage Out Of Range",
+		0x0064: "Frequency Out Of Range",
+		0x0065: "Overload",
+		0x0066: "Over Charged",
+		0x0067: "Over Temperature",
+		0x0068: "Shutdown Requested",
+		0x0069: "Shutdown Imminent",
+		0x006b: "Switch On/Off",
+		0x006c: "Switchable",
+		0x006d: "Used",
+		0x006e: "Boost",
+		0x006f: "Buck",
+		0x0070: "Initialized",
+		0x0071: "Tested",
+		0x0072: "Awaiting Power",
+		0x0073: "Communication Lost",
+		0x00fd: "iManufacturer",
+		0x00fe: "iProduct",
+		0x00ff: "iSerialNumber",
+	}},
+	0x0085: {name: "Battery System", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Smart Battery Battery Mode",
+		0x0002: "Smart Battery Battery Status",
+		0x0003: "Smart Battery Alarm Warning",
+		0x0004: "Smart Battery Charger Mode",
+		0x0005: "Smart Battery Charger Status",
+		0x0006: "Smart Battery Charger Spec Info",
+		0x0007: "Smart Battery Selector State",
+		0x0008: "Smart Battery Selector Presets",
+		0x0009: "Smart Battery Selector Info",
+		0x0010: "Optional Mfg Function 1",
+		0x0011: "Optional Mfg Function 2",
+		0x0012: "Optional Mfg Function 3",
+		0x0013: "Optional Mfg Function 4",
+		0x0014: "Optional Mfg Function 5",
+		0x0015: "Connection To SMBus",
+		0x0016: "Output Connection",
+		0x0017: "Charger Connection",
+		0x0018: "Battery Insertion",
+		0x0019: "Use Next",
+		0x001a: "OK To Use",
+		0x001b: "Battery Supported",
+		0x001c: "Selector Revision",
+		0x001d: "Charging Indicator",
+		0x0028: "Manufacturer Access",
+		0x0029: "Remaining Capacity Limit",
+		0x002a: "Remaining Time Limit",
+		0x002b: "At Rate",
+		0x002c: "Capacity Mode",
+		0x002d: "Broadcast To Charger",
+		0x002e: "Primary Battery",
+		0x002f: "Charge Controller",
+		0x0040: "Terminate Charge",
+		0x0041: "Terminate Discharge",
+		0x0042: "Below Remaining Capacity Limit",
+		0x0043: "Remaining Time Limit Expired",
+		0x0044: "Charging",
+		0x0045: "Discharging",
+		0x0046: "Fully Charged",
+		0x0047: "Fully Discharged",
+		0x0048: "Conditioning Flag",
+		0x0049: "At Rate OK",
+		0x004a: "Smart Battery Error Code",
+		0x004b: "Need Replacement",
+		0x0060: "At Rate Time To Full",
+		0x0061: "At Rate Time To Empty",
+		0x0062: "Average Current",
+		0x0063: "Max Error",
+		0x0064: "Relative State Of Charge",
+		0x0065: "Absolute State Of Charge",
+		0x0066: "Remaining Capacity",
+		0x0067: "Full Charge Capacity",
+		0x0068: "Run Time To Empty",
+		0x0069: "Average Time To Empty",
+		0x006a: "Average Time To Full",
+		0x006b: "Cycle Count",
+		0x0080: "Battery Pack Model Level",
+		0x0081: "Internal Charge Controller",
+		0x0082: "Primary Battery Support",
+		0x0083: "Design Capacity",
+		0x0084: "Specification Info",
+		0x0085: "Manufacture Date",
+		0x0086: "Serial Number",
+		0x0087: "iManufacturer Name",
+		0x0088: "iDevice Name",
+		0x0089: "iDevice Chemistry",
+		0x008a: "Manufacturer Data",
+		0x008b: "Rechargeable",
+		0x008c: "Warning Capacity Limit",
+		0x008d: "Capacity Granularity 1",
+		0x008e: "Capacity Granularity 2",
+		0x008f: "iOEM Information",
+		0x00c0: "Inhibit Charge",
+		0x00c1: "Enable Polling",
+		0x00c2: "Reset To Zero",
+		0x00d0: "AC Present",
+		0x00d1: "Battery Present",
+		0x00d2: "Power Fail",
+		0x00d3: "Alarm Inhibited",
+		0x00d4: "Thermistor Under Range",
+		0x00d5: "Thermistor Hot",
+		0x00d6: "Thermistor Cold",
+		0x00d7: "Thermistor Over Range",
+		0x00d8: "Voltage Out Of Range",
+		0x00d9: "Current Out Of Range",
+		0x00da: "Current Not Regulated",
+		0x00db: "Voltage Not Regulated",
+		0x00dc: "Master Mode",
+		0x00f0: "Charger Selector Support",
+		0x00f1: "Charger Spec",
+		0x00f2: "Level 2",
+		0x00f3: "Level 3",
+	}},
+	0x008c: {name: "Barcode Scanner", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Barcode Badge Reader",
+		0x0002: "Barcode Scanner",
+		0x0003: "Dumb Bar Code Scanner",
+		0x0004: "Cordless Scanner Base",
+		0x0005: "Bar Code Scanner Cradle",
+		0x0010: "Attribute Report",
+		0x0011: "Settings Report",
+		0x0012: "Scanned Data Report",
+		0x0013: "Raw Scanned Data Report",
+		0x0014: "Trigger Report",
+		0x0015: "Status Report",
+		0x0016: "UPC/EAN Control Report",
+		0x0017: "EAN 2/3 Label Control Report",
+		0x0018: "Code 39 Control Report",
+		0x0019: "Interleaved 2 of 5 Control Report",
+		0x001a: "Standard 2 of 5 Control Report",
+		0x001b: "MSI Plessey Control Report",
+		0x001c: "Codabar Control Report",
+		0x001d: "Code 128 Control Report",
+		0x001e: "Misc 1D Control Report",
+		0x001f: "2D Control Report",
+	}},
+	0x008d: {name: "Scales", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "Scales",
+		0x0020: "Scale Device",
+		0x0021: "Scale Class",
+		0x0022: "Scale Class I Metric",
+		0x0023: "Scale Class II Metric",
+		0x0024: "Scale Class III Metric",
+		0x0025: "Scale Class IIIL Metric",
+		0x0026: "Scale Class IV Metric",
+		0x0027: "Scale Class III English",
+		0x0028: "Scale Class IIIL English",
+		0x0029: "Scale Class IV English",
+		0x002a: "Scale Class Generic",
+		0x0030: "Scale Attribute Report",
+		0x0031: "Scale Control Report",
+		0x0032: "Scale Data Report",
+		0x0033: "Scale Status Report",
+		0x0034: "Scale Weight Limit Report",
+		0x0035: "Scale Statistics Report",
+		0x0040: "Data Weight",
+		0x0041: "Data Scaling",
+		0x0050: "Weight Unit",
+		0x0051: "Weight Unit Milligram",
+		0x0052: "Weight Unit Gram",
+		0x0053: "Weight Unit Kilogram",
+		0x0054: "Weight Unit Carats",
+		0x0055: "Weight Unit Taels",
+		0x0056: "Weight Unit Grains",
+		0x0057: "Weight Unit Pennyweights",
+		0x0058: "Weight Unit Metric Ton",
+		0x0059: "Weight Unit Avoir Ton",
+		0x005a: "Weight Unit Troy Ounce",
+		0x005b: "Weight Unit Ounce",
+		0x005c: "Weight Unit Pound",
+		0x0060: "Calibration Count",
+		0x0061: "Re-Zero Count",
+		0x0070: "Scale Status",
+		0x0071: "Scale Status Fault",
+		0x0072: "Scale Status Stable at Center of Zero",
+		0x0073: "Scale Status In Motion",
+		0x0074: "Scale Status Weight Stable",
+		0x0075: "Scale Status Under Zero",
+		0x0076: "Scale Status Over Weight Limit",
+		0x0077: "Scale Status Requires Calibration",
+		0x0078: "Scale Status Requires Rezeroing",
+		0x0080: "Zero Scale",
+		0x0081: "Enforced Zero Return",
+	}},
+	0x008e: {name: "Magnetic Stripe Reader", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "MSR Device Read-Only",
+		0x0011: "Track 1 Length",
+		0x0012: "Track 2 Length",
+		0x0013: "Track 3 Length",
+		0x0014: "Track JIS Length",
+		0x0020: "Track Data",
+		0x0021: "Track 1 Data",
+		0x0022: "Track 2 Data",
+		0x0023: "Track 3 Data",
+		0x0024: "Track JIS Data",
+	}},
+	0x0090: {name: "Camera Control", usages: map[uint16]string{
+		0x0020: "Camera Auto-focus",
+		0x0021: "Camera Shutter",
+	}},
+	0x0091: {name: "Arcade", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "General Purpose IO Card",
+		0x0002: "Coin Door",
+		0x0003: "Watchdog Timer",
+		0x0030: "General Purpose Analog Input State",
+		0x0031: "General Purpose Digital Input State",
+		0x0032: "General Purpose Optical Input State",
+		0x0033: "General Purpose Digital Output State",
+		0x0034: "Number of Coin Doors",
+		0x0035: "Coin Drawer Drop Count",
+		0x0036: "Coin Drawer Start",
+		0x0037: "Coin Drawer Service",
+		0x0038: "Coin Drawer Tilt",
+		0x0039: "Coin Door Test",
+		0x0040: "Coin Door Lockout",
+		0x0041: "Watchdog Timeout",
+		0x0042: "Watchdog Action",
+		0x0043: "Watchdog Reboot",
+		0x0044: "Watchdog Restart",
+		0x0045: "Alarm Input",
+		0x0046: "Coin Door Counter",
+		0x0047: "I/O Direction Mapping",
+		0x0048: "Set I/O Direction Mapping",
+		0x0049: "Extended Optical Input State",
+		0x004a: "Pin Pad Input State",
+		0x004b: "Pin Pad Status",
+		0x004c: "Pin Pad Output",
+		0x004d: "Pin Pad Command",
+	}},
+	0xf1d0: {name: "FIDO Alliance", usages: map[uint16]string{
+		0x0000: "Undefined",
+		0x0001: "U2F Authenticator Device",
+		0x0020: "Input Report Data",
+		0x0021: "Output Report Data",
+	}},
+}
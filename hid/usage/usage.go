@@ -0,0 +1,241 @@
+// Package usage names the usage pages and usages of the HID Usage Tables.
+//
+// The tables are generated from hut.txt, run go generate after editing it. Usages following a rule,
+// such as buttons, ordinals, Unicode characters and the modifiers of sensor usages, are named by the
+// lookup functions. Every lookup works both ways: Name and ID, PageName and PageID, String and Parse.
+package usage
+
+//go:generate go run gen.go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Usage pages used by this module.
+const (
+	PageGenericDesktop    = 0x01
+	PageSimulation        = 0x02
+	PageGame              = 0x05
+	PageGenericDevice     = 0x06
+	PageKeyboard          = 0x07
+	PageLED               = 0x08
+	PageButton            = 0x09
+	PageOrdinal           = 0x0A
+	PageTelephony         = 0x0B
+	PageConsumer          = 0x0C
+	PageDigitizer         = 0x0D
+	PageHaptics           = 0x0E
+	PagePID               = 0x0F
+	PageUnicode           = 0x10
+	PageSensor            = 0x20
+	PageMonitorEnumerated = 0x81
+	PagePower             = 0x84
+	PageBattery           = 0x85
+	PageFIDO              = 0xF1D0
+	// PageVendorMinimum is the first vendor defined page, all pages up to 0xFFFF are vendor defined.
+	PageVendorMinimum = 0xFF00
+)
+
+type page struct {
+	name   string
+	usages map[uint16]string
+}
+
+// sensorModifiers name the modifier in bits 12-15 of sensor page usages.
+var sensorModifiers = [16]string{
+	"", "Change Sensitivity Absolute", "Maximum", "Minimum", "Accuracy", "Resolution", "Threshold High",
+	"Threshold Low", "Calibration Offset", "Calibration Multiplier", "Report Interval", "Frequency Max",
+	"Period Max", "Change Sensitivity Range Percent", "Change Sensitivity Relative Percent", "Vendor Reserved",
+}
+
+var (
+	reverseOnce sync.Once
+	// pageIDs and usageIDs map lower case names to IDs.
+	pageIDs  map[string]uint16
+	usageIDs map[uint16]map[string]uint16
+	// pageNames are the page names, longest first, for Parse.
+	pageNames []string
+)
+
+func buildReverse() {
+	pageIDs = make(map[string]uint16)
+	usageIDs = make(map[uint16]map[string]uint16)
+	for id, p := range pages {
+		pageIDs[strings.ToLower(p.name)] = id
+		pageNames = append(pageNames, p.name)
+		ids := make(map[string]uint16)
+		for usage, name := range p.usages {
+			key := strings.ToLower(name)
+			if prev, exist := ids[key]; !exist || usage < prev {
+				ids[key] = usage
+			}
+		}
+		usageIDs[id] = ids
+	}
+	sort.Slice(pageNames, func(i, j int) bool {
+		if len(pageNames[i]) != len(pageNames[j]) {
+			return len(pageNames[i]) > len(pageNames[j])
+		}
+		return pageNames[i] < pageNames[j]
+	})
+}
+
+// PageName returns the name of a usage page, eg. "Generic Desktop", "Vendor Defined 0xFF00" or "0x0013" if unknown.
+func PageName(id uint16) string {
+	if p, exist := pages[id]; exist {
+		return p.name
+	}
+	if id >= PageVendorMinimum {
+		return fmt.Sprintf("Vendor Defined 0x%.4X", id)
+	}
+	return fmt.Sprintf("0x%.4x", id)
+}
+
+// PageID returns the usage page of a name returned by PageName, ignoring case. Hexadecimal IDs are accepted.
+func PageID(name string) (uint16, bool) {
+	reverseOnce.Do(buildReverse)
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if id, exist := pageIDs[lower]; exist {
+		return id, true
+	}
+	if strings.HasPrefix(lower, "vendor defined ") {
+		id, ok := parseHex(lower[len("vendor defined "):])
+		return id, ok && id >= PageVendorMinimum
+	}
+	return parseHex(lower)
+}
+
+// Lookup returns the name of a usage, false if the usage is not known.
+func Lookup(page, id uint16) (string, bool) {
+	switch page {
+	case PageButton:
+		if id == 0 {
+			return "No Button Pressed", true
+		}
+		return fmt.Sprintf("Button %d", id), true
+	case PageOrdinal:
+		if id == 0 {
+			return "", false
+		}
+		return fmt.Sprintf("Instance %d", id), true
+	case PageUnicode:
+		return fmt.Sprintf("U+%.4X", id), true
+	case PageMonitorEnumerated:
+		if id == 0 {
+			return "No ENUM", true
+		}
+		return fmt.Sprintf("ENUM %d", id), true
+	}
+	p, exist := pages[page]
+	if !exist {
+		return "", false
+	}
+	if name, exist := p.usages[id]; exist {
+		return name, true
+	}
+	if page == PageSensor && id >= 0x1000 {
+		if name, exist := p.usages[id&0x0FFF]; exist {
+			return fmt.Sprintf("%s (%s)", name, sensorModifiers[id>>12]), true
+		}
+	}
+	return "", false
+}
+
+// Name returns the name of a usage, eg. "X" or "Button 3", or the hexadecimal ID if the usage is not known.
+func Name(page, id uint16) string {
+	if name, ok := Lookup(page, id); ok {
+		return name
+	}
+	return fmt.Sprintf("0x%.4x", id)
+}
+
+// ID returns the usage ID of a name returned by Name, ignoring case. Hexadecimal IDs are accepted.
+func ID(page uint16, name string) (uint16, bool) {
+	reverseOnce.Do(buildReverse)
+	name = strings.TrimSpace(name)
+	lower := strings.ToLower(name)
+	if id, exist := usageIDs[page][lower]; exist {
+		return id, true
+	}
+	var n uint64
+	var err error
+	switch {
+	case page == PageButton && lower == "no button pressed":
+		return 0, true
+	case page == PageButton && strings.HasPrefix(lower, "button "):
+		n, err = strconv.ParseUint(name[len("button "):], 10, 16)
+	case page == PageOrdinal && strings.HasPrefix(lower, "instance "):
+		n, err = strconv.ParseUint(name[len("instance "):], 10, 16)
+	case page == PageUnicode && strings.HasPrefix(lower, "u+"):
+		n, err = strconv.ParseUint(name[2:], 16, 16)
+	case page == PageMonitorEnumerated && lower == "no enum":
+		return 0, true
+	case page == PageMonitorEnumerated && strings.HasPrefix(lower, "enum "):
+		n, err = strconv.ParseUint(name[len("enum "):], 10, 16)
+	case page == PageSensor && strings.HasSuffix(lower, ")"):
+		return sensorID(name)
+	default:
+		return parseHex(lower)
+	}
+	return uint16(n), err == nil
+}
+
+// sensorID parses a sensor usage with a modifier, eg. "Data Field: Acceleration (Maximum)".
+func sensorID(name string) (uint16, bool) {
+	open := strings.LastIndex(name, " (")
+	if open < 0 {
+		return 0, false
+	}
+	modifier := strings.ToLower(name[open+2 : len(name)-1])
+	for i, m := range sensorModifiers {
+		if i == 0 || strings.ToLower(m) != modifier {
+			continue
+		}
+		if id, exist := usageIDs[PageSensor][strings.ToLower(name[:open])]; exist && id < 0x1000 {
+			return uint16(i)<<12 | id, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the page and usage name separated by a slash, eg. "Generic Desktop/X".
+func String(page, id uint16) string {
+	return PageName(page) + "/" + Name(page, id)
+}
+
+// Parse parses a usage formatted by String, ignoring case. Page and usage may be hexadecimal, eg. "0xff00/0x01".
+func Parse(s string) (page, id uint16, err error) {
+	reverseOnce.Do(buildReverse)
+	lower := strings.ToLower(s)
+	// page names contain slashes too, so the longest matching page name wins
+	candidates := append([]string{}, pageNames...)
+	if i := strings.Index(s, "/"); i >= 0 {
+		candidates = append(candidates, s[:i])
+	}
+	for _, name := range candidates {
+		if !strings.HasPrefix(lower, strings.ToLower(name)+"/") {
+			continue
+		}
+		page, ok := PageID(name)
+		if !ok {
+			continue
+		}
+		if id, ok := ID(page, s[len(name)+1:]); ok {
+			return page, id, nil
+		}
+		return 0, 0, fmt.Errorf("unknown usage %q on page %s", s[len(name)+1:], PageName(page))
+	}
+	return 0, 0, fmt.Errorf("invalid usage %q, expected PAGE/USAGE", s)
+}
+
+func parseHex(s string) (uint16, bool) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[2:], 16, 16)
+	return uint16(n), err == nil
+}
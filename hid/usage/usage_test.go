@@ -0,0 +1,60 @@
+package usage
+
+import (
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, test := range []struct {
+		page, id uint16
+		name     string
+	}{
+		{PageGenericDesktop, 0x30, "Generic Desktop/X"},
+		{PageKeyboard, 0x04, "Keyboard/Keypad/Keyboard a and A"},
+		{PageLED, 0x02, "LED/Caps Lock"},
+		{PageButton, 3, "Button/Button 3"},
+		{PageButton, 0, "Button/No Button Pressed"},
+		{PageOrdinal, 2, "Ordinal/Instance 2"},
+		{PageConsumer, 0xE9, "Consumer/Volume Increment"},
+		{PageDigitizer, 0x42, "Digitizers/Tip Switch"},
+		{PageSensor, 0x73, "Sensors/Motion: Accelerometer 3D"},
+		{PageSensor, 0x2453, "Sensors/Data Field: Acceleration Axis X (Maximum)"},
+		{PageBattery, 0x66, "Battery System/Remaining Capacity"},
+		{PageUnicode, 0x263A, "Unicode/U+263A"},
+		{PageFIDO, 0x01, "FIDO Alliance/U2F Authenticator Device"},
+		{0xFF00, 0x01, "Vendor Defined 0xFF00/0x0001"},
+		{0x0013, 0x05, "0x0013/0x0005"},
+	} {
+		if s := String(test.page, test.id); s != test.name {
+			t.Fatalf("0x%.4x:0x%.4x: expected %q, got %q", test.page, test.id, test.name, s)
+		}
+		page, id, err := Parse(test.name)
+		if err != nil || page != test.page || id != test.id {
+			t.Fatalf("%s: parsed 0x%.4x:0x%.4x, %v", test.name, page, id, err)
+		}
+	}
+	if _, _, err := Parse("Generic Desktop/No Such Usage"); err == nil {
+		t.Fatal("expected an error for an unknown usage")
+	}
+	if page, ok := PageID("generic desktop"); !ok || page != PageGenericDesktop {
+		t.Fatalf("unexpected page 0x%.4x", page)
+	}
+}
+
+// TestRoundTrip checks that every name in the tables maps back to its ID.
+func TestRoundTrip(t *testing.T) {
+	for pageID, p := range pages {
+		if id, ok := PageID(p.name); !ok || id != pageID {
+			t.Fatalf("page %q: got 0x%.4x", p.name, id)
+		}
+		for id, name := range p.usages {
+			page, parsed, err := Parse(String(pageID, id))
+			if err != nil || page != pageID || parsed != id {
+				t.Fatalf("%s: got 0x%.4x:0x%.4x, %v", String(pageID, id), page, parsed, err)
+			}
+			if got, ok := ID(pageID, name); !ok || got != id {
+				t.Fatalf("%s: ID returned 0x%.4x", name, got)
+			}
+		}
+	}
+}
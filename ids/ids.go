@@ -0,0 +1,349 @@
+// Package ids parses the usb.ids database maintained at http://www.linux-usb.org/usb-ids.html
+// and provides name lookups for vendors, products, classes, subclasses, protocols and languages.
+//
+// Only the vendor (no prefix), class (C) and language (L) sections are parsed,
+// all other sections are skipped.
+package ids
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SystemPaths are the locations Default looks for usb.ids, in order.
+var SystemPaths = []string{
+	"/usr/share/hwdata/usb.ids",
+	"/usr/share/misc/usb.ids",
+	"/usr/share/usb.ids",
+	"/var/lib/usbutils/usb.ids",
+}
+
+//go:embed usb.ids
+var snapshot string
+
+type (
+	Vendor struct {
+		ID       uint16
+		Name     string
+		Products map[uint16]string
+	}
+
+	Class struct {
+		ID         uint8
+		Name       string
+		SubClasses map[uint8]*SubClass
+	}
+
+	SubClass struct {
+		ID        uint8
+		Name      string
+		Protocols map[uint8]string
+	}
+
+	Language struct {
+		ID       uint16
+		Name     string
+		Dialects map[uint8]string
+	}
+
+	// Database is a parsed usb.ids file.
+	Database struct {
+		// Version is the value of the "# Version:" comment, if present.
+		Version   string
+		Vendors   map[uint16]*Vendor
+		Classes   map[uint8]*Class
+		Languages map[uint16]*Language
+	}
+)
+
+var (
+	defaultOnce sync.Once
+	defaultDB   *Database
+
+	embeddedOnce sync.Once
+	embeddedDB   *Database
+	embeddedErr  error
+)
+
+// Default returns the first database found in SystemPaths, or the trimmed embedded snapshot if
+// none could be loaded. The database is loaded once.
+func Default() *Database {
+	defaultOnce.Do(func() {
+		for _, path := range SystemPaths {
+			if db, err := Load(path); err == nil {
+				defaultDB = db
+				return
+			}
+		}
+		defaultDB = Embedded()
+	})
+	return defaultDB
+}
+
+// Embedded returns the database parsed from the snapshot compiled into the package. The snapshot
+// is parsed once and the same database is returned on every call, it must not be modified.
+//
+// The snapshot is a trimmed copy of usb.ids with a few common vendors and languages and the
+// defined class codes. The complete list is published at http://www.linux-usb.org/usb.ids and
+// installed by the hwdata or usbutils packages of most distributions, use Load or Default to
+// read it.
+func Embedded() *Database {
+	embeddedOnce.Do(func() {
+		embeddedDB, embeddedErr = Parse(strings.NewReader(snapshot))
+	})
+	if embeddedErr != nil {
+		panic(fmt.Sprintf("ids: embedded snapshot: %v", embeddedErr))
+	}
+	return embeddedDB
+}
+
+// Load parses the usb.ids file at path.
+func Load(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	db, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Parse parses a database in usb.ids format.
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{
+		Vendors:   make(map[uint16]*Vendor),
+		Classes:   make(map[uint8]*Class),
+		Languages: make(map[uint16]*Language),
+	}
+	var (
+		vendor   *Vendor
+		class    *Class
+		subClass *SubClass
+		language *Language
+		// skip is set while in a section that is not parsed.
+		skip bool
+	)
+	reset := func() {
+		vendor, class, subClass, language, skip = nil, nil, nil, nil, false
+	}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.HasPrefix(line, "# Version:") {
+			db.Version = strings.TrimSpace(strings.TrimPrefix(line, "# Version:"))
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		depth := 0
+		for depth < len(line) && line[depth] == '\t' {
+			depth++
+		}
+		line = line[depth:]
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("line %d: %s", lineNumber, fmt.Sprintf(format, args...))
+		}
+		switch depth {
+		case 0:
+			reset()
+			switch {
+			case strings.HasPrefix(line, "C "):
+				id, name, err := splitEntry(line[2:], 8)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				class = &Class{ID: uint8(id), Name: name, SubClasses: make(map[uint8]*SubClass)}
+				db.Classes[class.ID] = class
+			case strings.HasPrefix(line, "L "):
+				id, name, err := splitEntry(line[2:], 16)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				language = &Language{ID: uint16(id), Name: name, Dialects: make(map[uint8]string)}
+				db.Languages[language.ID] = language
+			case isVendorEntry(line):
+				id, name, err := splitEntry(line, 16)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				vendor = &Vendor{ID: uint16(id), Name: name, Products: make(map[uint16]string)}
+				db.Vendors[vendor.ID] = vendor
+			default:
+				skip = true
+			}
+		case 1:
+			switch {
+			case vendor != nil:
+				id, name, err := splitEntry(line, 16)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				vendor.Products[uint16(id)] = name
+			case class != nil:
+				id, name, err := splitEntry(line, 8)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				subClass = &SubClass{ID: uint8(id), Name: name, Protocols: make(map[uint8]string)}
+				class.SubClasses[subClass.ID] = subClass
+			case language != nil:
+				id, name, err := splitEntry(line, 8)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				language.Dialects[uint8(id)] = name
+			case !skip:
+				return nil, fail("unexpected child entry %q", line)
+			}
+		case 2:
+			switch {
+			case subClass != nil:
+				id, name, err := splitEntry(line, 8)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				subClass.Protocols[uint8(id)] = name
+			case vendor != nil, language != nil, skip:
+				// Vendor interface entries are not used
+			default:
+				return nil, fail("unexpected child entry %q", line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// isVendorEntry reports if a top level line starts with a 4 digit hex id.
+func isVendorEntry(line string) bool {
+	if len(line) < 5 || line[4] != ' ' {
+		return false
+	}
+	for _, c := range []byte(line[:4]) {
+		if !('0' <= c && c <= '9') && !('a' <= c && c <= 'f') && !('A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitEntry splits an "id  name" entry.
+func splitEntry(line string, bitSize int) (uint64, string, error) {
+	fields := strings.SplitN(line, " ", 2)
+	if len(fields) != 2 {
+		return 0, "", fmt.Errorf("malformed entry %q", line)
+	}
+	id, err := strconv.ParseUint(fields[0], 16, bitSize)
+	if err != nil {
+		return 0, "", fmt.Errorf("malformed id in %q: %w", line, err)
+	}
+	return id, strings.TrimSpace(fields[1]), nil
+}
+
+// Vendor returns the name of a vendor, or "" if unknown.
+func (db *Database) Vendor(vendorID uint16) string {
+	if vendor, exist := db.Vendors[vendorID]; exist {
+		return vendor.Name
+	}
+	return ""
+}
+
+// Product returns the name of a product, or "" if unknown.
+func (db *Database) Product(vendorID, productID uint16) string {
+	if vendor, exist := db.Vendors[vendorID]; exist {
+		return vendor.Products[productID]
+	}
+	return ""
+}
+
+// Class returns the name of a class, or "" if unknown.
+func (db *Database) Class(class usb.ClassCode) string {
+	if c, exist := db.Classes[uint8(class)]; exist {
+		return c.Name
+	}
+	return ""
+}
+
+// SubClass returns the name of a subclass, or "" if unknown.
+func (db *Database) SubClass(class usb.ClassCode, subClass usb.SubClass) string {
+	if c, exist := db.Classes[uint8(class)]; exist {
+		if s, exist := c.SubClasses[uint8(subClass)]; exist {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// Protocol returns the name of a protocol, or "" if unknown.
+func (db *Database) Protocol(class usb.ClassCode, subClass usb.SubClass, protocol uint8) string {
+	if c, exist := db.Classes[uint8(class)]; exist {
+		if s, exist := c.SubClasses[uint8(subClass)]; exist {
+			return s.Protocols[protocol]
+		}
+	}
+	return ""
+}
+
+// Language returns the name of a language ID, including the dialect if known, or "" if unknown.
+func (db *Database) Language(id usb.LangID) string {
+	language, exist := db.Languages[uint16(id.Primary())]
+	if !exist {
+		return ""
+	}
+	if dialect, exist := language.Dialects[id.Sub()]; exist {
+		return fmt.Sprintf("%s(%s)", language.Name, dialect)
+	}
+	return language.Name
+}
+
+// DeviceInfo holds the human readable names of a device descriptor.
+// Names not found in the database are empty.
+type DeviceInfo struct {
+	VendorID  uint16
+	ProductID uint16
+	Vendor    string
+	Product   string
+	Class     string
+	SubClass  string
+	Protocol  string
+}
+
+// DeviceInfo looks up the names of a device descriptor.
+func (db *Database) DeviceInfo(desc *usb.DeviceDescriptor) *DeviceInfo {
+	return &DeviceInfo{
+		VendorID:  desc.IDVendor,
+		ProductID: desc.IDProduct,
+		Vendor:    db.Vendor(desc.IDVendor),
+		Product:   db.Product(desc.IDVendor, desc.IDProduct),
+		Class:     db.Class(desc.BDeviceClass),
+		SubClass:  db.SubClass(desc.BDeviceClass, desc.BDeviceSubClass),
+		Protocol:  db.Protocol(desc.BDeviceClass, desc.BDeviceSubClass, desc.BDeviceProtocol),
+	}
+}
+
+// String formats the info like lsusb, "1d6b:0002 Linux Foundation 2.0 root hub".
+func (info *DeviceInfo) String() string {
+	res := fmt.Sprintf("%.4x:%.4x", info.VendorID, info.ProductID)
+	if info.Vendor != "" {
+		res += " " + info.Vendor
+	}
+	if info.Product != "" {
+		res += " " + info.Product
+	}
+	return res
+}
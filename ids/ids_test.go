@@ -0,0 +1,37 @@
+package ids
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"strings"
+	"testing"
+)
+
+func TestEmbedded(t *testing.T) {
+	db := Embedded()
+	info := db.DeviceInfo(&usb.DeviceDescriptor{IDVendor: 0x1d6b, IDProduct: 0x0002, BDeviceClass: usb.ClassCodeDeviceHub})
+	if info.String() != "1d6b:0002 Linux Foundation 2.0 root hub" || info.Class != "Hub" {
+		t.Fatalf("unexpected device info %+v", info)
+	}
+	if name := db.Protocol(usb.ClassCodeInterfaceHID, 1, 1); name != "Keyboard" {
+		t.Fatalf("unexpected protocol name %q", name)
+	}
+	if name := db.Language(usb.LangIDEnglishUS); name != "English(US)" {
+		t.Fatalf("unexpected language name %q", name)
+	}
+	if Embedded() != db {
+		t.Fatal("the embedded snapshot was parsed again")
+	}
+}
+
+func TestParseSkipsSections(t *testing.T) {
+	db, err := Parse(strings.NewReader("# Version: 2024.01.01\n" +
+		"abcd  Vendor\n\t0001  Product\n\t\t00  Interface\n" +
+		"HUT 01  Generic Desktop Controls\n\t001  Pointer\n" +
+		"BIAS 0  Self powered\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Version != "2024.01.01" || db.Product(0xabcd, 1) != "Product" {
+		t.Fatalf("unexpected database %+v", db)
+	}
+}
@@ -0,0 +1,156 @@
+// Package lint checks a parsed descriptor set against the rules of the USB specification,
+// in the spirit of the USB-IF compliance tools.
+//
+// Each rule refers to the section of the USB 3.2 specification (or the ECN) it is taken from.
+// Findings are only as good as the descriptors, a device passing lint may still fail
+// electrical or protocol level compliance testing.
+package lint
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"sort"
+)
+
+type Severity uint8
+
+const (
+	SeverityInfo = Severity(iota)
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", uint8(s))
+}
+
+type (
+	// Finding is a single rule violation.
+	Finding struct {
+		Rule     string
+		Severity Severity
+		// Section of the specification the rule is taken from.
+		Section string
+		// Path locates the offending descriptor, eg. "config 1/interface 0.1/endpoint 0x81".
+		Path    string
+		Message string
+	}
+
+	// Reporter is used by a rule to report a finding at path.
+	Reporter func(path string, format string, args ...any)
+
+	// Rule is a single compliance check.
+	Rule struct {
+		ID          string
+		Section     string
+		Severity    Severity
+		Description string
+		Check       func(set *usb.DescriptorSet, report Reporter)
+	}
+)
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s, %s]", f.Severity, f.Path, f.Message, f.Rule, f.Section)
+}
+
+var rules = make([]*Rule, 0, 32)
+
+// Register adds a rule to the set of rules run by Check.
+// Class packages may register class-specific rules from an init function.
+func Register(rule *Rule) {
+	for _, registered := range rules {
+		if registered.ID == rule.ID {
+			panic(fmt.Sprintf("lint rule %s registered twice", rule.ID))
+		}
+	}
+	rules = append(rules, rule)
+}
+
+// Rules returns all registered rules sorted by ID.
+func Rules() []*Rule {
+	res := make([]*Rule, len(rules))
+	copy(res, rules)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// Check runs all registered rules over the descriptor set.
+// Findings are returned in rule registration order.
+func Check(set *usb.DescriptorSet) []Finding {
+	res := make([]Finding, 0, 8)
+	if set == nil || set.Device == nil {
+		return append(res, Finding{
+			Rule:     "device.missing",
+			Severity: SeverityError,
+			Section:  "9.6.1",
+			Path:     "device",
+			Message:  "no device descriptor",
+		})
+	}
+	for _, rule := range rules {
+		rule := rule
+		rule.Check(set, func(path string, format string, args ...any) {
+			res = append(res, Finding{
+				Rule:     rule.ID,
+				Severity: rule.Severity,
+				Section:  rule.Section,
+				Path:     path,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+	}
+	return res
+}
+
+// Max returns the highest severity among the findings, or SeverityInfo if there are none.
+func Max(findings []Finding) Severity {
+	res := SeverityInfo
+	for _, f := range findings {
+		if f.Severity > res {
+			res = f.Severity
+		}
+	}
+	return res
+}
+
+func configPath(cfg *usb.Configuration) string {
+	return fmt.Sprintf("config %d", cfg.BConfigurationValue)
+}
+
+func altPath(cfg *usb.Configuration, alt *usb.AltSetting) string {
+	return fmt.Sprintf("%s/interface %d.%d", configPath(cfg), alt.BInterfaceNumber, alt.BAlternateSetting)
+}
+
+func endpointPath(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint) string {
+	return fmt.Sprintf("%s/endpoint 0x%.2X", altPath(cfg, alt), ep.BEndpointAddress)
+}
+
+// forEachAlt calls fn for every alternate setting of every configuration.
+func forEachAlt(set *usb.DescriptorSet, fn func(cfg *usb.Configuration, alt *usb.AltSetting)) {
+	for _, cfg := range set.Configurations {
+		for _, iface := range cfg.Interfaces {
+			for _, alt := range iface.AltSettings {
+				fn(cfg, alt)
+			}
+		}
+	}
+}
+
+// forEachEndpoint calls fn for every endpoint of every alternate setting.
+func forEachEndpoint(set *usb.DescriptorSet, fn func(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint)) {
+	forEachAlt(set, func(cfg *usb.Configuration, alt *usb.AltSetting) {
+		for _, ep := range alt.Endpoints {
+			fn(cfg, alt, ep)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package lint
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"testing"
+)
+
+func hasFinding(findings []Finding, rule string) bool {
+	for _, f := range findings {
+		if f.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheck(t *testing.T) {
+	cfg := usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+		Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}).
+		Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: uint8(usb.TransferTypeBulk), WMaxPacketSize: 512}).
+		Configuration()
+	data, err := cfg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = usb.ParseConfiguration(data); err != nil {
+		t.Fatal(err)
+	}
+	set := &usb.DescriptorSet{
+		Device: &usb.DeviceDescriptor{
+			BcdUSB:             0x0200,
+			BMaxPacketSize0:    64,
+			BNumConfigurations: 1,
+		},
+		Configurations: []*usb.Configuration{cfg},
+	}
+	if findings := Check(set); len(findings) != 0 {
+		t.Fatalf("unexpected findings %v", findings)
+	}
+
+	set.Device.BcdUSB = 0x0300
+	set.Device.BDeviceSubClass = 1
+	findings := Check(set)
+	for _, rule := range []string{"device.subclass", "device.maxpacket0", "endpoint.max-packet-size", "endpoint.ss-companion", "bos.required"} {
+		if !hasFinding(findings, rule) {
+			t.Errorf("expected finding for %s in %v", rule, findings)
+		}
+	}
+	if Max(findings) != SeverityError {
+		t.Errorf("expected error severity")
+	}
+}
+
+func TestTotalLength(t *testing.T) {
+	// An audio class 1.0 configuration with a 9 byte endpoint, wTotalLength 27.
+	data := []byte{
+		0x09, 0x02, 0x1B, 0x00, 0x01, 0x01, 0x00, 0x80, 0x32,
+		0x09, 0x04, 0x00, 0x01, 0x01, 0x01, 0x02, 0x00, 0x00,
+		0x09, 0x05, 0x01, 0x09, 0xC0, 0x00, 0x01, 0x00, 0x00,
+	}
+	cfg, err := usb.ParseConfiguration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &usb.DescriptorSet{
+		Device:         &usb.DeviceDescriptor{BcdUSB: 0x0110, BMaxPacketSize0: 64, BNumConfigurations: 1},
+		Configurations: []*usb.Configuration{cfg},
+	}
+	if findings := Check(set); hasFinding(findings, "config.total-length") {
+		t.Fatalf("unexpected findings %v", findings)
+	}
+	cfg.WTotalLength = 25
+	if findings := Check(set); !hasFinding(findings, "config.total-length") {
+		t.Fatalf("expected a config.total-length finding in %v", findings)
+	}
+}
+
+func TestBOSTotalLength(t *testing.T) {
+	// A USB 2.0 Extension capability padded to 9 bytes, wTotalLength 14.
+	data := []byte{
+		0x05, 0x0F, 0x0E, 0x00, 0x01,
+		0x09, 0x10, 0x02, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	bos, err := usb.ParseBOS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &usb.DescriptorSet{
+		Device: &usb.DeviceDescriptor{BcdUSB: 0x0201, BMaxPacketSize0: 64},
+		BOS:    bos,
+	}
+	if findings := Check(set); hasFinding(findings, "bos.header") {
+		t.Fatalf("unexpected findings %v", findings)
+	}
+	bos.WTotalLength = 12
+	if findings := Check(set); !hasFinding(findings, "bos.header") {
+		t.Fatalf("expected a bos.header finding in %v", findings)
+	}
+}
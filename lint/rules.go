@@ -0,0 +1,600 @@
+package lint
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"reflect"
+)
+
+func init() {
+	for _, rule := range standardRules {
+		Register(rule)
+	}
+}
+
+var standardRules = []*Rule{
+	{
+		ID:          "device.subclass",
+		Section:     "9.6.1",
+		Severity:    SeverityError,
+		Description: "bDeviceSubClass and bDeviceProtocol shall be zero when bDeviceClass is zero",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			dev := set.Device
+			if dev.BDeviceClass == 0 && (dev.BDeviceSubClass != 0 || dev.BDeviceProtocol != 0) {
+				report("device", "bDeviceClass is 0 but bDeviceSubClass/bDeviceProtocol is %.2X/%.2X",
+					uint8(dev.BDeviceSubClass), dev.BDeviceProtocol)
+			}
+		},
+	},
+	{
+		ID:          "device.class-usage",
+		Section:     "Defined Class Codes",
+		Severity:    SeverityWarning,
+		Description: "bDeviceClass/bDeviceSubClass/bDeviceProtocol shall be a class code defined for use in device descriptors",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			triple := set.Device.ClassTriple()
+			if !triple.ValidForDevice() {
+				report("device", "class %v is not defined for use in device descriptors", triple)
+			}
+		},
+	},
+	{
+		ID:          "device.bcd",
+		Section:     "9.6.1",
+		Severity:    SeverityError,
+		Description: "bcdUSB and bcdDevice shall be binary-coded decimal",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if !validBCD(set.Device.BcdUSB) {
+				report("device", "bcdUSB %.4X is not BCD", set.Device.BcdUSB)
+			}
+			if !validBCD(set.Device.BcdDevice) {
+				report("device", "bcdDevice %.4X is not BCD", set.Device.BcdDevice)
+			}
+		},
+	},
+	{
+		ID:          "device.maxpacket0",
+		Section:     "9.6.1",
+		Severity:    SeverityError,
+		Description: "bMaxPacketSize0 shall be 09H for Enhanced SuperSpeed devices and 8, 16, 32 or 64 otherwise",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			size := set.Device.BMaxPacketSize0
+			if set.EnhancedSuperSpeed() {
+				if size != 9 {
+					report("device", "bMaxPacketSize0 is %d, Enhanced SuperSpeed devices shall use 9 (512 bytes)", size)
+				}
+				return
+			}
+			switch size {
+			case 8, 16, 32, 64:
+			default:
+				report("device", "bMaxPacketSize0 is %d, expected 8, 16, 32 or 64", size)
+			}
+		},
+	},
+	{
+		ID:          "device.num-configurations",
+		Section:     "9.6.1",
+		Severity:    SeverityError,
+		Description: "bNumConfigurations shall match the number of configurations and be at least one",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			num := int(set.Device.BNumConfigurations)
+			if num == 0 {
+				report("device", "bNumConfigurations is 0, all devices shall provide at least one configuration")
+			}
+			if set.Configurations != nil && len(set.Configurations) != num {
+				report("device", "bNumConfigurations is %d but %d configurations were read", num, len(set.Configurations))
+			}
+		},
+	},
+	{
+		ID:          "config.total-length",
+		Section:     "9.6.3",
+		Severity:    SeverityError,
+		Description: "wTotalLength shall be the combined length of all descriptors of the configuration",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				total := 0
+				for _, desc := range cfg.Descriptors() {
+					length, err := descriptorLength(desc)
+					if err != nil {
+						report(configPath(cfg), "configuration can not be encoded: %v", err)
+						total = -1
+						break
+					}
+					total += length
+				}
+				if total >= 0 && int(cfg.WTotalLength) != total {
+					report(configPath(cfg), "wTotalLength is %d, descriptors add up to %d", cfg.WTotalLength, total)
+				}
+			}
+		},
+	},
+	{
+		ID:          "config.num-interfaces",
+		Section:     "9.6.3",
+		Severity:    SeverityError,
+		Description: "bNumInterfaces shall match the number of interfaces",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				if int(cfg.BNumInterfaces) != len(cfg.Interfaces) {
+					report(configPath(cfg), "bNumInterfaces is %d, configuration has %d interfaces",
+						cfg.BNumInterfaces, len(cfg.Interfaces))
+				}
+			}
+		},
+	},
+	{
+		ID:          "config.value",
+		Section:     "9.6.3",
+		Severity:    SeverityError,
+		Description: "bConfigurationValue shall be non-zero and unique",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			seen := make(map[uint8]bool)
+			for _, cfg := range set.Configurations {
+				if cfg.BConfigurationValue == 0 {
+					report(configPath(cfg), "bConfigurationValue 0 selects the address state and can not be used")
+				}
+				if seen[cfg.BConfigurationValue] {
+					report(configPath(cfg), "bConfigurationValue %d is used by more than one configuration", cfg.BConfigurationValue)
+				}
+				seen[cfg.BConfigurationValue] = true
+			}
+		},
+	},
+	{
+		ID:          "config.attributes",
+		Section:     "9.6.3",
+		Severity:    SeverityWarning,
+		Description: "bmAttributes bit 7 shall be one and bits 4:0 shall be zero",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				if cfg.BmAttributes&0x80 == 0 {
+					report(configPath(cfg), "bmAttributes %.2X has reserved bit 7 cleared", cfg.BmAttributes)
+				}
+				if cfg.BmAttributes&0x1F != 0 {
+					report(configPath(cfg), "bmAttributes %.2X has reserved bits 4:0 set", cfg.BmAttributes)
+				}
+			}
+		},
+	},
+	{
+		ID:          "interface.numbers",
+		Section:     "9.6.5",
+		Severity:    SeverityError,
+		Description: "bInterfaceNumber shall be a zero-based index of the interfaces in the configuration",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				for number := range cfg.Interfaces {
+					if cfg.Interface(uint8(number)) == nil {
+						report(configPath(cfg), "interface %d is missing, interface numbers shall be contiguous from zero", number)
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "interface.alt-settings",
+		Section:     "9.6.5",
+		Severity:    SeverityError,
+		Description: "every interface shall have alternate setting zero and alternate settings shall be unique",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				for _, iface := range cfg.Interfaces {
+					path := fmt.Sprintf("%s/interface %d", configPath(cfg), iface.Number)
+					if iface.AltSetting(0) == nil {
+						report(path, "interface has no default alternate setting 0")
+					}
+					seen := make(map[uint8]bool)
+					for _, alt := range iface.AltSettings {
+						if seen[alt.BAlternateSetting] {
+							report(path, "alternate setting %d is defined more than once", alt.BAlternateSetting)
+						}
+						seen[alt.BAlternateSetting] = true
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "interface.num-endpoints",
+		Section:     "9.6.5",
+		Severity:    SeverityError,
+		Description: "bNumEndpoints shall match the number of endpoint descriptors",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			forEachAlt(set, func(cfg *usb.Configuration, alt *usb.AltSetting) {
+				if int(alt.BNumEndpoints) != len(alt.Endpoints) {
+					report(altPath(cfg, alt), "bNumEndpoints is %d, interface has %d endpoints",
+						alt.BNumEndpoints, len(alt.Endpoints))
+				}
+			})
+		},
+	},
+	{
+		ID:          "interface.subclass",
+		Section:     "9.6.5",
+		Severity:    SeverityError,
+		Description: "bInterfaceClass zero is reserved and bInterfaceSubClass shall be zero when bInterfaceClass is zero",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			forEachAlt(set, func(cfg *usb.Configuration, alt *usb.AltSetting) {
+				if alt.BInterfaceClass != 0 {
+					return
+				}
+				report(altPath(cfg, alt), "bInterfaceClass 0 is reserved for future standardization")
+				if alt.BInterfaceSubClass != 0 {
+					report(altPath(cfg, alt), "bInterfaceClass is 0 but bInterfaceSubClass is %.2X", uint8(alt.BInterfaceSubClass))
+				}
+			})
+		},
+	},
+	{
+		ID:          "interface.class-usage",
+		Section:     "Defined Class Codes",
+		Severity:    SeverityWarning,
+		Description: "interface and function class codes shall be defined for use in interface descriptors",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			forEachAlt(set, func(cfg *usb.Configuration, alt *usb.AltSetting) {
+				// Class zero is reported by interface.subclass
+				triple := alt.ClassTriple()
+				if triple.Class != 0 && !triple.ValidForInterface() {
+					report(altPath(cfg, alt), "class %v is not defined for use in interface descriptors", triple)
+				}
+			})
+			for _, cfg := range set.Configurations {
+				for _, iface := range cfg.Interfaces {
+					iad := iface.Association
+					if iad == nil || iad.BFirstInterface != iface.Number {
+						continue
+					}
+					triple := iad.ClassTriple()
+					if triple.Class != 0 && !triple.ValidForInterface() {
+						report(configPath(cfg), "function class %v of interface association %d is not defined for use in interface descriptors",
+							triple, iad.BFirstInterface)
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "endpoint.address",
+		Section:     "9.6.6",
+		Severity:    SeverityError,
+		Description: "bEndpointAddress shall not use endpoint zero or reserved bits, and shall be unique within an interface",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			forEachAlt(set, func(cfg *usb.Configuration, alt *usb.AltSetting) {
+				seen := make(map[uint8]bool)
+				for _, ep := range alt.Endpoints {
+					path := endpointPath(cfg, alt, ep)
+					if ep.BEndpointAddress&0x0F == 0 {
+						report(path, "endpoint zero shall not have an endpoint descriptor")
+					}
+					if ep.BEndpointAddress&0x70 != 0 {
+						report(path, "reserved address bits 6:4 are set")
+					}
+					if seen[ep.BEndpointAddress] {
+						report(path, "endpoint address is used more than once in the interface")
+					}
+					seen[ep.BEndpointAddress] = true
+				}
+			})
+		},
+	},
+	{
+		ID:          "endpoint.max-packet-size",
+		Section:     "9.6.6",
+		Severity:    SeverityError,
+		Description: "wMaxPacketSize shall be valid for the transfer type",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			superSpeed := set.EnhancedSuperSpeed()
+			forEachEndpoint(set, func(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint) {
+				path := endpointPath(cfg, alt, ep)
+				size := ep.WMaxPacketSize & 0x07FF
+				mult := (ep.WMaxPacketSize >> 11) & 0x03
+				switch ep.TransferType() {
+				case usb.TransferTypeBulk:
+					if superSpeed && size != 1024 {
+						report(path, "bulk wMaxPacketSize is %d, shall be 1024 at Gen X speed", size)
+					}
+					if !superSpeed && size != 512 && size != 8 && size != 16 && size != 32 && size != 64 {
+						report(path, "bulk wMaxPacketSize is %d, expected 8, 16, 32, 64 or 512", size)
+					}
+				case usb.TransferTypeInterrupt, usb.TransferTypeIsochronous:
+					if size > 1024 {
+						report(path, "wMaxPacketSize is %d, maximum is 1024", size)
+					}
+					if mult == 3 {
+						report(path, "additional transactions per microframe (bits 12:11) is reserved value 3")
+					}
+				}
+				if ep.WMaxPacketSize&0xE000 != 0 {
+					report(path, "reserved wMaxPacketSize bits 15:13 are set")
+				}
+			})
+		},
+	},
+	{
+		ID:          "endpoint.interval",
+		Section:     "9.6.6",
+		Severity:    SeverityError,
+		Description: "bInterval shall be in range for periodic endpoints",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			superSpeed := set.EnhancedSuperSpeed()
+			forEachEndpoint(set, func(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint) {
+				path := endpointPath(cfg, alt, ep)
+				switch ep.TransferType() {
+				case usb.TransferTypeIsochronous:
+					if ep.BInterval < 1 || ep.BInterval > 16 {
+						report(path, "isochronous bInterval is %d, shall be 1 to 16", ep.BInterval)
+					}
+				case usb.TransferTypeInterrupt:
+					if ep.BInterval == 0 {
+						report(path, "interrupt bInterval shall not be zero")
+					}
+					if superSpeed && ep.BInterval > 16 {
+						report(path, "interrupt bInterval is %d, shall be 1 to 16 at Gen X speed", ep.BInterval)
+					}
+				}
+			})
+		},
+	},
+	{
+		ID:          "endpoint.ss-companion",
+		Section:     "9.6.7",
+		Severity:    SeverityError,
+		Description: "every endpoint of an Enhanced SuperSpeed device shall be followed by a SuperSpeed Endpoint Companion descriptor",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			superSpeed := set.EnhancedSuperSpeed()
+			forEachEndpoint(set, func(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint) {
+				path := endpointPath(cfg, alt, ep)
+				if superSpeed && ep.SSCompanion == nil {
+					report(path, "missing SuperSpeed Endpoint Companion descriptor")
+				}
+				if !superSpeed && ep.SSCompanion != nil {
+					report(path, "SuperSpeed Endpoint Companion descriptor on a device with bcdUSB %.4X", set.Device.BcdUSB)
+				}
+			})
+		},
+	},
+	{
+		ID:          "endpoint.ss-companion-fields",
+		Section:     "9.6.7",
+		Severity:    SeverityError,
+		Description: "SuperSpeed Endpoint Companion fields shall be valid for the transfer type",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			forEachEndpoint(set, func(cfg *usb.Configuration, alt *usb.AltSetting, ep *usb.Endpoint) {
+				companion := ep.SSCompanion
+				if companion == nil {
+					return
+				}
+				path := endpointPath(cfg, alt, ep)
+				if companion.BMaxBurst > 15 {
+					report(path, "bMaxBurst is %d, shall be 0 to 15", companion.BMaxBurst)
+				}
+				switch ep.TransferType() {
+				case usb.TransferTypeControl:
+					if companion.BMaxBurst != 0 {
+						report(path, "bMaxBurst shall be 0 for control endpoints")
+					}
+				case usb.TransferTypeBulk:
+					if companion.BmAttributes&0x1F > 16 {
+						report(path, "max streams is %d, shall be 0 to 16", companion.BmAttributes&0x1F)
+					}
+					if companion.WBytesPerInterval != 0 {
+						report(path, "wBytesPerInterval is reserved for bulk endpoints and shall be zero")
+					}
+				case usb.TransferTypeIsochronous:
+					mult := companion.BmAttributes & 0x03
+					if mult > 2 {
+						report(path, "mult is %d, maximum is 2", mult)
+					}
+					if mult != 0 && companion.BMaxBurst == 0 {
+						report(path, "mult shall be zero when bMaxBurst is zero")
+					}
+					if companion.BmAttributes&0x80 != 0 && ep.SSPIsoCompanion == nil {
+						report(path, "SSP ISO Companion bit set but no SuperSpeedPlus Isochronous Endpoint Companion follows")
+					}
+				case usb.TransferTypeInterrupt:
+					if companion.BMaxBurst > 0 && ep.WMaxPacketSize&0x07FF != 1024 {
+						report(path, "wMaxPacketSize shall be 1024 when bMaxBurst is non-zero")
+					}
+				}
+			})
+		},
+	},
+	{
+		ID:          "iad.order",
+		Section:     "9.6.4",
+		Severity:    SeverityError,
+		Description: "an interface association descriptor shall precede the contiguous interfaces it associates",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			for _, cfg := range set.Configurations {
+				seen := make(map[uint8]bool)
+				for _, desc := range cfg.Descriptors() {
+					switch x := desc.(type) {
+					case *usb.InterfaceDescriptor:
+						seen[x.BInterfaceNumber] = true
+					case *usb.InterfaceAssociationDescriptor:
+						path := fmt.Sprintf("%s/association %d", configPath(cfg), x.BFirstInterface)
+						if x.BInterfaceCount < 2 {
+							report(path, "association covers %d interface(s), an association shall include two or more", x.BInterfaceCount)
+						}
+						if x.BFunctionClass == 0 {
+							report(path, "bFunctionClass zero is not allowed")
+						}
+						for number := x.BFirstInterface; number < x.BFirstInterface+x.BInterfaceCount; number++ {
+							if seen[number] {
+								report(path, "interface %d precedes the association descriptor", number)
+							}
+							if cfg.Interface(number) == nil {
+								report(path, "associated interface %d does not exist", number)
+							}
+						}
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "iad.device-class",
+		Section:     "IAD ECN",
+		Severity:    SeverityWarning,
+		Description: "devices using interface association descriptors should use the Multi-interface Function class codes EF/02/01",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			dev := set.Device
+			if dev.ClassTriple() == usb.ClassTripleIAD {
+				return
+			}
+			for _, cfg := range set.Configurations {
+				for _, desc := range cfg.Descriptors() {
+					if _, ok := desc.(*usb.InterfaceAssociationDescriptor); ok {
+						report("device", "configuration %d uses interface associations but device class is %v",
+							cfg.BConfigurationValue, dev.ClassTriple())
+						return
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "bos.required",
+		Section:     "9.6.2",
+		Severity:    SeverityError,
+		Description: "devices with bcdUSB 0201H or later shall provide a BOS descriptor",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if set.Device.BcdUSB >= 0x0201 && set.BOS == nil {
+				report("bos", "bcdUSB is %.4X but no BOS descriptor was read", set.Device.BcdUSB)
+			}
+		},
+	},
+	{
+		ID:          "bos.header",
+		Section:     "9.6.2",
+		Severity:    SeverityError,
+		Description: "wTotalLength and bNumDeviceCaps shall describe the capabilities following the BOS descriptor",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if set.BOS == nil {
+				return
+			}
+			if int(set.BOS.BNumDeviceCaps) != len(set.BOS.Capabilities) {
+				report("bos", "bNumDeviceCaps is %d, BOS has %d capabilities", set.BOS.BNumDeviceCaps, len(set.BOS.Capabilities))
+			}
+			total := 0
+			for _, desc := range append([]usb.Descriptor{set.BOS.BOSDescriptor}, set.BOS.Capabilities...) {
+				length, err := descriptorLength(desc)
+				if err != nil {
+					report("bos", "BOS can not be encoded: %v", err)
+					return
+				}
+				total += length
+			}
+			if int(set.BOS.WTotalLength) != total {
+				report("bos", "wTotalLength is %d, descriptors add up to %d", set.BOS.WTotalLength, total)
+			}
+		},
+	},
+	{
+		ID:          "bos.superspeed-capabilities",
+		Section:     "9.6.2.1",
+		Severity:    SeverityError,
+		Description: "Enhanced SuperSpeed devices shall include the USB 2.0 Extension and SuperSpeed USB device capabilities",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if !set.EnhancedSuperSpeed() || set.BOS == nil {
+				return
+			}
+			var ext *usb.CapUSB20ExtensionDescriptor
+			var ss *usb.CapSuperSpeedUSBDescriptor
+			for _, capability := range set.BOS.Capabilities {
+				switch x := capability.(type) {
+				case *usb.CapUSB20ExtensionDescriptor:
+					ext = x
+				case *usb.CapSuperSpeedUSBDescriptor:
+					ss = x
+				}
+			}
+			if ext == nil {
+				report("bos", "missing USB 2.0 Extension capability")
+			} else if ext.BMAttributes&0x02 == 0 {
+				report("bos/usb20-extension", "LPM bit is not set, Enhanced SuperSpeed devices shall support LPM")
+			}
+			if ss == nil {
+				report("bos", "missing SuperSpeed USB capability")
+			}
+		},
+	},
+	{
+		ID:          "bos.superspeed-latency",
+		Section:     "9.6.2.2",
+		Severity:    SeverityError,
+		Description: "U1 and U2 device exit latencies shall be in range",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if set.BOS == nil {
+				return
+			}
+			for _, capability := range set.BOS.Capabilities {
+				if ss, ok := capability.(*usb.CapSuperSpeedUSBDescriptor); ok {
+					if ss.BU1DevExitLat > 0x0A {
+						report("bos/superspeed", "bU1DevExitLat is %d, maximum is 10", ss.BU1DevExitLat)
+					}
+					if ss.WU2DevExitLat > 0x07FF {
+						report("bos/superspeed", "wU2DevExitLat is %d, maximum is 2047", ss.WU2DevExitLat)
+					}
+					if ss.WSpeedsSupported&(1<<ss.BFunctionalitySupport) == 0 {
+						report("bos/superspeed", "bFunctionalitySupport %d is not in wSpeedsSupported %.4X",
+							ss.BFunctionalitySupport, ss.WSpeedsSupported)
+					}
+				}
+			}
+		},
+	},
+	{
+		ID:          "qualifier.speed",
+		Section:     "9.6.2",
+		Severity:    SeverityError,
+		Description: "devices operating at Gen X speed shall not support the device qualifier and other speed configuration descriptors",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if set.EnhancedSuperSpeed() && (set.Qualifier != nil || len(set.OtherSpeedConfigurations) > 0) {
+				report("qualifier", "Enhanced SuperSpeed device provides a device qualifier")
+			}
+		},
+	},
+	{
+		ID:          "qualifier.num-configurations",
+		Section:     "9.6.2",
+		Severity:    SeverityError,
+		Description: "bNumConfigurations of the device qualifier shall match the number of other speed configurations",
+		Check: func(set *usb.DescriptorSet, report Reporter) {
+			if set.Qualifier == nil {
+				return
+			}
+			if set.Qualifier.BReserved != 0 {
+				report("qualifier", "bReserved is %.2X, shall be zero", set.Qualifier.BReserved)
+			}
+			if set.OtherSpeedConfigurations != nil && int(set.Qualifier.BNumConfigurations) != len(set.OtherSpeedConfigurations) {
+				report("qualifier", "bNumConfigurations is %d but %d other speed configurations were read",
+					set.Qualifier.BNumConfigurations, len(set.OtherSpeedConfigurations))
+			}
+		},
+	},
+}
+
+func validBCD(value uint16) bool {
+	for ; value != 0; value >>= 4 {
+		if value&0x0F > 9 {
+			return false
+		}
+	}
+	return true
+}
+
+// descriptorLength returns bLength of a parsed descriptor. Descriptors built in memory have no
+// bLength yet, their encoded length is used instead.
+func descriptorLength(desc usb.Descriptor) (int, error) {
+	v := reflect.Indirect(reflect.ValueOf(desc))
+	if v.Kind() == reflect.Struct && v.NumField() > 0 {
+		if header, ok := v.Field(0).Interface().(usb.DescriptorHeader); ok && header.Length != 0 {
+			return int(header.Length), nil
+		}
+	}
+	data, err := usb.MarshalDescriptor(desc)
+	return len(data), err
+}
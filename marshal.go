@@ -0,0 +1,177 @@
+package usb
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// MarshalDescriptor returns the wire representation of a descriptor.
+//
+// Descriptors implementing encoding.BinaryMarshaler are encoded with their own MarshalBinary,
+// any other descriptor struct is encoded field by field in declaration order, the same way it is parsed.
+//
+// BLength is always computed from the encoded data.
+// If DescriptorType is zero it is looked up from the registered descriptor types,
+// this allows fixtures to be written without filling in the header.
+func MarshalDescriptor(d Descriptor) ([]byte, error) {
+	if m, ok := d.(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	return marshalDescriptor(d)
+}
+
+func marshalDescriptor(d Descriptor) ([]byte, error) {
+	val := reflect.ValueOf(d)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can not marshal descriptor of type %T", d)
+	}
+	elem := val.Elem()
+	buff := &bytes.Buffer{}
+	buff.Write([]byte{0, byte(descriptorTypeOf(d))})
+	for elemIndex := 1; elemIndex < elem.NumField(); elemIndex++ {
+		field := elem.Field(elemIndex)
+		if field.Kind() == reflect.Slice && field.Len() == 0 {
+			continue
+		}
+		if err := binary.Write(buff, binary.LittleEndian, field.Interface()); err != nil {
+			return nil, fmt.Errorf("%T.%s: %w", d, elem.Type().Field(elemIndex).Name, err)
+		}
+	}
+	return finishDescriptor(buff.Bytes())
+}
+
+// finishDescriptor fills in BLength of an encoded descriptor.
+func finishDescriptor(data []byte) ([]byte, error) {
+	if len(data) > 0xFF {
+		return nil, fmt.Errorf("descriptor %v too long: %d bytes", DescriptorType(data[1]), len(data))
+	}
+	data[0] = uint8(len(data))
+	return data, nil
+}
+
+// descriptorTypeOf returns the descriptor type from the header, or the registered
+// type of the descriptor if the header type is not set.
+func descriptorTypeOf(d Descriptor) DescriptorType {
+	if typ := d.Type(); typ != 0 {
+		return typ
+	}
+	descType := reflect.TypeOf(d).Elem()
+	for typ, registered := range descriptorMap {
+		if registered == descType {
+			return typ
+		}
+	}
+	if _, isCapability := capabilityOf(d); isCapability {
+		return DescriptorTypeDeviceCapability
+	}
+	return 0
+}
+
+// capabilityOf returns the registered capability type of a capability descriptor struct.
+func capabilityOf(d Descriptor) (Capability, bool) {
+	descType := reflect.TypeOf(d).Elem()
+	for capability, registered := range capabilityMap {
+		if registered == descType {
+			return capability, true
+		}
+	}
+	return 0, false
+}
+
+// marshalCapability encodes a device capability descriptor, filling in
+// BDevCapabilityType if it is not set.
+func marshalCapability(d Descriptor, capType Capability) ([]byte, error) {
+	data, err := marshalDescriptor(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 2 && capType == 0 {
+		if registered, ok := capabilityOf(d); ok {
+			data[2] = uint8(registered)
+		}
+	}
+	return data, nil
+}
+
+func (d *UnknownDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *DeviceDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *ConfigurationDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *InterfaceDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *InterfaceAssociationDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *EndpointDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *StringDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *SSEndpointCompanionDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *SSPIsochronousEndpointCompanionDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *DeviceQualifierDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *OtherSpeedConfigurationDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *BOSDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalDescriptor(d)
+}
+
+func (d *DeviceCapabilityDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapUSB20ExtensionDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapSuperSpeedUSBDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapContainerIDDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapPlatformDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapSuperSpeedPlusUSBDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapPrecisionTimeDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
+
+func (d *CapConfigurationSummaryDescriptor) MarshalBinary() ([]byte, error) {
+	return marshalCapability(d, d.BDevCapabilityType)
+}
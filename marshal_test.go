@@ -0,0 +1,149 @@
+package usb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalEndpoint(t *testing.T) {
+	ep := &EndpointDescriptor{
+		BEndpointAddress: 0x81,
+		BmAttributes:     uint8(TransferTypeInterrupt),
+		WMaxPacketSize:   64,
+		BInterval:        10,
+	}
+	data, err := ep.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{0x07, 0x05, 0x81, 0x03, 0x40, 0x00, 0x0A}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("got % X, expected % X", data, expected)
+	}
+}
+
+func TestConfigurationRoundTrip(t *testing.T) {
+	data, err := NewConfigBuilder(&ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+		Interface(&InterfaceDescriptor{BInterfaceClass: ClassCodeInterfaceHID},
+			&UnknownDescriptor{DescriptorHeader: DescriptorHeader{DescriptorType: 0x21}, Data: []byte{0x11, 0x01, 0, 1, 0x22, 0x3F, 0}}).
+		Endpoint(&EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 10}).
+		Interface(&InterfaceDescriptor{BInterfaceNumber: 1, BInterfaceClass: ClassCodeVendorSpecific}).
+		Endpoint(&EndpointDescriptor{BEndpointAddress: 0x02, BmAttributes: 2, WMaxPacketSize: 1024},
+			&SSEndpointCompanionDescriptor{BMaxBurst: 15}).
+		MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(data[2])|int(data[3])<<8 != len(data) {
+		t.Fatalf("wTotalLength %d, expected %d", int(data[2])|int(data[3])<<8, len(data))
+	}
+	cfg, err := ParseConfiguration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BNumInterfaces != 2 || len(cfg.Interfaces) != 2 {
+		t.Fatalf("expected 2 interfaces, got %d/%d", cfg.BNumInterfaces, len(cfg.Interfaces))
+	}
+	hidAlt := cfg.Interfaces[0].AltSettings[0]
+	if hidAlt.BNumEndpoints != 1 || len(hidAlt.Extra) != 1 {
+		t.Fatalf("unexpected HID interface %+v", hidAlt)
+	}
+	if cfg.Interfaces[1].AltSettings[0].Endpoints[0].SSCompanion == nil {
+		t.Fatal("missing endpoint companion")
+	}
+	again, err := cfg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("round trip mismatch\n% X\n% X", data, again)
+	}
+}
+
+func TestTrailingBytesRoundTrip(t *testing.T) {
+	// An audio class 1.0 streaming interface with a 9 byte isochronous endpoint carrying bRefresh and bSynchAddress.
+	data := []byte{
+		0x09, 0x02, 0x1B, 0x00, 0x01, 0x01, 0x00, 0x80, 0x32,
+		0x09, 0x04, 0x00, 0x01, 0x01, 0x01, 0x02, 0x00, 0x00,
+		0x09, 0x05, 0x01, 0x09, 0xC0, 0x00, 0x01, 0x00, 0x00,
+	}
+	cfg, err := ParseConfiguration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep := cfg.Interfaces[0].AltSettings[0].Endpoints[0]
+	// The header stays comparable, the trailing bytes are kept by the endpoint.
+	if ep.DescriptorHeader != (DescriptorHeader{Length: 9, DescriptorType: DescriptorTypeEndpoint}) || !bytes.Equal(ep.Trailing, []byte{0x00, 0x00}) {
+		t.Fatalf("unexpected endpoint %+v", ep.EndpointDescriptor)
+	}
+	encoded, err := ep.MarshalBinary()
+	if err != nil || !bytes.Equal(encoded, data[18:]) {
+		t.Fatalf("endpoint round trip mismatch: % X, %v", encoded, err)
+	}
+	again, err := cfg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("round trip mismatch\n% X\n% X", data, again)
+	}
+}
+
+func TestBOSRoundTrip(t *testing.T) {
+	bos := &BOS{
+		Capabilities: []Descriptor{
+			&CapUSB20ExtensionDescriptor{BMAttributes: 0x02},
+			&CapSuperSpeedUSBDescriptor{BMAttributes: 0, WSpeedsSupported: 0x0E, BFunctionalitySupport: 1, BU1DevExitLat: 10, WU2DevExitLat: 0x20},
+			&CapPlatformDescriptor{PlatformCapabilityUUID: [16]byte{1, 2, 3}, CapabilityData: []byte{0xAA, 0xBB}},
+		},
+	}
+	data, err := bos.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseBOS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.BNumDeviceCaps != 3 || int(parsed.WTotalLength) != len(data) {
+		t.Fatalf("unexpected BOS header %+v", parsed.BOSDescriptor)
+	}
+	ss, ok := parsed.Capabilities[1].(*CapSuperSpeedUSBDescriptor)
+	if !ok || ss.BDevCapabilityType != CapSuperSpeedUSB || ss.WU2DevExitLat != 0x20 {
+		t.Fatalf("unexpected capability %#v", parsed.Capabilities[1])
+	}
+	platform, ok := parsed.Capabilities[2].(*CapPlatformDescriptor)
+	if !ok || !bytes.Equal(platform.CapabilityData, []byte{0xAA, 0xBB}) {
+		t.Fatalf("unexpected capability %#v", parsed.Capabilities[2])
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	data, err := NewConfigBuilder(&ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80}).
+		Association(&InterfaceAssociationDescriptor{BFirstInterface: 0, BInterfaceCount: 2, BFunctionClass: ClassCodeCDCControl, BFunctionSubClass: 2}).
+		Interface(&InterfaceDescriptor{BInterfaceNumber: 0, BInterfaceClass: ClassCodeCDCControl, BInterfaceSubClass: 2}).
+		Endpoint(&EndpointDescriptor{BEndpointAddress: 0x83, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 16}).
+		Interface(&InterfaceDescriptor{BInterfaceNumber: 1, BInterfaceClass: ClassCodeInterfaceCDCData}).
+		Interface(&InterfaceDescriptor{BInterfaceNumber: 2, BInterfaceClass: ClassCodeInterfaceHID}).
+		MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[9+1] != uint8(DescriptorTypeInterfaceAssociation) {
+		t.Fatalf("expected association after configuration descriptor, got % X", data)
+	}
+	cfg, err := ParseConfiguration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	functions := cfg.Functions()
+	if len(functions) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(functions))
+	}
+	if functions[0].Association == nil || len(functions[0].Interfaces) != 2 || functions[0].Class != ClassCodeCDCControl {
+		t.Fatalf("unexpected CDC function %+v", functions[0])
+	}
+	if functions[1].Association != nil || functions[1].Class != ClassCodeInterfaceHID || !functions[1].Contains(2) {
+		t.Fatalf("unexpected HID function %+v", functions[1])
+	}
+}
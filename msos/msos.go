@@ -0,0 +1,186 @@
+// Package msos retrieves and parses Microsoft OS descriptors.
+//
+// Microsoft OS 2.0 descriptors are announced by a platform capability in the BOS and fetched with a
+// vendor request using the vendor code from the capability.
+// Microsoft OS 1.0 descriptors are announced by the string descriptor at index 0xEE, the extended
+// compat ID and extended properties descriptors are fetched with a vendor request using the
+// vendor code from that string.
+//
+// Documentation: https://learn.microsoft.com/en-us/windows-hardware/drivers/usbcon/microsoft-defined-usb-descriptors
+package msos
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"strings"
+	"unicode/utf16"
+)
+
+// Registry property data types.
+type PropertyDataType uint32
+
+const (
+	RegSZ                = PropertyDataType(1)
+	RegExpandSZ          = PropertyDataType(2)
+	RegBinary            = PropertyDataType(3)
+	RegDWordLittleEndian = PropertyDataType(4)
+	RegDWordBigEndian    = PropertyDataType(5)
+	RegLink              = PropertyDataType(6)
+	RegMultiSZ           = PropertyDataType(7)
+)
+
+func (t PropertyDataType) String() string {
+	switch t {
+	case RegSZ:
+		return "REG_SZ"
+	case RegExpandSZ:
+		return "REG_EXPAND_SZ"
+	case RegBinary:
+		return "REG_BINARY"
+	case RegDWordLittleEndian:
+		return "REG_DWORD_LITTLE_ENDIAN"
+	case RegDWordBigEndian:
+		return "REG_DWORD_BIG_ENDIAN"
+	case RegLink:
+		return "REG_LINK"
+	case RegMultiSZ:
+		return "REG_MULTI_SZ"
+	}
+	return fmt.Sprintf("PropertyDataType(%d)", uint32(t))
+}
+
+// Well known property names
+const (
+	PropertyDeviceInterfaceGUIDs = "DeviceInterfaceGUIDs"
+	PropertyDeviceInterfaceGUID  = "DeviceInterfaceGUID"
+)
+
+// RegistryProperty is a registry property from a MS OS 2.0 registry property descriptor
+// or a MS OS 1.0 extended properties section.
+type RegistryProperty struct {
+	DataType PropertyDataType
+	Name     string
+	Data     []byte
+}
+
+// Strings returns the value of a REG_SZ, REG_EXPAND_SZ, REG_LINK or REG_MULTI_SZ property.
+// Empty strings and the terminating NULs are removed.
+func (p *RegistryProperty) Strings() []string {
+	res := make([]string, 0, 1)
+	for _, str := range strings.Split(decodeUTF16(p.Data), "\x00") {
+		if str != "" {
+			res = append(res, str)
+		}
+	}
+	return res
+}
+
+// DWord returns the value of a REG_DWORD_LITTLE_ENDIAN or REG_DWORD_BIG_ENDIAN property.
+func (p *RegistryProperty) DWord() (uint32, error) {
+	if len(p.Data) != 4 {
+		return 0, fmt.Errorf("property %s: expected 4 bytes, got %d", p.Name, len(p.Data))
+	}
+	switch p.DataType {
+	case RegDWordLittleEndian:
+		return binary.LittleEndian.Uint32(p.Data), nil
+	case RegDWordBigEndian:
+		return binary.BigEndian.Uint32(p.Data), nil
+	}
+	return 0, fmt.Errorf("property %s is %v", p.Name, p.DataType)
+}
+
+func (p *RegistryProperty) String() string {
+	switch p.DataType {
+	case RegSZ, RegExpandSZ, RegLink, RegMultiSZ:
+		return fmt.Sprintf("%s (%v) = %q", p.Name, p.DataType, p.Strings())
+	case RegDWordLittleEndian, RegDWordBigEndian:
+		if value, err := p.DWord(); err == nil {
+			return fmt.Sprintf("%s (%v) = 0x%.8X", p.Name, p.DataType, value)
+		}
+	}
+	return fmt.Sprintf("%s (%v) = % X", p.Name, p.DataType, p.Data)
+}
+
+// trimID returns a compatible ID with the NUL padding removed.
+func trimID(id [8]byte) string {
+	return strings.TrimRight(string(id[:]), "\x00")
+}
+
+func decodeUTF16(data []byte) string {
+	codes := make([]uint16, len(data)/2)
+	for i := range codes {
+		codes[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(codes))
+}
+
+// vendorRequest issues an IN vendor request of the specified length.
+func vendorRequest(dev *usb.Device, recipient usb.RequestType, vendorCode uint8, value, index uint16, length int) ([]byte, error) {
+	data := make([]byte, length)
+	n, err := dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|recipient, vendorCode, value, index, data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+// Descriptors is everything Read could retrieve from a device.
+type Descriptors struct {
+	// DescriptorSets are the MS OS 2.0 descriptor sets, one per Windows version announced by the platform capability.
+	DescriptorSets []*DescriptorSet
+
+	// OSString is the MS OS 1.0 string descriptor, nil if the device does not support MS OS 1.0 descriptors.
+	OSString *OSStringDescriptor
+
+	// CompatID is the MS OS 1.0 extended compat ID descriptor.
+	CompatID *ExtendedCompatID
+
+	// Properties holds the MS OS 1.0 extended properties by interface number.
+	Properties map[uint8]*ExtendedProperties
+}
+
+// Read retrieves all MS OS 2.0 and 1.0 descriptors supported by an open device.
+// Descriptors the device does not support are left empty, only transfer errors on
+// descriptors the device announced are returned.
+func Read(dev *usb.Device) (*Descriptors, error) {
+	res := &Descriptors{
+		Properties: make(map[uint8]*ExtendedProperties),
+	}
+	if bos, err := dev.GetBOSDescriptor(); err == nil {
+		if platform := FindPlatformCapability(bos); platform != nil {
+			infos, err := ParsePlatformCapability(platform)
+			if err != nil {
+				return nil, err
+			}
+			for _, info := range infos {
+				set, err := GetDescriptorSet(dev, info)
+				if err != nil {
+					return nil, err
+				}
+				res.DescriptorSets = append(res.DescriptorSets, set)
+			}
+		}
+	}
+	osString, err := GetOSStringDescriptor(dev)
+	if err != nil {
+		return res, nil
+	}
+	res.OSString = osString
+	if compat, err := GetExtendedCompatID(dev, osString.VendorCode); err == nil {
+		res.CompatID = compat
+	}
+	if set, err := dev.GetDescriptorSet(); err == nil {
+		for _, cfg := range set.Configurations {
+			for _, iface := range cfg.Interfaces {
+				if _, exist := res.Properties[iface.Number]; exist {
+					continue
+				}
+				if props, err := GetExtendedProperties(dev, osString.VendorCode, iface.Number); err == nil {
+					res.Properties[iface.Number] = props
+				}
+			}
+		}
+	}
+	return res, nil
+}
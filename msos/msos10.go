@@ -0,0 +1,212 @@
+package msos
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+)
+
+// OSStringIndex is the string descriptor index of the MS OS 1.0 string descriptor.
+const OSStringIndex = 0xEE
+
+// OSStringSignature is the signature of the MS OS 1.0 string descriptor.
+const OSStringSignature = "MSFT100"
+
+// wIndex values of the MS OS 1.0 vendor requests
+const (
+	ExtendedCompatIDIndex   = 0x0004
+	ExtendedPropertiesIndex = 0x0005
+)
+
+const (
+	compatIDHeaderLength     = 16
+	compatIDFunctionLength   = 24
+	propertiesHeaderLength   = 10
+	propertiesSectionMinimum = 14
+)
+
+// OSStringDescriptor is the MS OS 1.0 string descriptor.
+type OSStringDescriptor struct {
+	Signature  string
+	VendorCode uint8
+	// Flags bit 1 indicates support for the ContainerID descriptor.
+	Flags uint8
+}
+
+// CompatIDFunction is one function section of the extended compat ID descriptor.
+type CompatIDFunction struct {
+	FirstInterfaceNumber uint8
+	CompatibleID         string
+	SubCompatibleID      string
+}
+
+// ExtendedCompatID is the MS OS 1.0 extended compat ID descriptor.
+type ExtendedCompatID struct {
+	Version   uint16
+	Functions []CompatIDFunction
+}
+
+// ExtendedProperties is the MS OS 1.0 extended properties descriptor.
+type ExtendedProperties struct {
+	Version    uint16
+	Properties []*RegistryProperty
+}
+
+// GetOSStringDescriptor retrieves the MS OS 1.0 string descriptor from string index 0xEE.
+func GetOSStringDescriptor(dev *usb.Device) (*OSStringDescriptor, error) {
+	data, err := dev.GetDescriptor(usb.DescriptorTypeString, OSStringIndex, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[1] != uint8(usb.DescriptorTypeString) {
+		return nil, fmt.Errorf("invalid MS OS string descriptor % X", data)
+	}
+	return ParseOSStringDescriptor(data[2:])
+}
+
+// ParseOSStringDescriptor parses the body (without header) of the MS OS 1.0 string descriptor.
+func ParseOSStringDescriptor(data []byte) (*OSStringDescriptor, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("MS OS string descriptor too short: %d bytes", len(data))
+	}
+	signature := decodeUTF16(data[:14])
+	if signature != OSStringSignature {
+		return nil, fmt.Errorf("invalid MS OS string signature %q", signature)
+	}
+	return &OSStringDescriptor{
+		Signature:  signature,
+		VendorCode: data[14],
+		Flags:      data[15],
+	}, nil
+}
+
+// GetExtendedCompatID retrieves the extended compat ID descriptor.
+func GetExtendedCompatID(dev *usb.Device, vendorCode uint8) (*ExtendedCompatID, error) {
+	data, err := readExtended(dev, usb.RequestRecipientDevice, vendorCode, 0, ExtendedCompatIDIndex, compatIDHeaderLength)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExtendedCompatID(data)
+}
+
+// GetExtendedProperties retrieves the extended properties descriptor of an interface.
+func GetExtendedProperties(dev *usb.Device, vendorCode uint8, iface uint8) (*ExtendedProperties, error) {
+	data, err := readExtended(dev, usb.RequestRecipientInterface, vendorCode, uint16(iface), ExtendedPropertiesIndex, propertiesHeaderLength)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExtendedProperties(data)
+}
+
+// readExtended reads the header of an extended descriptor to learn dwLength, then the whole descriptor.
+func readExtended(dev *usb.Device, recipient usb.RequestType, vendorCode uint8, value, index uint16, headerLength int) ([]byte, error) {
+	header, err := vendorRequest(dev, recipient, vendorCode, value, index, headerLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 4 {
+		return nil, fmt.Errorf("short extended descriptor header: %d bytes", len(header))
+	}
+	length := binary.LittleEndian.Uint32(header)
+	if length > 0xFFFF {
+		return nil, fmt.Errorf("invalid extended descriptor length %d", length)
+	}
+	if int(length) <= len(header) {
+		return header, nil
+	}
+	return vendorRequest(dev, recipient, vendorCode, value, index, int(length))
+}
+
+// ParseExtendedCompatID parses an extended compat ID descriptor.
+func ParseExtendedCompatID(data []byte) (*ExtendedCompatID, error) {
+	if len(data) < compatIDHeaderLength {
+		return nil, fmt.Errorf("extended compat ID descriptor too short: %d bytes", len(data))
+	}
+	length := int(binary.LittleEndian.Uint32(data))
+	if length > len(data) {
+		return nil, fmt.Errorf("extended compat ID descriptor truncated: dwLength %d, got %d bytes", length, len(data))
+	}
+	if index := binary.LittleEndian.Uint16(data[6:]); index != ExtendedCompatIDIndex {
+		return nil, fmt.Errorf("unexpected wIndex %d in extended compat ID descriptor", index)
+	}
+	count := int(data[8])
+	if compatIDHeaderLength+count*compatIDFunctionLength > length {
+		return nil, fmt.Errorf("extended compat ID descriptor: %d functions don't fit in %d bytes", count, length)
+	}
+	res := &ExtendedCompatID{
+		Version:   binary.LittleEndian.Uint16(data[4:]),
+		Functions: make([]CompatIDFunction, 0, count),
+	}
+	data = data[compatIDHeaderLength:]
+	for i := 0; i < count; i++ {
+		var compatible, subCompatible [8]byte
+		copy(compatible[:], data[2:10])
+		copy(subCompatible[:], data[10:18])
+		res.Functions = append(res.Functions, CompatIDFunction{
+			FirstInterfaceNumber: data[0],
+			CompatibleID:         trimID(compatible),
+			SubCompatibleID:      trimID(subCompatible),
+		})
+		data = data[compatIDFunctionLength:]
+	}
+	return res, nil
+}
+
+// ParseExtendedProperties parses an extended properties descriptor.
+func ParseExtendedProperties(data []byte) (*ExtendedProperties, error) {
+	if len(data) < propertiesHeaderLength {
+		return nil, fmt.Errorf("extended properties descriptor too short: %d bytes", len(data))
+	}
+	length := int(binary.LittleEndian.Uint32(data))
+	if length > len(data) || length < propertiesHeaderLength {
+		return nil, fmt.Errorf("invalid extended properties descriptor length %d, got %d bytes", length, len(data))
+	}
+	if index := binary.LittleEndian.Uint16(data[6:]); index != ExtendedPropertiesIndex {
+		return nil, fmt.Errorf("unexpected wIndex %d in extended properties descriptor", index)
+	}
+	count := int(binary.LittleEndian.Uint16(data[8:]))
+	res := &ExtendedProperties{
+		Version:    binary.LittleEndian.Uint16(data[4:]),
+		Properties: make([]*RegistryProperty, 0, count),
+	}
+	data = data[propertiesHeaderLength:length]
+	for i := 0; i < count; i++ {
+		if len(data) < propertiesSectionMinimum {
+			return nil, fmt.Errorf("extended property %d truncated", i)
+		}
+		size := int(binary.LittleEndian.Uint32(data))
+		if size < propertiesSectionMinimum || size > len(data) {
+			return nil, fmt.Errorf("invalid extended property %d size %d, %d bytes left", i, size, len(data))
+		}
+		section := data[:size]
+		dataType := PropertyDataType(binary.LittleEndian.Uint32(section[4:]))
+		nameLength := int(binary.LittleEndian.Uint16(section[8:]))
+		if 10+nameLength+4 > size {
+			return nil, fmt.Errorf("extended property %d: name length %d exceeds section", i, nameLength)
+		}
+		name := section[10 : 10+nameLength]
+		valueLength := int(binary.LittleEndian.Uint32(section[10+nameLength:]))
+		value := section[10+nameLength+4:]
+		if valueLength > len(value) {
+			return nil, fmt.Errorf("extended property %d: data length %d exceeds section", i, valueLength)
+		}
+		res.Properties = append(res.Properties, &RegistryProperty{
+			DataType: dataType,
+			Name:     trimNUL(decodeUTF16(name)),
+			Data:     value[:valueLength],
+		})
+		data = data[size:]
+	}
+	return res, nil
+}
+
+// DeviceInterfaceGUIDs returns the values of the DeviceInterfaceGUIDs/DeviceInterfaceGUID properties.
+func (p *ExtendedProperties) DeviceInterfaceGUIDs() []string {
+	var res []string
+	for _, prop := range p.Properties {
+		if prop.Name == PropertyDeviceInterfaceGUIDs || prop.Name == PropertyDeviceInterfaceGUID {
+			res = append(res, prop.Strings()...)
+		}
+	}
+	return res
+}
@@ -0,0 +1,453 @@
+package msos
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+)
+
+// PlatformCapabilityUUID identifies the MS OS 2.0 platform capability, {D8DD60DF-4589-4CC7-9CD2-659D9E648A9F}.
+var PlatformCapabilityUUID = [16]byte{
+	0xDF, 0x60, 0xDD, 0xD8, 0x89, 0x45, 0xC7, 0x4C,
+	0x9C, 0xD2, 0x65, 0x9D, 0x9E, 0x64, 0x8A, 0x9F,
+}
+
+// Windows versions used in dwWindowsVersion
+const (
+	WindowsVersion81 = uint32(0x06030000)
+	WindowsVersion10 = uint32(0x0A000000)
+)
+
+// wIndex values of the MS OS 2.0 vendor requests
+const (
+	DescriptorIndex   = 0x07
+	SetAltEnumeration = 0x08
+)
+
+// MS OS 2.0 descriptor types
+type DescriptorType uint16
+
+const (
+	TypeSetHeaderDescriptor        = DescriptorType(0x00)
+	TypeSubsetHeaderConfiguration  = DescriptorType(0x01)
+	TypeSubsetHeaderFunction       = DescriptorType(0x02)
+	TypeFeatureCompatibleID        = DescriptorType(0x03)
+	TypeFeatureRegProperty         = DescriptorType(0x04)
+	TypeFeatureMinResumeTime       = DescriptorType(0x05)
+	TypeFeatureModelID             = DescriptorType(0x06)
+	TypeFeatureCCGPDevice          = DescriptorType(0x07)
+	TypeFeatureVendorRevision      = DescriptorType(0x08)
+	setHeaderLength                = 10
+	subsetHeaderLength             = 8
+	platformDescriptorSetInfoSize  = 8
+	minimumFeatureDescriptorLength = 4
+)
+
+func (t DescriptorType) String() string {
+	switch t {
+	case TypeSetHeaderDescriptor:
+		return "MS_OS_20_SET_HEADER_DESCRIPTOR"
+	case TypeSubsetHeaderConfiguration:
+		return "MS_OS_20_SUBSET_HEADER_CONFIGURATION"
+	case TypeSubsetHeaderFunction:
+		return "MS_OS_20_SUBSET_HEADER_FUNCTION"
+	case TypeFeatureCompatibleID:
+		return "MS_OS_20_FEATURE_COMPATBLE_ID"
+	case TypeFeatureRegProperty:
+		return "MS_OS_20_FEATURE_REG_PROPERTY"
+	case TypeFeatureMinResumeTime:
+		return "MS_OS_20_FEATURE_MIN_RESUME_TIME"
+	case TypeFeatureModelID:
+		return "MS_OS_20_FEATURE_MODEL_ID"
+	case TypeFeatureCCGPDevice:
+		return "MS_OS_20_FEATURE_CCGP_DEVICE"
+	case TypeFeatureVendorRevision:
+		return "MS_OS_20_FEATURE_VENDOR_REVISION"
+	}
+	return fmt.Sprintf("DescriptorType(0x%.4X)", uint16(t))
+}
+
+type (
+	// DescriptorSetInfo is one descriptor set information structure from the platform capability data.
+	DescriptorSetInfo struct {
+		// WindowsVersion is the minimum Windows version the descriptor set applies to.
+		WindowsVersion uint32
+		// TotalLength of the descriptor set, used as wLength of the vendor request.
+		TotalLength uint16
+		// VendorCode is used as bRequest to retrieve the descriptor set.
+		VendorCode uint8
+		// AltEnumCode is non-zero if the device supports alternate enumeration.
+		AltEnumCode uint8
+	}
+
+	// Feature is one of the feature descriptors.
+	Feature interface {
+		FeatureType() DescriptorType
+	}
+
+	// DescriptorSet is a parsed MS OS 2.0 descriptor set.
+	DescriptorSet struct {
+		WindowsVersion uint32
+		// Features applying to the whole device.
+		Features       []Feature
+		Configurations []*ConfigurationSubset
+	}
+
+	// ConfigurationSubset holds the features applying to one configuration.
+	ConfigurationSubset struct {
+		// ConfigurationValue is, despite the name, the zero based index of the configuration.
+		ConfigurationValue uint8
+		Features           []Feature
+		Functions          []*FunctionSubset
+	}
+
+	// FunctionSubset holds the features applying to one function, starting at FirstInterface.
+	FunctionSubset struct {
+		FirstInterface uint8
+		Features       []Feature
+	}
+
+	// CompatibleID is the MS_OS_20_FEATURE_COMPATBLE_ID descriptor, eg. "WINUSB".
+	CompatibleID struct {
+		CompatibleID    string
+		SubCompatibleID string
+	}
+
+	// RegistryPropertyFeature is the MS_OS_20_FEATURE_REG_PROPERTY descriptor.
+	RegistryPropertyFeature struct {
+		RegistryProperty
+	}
+
+	// MinResumeTime is the MS_OS_20_FEATURE_MIN_RESUME_TIME descriptor.
+	MinResumeTime struct {
+		// ResumeRecoveryTime in milliseconds, 0-10.
+		ResumeRecoveryTime uint8
+		// ResumeSignalingTime in milliseconds, 1-20.
+		ResumeSignalingTime uint8
+	}
+
+	// ModelID is the MS_OS_20_FEATURE_MODEL_ID descriptor.
+	ModelID struct {
+		ModelID [16]byte
+	}
+
+	// CCGPDevice is the MS_OS_20_FEATURE_CCGP_DEVICE descriptor.
+	CCGPDevice struct{}
+
+	// VendorRevision is the MS_OS_20_FEATURE_VENDOR_REVISION descriptor.
+	VendorRevision struct {
+		VendorRevision uint16
+	}
+
+	// UnknownFeature is a feature descriptor of an unknown type.
+	UnknownFeature struct {
+		DescriptorType DescriptorType
+		Data           []byte
+	}
+)
+
+func (f *CompatibleID) FeatureType() DescriptorType            { return TypeFeatureCompatibleID }
+func (f *RegistryPropertyFeature) FeatureType() DescriptorType { return TypeFeatureRegProperty }
+func (f *MinResumeTime) FeatureType() DescriptorType           { return TypeFeatureMinResumeTime }
+func (f *ModelID) FeatureType() DescriptorType                 { return TypeFeatureModelID }
+func (f *CCGPDevice) FeatureType() DescriptorType              { return TypeFeatureCCGPDevice }
+func (f *VendorRevision) FeatureType() DescriptorType          { return TypeFeatureVendorRevision }
+func (f *UnknownFeature) FeatureType() DescriptorType          { return f.DescriptorType }
+
+// FindPlatformCapability returns the MS OS 2.0 platform capability of the BOS, or nil if there is none.
+func FindPlatformCapability(bos *usb.BOS) *usb.CapPlatformDescriptor {
+	return bos.FindPlatformCapability(PlatformCapabilityUUID)
+}
+
+// ParsePlatformCapability returns the descriptor set information structures of a MS OS 2.0 platform capability.
+func ParsePlatformCapability(platform *usb.CapPlatformDescriptor) ([]DescriptorSetInfo, error) {
+	if platform.PlatformCapabilityUUID != PlatformCapabilityUUID {
+		return nil, fmt.Errorf("not a MS OS 2.0 platform capability: %s", platform.UUIDString())
+	}
+	data := platform.CapabilityData
+	if len(data) == 0 || len(data)%platformDescriptorSetInfoSize != 0 {
+		return nil, fmt.Errorf("invalid MS OS 2.0 platform capability data length %d", len(data))
+	}
+	res := make([]DescriptorSetInfo, 0, len(data)/platformDescriptorSetInfoSize)
+	for ; len(data) > 0; data = data[platformDescriptorSetInfoSize:] {
+		res = append(res, DescriptorSetInfo{
+			WindowsVersion: binary.LittleEndian.Uint32(data),
+			TotalLength:    binary.LittleEndian.Uint16(data[4:]),
+			VendorCode:     data[6],
+			AltEnumCode:    data[7],
+		})
+	}
+	return res, nil
+}
+
+// GetDescriptorSet retrieves and parses the descriptor set described by info.
+func GetDescriptorSet(dev *usb.Device, info DescriptorSetInfo) (*DescriptorSet, error) {
+	data, err := vendorRequest(dev, usb.RequestRecipientDevice, info.VendorCode, 0, DescriptorIndex, int(info.TotalLength))
+	if err != nil {
+		return nil, err
+	}
+	return ParseDescriptorSet(data)
+}
+
+// SetAltEnum requests the device to use the alternate enumeration identified by altEnumCode.
+// An altEnumCode of zero restores the default enumeration.
+func SetAltEnum(dev *usb.Device, info DescriptorSetInfo, altEnumCode uint8) error {
+	_, err := dev.Ctrl(usb.RequestDirectionOut|usb.RequestTypeVendor|usb.RequestRecipientDevice,
+		info.VendorCode, uint16(altEnumCode)<<8, SetAltEnumeration, nil)
+	return err
+}
+
+// readHeader returns the length and type of the descriptor at the start of data.
+func readHeader(data []byte) (int, DescriptorType, error) {
+	if len(data) < minimumFeatureDescriptorLength {
+		return 0, 0, fmt.Errorf("truncated descriptor: %d bytes", len(data))
+	}
+	length := int(binary.LittleEndian.Uint16(data))
+	typ := DescriptorType(binary.LittleEndian.Uint16(data[2:]))
+	if length < minimumFeatureDescriptorLength || length > len(data) {
+		return 0, 0, fmt.Errorf("invalid %v length %d, %d bytes left", typ, length, len(data))
+	}
+	return length, typ, nil
+}
+
+// ParseDescriptorSet parses a complete MS OS 2.0 descriptor set.
+func ParseDescriptorSet(data []byte) (*DescriptorSet, error) {
+	length, typ, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TypeSetHeaderDescriptor || length != setHeaderLength {
+		return nil, fmt.Errorf("expected set header, got %v with length %d", typ, length)
+	}
+	totalLength := int(binary.LittleEndian.Uint16(data[8:]))
+	if totalLength > len(data) {
+		return nil, fmt.Errorf("descriptor set truncated: wTotalLength %d, got %d bytes", totalLength, len(data))
+	}
+	res := &DescriptorSet{
+		WindowsVersion: binary.LittleEndian.Uint32(data[4:]),
+	}
+	data = data[setHeaderLength:totalLength]
+	for len(data) > 0 {
+		length, typ, err := readHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case TypeSubsetHeaderConfiguration:
+			subsetLength, err := subsetLengthOf(data, length)
+			if err != nil {
+				return nil, err
+			}
+			cfg, err := parseConfigurationSubset(data[:subsetLength])
+			if err != nil {
+				return nil, err
+			}
+			res.Configurations = append(res.Configurations, cfg)
+			length = subsetLength
+		case TypeSubsetHeaderFunction:
+			return nil, fmt.Errorf("function subset outside of configuration subset")
+		default:
+			feature, err := parseFeature(typ, data[:length])
+			if err != nil {
+				return nil, err
+			}
+			res.Features = append(res.Features, feature)
+		}
+		data = data[length:]
+	}
+	return res, nil
+}
+
+// subsetLengthOf validates a subset header and returns the length of the complete subset.
+func subsetLengthOf(data []byte, headerLength int) (int, error) {
+	if headerLength != subsetHeaderLength {
+		return 0, fmt.Errorf("invalid subset header length %d", headerLength)
+	}
+	subsetLength := int(binary.LittleEndian.Uint16(data[6:]))
+	if subsetLength < subsetHeaderLength || subsetLength > len(data) {
+		return 0, fmt.Errorf("invalid subset length %d, %d bytes left", subsetLength, len(data))
+	}
+	return subsetLength, nil
+}
+
+func parseConfigurationSubset(data []byte) (*ConfigurationSubset, error) {
+	res := &ConfigurationSubset{
+		ConfigurationValue: data[4],
+	}
+	data = data[subsetHeaderLength:]
+	for len(data) > 0 {
+		length, typ, err := readHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case TypeSubsetHeaderFunction:
+			subsetLength, err := subsetLengthOf(data, length)
+			if err != nil {
+				return nil, err
+			}
+			function, err := parseFunctionSubset(data[:subsetLength])
+			if err != nil {
+				return nil, err
+			}
+			res.Functions = append(res.Functions, function)
+			length = subsetLength
+		case TypeSubsetHeaderConfiguration, TypeSetHeaderDescriptor:
+			return nil, fmt.Errorf("unexpected %v in configuration subset", typ)
+		default:
+			feature, err := parseFeature(typ, data[:length])
+			if err != nil {
+				return nil, err
+			}
+			res.Features = append(res.Features, feature)
+		}
+		data = data[length:]
+	}
+	return res, nil
+}
+
+func parseFunctionSubset(data []byte) (*FunctionSubset, error) {
+	res := &FunctionSubset{
+		FirstInterface: data[4],
+	}
+	data = data[subsetHeaderLength:]
+	for len(data) > 0 {
+		length, typ, err := readHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case TypeSubsetHeaderFunction, TypeSubsetHeaderConfiguration, TypeSetHeaderDescriptor:
+			return nil, fmt.Errorf("unexpected %v in function subset", typ)
+		}
+		feature, err := parseFeature(typ, data[:length])
+		if err != nil {
+			return nil, err
+		}
+		res.Features = append(res.Features, feature)
+		data = data[length:]
+	}
+	return res, nil
+}
+
+func parseFeature(typ DescriptorType, data []byte) (Feature, error) {
+	body := data[minimumFeatureDescriptorLength:]
+	short := func(expected int) error {
+		return fmt.Errorf("%v: expected %d bytes, got %d", typ, expected+minimumFeatureDescriptorLength, len(data))
+	}
+	switch typ {
+	case TypeFeatureCompatibleID:
+		if len(body) < 16 {
+			return nil, short(16)
+		}
+		res := &CompatibleID{}
+		var id [8]byte
+		copy(id[:], body[:8])
+		res.CompatibleID = trimID(id)
+		copy(id[:], body[8:16])
+		res.SubCompatibleID = trimID(id)
+		return res, nil
+	case TypeFeatureRegProperty:
+		if len(body) < 4 {
+			return nil, short(4)
+		}
+		dataType := PropertyDataType(binary.LittleEndian.Uint16(body))
+		nameLength := int(binary.LittleEndian.Uint16(body[2:]))
+		if len(body) < 4+nameLength+2 {
+			return nil, short(4 + nameLength + 2)
+		}
+		name := body[4 : 4+nameLength]
+		valueLength := int(binary.LittleEndian.Uint16(body[4+nameLength:]))
+		value := body[4+nameLength+2:]
+		if len(value) < valueLength {
+			return nil, short(4 + nameLength + 2 + valueLength)
+		}
+		return &RegistryPropertyFeature{
+			RegistryProperty: RegistryProperty{
+				DataType: dataType,
+				Name:     trimNUL(decodeUTF16(name)),
+				Data:     value[:valueLength],
+			},
+		}, nil
+	case TypeFeatureMinResumeTime:
+		if len(body) < 2 {
+			return nil, short(2)
+		}
+		return &MinResumeTime{ResumeRecoveryTime: body[0], ResumeSignalingTime: body[1]}, nil
+	case TypeFeatureModelID:
+		if len(body) < 16 {
+			return nil, short(16)
+		}
+		res := &ModelID{}
+		copy(res.ModelID[:], body)
+		return res, nil
+	case TypeFeatureCCGPDevice:
+		return &CCGPDevice{}, nil
+	case TypeFeatureVendorRevision:
+		if len(body) < 2 {
+			return nil, short(2)
+		}
+		return &VendorRevision{VendorRevision: binary.LittleEndian.Uint16(body)}, nil
+	}
+	return &UnknownFeature{DescriptorType: typ, Data: body}, nil
+}
+
+func trimNUL(s string) string {
+	for len(s) > 0 && s[len(s)-1] == 0 {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// CompatibleIDs returns the compatible IDs of the descriptor set by first interface number.
+// Device level and configuration level compatible IDs are returned for interface 0.
+func (s *DescriptorSet) CompatibleIDs() map[uint8]*CompatibleID {
+	res := make(map[uint8]*CompatibleID)
+	s.walk(func(iface uint8, feature Feature) {
+		if id, ok := feature.(*CompatibleID); ok {
+			res[iface] = id
+		}
+	})
+	return res
+}
+
+// Properties returns the registry properties of the descriptor set by first interface number.
+// Device level and configuration level properties are returned for interface 0.
+func (s *DescriptorSet) Properties() map[uint8][]*RegistryProperty {
+	res := make(map[uint8][]*RegistryProperty)
+	s.walk(func(iface uint8, feature Feature) {
+		if prop, ok := feature.(*RegistryPropertyFeature); ok {
+			res[iface] = append(res[iface], &prop.RegistryProperty)
+		}
+	})
+	return res
+}
+
+// DeviceInterfaceGUIDs returns the DeviceInterfaceGUIDs/DeviceInterfaceGUID values by first interface number.
+func (s *DescriptorSet) DeviceInterfaceGUIDs() map[uint8][]string {
+	res := make(map[uint8][]string)
+	for iface, props := range s.Properties() {
+		for _, prop := range props {
+			if prop.Name == PropertyDeviceInterfaceGUIDs || prop.Name == PropertyDeviceInterfaceGUID {
+				res[iface] = append(res[iface], prop.Strings()...)
+			}
+		}
+	}
+	return res
+}
+
+func (s *DescriptorSet) walk(fn func(iface uint8, feature Feature)) {
+	for _, feature := range s.Features {
+		fn(0, feature)
+	}
+	for _, cfg := range s.Configurations {
+		for _, feature := range cfg.Features {
+			fn(0, feature)
+		}
+		for _, function := range cfg.Functions {
+			for _, feature := range function.Features {
+				fn(function.FirstInterface, feature)
+			}
+		}
+	}
+}
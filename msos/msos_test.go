@@ -0,0 +1,229 @@
+package msos
+
+import (
+	"bytes"
+	"encoding/binary"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16le(s string) []byte {
+	var res []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		res = append(res, byte(c), byte(c>>8))
+	}
+	return res
+}
+
+func TestParseDescriptorSet(t *testing.T) {
+	name := utf16le(PropertyDeviceInterfaceGUIDs + "\x00")
+	value := utf16le("{88BAE032-5A81-49F0-BC3D-A4FF138216D6}\x00\x00")
+	property := []byte{0, 0, 0x04, 0x00, byte(RegMultiSZ), 0, byte(len(name)), 0}
+	property = append(property, name...)
+	property = append(property, byte(len(value)), 0)
+	property = append(property, value...)
+	property[0] = byte(len(property))
+	compat := []byte{0x14, 0x00, 0x03, 0x00, 'W', 'I', 'N', 'U', 'S', 'B', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	function := []byte{0x08, 0x00, 0x02, 0x00, 0x01, 0x00, 0, 0}
+	function = append(function, compat...)
+	function = append(function, property...)
+	function[6] = byte(len(function))
+	config := []byte{0x08, 0x00, 0x01, 0x00, 0x00, 0x00, 0, 0}
+	config = append(config, function...)
+	config[6] = byte(len(config))
+	set := []byte{0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x06, 0, 0}
+	set = append(set, config...)
+	set[8] = byte(len(set))
+
+	parsed, err := ParseDescriptorSet(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.WindowsVersion != WindowsVersion81 || len(parsed.Configurations) != 1 {
+		t.Fatalf("unexpected descriptor set %+v", parsed)
+	}
+	ids := parsed.CompatibleIDs()
+	if ids[1] == nil || ids[1].CompatibleID != "WINUSB" {
+		t.Fatalf("unexpected compatible IDs %+v", ids)
+	}
+	guids := parsed.DeviceInterfaceGUIDs()[1]
+	if len(guids) != 1 || guids[0] != "{88BAE032-5A81-49F0-BC3D-A4FF138216D6}" {
+		t.Fatalf("unexpected interface GUIDs %q", guids)
+	}
+}
+
+func TestParseOSStringDescriptor(t *testing.T) {
+	// The body of the descriptor 12 03 4D 00 53 00 46 00 54 00 31 00 30 00 30 00 20 00.
+	body := append(utf16le(OSStringSignature), 0x20, 0x00)
+	desc, err := ParseOSStringDescriptor(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *desc != (OSStringDescriptor{Signature: OSStringSignature, VendorCode: 0x20}) {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	withContainerID := append(utf16le(OSStringSignature), 0x21, 0x02)
+	if desc, err := ParseOSStringDescriptor(withContainerID); err != nil || desc.VendorCode != 0x21 || desc.Flags != 0x02 {
+		t.Fatalf("descriptor with flags = %+v, %v", desc, err)
+	}
+	if _, err := ParseOSStringDescriptor(body[:15]); err == nil {
+		t.Fatal("expected an error for a truncated descriptor")
+	}
+	if _, err := ParseOSStringDescriptor(append(utf16le("MSFT200"), 0x20, 0x00)); err == nil {
+		t.Fatal("expected an error for an unknown signature")
+	}
+}
+
+// compatID is the extended compat ID descriptor of a device with WinUSB on interface 0 and
+// RNDIS on interface 2.
+var compatID = []byte{
+	0x40, 0x00, 0x00, 0x00, 0x00, 0x01, 0x04, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x01, 'W', 'I', 'N', 'U', 'S', 'B', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x02, 0x01, 'R', 'N', 'D', 'I', 'S', 0x00, 0x00, 0x00, '5', '1', '6', '2', '0', '0', '1', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestParseExtendedCompatID(t *testing.T) {
+	desc, err := ParseExtendedCompatID(compatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Version != 0x0100 || len(desc.Functions) != 2 ||
+		desc.Functions[0] != (CompatIDFunction{FirstInterfaceNumber: 0, CompatibleID: "WINUSB"}) ||
+		desc.Functions[1] != (CompatIDFunction{FirstInterfaceNumber: 2, CompatibleID: "RNDIS", SubCompatibleID: "5162001"}) {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+
+	tooMany := append([]byte(nil), compatID...)
+	tooMany[8] = 3
+	wrongIndex := append([]byte(nil), compatID...)
+	wrongIndex[6] = ExtendedPropertiesIndex
+	for name, data := range map[string][]byte{
+		"truncated":      compatID[:len(compatID)-1],
+		"short header":   compatID[:15],
+		"too many":       tooMany,
+		"wrong wIndex":   wrongIndex,
+		"empty":          nil,
+		"header only":    compatID[:16],
+		"function split": append(append([]byte{0x20, 0, 0, 0}, compatID[4:16]...), compatID[16:32]...),
+	} {
+		if desc, err := ParseExtendedCompatID(data); err == nil {
+			t.Errorf("%s: expected an error, got %+v", name, desc)
+		}
+	}
+}
+
+// property encodes an extended properties section.
+func property(dataType PropertyDataType, name string, value []byte) []byte {
+	encodedName := utf16le(name + "\x00")
+	section := make([]byte, 10, 14+len(encodedName)+len(value))
+	binary.LittleEndian.PutUint32(section[4:], uint32(dataType))
+	binary.LittleEndian.PutUint16(section[8:], uint16(len(encodedName)))
+	section = append(section, encodedName...)
+	section = append(section, byte(len(value)), 0, 0, 0)
+	section = append(section, value...)
+	binary.LittleEndian.PutUint32(section, uint32(len(section)))
+	return section
+}
+
+// extendedProperties encodes an extended properties descriptor with the sections.
+func extendedProperties(sections ...[]byte) []byte {
+	data := []byte{0, 0, 0, 0, 0x00, 0x01, 0x05, 0x00, byte(len(sections)), 0x00}
+	for _, section := range sections {
+		data = append(data, section...)
+	}
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+	return data
+}
+
+func TestParseExtendedProperties(t *testing.T) {
+	guid := property(RegSZ, PropertyDeviceInterfaceGUID, utf16le("{88BAE032-5A81-49F0-BC3D-A4FF138216D6}\x00"))
+	dword := property(RegDWordLittleEndian, "SelectiveSuspendEnabled", []byte{1, 0, 0, 0})
+	data := extendedProperties(guid, dword)
+	desc, err := ParseExtendedProperties(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Version != 0x0100 || len(desc.Properties) != 2 || desc.Properties[1].Name != "SelectiveSuspendEnabled" {
+		t.Fatalf("unexpected descriptor %+v", desc)
+	}
+	if guids := desc.DeviceInterfaceGUIDs(); len(guids) != 1 || guids[0] != "{88BAE032-5A81-49F0-BC3D-A4FF138216D6}" {
+		t.Fatalf("unexpected interface GUIDs %q", guids)
+	}
+	if value, err := desc.Properties[1].DWord(); err != nil || value != 1 {
+		t.Fatalf("DWord = %d, %v", value, err)
+	}
+
+	missingSection := extendedProperties(guid, dword)
+	missingSection[8] = 3
+	nameTooLong := append([]byte(nil), dword...)
+	nameTooLong[8] = byte(len(dword))
+	valueTooLong := append([]byte(nil), dword...)
+	valueTooLong[len(dword)-8] = 5
+	sectionTooLong := append([]byte(nil), dword...)
+	sectionTooLong[0]++
+	wrongIndex := extendedProperties(guid)
+	wrongIndex[6] = ExtendedCompatIDIndex
+	for name, data := range map[string][]byte{
+		"truncated":        data[:len(data)-1],
+		"short header":     data[:9],
+		"missing section":  missingSection,
+		"name too long":    extendedProperties(nameTooLong),
+		"value too long":   extendedProperties(valueTooLong),
+		"section too long": extendedProperties(sectionTooLong),
+		"short section":    extendedProperties(dword[:13]),
+		"wrong wIndex":     wrongIndex,
+	} {
+		if desc, err := ParseExtendedProperties(data); err == nil {
+			t.Errorf("%s: expected an error, got %+v", name, desc)
+		}
+	}
+}
+
+func TestGetExtended(t *testing.T) {
+	properties := extendedProperties(property(RegMultiSZ, PropertyDeviceInterfaceGUIDs, utf16le("{88BAE032-5A81-49F0-BC3D-A4FF138216D6}\x00\x00")))
+	simDev, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []usb.SetupPacket
+	simDev.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		requests = append(requests, setup)
+		switch {
+		case setup.Request != 0x20:
+		case setup.RequestType == 0xC0 && setup.Index == ExtendedCompatIDIndex:
+			return copy(data, compatID), nil
+		case setup.RequestType == 0xC1 && setup.Index == ExtendedPropertiesIndex && setup.Value == 0:
+			return copy(data, properties), nil
+		}
+		return 0, syscall.EPIPE
+	}
+	dev := simDev.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+
+	desc, err := GetExtendedCompatID(dev, 0x20)
+	if err != nil || len(desc.Functions) != 2 {
+		t.Fatalf("compat ID = %+v, %v", desc, err)
+	}
+	if len(requests) != 2 || requests[0].Length != compatIDHeaderLength || requests[1].Length != uint16(len(compatID)) {
+		t.Fatalf("expected the header and then the whole descriptor to be read, got %+v", requests)
+	}
+	props, err := GetExtendedProperties(dev, 0x20, 0)
+	if err != nil || len(props.DeviceInterfaceGUIDs()) != 1 {
+		t.Fatalf("properties = %+v, %v", props, err)
+	}
+	if _, err := GetExtendedProperties(dev, 0x20, 1); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for an interface without properties, got %v", err)
+	}
+	if !bytes.Equal(props.Properties[0].Data[:2], []byte{'{', 0}) {
+		t.Fatalf("unexpected property data % x", props.Properties[0].Data)
+	}
+}
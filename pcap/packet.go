@@ -0,0 +1,208 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"time"
+)
+
+// HeaderSize is the size of the usbmon header preceding the data of every packet.
+const HeaderSize = 64
+
+// EventType is the kind of usbmon event.
+type EventType uint8
+
+const (
+	EventSubmit   = EventType('S')
+	EventComplete = EventType('C')
+	EventError    = EventType('E')
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventSubmit:
+		return "submit"
+	case EventComplete:
+		return "complete"
+	case EventError:
+		return "error"
+	}
+	return fmt.Sprintf("EventType(%d)", uint8(e))
+}
+
+// Data flags telling why a packet carries no data.
+const (
+	DataPresent    = uint8(0)
+	DataInSubmit   = uint8('<')
+	DataOutResult  = uint8('>')
+	DataNotPresent = uint8('-')
+)
+
+// Linux URB transfer flags.
+const (
+	URBShortNotOK = uint32(0x0001)
+	URBISOASAP    = uint32(0x0002)
+	URBZeroPacket = uint32(0x0040)
+	URBDirIn      = uint32(0x0200)
+)
+
+// ISODescriptor is the status of one isochronous packet.
+type ISODescriptor struct {
+	Status int32
+	Offset uint32
+	Length uint32
+}
+
+// Packet is a usbmon event as stored in LINKTYPE_USB_LINUX_MMAPPED captures: a submission or
+// completion of a URB with its setup packet and data.
+type Packet struct {
+	// ID identifies the URB, the submission and completion of a transfer share the same ID.
+	ID           uint64
+	Event        EventType
+	TransferType usb.TransferType
+	// Endpoint is the endpoint address including the direction bit.
+	Endpoint     uint8
+	DeviceNumber uint8
+	BusNumber    uint16
+
+	// HasSetup is set when Setup holds the setup packet of a control submission.
+	HasSetup bool
+	Setup    usb.SetupPacket
+	// DataFlag is DataPresent or the reason there is no data.
+	DataFlag uint8
+
+	Timestamp time.Time
+	// Status is 0 or a negative errno, -EINPROGRESS for submissions.
+	Status int32
+	// Length is the URB length: the requested length when submitted and the actual length on completion.
+	Length uint32
+
+	// ErrorCount and the ISODescriptors are only used by isochronous transfers.
+	ErrorCount     int32
+	ISODescriptors []ISODescriptor
+
+	Interval      int32
+	StartFrame    int32
+	TransferFlags uint32
+
+	// Data is the captured data, it may be shorter than Length.
+	Data []byte
+}
+
+// In reports whether the transfer is device to host.
+func (p *Packet) In() bool {
+	return p.Endpoint&0x80 != 0
+}
+
+// usbmon numbers transfer types differently than endpoint descriptors do.
+var (
+	monTransferTypes = [...]uint8{
+		usb.TransferTypeControl:     2,
+		usb.TransferTypeIsochronous: 0,
+		usb.TransferTypeBulk:        3,
+		usb.TransferTypeInterrupt:   1,
+	}
+	transferTypes = [...]usb.TransferType{
+		0: usb.TransferTypeIsochronous,
+		1: usb.TransferTypeInterrupt,
+		2: usb.TransferTypeControl,
+		3: usb.TransferTypeBulk,
+	}
+)
+
+// MarshalBinary encodes the header, the isochronous descriptors and the data in little endian byte order,
+// the host byte order of the machines usbmon captures are usually made on.
+func (p *Packet) MarshalBinary() ([]byte, error) {
+	data := make([]byte, HeaderSize, HeaderSize+len(p.ISODescriptors)*16+len(p.Data))
+	le := binary.LittleEndian
+	le.PutUint64(data[0:], p.ID)
+	data[8] = uint8(p.Event)
+	data[9] = monTransferTypes[p.TransferType&3]
+	data[10] = p.Endpoint
+	data[11] = p.DeviceNumber
+	le.PutUint16(data[12:], p.BusNumber)
+	data[14] = DataNotPresent
+	if p.HasSetup {
+		data[14] = 0
+		setup, _ := p.Setup.MarshalBinary()
+		copy(data[40:], setup)
+	} else {
+		le.PutUint32(data[40:], uint32(p.ErrorCount))
+		le.PutUint32(data[44:], uint32(len(p.ISODescriptors)))
+	}
+	data[15] = p.DataFlag
+	le.PutUint64(data[16:], uint64(p.Timestamp.Unix()))
+	le.PutUint32(data[24:], uint32(p.Timestamp.Nanosecond()/1000))
+	le.PutUint32(data[28:], uint32(p.Status))
+	le.PutUint32(data[32:], p.Length)
+	le.PutUint32(data[36:], uint32(len(p.Data)))
+	le.PutUint32(data[48:], uint32(p.Interval))
+	le.PutUint32(data[52:], uint32(p.StartFrame))
+	le.PutUint32(data[56:], p.TransferFlags)
+	le.PutUint32(data[60:], uint32(len(p.ISODescriptors)))
+	for _, desc := range p.ISODescriptors {
+		var buf [16]byte
+		le.PutUint32(buf[0:], uint32(desc.Status))
+		le.PutUint32(buf[4:], desc.Offset)
+		le.PutUint32(buf[8:], desc.Length)
+		data = append(data, buf[:]...)
+	}
+	return append(data, p.Data...), nil
+}
+
+// UnmarshalBinary decodes a packet encoded by MarshalBinary or captured by the usbmon mmap interface.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	return p.unmarshal(data, binary.LittleEndian)
+}
+
+// unmarshal decodes a packet with the header and isochronous descriptors in the byte order of the
+// capturing host, which is the byte order of the file for pcap and pcapng captures.
+func (p *Packet) unmarshal(data []byte, order binary.ByteOrder) error {
+	if len(data) < HeaderSize {
+		return fmt.Errorf("usbmon packet too short: %d bytes", len(data))
+	}
+	*p = Packet{
+		ID:            order.Uint64(data[0:]),
+		Event:         EventType(data[8]),
+		TransferType:  transferTypes[data[9]&3],
+		Endpoint:      data[10],
+		DeviceNumber:  data[11],
+		BusNumber:     order.Uint16(data[12:]),
+		HasSetup:      data[14] == 0,
+		DataFlag:      data[15],
+		Timestamp:     time.Unix(int64(order.Uint64(data[16:])), int64(int32(order.Uint32(data[24:])))*1000),
+		Status:        int32(order.Uint32(data[28:])),
+		Length:        order.Uint32(data[32:]),
+		Interval:      int32(order.Uint32(data[48:])),
+		StartFrame:    int32(order.Uint32(data[52:])),
+		TransferFlags: order.Uint32(data[56:]),
+	}
+	captured := int(order.Uint32(data[36:]))
+	ndesc := int(order.Uint32(data[60:]))
+	if p.HasSetup {
+		p.Setup.UnmarshalBinary(data[40:48])
+	} else {
+		p.ErrorCount = int32(order.Uint32(data[40:]))
+	}
+	data = data[HeaderSize:]
+	if p.TransferType == usb.TransferTypeIsochronous && ndesc > 0 {
+		if ndesc*16 > len(data) {
+			return fmt.Errorf("usbmon packet truncated: %d isochronous descriptors", ndesc)
+		}
+		for i := 0; i < ndesc; i++ {
+			p.ISODescriptors = append(p.ISODescriptors, ISODescriptor{
+				Status: int32(order.Uint32(data[0:])),
+				Offset: order.Uint32(data[4:]),
+				Length: order.Uint32(data[8:]),
+			})
+			data = data[16:]
+		}
+	}
+	if captured > len(data) {
+		return fmt.Errorf("usbmon packet truncated: %d of %d captured bytes", len(data), captured)
+	}
+	data = data[:captured]
+	p.Data = append([]byte{}, data...)
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package pcap writes USB transfers to pcap and pcapng files using LINKTYPE_USB_LINUX_MMAPPED,
+// the format of the Linux usbmon binary interface, so Wireshark's USB dissectors decode them.
+//
+// A Writer is a usb.Capture hook, every transfer of a device is written as a submit and complete pair
+// with the setup packet, status and timestamps as seen by the library:
+//
+//	file, _ := os.Create("capture.pcapng")
+//	w, _ := pcap.NewNGWriter(file)
+//	dev.SetCapture(w)
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	usb "github.com/daedaluz/gousb"
+	"io"
+	"sync"
+	"syscall"
+)
+
+// LinkTypeUSBLinuxMMapped is the link type of usbmon captures with the 64 byte header.
+const LinkTypeUSBLinuxMMapped = 220
+
+// SnapLen is the snapshot length written to the file headers.
+const SnapLen = 0x40000
+
+const (
+	pcapMagic = 0xA1B2C3D4
+
+	ngSectionHeader  = 0x0A0D0D0A
+	ngInterface      = 0x00000001
+	ngEnhancedPacket = 0x00000006
+	ngByteOrderMagic = 0x1A2B3C4D
+	ngSectionUnknown = 0xFFFFFFFFFFFFFFFF
+)
+
+// Writer writes packets to a pcap or pcapng stream, it is safe for concurrent use.
+type Writer struct {
+	w  io.Writer
+	ng bool
+
+	lock sync.Mutex
+	err  error
+}
+
+// NewWriter writes the pcap file header and returns a Writer for the packets.
+func NewWriter(w io.Writer) (*Writer, error) {
+	header := make([]byte, 24)
+	le := binary.LittleEndian
+	le.PutUint32(header[0:], pcapMagic)
+	le.PutUint16(header[4:], 2)
+	le.PutUint16(header[6:], 4)
+	le.PutUint32(header[16:], SnapLen)
+	le.PutUint32(header[20:], LinkTypeUSBLinuxMMapped)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// NewNGWriter writes a pcapng section header and interface description and returns a Writer for the packets.
+// Timestamps use the default microsecond resolution.
+func NewNGWriter(w io.Writer) (*Writer, error) {
+	header := make([]byte, 28+20)
+	le := binary.LittleEndian
+	le.PutUint32(header[0:], ngSectionHeader)
+	le.PutUint32(header[4:], 28)
+	le.PutUint32(header[8:], ngByteOrderMagic)
+	le.PutUint16(header[12:], 1)
+	le.PutUint16(header[14:], 0)
+	le.PutUint64(header[16:], ngSectionUnknown)
+	le.PutUint32(header[24:], 28)
+
+	idb := header[28:]
+	le.PutUint32(idb[0:], ngInterface)
+	le.PutUint32(idb[4:], 20)
+	le.PutUint16(idb[8:], LinkTypeUSBLinuxMMapped)
+	le.PutUint32(idb[12:], SnapLen)
+	le.PutUint32(idb[16:], 20)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, ng: true}, nil
+}
+
+// WritePacket writes one packet.
+func (w *Writer) WritePacket(p *Packet) error {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	micros := p.Timestamp.UnixNano() / 1000
+	le := binary.LittleEndian
+	var record []byte
+	if w.ng {
+		padded := (len(data) + 3) &^ 3
+		record = make([]byte, 28+padded+4)
+		le.PutUint32(record[0:], ngEnhancedPacket)
+		le.PutUint32(record[4:], uint32(len(record)))
+		le.PutUint32(record[12:], uint32(uint64(micros)>>32))
+		le.PutUint32(record[16:], uint32(micros))
+		le.PutUint32(record[20:], uint32(len(data)))
+		le.PutUint32(record[24:], uint32(len(data)))
+		copy(record[28:], data)
+		le.PutUint32(record[len(record)-4:], uint32(len(record)))
+	} else {
+		record = make([]byte, 16+len(data))
+		le.PutUint32(record[0:], uint32(micros/1000000))
+		le.PutUint32(record[4:], uint32(micros%1000000))
+		le.PutUint32(record[8:], uint32(len(data)))
+		le.PutUint32(record[12:], uint32(len(data)))
+		copy(record[16:], data)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	_, w.err = w.w.Write(record)
+	return w.err
+}
+
+// CaptureTransfer implements usb.Capture, writing the submit and complete packets of the transfer.
+// Write errors stop the capture and are returned by Err.
+func (w *Writer) CaptureTransfer(dev *usb.Device, record *usb.CaptureRecord) {
+	submit, complete := Packets(dev, record)
+	if err := w.WritePacket(submit); err != nil {
+		return
+	}
+	w.WritePacket(complete)
+}
+
+// Err returns the first write error.
+func (w *Writer) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.err
+}
+
+// Status converts a transfer error to the negative errno usbmon reports, -EIO for errors without an errno.
+func Status(err error) int32 {
+	if err == nil {
+		return 0
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return -int32(errno)
+	}
+	return -int32(syscall.EIO)
+}
+
+// Packets converts a captured transfer to the submit and complete packets usbmon would have recorded.
+func Packets(dev *usb.Device, record *usb.CaptureRecord) (submit, complete *Packet) {
+	submit = &Packet{
+		ID:           record.ID,
+		Event:        EventSubmit,
+		TransferType: record.Type,
+		Endpoint:     record.Endpoint,
+		DeviceNumber: uint8(dev.DeviceNumber),
+		BusNumber:    uint16(dev.BusNumber),
+		Timestamp:    record.Submitted,
+		Status:       -int32(syscall.EINPROGRESS),
+		Length:       uint32(record.Length),
+	}
+	if record.In() {
+		submit.TransferFlags = URBDirIn
+	}
+	complete = &Packet{}
+	*complete = *submit
+	complete.Event = EventComplete
+	complete.Timestamp = record.Completed
+	complete.Status = Status(record.Err)
+	if record.Actual > 0 {
+		complete.Length = uint32(record.Actual)
+	} else {
+		complete.Length = 0
+	}
+
+	if record.Type == usb.TransferTypeControl {
+		submit.HasSetup = true
+		submit.Setup = record.Setup
+	}
+	if record.In() {
+		submit.DataFlag = DataInSubmit
+		complete.Data = record.Data
+	} else {
+		submit.Data = record.Data
+		complete.DataFlag = DataOutResult
+	}
+	return submit, complete
+}
@@ -0,0 +1,295 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"io"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCapture(t *testing.T) {
+	simDev, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := simDev.Descriptors.Device.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := simDev.USB()
+	if err := device.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer device.Close()
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device.SetCapture(w)
+	if _, err := device.GetDeviceDescriptor(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := device.GetDescriptor(usb.DescriptorTypeBOS, 0, 0); err != syscall.EPIPE {
+		t.Fatalf("expected EPIPE for missing BOS, got %v", err)
+	}
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	if binary.LittleEndian.Uint32(data[20:]) != LinkTypeUSBLinuxMMapped {
+		t.Fatal("wrong link type")
+	}
+	var packets []*Packet
+	for data = data[24:]; len(data) > 0; {
+		length := int(binary.LittleEndian.Uint32(data[8:]))
+		p := &Packet{}
+		if err := p.UnmarshalBinary(data[16 : 16+length]); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, p)
+		data = data[16+length:]
+	}
+	if len(packets) != 4 {
+		t.Fatalf("expected 4 packets, got %d", len(packets))
+	}
+	submit, complete := packets[0], packets[1]
+	if submit.Event != EventSubmit || !submit.HasSetup || submit.Setup.Request != usb.ReqGetDescriptor ||
+		submit.Setup.Value != 0x0100 || submit.Endpoint != 0x80 || submit.Status != -int32(syscall.EINPROGRESS) {
+		t.Fatalf("unexpected submit %+v", submit)
+	}
+	if complete.Event != EventComplete || complete.ID != submit.ID || complete.Status != 0 || !bytes.Equal(complete.Data, dev) {
+		t.Fatalf("unexpected complete %+v", complete)
+	}
+	if packets[3].Status != -int32(syscall.EPIPE) {
+		t.Fatalf("expected -EPIPE status, got %d", packets[3].Status)
+	}
+}
+
+// The captures below are assembled field by field following the pcap and pcapng specifications,
+// so the reader is tested against files not written by Writer: big endian sections, options,
+// interfaces of other link types, simple packet blocks and nanosecond timestamps.
+
+// testPackets are a GET_DESCRIPTOR submission and completion and an isochronous IN completion.
+var testPackets = []*Packet{{
+	ID: 0xffff8801d4e31e00, Event: EventSubmit, TransferType: usb.TransferTypeControl, Endpoint: 0x80, DeviceNumber: 5, BusNumber: 1,
+	HasSetup: true, Setup: usb.SetupPacket{RequestType: 0x80, Request: usb.ReqGetDescriptor, Value: 0x0100, Length: 18}, DataFlag: DataInSubmit,
+	Timestamp: time.Unix(1700000000, 123000), Status: -int32(syscall.EINPROGRESS), Length: 18, TransferFlags: URBDirIn,
+}, {
+	ID: 0xffff8801d4e31e00, Event: EventComplete, TransferType: usb.TransferTypeControl, Endpoint: 0x80, DeviceNumber: 5, BusNumber: 1,
+	Timestamp: time.Unix(1700000000, 456000), Length: 18, TransferFlags: URBDirIn,
+	Data: []byte{0x12, 0x01, 0x00, 0x02, 0, 0, 0, 0x40, 0x09, 0x12, 0x01, 0x00, 0x00, 0x01, 1, 2, 3, 1},
+}, {
+	ID: 0xffff8801d4e32a00, Event: EventComplete, TransferType: usb.TransferTypeIsochronous, Endpoint: 0x83, DeviceNumber: 7, BusNumber: 2,
+	Timestamp: time.Unix(1700000001, 0), Length: 4, Interval: 1, StartFrame: 348, TransferFlags: URBDirIn | URBISOASAP,
+	ISODescriptors: []ISODescriptor{{Status: 0, Offset: 0, Length: 2}, {Status: -18, Offset: 192, Length: 2}},
+	Data:           []byte{1, 2, 3, 4},
+}}
+
+// hostPacket encodes p as captured on a host with the given byte order.
+func hostPacket(t *testing.T, order binary.ByteOrder, p *Packet) []byte {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order == binary.LittleEndian {
+		return data
+	}
+	swap := func(field []byte) {
+		for i, j := 0, len(field)-1; i < j; i, j = i+1, j-1 {
+			field[i], field[j] = field[j], field[i]
+		}
+	}
+	swap(data[0:8])
+	swap(data[12:14])
+	swap(data[16:24])
+	for offset := 24; offset < HeaderSize; offset += 4 {
+		if offset < 40 || offset >= 48 || !p.HasSetup {
+			swap(data[offset : offset+4])
+		}
+	}
+	for offset := HeaderSize; offset < HeaderSize+16*len(p.ISODescriptors); offset += 4 {
+		swap(data[offset : offset+4])
+	}
+	return data
+}
+
+// block encodes a pcapng block, the body is padded to 32 bits.
+func block(order binary.ByteOrder, blockType uint32, body ...[]byte) []byte {
+	data := make([]byte, 8)
+	order.PutUint32(data, blockType)
+	for _, part := range body {
+		data = append(data, part...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	data = append(data, 0, 0, 0, 0)
+	order.PutUint32(data[4:], uint32(len(data)))
+	order.PutUint32(data[len(data)-4:], uint32(len(data)))
+	return data
+}
+
+// option encodes a pcapng option, the value is padded to 32 bits.
+func option(order binary.ByteOrder, code uint16, value []byte) []byte {
+	data := make([]byte, 4, 4+len(value)+3)
+	order.PutUint16(data, code)
+	order.PutUint16(data[2:], uint16(len(value)))
+	data = append(data, value...)
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	return data
+}
+
+func u16(order binary.ByteOrder, v uint16) []byte {
+	data := make([]byte, 2)
+	order.PutUint16(data, v)
+	return data
+}
+
+func u32(order binary.ByteOrder, values ...uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		order.PutUint32(data[4*i:], v)
+	}
+	return data
+}
+
+// section encodes a pcapng section header with an shb_userappl option.
+func section(order binary.ByteOrder) []byte {
+	return block(order, ngSectionHeader, u32(order, ngByteOrderMagic), u16(order, 1), u16(order, 0),
+		[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		option(order, 4, []byte("hand assembled")), option(order, 0, nil))
+}
+
+func enhancedPacket(order binary.ByteOrder, iface uint32, data []byte, options ...[]byte) []byte {
+	body := [][]byte{u32(order, iface, 0, 0, uint32(len(data)), uint32(len(data))), data}
+	return block(order, ngEnhancedPacket, append(body, options...)...)
+}
+
+func readAll(t *testing.T, data []byte) []*Packet {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []*Packet
+	for {
+		p, err := r.ReadPacket()
+		if err == io.EOF {
+			return res
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, p)
+	}
+}
+
+func expectPackets(t *testing.T, name string, packets, expected []*Packet) {
+	if len(packets) != len(expected) {
+		t.Fatalf("%s: expected %d packets, got %d", name, len(expected), len(packets))
+	}
+	for i, p := range packets {
+		if p.Data == nil {
+			p.Data = []byte{}
+		}
+		want := *expected[i]
+		if want.Data == nil {
+			want.Data = []byte{}
+		}
+		if !p.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("%s: packet %d at %v, expected %v", name, i, p.Timestamp, want.Timestamp)
+		}
+		p.Timestamp = want.Timestamp
+		if !reflect.DeepEqual(p, &want) {
+			t.Errorf("%s: packet %d\n%+v\nexpected\n%+v", name, i, p, &want)
+		}
+	}
+}
+
+func TestReadPcap(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range testPackets {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectPackets(t, "little endian", readAll(t, buf.Bytes()), testPackets)
+
+	// A big endian capture with nanosecond timestamps.
+	be := binary.BigEndian
+	data := append(u32(be, pcapMagicNano), u16(be, 2)...)
+	data = append(data, u16(be, 4)...)
+	data = append(data, u32(be, 0, 0, SnapLen, LinkTypeUSBLinuxMMapped)...)
+	for _, p := range testPackets {
+		packet := hostPacket(t, be, p)
+		data = append(data, u32(be, uint32(p.Timestamp.Unix()), uint32(p.Timestamp.Nanosecond()), uint32(len(packet)), uint32(len(packet)))...)
+		data = append(data, packet...)
+	}
+	expectPackets(t, "big endian", readAll(t, data), testPackets)
+
+	// Truncated records.
+	r, err := NewReader(bytes.NewReader(data[:len(data)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, err = r.ReadPacket()
+	}
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a truncated record, got %v", err)
+	}
+
+	// Packets of LINKTYPE_USB_LINUX with the 48 byte header are skipped.
+	be.PutUint32(data[20:], 189)
+	if packets := readAll(t, data); len(packets) != 0 {
+		t.Fatalf("expected no packets of link type 189, got %d", len(packets))
+	}
+	if _, err := NewReader(bytes.NewReader(make([]byte, 24))); err == nil {
+		t.Fatal("expected an error for an unknown magic number")
+	}
+}
+
+func TestReadPcapNG(t *testing.T) {
+	be, le := binary.BigEndian, binary.LittleEndian
+	// A big endian section with an ethernet interface and a usbmon interface with options.
+	data := section(be)
+	data = append(data, block(be, ngInterface, u16(be, 1), u16(be, 0), u32(be, SnapLen))...)
+	data = append(data, block(be, ngInterface, u16(be, LinkTypeUSBLinuxMMapped), u16(be, 0), u32(be, SnapLen),
+		option(be, 2, []byte("usbmon1")), option(be, 9, []byte{6}), option(be, 0, nil))...)
+	data = append(data, enhancedPacket(be, 0, make([]byte, 60))...)
+	data = append(data, enhancedPacket(be, 1, hostPacket(t, be, testPackets[0]), option(be, 1, []byte("comment")), option(be, 0, nil))...)
+	data = append(data, enhancedPacket(be, 1, hostPacket(t, be, testPackets[1]))...)
+	// A little endian section with a simple packet block and a block of an unknown type.
+	data = append(data, section(le)...)
+	data = append(data, block(le, ngInterface, u16(le, LinkTypeUSBLinuxMMapped), u16(le, 0), u32(le, SnapLen))...)
+	iso := hostPacket(t, le, testPackets[2])
+	data = append(data, block(le, ngSimplePacket, u32(le, uint32(len(iso))), iso)...)
+	data = append(data, block(le, 0x00000BAD, []byte("custom"))...)
+
+	packets := readAll(t, data)
+	// Timestamps are taken from the usbmon header, so the zero block timestamps do not matter.
+	expectPackets(t, "pcapng", packets, testPackets)
+
+	// A packet on an interface that was not described.
+	bad := append(section(le), enhancedPacket(le, 0, iso)...)
+	r, err := NewReader(bytes.NewReader(bad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadPacket(); err == nil {
+		t.Fatal("expected an error for a packet of an unknown interface")
+	}
+}
@@ -0,0 +1,157 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	pcapMagicNano = 0xA1B23C4D
+
+	ngSimplePacket = 0x00000003
+)
+
+// Reader reads packets from a pcap or pcapng stream written by any tool using LinkTypeUSBLinuxMMapped,
+// such as Wireshark or tcpdump capturing on a usbmon interface.
+// Both byte orders are accepted, the usbmon headers are expected in the byte order of the file
+// as libpcap writes them. Packets of other link types are skipped.
+type Reader struct {
+	r     *bufio.Reader
+	ng    bool
+	order binary.ByteOrder
+
+	// linkType of a pcap file.
+	linkType uint32
+	// interfaces are the link types of the interfaces in the current pcapng section.
+	interfaces []uint16
+}
+
+// NewReader reads the file header, the format is detected from its magic number.
+// Record timestamps are ignored in favour of the timestamp in the usbmon header.
+func NewReader(r io.Reader) (*Reader, error) {
+	res := &Reader{r: bufio.NewReader(r)}
+	magic, err := res.r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(magic) == ngSectionHeader {
+		res.ng = true
+		return res, nil
+	}
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(res.r, header); err != nil {
+		return nil, err
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		if magic := order.Uint32(header); magic != pcapMagic && magic != pcapMagicNano {
+			continue
+		}
+		res.order = order
+		res.linkType = res.order.Uint32(header[20:]) & 0xFFFF
+		return res, nil
+	}
+	return nil, fmt.Errorf("not a pcap or pcapng file")
+}
+
+// ReadPacket returns the next packet, io.EOF at the end of the stream.
+func (r *Reader) ReadPacket() (*Packet, error) {
+	for {
+		data, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+		p := &Packet{}
+		if err := p.unmarshal(data, r.order); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// next returns the data of the next record, nil for records that are not usbmon packets.
+func (r *Reader) next() ([]byte, error) {
+	if r.ng {
+		return r.nextBlock()
+	}
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		return nil, err
+	}
+	data := make([]byte, r.order.Uint32(header[8:]))
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, unexpected(err)
+	}
+	if r.linkType != LinkTypeUSBLinuxMMapped {
+		return nil, nil
+	}
+	return data, nil
+}
+
+func (r *Reader) nextBlock() ([]byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(header) == ngSectionHeader {
+		magic, err := r.r.Peek(4)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		r.order = binary.LittleEndian
+		if binary.BigEndian.Uint32(magic) == ngByteOrderMagic {
+			r.order = binary.BigEndian
+		}
+		r.interfaces = nil
+	}
+	length := int(r.order.Uint32(header[4:]))
+	if length < 12 || length%4 != 0 {
+		return nil, fmt.Errorf("invalid pcapng block length %d", length)
+	}
+	body := make([]byte, length-8)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return nil, unexpected(err)
+	}
+	body = body[:len(body)-4]
+	switch r.order.Uint32(header) {
+	case ngInterface:
+		if len(body) < 8 {
+			return nil, fmt.Errorf("truncated pcapng interface description")
+		}
+		r.interfaces = append(r.interfaces, r.order.Uint16(body))
+	case ngEnhancedPacket:
+		if len(body) < 20 {
+			return nil, fmt.Errorf("truncated pcapng packet")
+		}
+		id := int(r.order.Uint32(body))
+		captured := int(r.order.Uint32(body[12:]))
+		if id >= len(r.interfaces) || captured > len(body)-20 {
+			return nil, fmt.Errorf("invalid pcapng packet")
+		}
+		if r.interfaces[id] != LinkTypeUSBLinuxMMapped {
+			return nil, nil
+		}
+		return body[20 : 20+captured], nil
+	case ngSimplePacket:
+		if len(r.interfaces) == 0 || r.interfaces[0] != LinkTypeUSBLinuxMMapped || len(body) < 4 {
+			return nil, nil
+		}
+		captured := int(r.order.Uint32(body))
+		if captured > len(body)-4 {
+			captured = len(body) - 4
+		}
+		return body[4 : 4+captured], nil
+	}
+	return nil, nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+	"syscall"
+)
+
+// backend is the usb.Backend of an open replay device.
+type backend struct {
+	dev *Device
+}
+
+func (b *backend) Control(setup usb.SetupPacket, data []byte, timeout uint32) (int, error) {
+	if int(setup.Length) > len(data) {
+		return 0, syscall.EINVAL
+	}
+	data = data[:setup.Length]
+	ep := uint8(setup.RequestType & usb.RequestDirectionIn)
+	return b.dev.replay(usb.TransferTypeControl, ep, &setup, data, "CONTROL "+setup.String())
+}
+
+func (b *backend) Bulk(ep uint8, data []byte, timeout uint32) (int, error) {
+	return b.dev.replay(usb.TransferTypeBulk, ep, nil, data, describe("BULK", ep, data))
+}
+
+func (b *backend) Interrupt(ep uint8, data []byte, timeout uint32) (int, error) {
+	return b.dev.replay(usb.TransferTypeInterrupt, ep, nil, data, describe("INTERRUPT", ep, data))
+}
+
+// ClaimInterface, ReleaseInterface and Reset are not visible on the bus, so they always succeed.
+
+func (b *backend) ClaimInterface(iface uint8) error {
+	return nil
+}
+
+func (b *backend) ReleaseInterface(iface uint8) error {
+	return nil
+}
+
+func (b *backend) Reset() error {
+	return nil
+}
+
+func (b *backend) Close() error {
+	return nil
+}
+
+func describe(typ string, ep uint8, data []byte) string {
+	if ep&usb.EndpointDirectionIn != 0 {
+		return fmt.Sprintf("%s IN 0x%.2x len %d", typ, ep, len(data))
+	}
+	return fmt.Sprintf("%s OUT 0x%.2x len %d", typ, ep, len(data))
+}
+
+// replay answers a transfer from the recording.
+func (d *Device) replay(typ usb.TransferType, ep uint8, setup *usb.SetupPacket, data []byte, got string) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	idx, err := d.find(typ, ep, setup, got)
+	if err != nil {
+		return 0, err
+	}
+	t := d.Transfers[idx]
+	d.markUsed(idx)
+	d.next = idx + 1
+
+	if ep&usb.EndpointDirectionIn == 0 {
+		recorded := t.Submit.Data
+		switch {
+		case len(data) != int(t.Submit.Length):
+			return 0, d.diverge(idx, got, fmt.Sprintf("wrote %d bytes, recorded %d", len(data), t.Submit.Length))
+		case !bytes.HasPrefix(data, recorded):
+			return 0, d.diverge(idx, got, fmt.Sprintf("wrote % X, recorded % X", data, recorded))
+		}
+	} else if len(data) < len(t.Complete.Data) {
+		return 0, d.diverge(idx, got, fmt.Sprintf("read into %d bytes, recorded %d", len(data), len(t.Complete.Data)))
+	}
+
+	n := t.Actual()
+	if ep&usb.EndpointDirectionIn != 0 {
+		n = copy(data, t.Complete.Data)
+	}
+	if status := t.Status(); status != 0 {
+		return n, syscall.Errno(-status)
+	}
+	return n, nil
+}
+
+// find returns the index of the recorded transfer answering a transfer.
+func (d *Device) find(typ usb.TransferType, ep uint8, setup *usb.SetupPacket, got string) (int, error) {
+	matches := func(t *analyzer.Transfer) bool {
+		if t.Type() != typ {
+			return false
+		}
+		if typ == usb.TransferTypeControl {
+			return t.Setup() != nil && *t.Setup() == *setup
+		}
+		return t.Endpoint() == ep
+	}
+	if d.Mode == InOrder {
+		for d.next < len(d.Transfers) && d.isUsed(d.next) {
+			d.next++
+		}
+		if d.next == len(d.Transfers) {
+			return -1, d.diverge(-1, got, "end of recording")
+		}
+		if !matches(d.Transfers[d.next]) {
+			return -1, d.diverge(d.next, got, "unexpected transfer")
+		}
+		return d.next, nil
+	}
+	reuse := -1
+	for i, t := range d.Transfers {
+		if !matches(t) {
+			continue
+		}
+		if !d.isUsed(i) {
+			return i, nil
+		}
+		reuse = i
+	}
+	if typ == usb.TransferTypeControl && reuse >= 0 {
+		return reuse, nil
+	}
+	if typ == usb.TransferTypeControl {
+		return -1, d.diverge(-1, got, "setup packet not recorded")
+	}
+	return -1, d.diverge(-1, got, "no more transfers recorded on the endpoint")
+}
+
+func (d *Device) diverge(idx int, got, reason string) error {
+	res := &Divergence{Index: idx, Got: got, Reason: reason}
+	if idx >= 0 {
+		res.Expected = d.Transfers[idx]
+	}
+	d.divergences = append(d.divergences, res)
+	return res
+}
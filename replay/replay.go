@@ -0,0 +1,235 @@
+// Package replay turns a capture of a device session into a virtual device answering from the recording,
+// so drivers can be tested against the behaviour of a real device without the device attached.
+//
+// Control transfers are answered with the recorded data stage and status, bulk and interrupt reads
+// with the recorded data. Transfers that differ from the recording, such as a different setup
+// packet or different data written to an OUT endpoint, are divergences: the transfer fails with a
+// *Divergence and the divergence is kept for inspection with Divergences.
+//
+//	rec, _ := replay.Load("session.pcapng", 1, 5)
+//	dev := rec.USB()
+//	dev.Open()
+//	runDriver(dev)
+//	if err := rec.Err(); err != nil { ... }
+//
+// Captures of the usbmon text interface truncate the data of transfers, binary or pcap captures should be used.
+package replay
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/analyzer"
+	"github.com/daedaluz/gousb/usbmon"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Mode selects how transfers are matched with the recording.
+type Mode int
+
+const (
+	// InOrder expects the transfers in the order they were submitted in the recording.
+	InOrder = Mode(iota)
+	// Matched answers control transfers from a recorded transfer with the same setup packet, preferring
+	// transfers that were not replayed yet, and bulk and interrupt transfers from the recorded transfers
+	// of the endpoint in order.
+	Matched
+)
+
+// Divergence is a transfer that differs from the recording.
+type Divergence struct {
+	// Index is the index of the recorded transfer in Device.Transfers, -1 if no transfer was found.
+	Index    int
+	Expected *analyzer.Transfer
+	// Got describes the transfer that was made, eg. "CONTROL 80 06 0100 0000 0012".
+	Got    string
+	Reason string
+}
+
+func (d *Divergence) Error() string {
+	if d.Expected == nil {
+		return fmt.Sprintf("replay: %s: %s", d.Got, d.Reason)
+	}
+	return fmt.Sprintf("replay: %s: %s, recorded transfer %d is %s", d.Got, d.Reason, d.Index, d.Expected.Description)
+}
+
+// Device is a recorded device session. The exported fields must not be changed while the device is open.
+type Device struct {
+	Name         string
+	BusNumber    int
+	DeviceNumber int
+	Mode         Mode
+
+	// Transfers are the recorded transfers in the order they were submitted.
+	Transfers []*analyzer.Transfer
+	// State holds the descriptors seen in the recording, used for the sysfs attributes. It may be nil.
+	State *analyzer.DeviceState
+
+	lock sync.Mutex
+	next int
+	// used marks the replayed transfers, it grows with Transfers, see markUsed.
+	used        []bool
+	divergences []*Divergence
+}
+
+// New returns a device replaying transfers in order.
+func New(transfers []*analyzer.Transfer, state *analyzer.DeviceState) *Device {
+	return &Device{
+		Name:         "replay",
+		BusNumber:    1,
+		DeviceNumber: 1,
+		Transfers:    transfers,
+		State:        state,
+	}
+}
+
+// Read reads the recorded transfers of a device until io.EOF.
+// The bus and device number of the returned device are the ones of the recording.
+func Read(r usbmon.Reader, bus, devnum int) (*Device, error) {
+	var transfers []*analyzer.Transfer
+	a := analyzer.New()
+	err := a.Run(usbmon.FilterDevice(r, bus, devnum), func(t *analyzer.Transfer) {
+		transfers = append(transfers, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// transfers complete out of order when several are pending, replay them in the order of submission
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].Submit.Timestamp.Before(transfers[j].Submit.Timestamp)
+	})
+	res := New(transfers, a.Device(uint16(bus), uint8(devnum)))
+	res.BusNumber = bus
+	res.DeviceNumber = devnum
+	return res, nil
+}
+
+// Load reads the recorded transfers of a device from a capture file in any format usbmon.Open accepts.
+func Load(path string, bus, devnum int) (*Device, error) {
+	r, closer, err := usbmon.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	res, err := Read(r, bus, devnum)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(res.Transfers) == 0 {
+		return nil, fmt.Errorf("%s: no transfers of device %d:%.3d", path, bus, devnum)
+	}
+	return res, nil
+}
+
+// USB returns a closed usb.Device backed by the recording.
+func (d *Device) USB() *usb.Device {
+	return usb.NewVirtualDevice(d.Name, d.BusNumber, d.DeviceNumber, func() (usb.Backend, error) {
+		return &backend{dev: d}, nil
+	}, d.sysfs)
+}
+
+// Divergences returns the divergences since the device was created or rewound.
+func (d *Device) Divergences() []*Divergence {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]*Divergence{}, d.divergences...)
+}
+
+// Err returns the first divergence, nil if the transfers matched the recording.
+func (d *Device) Err() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.divergences) == 0 {
+		return nil
+	}
+	return d.divergences[0]
+}
+
+// Remaining returns the recorded transfers that were not replayed.
+func (d *Device) Remaining() []*analyzer.Transfer {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var res []*analyzer.Transfer
+	for i, t := range d.Transfers {
+		if !d.isUsed(i) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Rewind restarts the replay from the beginning of the recording and forgets the divergences.
+func (d *Device) Rewind() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.next = 0
+	d.used = nil
+	d.divergences = nil
+}
+
+// isUsed reports whether the transfer at index i was replayed.
+func (d *Device) isUsed(i int) bool {
+	return i < len(d.used) && d.used[i]
+}
+
+// markUsed marks the transfer at index i as replayed. used is sized here rather than by New,
+// so a Device created as a struct literal or with transfers appended after New works.
+func (d *Device) markUsed(i int) {
+	for len(d.used) <= i {
+		d.used = append(d.used, false)
+	}
+	d.used[i] = true
+}
+
+// sysfs provides the attributes a real device has in sysfs from the descriptors seen in the recording.
+func (d *Device) sysfs(attr string) ([]byte, error) {
+	state := d.State
+	if state == nil || state.Device == nil {
+		return nil, fmt.Errorf("%s: no sysfs attribute %s", d.Name, attr)
+	}
+	dev := state.Device
+	var value string
+	switch attr {
+	case "descriptors":
+		data, err := dev.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]int, 0, len(state.Configurations))
+		for value := range state.Configurations {
+			values = append(values, int(value))
+		}
+		sort.Ints(values)
+		for _, value := range values {
+			cfgData, err := state.Configurations[uint8(value)].MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, cfgData...)
+		}
+		return data, nil
+	case "busnum":
+		value = strconv.Itoa(d.BusNumber)
+	case "devnum":
+		value = strconv.Itoa(d.DeviceNumber)
+	case "idVendor":
+		value = fmt.Sprintf("%.4x", dev.IDVendor)
+	case "idProduct":
+		value = fmt.Sprintf("%.4x", dev.IDProduct)
+	case "bcdDevice":
+		value = fmt.Sprintf("%.4x", dev.BcdDevice)
+	case "bNumConfigurations":
+		value = strconv.Itoa(int(dev.BNumConfigurations))
+	case "manufacturer":
+		value = state.Strings[dev.IManufacturer]
+	case "product":
+		value = state.Strings[dev.IProduct]
+	case "serial":
+		value = state.Strings[dev.ISerialNumber]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%s: no sysfs attribute %s", d.Name, attr)
+	}
+	return []byte(value + "\n"), nil
+}
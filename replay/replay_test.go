@@ -0,0 +1,175 @@
+package replay
+
+import (
+	"bytes"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/pcap"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+func loopback(t *testing.T) *sim.Device {
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0110, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, IProduct: 1, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0xC0, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x01, BmAttributes: 2, WMaxPacketSize: 64}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Strings = map[uint8]string{1: "Loopback"}
+	var pending []byte
+	res.Bulk = func(ep uint8, data []byte) (int, error) {
+		if ep&0x80 == 0 {
+			pending = append(pending[:0], data...)
+			return len(data), nil
+		}
+		return copy(data, pending), nil
+	}
+	return res
+}
+
+// session is the driver under test.
+func session(dev *usb.Device, message string) error {
+	if err := dev.Open(); err != nil {
+		return err
+	}
+	defer dev.Close()
+	if _, err := dev.GetDescriptorSet(); err != nil {
+		return err
+	}
+	if _, err := dev.GetProduct(); err != nil {
+		return err
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		return err
+	}
+	if _, err := dev.Bulk(0x01, []byte(message)); err != nil {
+		return err
+	}
+	buf := make([]byte, 64)
+	n, err := dev.Bulk(0x81, buf)
+	if err != nil {
+		return err
+	}
+	if string(buf[:n]) != message {
+		return syscall.EIO
+	}
+	if _, err := dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|usb.RequestRecipientDevice, 1, 0, 0, buf[:4]); err != syscall.EPIPE {
+		return syscall.EIO
+	}
+	return nil
+}
+
+func record(t *testing.T) *Device {
+	buf := &bytes.Buffer{}
+	w, err := pcap.NewNGWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := loopback(t).USB()
+	dev.SetCapture(w)
+	if err := session(dev, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := pcap.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Read(r, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestReplay(t *testing.T) {
+	rec := record(t)
+	dev := rec.USB()
+	if err := session(dev, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := rec.Remaining(); len(remaining) != 0 {
+		t.Fatalf("%d transfers not replayed, first %s", len(remaining), remaining[0])
+	}
+	product, err := dev.ReadSysfsString("product")
+	if err != nil || product != "Loopback" {
+		t.Fatalf("sysfs product = %q, %v", product, err)
+	}
+
+	rec.Rewind()
+	err = session(rec.USB(), "pong")
+	div, ok := err.(*Divergence)
+	if !ok {
+		t.Fatalf("expected a divergence, got %v", err)
+	}
+	if div.Expected == nil || div.Expected.Description != "BULK OUT 0x01 len 4" || div.Got != "BULK OUT 0x01 len 4" {
+		t.Fatalf("unexpected divergence %v", div)
+	}
+	if len(rec.Divergences()) != 1 || rec.Err() != div {
+		t.Fatalf("divergences = %v", rec.Divergences())
+	}
+}
+
+func TestMatched(t *testing.T) {
+	rec := record(t)
+	dev := rec.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+
+	if err := dev.SetConfiguration(1); err == nil {
+		t.Fatal("expected a divergence for an out of order request")
+	}
+	rec.Rewind()
+	rec.Mode = Matched
+	for i := 0; i < 2; i++ {
+		desc, err := dev.GetDeviceDescriptor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.IDVendor != 0x1209 {
+			t.Fatalf("vendor = %.4x", desc.IDVendor)
+		}
+	}
+	if _, err := dev.Bulk(0x81, make([]byte, 64)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Bulk(0x81, make([]byte, 64)); err == nil {
+		t.Fatal("expected a divergence when the recorded reads are exhausted")
+	}
+	if err := dev.SetConfiguration(2); err == nil {
+		t.Fatal("expected a divergence for a request that was not recorded")
+	}
+	if n := len(rec.Divergences()); n != 2 {
+		t.Fatalf("%d divergences, expected 2", n)
+	}
+}
+
+func TestUnallocated(t *testing.T) {
+	rec := record(t)
+	literal := &Device{Name: "literal", BusNumber: 1, DeviceNumber: 1, Transfers: rec.Transfers, State: rec.State}
+	if err := session(literal.USB(), "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := literal.Remaining(); len(remaining) != 0 || literal.Err() != nil {
+		t.Fatalf("%d transfers not replayed, %v", len(remaining), literal.Err())
+	}
+
+	appended := New(nil, rec.State)
+	appended.Transfers = append(appended.Transfers, rec.Transfers...)
+	if remaining := appended.Remaining(); len(remaining) != len(rec.Transfers) {
+		t.Fatalf("%d transfers remaining before the replay, expected %d", len(remaining), len(rec.Transfers))
+	}
+	if err := session(appended.USB(), "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := appended.Remaining(); len(remaining) != 0 {
+		t.Fatalf("%d transfers not replayed", len(remaining))
+	}
+}
@@ -0,0 +1,289 @@
+package sim
+
+import (
+	"encoding/binary"
+	usb "github.com/daedaluz/gousb"
+	"syscall"
+)
+
+// backend is the usb.Backend of an open simulated device.
+type backend struct {
+	dev *Device
+}
+
+func (b *backend) Control(setup usb.SetupPacket, data []byte, timeout uint32) (int, error) {
+	if int(setup.Length) > len(data) {
+		return 0, syscall.EINVAL
+	}
+	data = data[:setup.Length]
+	if setup.Type() == usb.RequestTypeStandard {
+		b.dev.lock.Lock()
+		reply, handled, err := b.dev.standard(setup, data)
+		b.dev.lock.Unlock()
+		if handled {
+			if err != nil {
+				return 0, err
+			}
+			return copy(data, reply), nil
+		}
+	}
+	if b.dev.Control == nil {
+		return 0, syscall.EPIPE
+	}
+	return b.dev.Control(setup, data)
+}
+
+func (b *backend) transfer(ep uint8, data []byte, transferType usb.TransferType, handler func(uint8, []byte) (int, error)) (int, error) {
+	b.dev.lock.Lock()
+	endpoint := b.dev.endpoint(ep)
+	halted := b.dev.halted[ep]
+	b.dev.lock.Unlock()
+	switch {
+	case endpoint == nil || endpoint.TransferType() != transferType:
+		return 0, syscall.ENOENT
+	case halted || handler == nil:
+		return 0, syscall.EPIPE
+	}
+	return handler(ep, data)
+}
+
+func (b *backend) Bulk(ep uint8, data []byte, timeout uint32) (int, error) {
+	return b.transfer(ep, data, usb.TransferTypeBulk, b.dev.Bulk)
+}
+
+func (b *backend) Interrupt(ep uint8, data []byte, timeout uint32) (int, error) {
+	return b.transfer(ep, data, usb.TransferTypeInterrupt, b.dev.Interrupt)
+}
+
+func (b *backend) ClaimInterface(iface uint8) error {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	cfg := b.dev.activeConfiguration()
+	if cfg == nil || cfg.Interface(iface) == nil {
+		return syscall.ENOENT
+	}
+	if b.dev.claimed[iface] || b.dev.driver(iface) != "" {
+		return syscall.EBUSY
+	}
+	if b.dev.claimed == nil {
+		b.dev.claimed = make(map[uint8]bool)
+	}
+	b.dev.claimed[iface] = true
+	return nil
+}
+
+func (b *backend) ReleaseInterface(iface uint8) error {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	if !b.dev.claimed[iface] {
+		return syscall.EINVAL
+	}
+	delete(b.dev.claimed, iface)
+	return nil
+}
+
+// GetDriver returns ENODATA for an interface without kernel driver, like usbfs.
+func (b *backend) GetDriver(iface uint32) (string, error) {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	if driver := b.dev.driver(uint8(iface)); driver != "" {
+		return driver, nil
+	}
+	return "", syscall.ENODATA
+}
+
+func (b *backend) DetachKernel(iface uint32) error {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	if b.dev.driver(uint8(iface)) == "" {
+		return syscall.ENODATA
+	}
+	if b.dev.detached == nil {
+		b.dev.detached = make(map[uint8]bool)
+	}
+	b.dev.detached[uint8(iface)] = true
+	return nil
+}
+
+// AttachKernel rebinds a detached driver, it does nothing for an interface that never had one.
+func (b *backend) AttachKernel(iface uint32) error {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	if b.dev.claimed[uint8(iface)] {
+		return syscall.EBUSY
+	}
+	delete(b.dev.detached, uint8(iface))
+	return nil
+}
+
+// Reset returns to the configured state with default alternate settings like the kernel does
+// after a port reset, then calls the Reset handler.
+func (b *backend) Reset() error {
+	b.dev.lock.Lock()
+	b.dev.remoteWakeup = false
+	b.dev.setConfiguration(b.dev.configuration)
+	b.dev.lock.Unlock()
+	if b.dev.Reset != nil {
+		return b.dev.Reset()
+	}
+	return nil
+}
+
+func (b *backend) Close() error {
+	b.dev.lock.Lock()
+	defer b.dev.lock.Unlock()
+	b.dev.claimed = nil
+	return nil
+}
+
+// standard answers the standard requests, handled is false for requests passed to the Control handler.
+// The device lock is held.
+func (d *Device) standard(setup usb.SetupPacket, data []byte) (reply []byte, handled bool, err error) {
+	recipient := setup.Recipient()
+	switch {
+	case setup.Request == usb.ReqGetDescriptor && recipient == usb.RequestRecipientDevice && setup.In():
+		reply, err := d.descriptor(usb.DescriptorType(setup.Value>>8), uint8(setup.Value), setup.Index)
+		if err != nil {
+			return nil, false, nil
+		}
+		return reply, true, nil
+	case setup.Request == usb.ReqGetStatus && setup.In():
+		return d.getStatus(recipient, setup.Value, setup.Index)
+	case setup.Request == usb.ReqSetFeature || setup.Request == usb.ReqClearFeature:
+		return nil, true, d.setFeature(recipient, usb.Feature(setup.Value), setup.Index, setup.Request == usb.ReqSetFeature)
+	case recipient != usb.RequestRecipientDevice && recipient != usb.RequestRecipientInterface:
+		return nil, false, nil
+	}
+	switch setup.Request {
+	case usb.ReqSetAddress:
+		d.address = uint8(setup.Value)
+		return nil, true, nil
+	case usb.ReqGetConfiguration:
+		return []byte{d.configuration}, true, nil
+	case usb.ReqSetConfiguration:
+		return nil, true, d.setConfiguration(uint8(setup.Value))
+	case usb.ReqGetInterface, usb.ReqSetInterface:
+		cfg := d.activeConfiguration()
+		if cfg == nil {
+			return nil, true, syscall.EPIPE
+		}
+		iface := cfg.Interface(uint8(setup.Index))
+		if iface == nil {
+			return nil, true, syscall.EPIPE
+		}
+		if setup.Request == usb.ReqGetInterface {
+			return []byte{d.altSettings[iface.Number]}, true, nil
+		}
+		alt := iface.AltSetting(uint8(setup.Value))
+		if alt == nil {
+			return nil, true, syscall.EPIPE
+		}
+		for _, ep := range alt.Endpoints {
+			delete(d.halted, ep.BEndpointAddress)
+		}
+		d.altSettings[iface.Number] = alt.BAlternateSetting
+		return nil, true, nil
+	}
+	return nil, false, nil
+}
+
+func (d *Device) getStatus(recipient usb.RequestType, value, index uint16) ([]byte, bool, error) {
+	if value != uint16(usb.StatusStandard) {
+		return nil, false, nil
+	}
+	status := uint16(0)
+	switch recipient {
+	case usb.RequestRecipientDevice:
+		cfg := d.activeConfiguration()
+		if cfg == nil && len(d.Descriptors.Configurations) > 0 {
+			cfg = d.Descriptors.Configurations[0]
+		}
+		if cfg != nil && cfg.BmAttributes&0x40 != 0 {
+			status |= 1
+		}
+		if d.remoteWakeup {
+			status |= 2
+		}
+	case usb.RequestRecipientInterface:
+		if cfg := d.activeConfiguration(); cfg == nil || cfg.Interface(uint8(index)) == nil {
+			return nil, true, syscall.EPIPE
+		}
+	case usb.RequestRecipientEndpoint:
+		ep := uint8(index)
+		if ep&0x7F != 0 && d.endpoint(ep) == nil {
+			return nil, true, syscall.EPIPE
+		}
+		if d.halted[ep] {
+			status |= 1
+		}
+	default:
+		return nil, false, nil
+	}
+	reply := make([]byte, 2)
+	binary.LittleEndian.PutUint16(reply, status)
+	return reply, true, nil
+}
+
+func (d *Device) setFeature(recipient usb.RequestType, feature usb.Feature, index uint16, set bool) error {
+	switch {
+	case recipient == usb.RequestRecipientDevice && feature == usb.FeatureDeviceRemoteWakeUp:
+		d.remoteWakeup = set
+		return nil
+	case recipient == usb.RequestRecipientEndpoint && feature == usb.FeatureEndpointHalt:
+		ep := uint8(index)
+		if ep&0x7F == 0 {
+			return nil
+		}
+		if d.endpoint(ep) == nil {
+			return syscall.EPIPE
+		}
+		if set {
+			d.halted[ep] = true
+		} else {
+			delete(d.halted, ep)
+		}
+		return nil
+	}
+	return syscall.EPIPE
+}
+
+// descriptor returns a standard descriptor, an error if the device does not have it.
+func (d *Device) descriptor(typ usb.DescriptorType, idx uint8, langID uint16) ([]byte, error) {
+	set := d.Descriptors
+	indexed := func(configs []*usb.Configuration) ([]byte, error) {
+		if int(idx) >= len(configs) {
+			return nil, syscall.EPIPE
+		}
+		return configs[idx].MarshalBinary()
+	}
+	switch typ {
+	case usb.DescriptorTypeDevice:
+		return set.Device.MarshalBinary()
+	case usb.DescriptorTypeConfig:
+		return indexed(set.Configurations)
+	case usb.DescriptorTypeBOS:
+		if set.BOS != nil {
+			return set.BOS.MarshalBinary()
+		}
+	case usb.DescriptorTypeDeviceQualifier:
+		if set.Qualifier != nil {
+			return set.Qualifier.MarshalBinary()
+		}
+	case usb.DescriptorTypeOtherSpeedConfiguration:
+		return indexed(set.OtherSpeedConfigurations)
+	case usb.DescriptorTypeString:
+		if idx == 0 {
+			return usb.NewLanguagesDescriptor(d.languages()...).MarshalBinary()
+		}
+		str, exist := d.Strings[idx]
+		if !exist {
+			return nil, syscall.EPIPE
+		}
+		for _, lang := range d.languages() {
+			if uint16(lang) == langID {
+				return usb.NewStringDescriptor(str).MarshalBinary()
+			}
+		}
+	}
+	return nil, syscall.EPIPE
+}
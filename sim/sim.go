@@ -0,0 +1,251 @@
+// Package sim simulates USB devices, so code built on usb.Device can be tested without hardware.
+//
+// A simulated device is described by its descriptors plus handler funcs. Standard requests are
+// answered from the descriptors and the simulated device state: GET_DESCRIPTOR, GET_STATUS,
+// SET/CLEAR_FEATURE, SET_ADDRESS, GET/SET_CONFIGURATION and GET/SET_INTERFACE.
+// Class and vendor requests, and standard requests the simulation can not answer, are passed to Control.
+//
+//	dev := sim.New(set)
+//	dev.Strings = map[uint8]string{1: "Gadget"}
+//	dev.Control = func(setup usb.SetupPacket, data []byte) (int, error) { ... }
+//	usbDev := dev.USB()
+//	usbDev.Open()
+package sim
+
+import (
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// Device is a simulated device. The exported fields must not be changed while the device is open.
+type Device struct {
+	Name         string
+	BusNumber    int
+	DeviceNumber int
+
+	Descriptors *usb.DescriptorSet
+
+	// Strings are served in every language of Languages, English (US) if nil. A non-nil empty
+	// Languages serves an empty LANGID list.
+	Strings   map[uint8]string
+	Languages []usb.LangID
+
+	// Speed is the sysfs "speed" attribute in Mbit/s, eg. "480".
+	Speed string
+
+	// Control handles control requests not answered by the simulation, the data stage of an IN
+	// request is filled in and its length returned. A nil handler stalls the requests.
+	Control func(setup usb.SetupPacket, data []byte) (int, error)
+
+	// Bulk and Interrupt handle transfers on endpoints of the active alternate settings,
+	// ep is the endpoint address including the direction bit. A nil handler stalls the transfers.
+	Bulk      func(ep uint8, data []byte) (int, error)
+	Interrupt func(ep uint8, data []byte) (int, error)
+
+	// Reset is called after the simulated state has been reset by a port reset.
+	Reset func() error
+
+	// Drivers are the names of the kernel drivers bound to interfaces, "usbfs" for an interface
+	// claimed by another process. An interface with a driver can not be claimed until the driver
+	// is detached.
+	Drivers map[uint8]string
+
+	lock          sync.Mutex
+	address       uint8
+	configuration uint8
+	altSettings   map[uint8]uint8
+	halted        map[uint8]bool
+	claimed       map[uint8]bool
+	detached      map[uint8]bool
+	remoteWakeup  bool
+}
+
+// New returns an unconfigured device with the descriptors of set.
+func New(set *usb.DescriptorSet) *Device {
+	return &Device{
+		Name:         "sim",
+		Descriptors:  set,
+		BusNumber:    1,
+		DeviceNumber: 1,
+	}
+}
+
+// Build returns an unconfigured device with a device descriptor and the configurations of builders,
+// see usb.BuildDescriptorSet.
+func Build(device *usb.DeviceDescriptor, configs ...*usb.ConfigBuilder) (*Device, error) {
+	set, err := usb.BuildDescriptorSet(device, configs...)
+	if err != nil {
+		return nil, err
+	}
+	return New(set), nil
+}
+
+// USB returns a closed usb.Device backed by the simulation.
+func (d *Device) USB() *usb.Device {
+	return usb.NewVirtualDevice(d.Name, d.BusNumber, d.DeviceNumber, func() (usb.Backend, error) {
+		return &backend{dev: d}, nil
+	}, d.sysfs)
+}
+
+// Address returns the address assigned with SET_ADDRESS.
+func (d *Device) Address() uint8 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.address
+}
+
+// Configuration returns the bConfigurationValue of the active configuration, 0 if unconfigured.
+func (d *Device) Configuration() uint8 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.configuration
+}
+
+// AltSetting returns the active alternate setting of an interface.
+func (d *Device) AltSetting(iface uint8) uint8 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.altSettings[iface]
+}
+
+// Halted reports whether an endpoint is halted.
+func (d *Device) Halted(ep uint8) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.halted[ep]
+}
+
+// Claimed reports whether an interface is claimed.
+func (d *Device) Claimed(iface uint8) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.claimed[iface]
+}
+
+// Detached reports whether the kernel driver of an interface is detached.
+func (d *Device) Detached(iface uint8) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.detached[iface]
+}
+
+// driver returns the kernel driver bound to an interface, "" if there is none or it is detached.
+// The device lock is held.
+func (d *Device) driver(iface uint8) string {
+	if d.detached[iface] {
+		return ""
+	}
+	return d.Drivers[iface]
+}
+
+// RemoteWakeup reports whether the host enabled remote wakeup.
+func (d *Device) RemoteWakeup() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.remoteWakeup
+}
+
+func (d *Device) languages() []usb.LangID {
+	if d.Languages == nil {
+		return []usb.LangID{0x0409}
+	}
+	return d.Languages
+}
+
+// activeConfiguration returns the active configuration, nil if unconfigured.
+func (d *Device) activeConfiguration() *usb.Configuration {
+	for _, cfg := range d.Descriptors.Configurations {
+		if d.configuration != 0 && cfg.BConfigurationValue == d.configuration {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// endpoint returns the endpoint of an active alternate setting, nil if there is none.
+func (d *Device) endpoint(address uint8) *usb.Endpoint {
+	cfg := d.activeConfiguration()
+	if cfg == nil {
+		return nil
+	}
+	for _, iface := range cfg.Interfaces {
+		alt := iface.AltSetting(d.altSettings[iface.Number])
+		if alt == nil {
+			continue
+		}
+		for _, ep := range alt.Endpoints {
+			if ep.BEndpointAddress == address {
+				return ep
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Device) setConfiguration(value uint8) error {
+	if value != 0 {
+		found := false
+		for _, cfg := range d.Descriptors.Configurations {
+			found = found || cfg.BConfigurationValue == value
+		}
+		if !found {
+			return syscall.EPIPE
+		}
+	}
+	d.configuration = value
+	d.altSettings = make(map[uint8]uint8)
+	d.halted = make(map[uint8]bool)
+	return nil
+}
+
+// sysfs provides the attributes a real device has in sysfs.
+func (d *Device) sysfs(attr string) ([]byte, error) {
+	dev := d.Descriptors.Device
+	var value string
+	switch attr {
+	case "descriptors":
+		data, err := dev.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		for _, cfg := range d.Descriptors.Configurations {
+			cfgData, err := cfg.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, cfgData...)
+		}
+		return data, nil
+	case "busnum":
+		value = strconv.Itoa(d.BusNumber)
+	case "devnum":
+		value = strconv.Itoa(d.DeviceNumber)
+	case "idVendor":
+		value = fmt.Sprintf("%.4x", dev.IDVendor)
+	case "idProduct":
+		value = fmt.Sprintf("%.4x", dev.IDProduct)
+	case "bcdDevice":
+		value = fmt.Sprintf("%.4x", dev.BcdDevice)
+	case "bNumConfigurations":
+		value = strconv.Itoa(int(dev.BNumConfigurations))
+	case "bConfigurationValue":
+		if cfg := d.Configuration(); cfg != 0 {
+			value = strconv.Itoa(int(cfg))
+		}
+	case "speed":
+		value = d.Speed
+	case "manufacturer":
+		value = d.Strings[dev.IManufacturer]
+	case "product":
+		value = d.Strings[dev.IProduct]
+	case "serial":
+		value = d.Strings[dev.ISerialNumber]
+	}
+	if value == "" && attr != "bConfigurationValue" {
+		return nil, fmt.Errorf("%s: no sysfs attribute %s", d.Name, attr)
+	}
+	return []byte(value + "\n"), nil
+}
@@ -0,0 +1,160 @@
+package sim
+
+import (
+	"bytes"
+	usb "github.com/daedaluz/gousb"
+	"syscall"
+	"testing"
+)
+
+func testDevice(t *testing.T) *Device {
+	res, err := Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, IProduct: 1, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0xC0, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 2, WMaxPacketSize: 64}).
+			Endpoint(&usb.EndpointDescriptor{BEndpointAddress: 0x01, BmAttributes: 2, WMaxPacketSize: 64}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Strings = map[uint8]string{1: "Loopback"}
+	return res
+}
+
+func TestStandardRequests(t *testing.T) {
+	sim := testDevice(t)
+	dev := sim.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+
+	set, err := dev.GetDescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Device.IDVendor != 0x1209 || len(set.Configurations) != 1 {
+		t.Fatalf("unexpected descriptor set %+v", set)
+	}
+	if product, err := dev.GetProduct(); err != nil || product != "Loopback" {
+		t.Fatalf("product = %q, %v", product, err)
+	}
+	if _, err := dev.Bulk(0x81, make([]byte, 64)); err != syscall.ENOENT {
+		t.Fatalf("bulk while unconfigured: expected ENOENT, got %v", err)
+	}
+	if err := dev.SetConfiguration(2); err != syscall.EPIPE {
+		t.Fatalf("invalid configuration: expected EPIPE, got %v", err)
+	}
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := dev.GetConfiguration(); err != nil || cfg != 1 {
+		t.Fatalf("configuration = %d, %v", cfg, err)
+	}
+	status, err := dev.GetDeviceStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.SelfPowered {
+		t.Fatal("expected self powered status")
+	}
+
+	if err := dev.SetFeature(usb.RequestRecipientEndpoint, usb.FeatureEndpointHalt, 0, 0x81); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Bulk(0x81, make([]byte, 64)); err != syscall.EPIPE {
+		t.Fatalf("bulk on halted endpoint: expected EPIPE, got %v", err)
+	}
+	if err := dev.ClearFeature(usb.RequestRecipientEndpoint, usb.FeatureEndpointHalt, 0x81); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Halted(0x81) {
+		t.Fatal("endpoint still halted")
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	sim := testDevice(t)
+	var pending []byte
+	sim.Bulk = func(ep uint8, data []byte) (int, error) {
+		if ep&0x80 == 0 {
+			pending = append(pending[:0], data...)
+			return len(data), nil
+		}
+		return copy(data, pending), nil
+	}
+	sim.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Type() == usb.RequestTypeVendor && setup.Request == 1 && setup.In() {
+			return copy(data, "vendor"), nil
+		}
+		return 0, syscall.EPIPE
+	}
+	dev := sim.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.ClaimInterface(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Bulk(0x01, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	n, err := dev.Bulk(0x81, buf)
+	if err != nil || !bytes.Equal(buf[:n], []byte("ping")) {
+		t.Fatalf("loopback = %q, %v", buf[:n], err)
+	}
+	n, err = dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|usb.RequestRecipientDevice, 1, 0, 0, buf)
+	if err != nil || string(buf[:n]) != "vendor" {
+		t.Fatalf("vendor request = %q, %v", buf[:n], err)
+	}
+	if _, err := dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|usb.RequestRecipientDevice, 2, 0, 0, buf); err != syscall.EPIPE {
+		t.Fatalf("unhandled vendor request: expected EPIPE, got %v", err)
+	}
+}
+
+func TestDrivers(t *testing.T) {
+	sim := testDevice(t)
+	sim.Drivers = map[uint8]string{0: "cdc_acm"}
+	dev := sim.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if err := dev.SetConfiguration(1); err != nil {
+		t.Fatal(err)
+	}
+	if driver, err := dev.GetDriver(0); err != nil || driver != "cdc_acm" {
+		t.Fatalf("driver = %q, %v", driver, err)
+	}
+	if err := dev.ClaimInterface(0); err != syscall.EBUSY {
+		t.Fatalf("expected EBUSY while the driver is bound, got %v", err)
+	}
+	if err := dev.DetachKernel(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.GetDriver(0); err != syscall.ENODATA || !sim.Detached(0) {
+		t.Fatalf("expected ENODATA after detach, got %v", err)
+	}
+	if err := dev.DetachKernel(0); err != syscall.ENODATA {
+		t.Fatalf("expected ENODATA detaching twice, got %v", err)
+	}
+	if err := dev.ClaimInterface(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.AttachKernel(0); err != syscall.EBUSY {
+		t.Fatalf("expected EBUSY attaching a claimed interface, got %v", err)
+	}
+	if err := dev.ReleaseInterface(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.AttachKernel(0); err != nil || sim.Detached(0) {
+		t.Fatalf("attach = %v", err)
+	}
+	if driver, err := dev.GetDriver(0); err != nil || driver != "cdc_acm" {
+		t.Fatalf("driver after attach = %q, %v", driver, err)
+	}
+}
@@ -0,0 +1,389 @@
+package usb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// SnapshotVersion is the version of the snapshot document written by Device.Snapshot.
+const SnapshotVersion = 1
+
+// ErrReadOnly is returned by operations a snapshot device can not perform.
+var ErrReadOnly = errors.New("device is a read-only snapshot")
+
+// snapshotSysfsAttributes are the sysfs attributes captured by Device.Snapshot.
+var snapshotSysfsAttributes = []string{
+	"authorized", "avoid_reset_quirk", "bConfigurationValue", "bDeviceClass", "bDeviceProtocol",
+	"bDeviceSubClass", "bMaxPacketSize0", "bMaxPower", "bNumConfigurations", "bNumInterfaces",
+	"bcdDevice", "bmAttributes", "busnum", "configuration", "devnum", "devpath", "idProduct",
+	"idVendor", "ltm_capable", "manufacturer", "maxchild", "product", "quirks", "removable",
+	"rx_lanes", "serial", "speed", "tx_lanes", "version",
+}
+
+// HexBytes is a byte slice encoded as a hex string in JSON.
+type HexBytes []byte
+
+func (h HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+func (h *HexBytes) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = data
+	return nil
+}
+
+// SnapshotString is a string descriptor in one language.
+type SnapshotString struct {
+	Index  uint8  `json:"index"`
+	LangID LangID `json:"langid"`
+	Value  string `json:"value"`
+}
+
+// Snapshot is everything needed to reproduce the descriptors, strings and status of a device
+// without the hardware. Descriptors are kept as the raw bytes returned by the device.
+type Snapshot struct {
+	Version      int    `json:"version"`
+	Name         string `json:"name"`
+	BusNumber    int    `json:"bus"`
+	DeviceNumber int    `json:"devnum"`
+
+	Device                   HexBytes   `json:"device"`
+	Configurations           []HexBytes `json:"configurations"`
+	BOS                      HexBytes   `json:"bos,omitempty"`
+	Qualifier                HexBytes   `json:"qualifier,omitempty"`
+	OtherSpeedConfigurations []HexBytes `json:"other_speed_configurations,omitempty"`
+
+	Languages []LangID         `json:"languages,omitempty"`
+	Strings   []SnapshotString `json:"strings,omitempty"`
+
+	// Status is the GET_STATUS response of the device, nil if it could not be read.
+	Status *uint16 `json:"status,omitempty"`
+	// Configuration is the GET_CONFIGURATION response of the device, nil if it could not be read.
+	Configuration *uint8 `json:"configuration,omitempty"`
+
+	Sysfs map[string]string `json:"sysfs,omitempty"`
+}
+
+// Snapshot captures the device. An open device is queried for all configurations, BOS, device
+// qualifier, strings in all languages and status. A closed device only provides what sysfs exposes.
+func (d *Device) Snapshot() (*Snapshot, error) {
+	res := &Snapshot{
+		Version:      SnapshotVersion,
+		Name:         d.Name,
+		BusNumber:    d.BusNumber,
+		DeviceNumber: d.DeviceNumber,
+		Sysfs:        make(map[string]string),
+	}
+	for _, attr := range snapshotSysfsAttributes {
+		if value, err := d.ReadSysfsString(attr); err == nil {
+			res.Sysfs[attr] = value
+		}
+	}
+	if !d.IsOpen() {
+		data, err := d.readSysfsFile("descriptors")
+		if err != nil {
+			return nil, err
+		}
+		if err := res.splitDescriptors(data); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if err := res.read(d); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *Snapshot) read(d *Device) error {
+	data, err := d.GetDescriptor(DescriptorTypeDevice, 0, 0)
+	if err != nil {
+		return err
+	}
+	s.Device = data
+	dev, err := ParseDescriptor(data)
+	if err != nil {
+		return err
+	}
+	devDesc, ok := dev.(*DeviceDescriptor)
+	if !ok {
+		return fmt.Errorf("expected device descriptor, got %v", dev.Type())
+	}
+	for idx := uint8(0); idx < devDesc.BNumConfigurations; idx++ {
+		cfg, err := d.getTotalDescriptor(DescriptorTypeConfig, idx, 9)
+		if err != nil {
+			return fmt.Errorf("configuration %d: %w", idx, err)
+		}
+		s.Configurations = append(s.Configurations, cfg)
+	}
+	if devDesc.BcdUSB >= 0x0201 {
+		if bos, err := d.getTotalDescriptor(DescriptorTypeBOS, 0, 5); err == nil {
+			s.BOS = bos
+		}
+	}
+	if devDesc.BcdUSB >= 0x0200 && devDesc.BcdUSB < 0x0300 {
+		if qualifier, err := d.GetDescriptor(DescriptorTypeDeviceQualifier, 0, 0); err == nil && len(qualifier) >= 10 {
+			s.Qualifier = qualifier
+			for idx := uint8(0); idx < qualifier[8]; idx++ {
+				cfg, err := d.getTotalDescriptor(DescriptorTypeOtherSpeedConfiguration, idx, 9)
+				if err != nil {
+					return fmt.Errorf("other speed configuration %d: %w", idx, err)
+				}
+				s.OtherSpeedConfigurations = append(s.OtherSpeedConfigurations, cfg)
+			}
+		}
+	}
+	if languages, err := d.GetLanguages(); err == nil {
+		s.Languages = languages
+		set, err := s.DescriptorSet()
+		if err != nil {
+			return err
+		}
+		for _, lang := range languages {
+			for _, idx := range set.StringIndexes() {
+				if value, err := d.GetStringDescriptor(idx, uint16(lang)); err == nil {
+					s.Strings = append(s.Strings, SnapshotString{Index: idx, LangID: lang, Value: value})
+				}
+			}
+		}
+	}
+	if status, err := d.getStatus(); err == nil {
+		s.Status = &status
+	}
+	if cfg, err := d.GetConfiguration(); err == nil {
+		value := uint8(cfg)
+		s.Configuration = &value
+	}
+	return nil
+}
+
+// getStatus returns the raw GET_STATUS response of the device.
+func (d *Device) getStatus() (uint16, error) {
+	data := make([]byte, 2)
+	_, err := d.Ctrl(RequestDirectionIn|RequestTypeStandard|RequestRecipientDevice,
+		ReqGetStatus, uint16(StatusStandard), 0, data)
+	return binary.LittleEndian.Uint16(data), err
+}
+
+// splitDescriptors splits the sysfs "descriptors" attribute in the device descriptor and configurations.
+func (s *Snapshot) splitDescriptors(data []byte) error {
+	if len(data) < 2 || int(data[0]) > len(data) {
+		return fmt.Errorf("truncated device descriptor")
+	}
+	s.Device = data[:data[0]]
+	for data = data[data[0]:]; len(data) > 0; {
+		if len(data) < 4 {
+			return fmt.Errorf("truncated configuration")
+		}
+		length := int(binary.LittleEndian.Uint16(data[2:]))
+		if length < 4 || length > len(data) {
+			return fmt.Errorf("invalid configuration length %d, %d bytes left", length, len(data))
+		}
+		s.Configurations = append(s.Configurations, data[:length])
+		data = data[length:]
+	}
+	return nil
+}
+
+// sysfsDescriptors returns the content of the sysfs "descriptors" attribute.
+func (s *Snapshot) sysfsDescriptors() []byte {
+	res := append([]byte{}, s.Device...)
+	for _, cfg := range s.Configurations {
+		res = append(res, cfg...)
+	}
+	return res
+}
+
+// DescriptorSet parses the descriptors of the snapshot.
+func (s *Snapshot) DescriptorSet() (*DescriptorSet, error) {
+	data := s.sysfsDescriptors()
+	data = append(data, s.BOS...)
+	set, err := ParseDescriptorSet(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Qualifier) > 0 {
+		desc, err := ParseDescriptor(s.Qualifier)
+		if err != nil {
+			return nil, err
+		}
+		if qualifier, ok := desc.(*DeviceQualifierDescriptor); ok {
+			set.Qualifier = qualifier
+		}
+		for _, data := range s.OtherSpeedConfigurations {
+			cfg, err := ParseConfiguration(data)
+			if err != nil {
+				return nil, err
+			}
+			set.OtherSpeedConfigurations = append(set.OtherSpeedConfigurations, cfg)
+		}
+	}
+	return set, nil
+}
+
+// Write writes the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// ReadSnapshot reads a snapshot document, rejecting versions newer than SnapshotVersion.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	res := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(res); err != nil {
+		return nil, err
+	}
+	if res.Version < 1 || res.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", res.Version)
+	}
+	if len(res.Device) == 0 {
+		return nil, fmt.Errorf("snapshot has no device descriptor")
+	}
+	return res, nil
+}
+
+// LoadSnapshot reads a snapshot document and returns it as a read-only device.
+func LoadSnapshot(r io.Reader) (*Device, error) {
+	snapshot, err := ReadSnapshot(r)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.NewDevice(), nil
+}
+
+// LoadSnapshotFile is LoadSnapshot reading from a file.
+func LoadSnapshotFile(path string) (*Device, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSnapshot(file)
+}
+
+// NewDevice returns a read-only device answering standard GET requests and sysfs reads from the snapshot.
+// Transfers changing the device state fail with ErrReadOnly.
+// Use AddVirtualDevice to make the device visible to EnumerateDevices.
+func (s *Snapshot) NewDevice() *Device {
+	return NewVirtualDevice(s.Name, s.BusNumber, s.DeviceNumber, func() (Backend, error) {
+		return &snapshotBackend{snapshot: s}, nil
+	}, s.sysfsFile)
+}
+
+// snapshotBackend is the Backend of an open snapshot device.
+type snapshotBackend struct {
+	snapshot *Snapshot
+}
+
+func (b *snapshotBackend) Control(setup SetupPacket, data []byte, timeout uint32) (int, error) {
+	return b.snapshot.control(setup, data)
+}
+
+func (b *snapshotBackend) Bulk(ep uint8, data []byte, timeout uint32) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (b *snapshotBackend) Interrupt(ep uint8, data []byte, timeout uint32) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (b *snapshotBackend) ClaimInterface(iface uint8) error {
+	return ErrReadOnly
+}
+
+func (b *snapshotBackend) ReleaseInterface(iface uint8) error {
+	return ErrReadOnly
+}
+
+func (b *snapshotBackend) Reset() error {
+	return ErrReadOnly
+}
+
+func (b *snapshotBackend) Close() error {
+	return nil
+}
+
+// control answers a control request from the snapshot.
+// Requests for data the device did not provide fail with EPIPE, like a stalled request.
+func (s *Snapshot) control(setup SetupPacket, payload []byte) (int, error) {
+	if setup.RequestType != RequestDirectionIn|RequestTypeStandard|RequestRecipientDevice {
+		return 0, ErrReadOnly
+	}
+	var data []byte
+	switch setup.Request {
+	case ReqGetDescriptor:
+		data = s.descriptor(DescriptorType(setup.Value>>8), uint8(setup.Value), setup.Index)
+	case ReqGetStatus:
+		if s.Status != nil && setup.Value == uint16(StatusStandard) {
+			data = []byte{uint8(*s.Status), uint8(*s.Status >> 8)}
+		}
+	case ReqGetConfiguration:
+		if s.Configuration != nil {
+			data = []byte{*s.Configuration}
+		}
+	default:
+		return 0, ErrReadOnly
+	}
+	if data == nil {
+		return 0, syscall.EPIPE
+	}
+	return copy(payload, data), nil
+}
+
+func (s *Snapshot) descriptor(typ DescriptorType, idx uint8, langID uint16) []byte {
+	indexed := func(list []HexBytes) []byte {
+		if int(idx) < len(list) {
+			return list[idx]
+		}
+		return nil
+	}
+	switch typ {
+	case DescriptorTypeDevice:
+		return s.Device
+	case DescriptorTypeConfig:
+		return indexed(s.Configurations)
+	case DescriptorTypeBOS:
+		return s.BOS
+	case DescriptorTypeDeviceQualifier:
+		return s.Qualifier
+	case DescriptorTypeOtherSpeedConfiguration:
+		return indexed(s.OtherSpeedConfigurations)
+	case DescriptorTypeString:
+		if idx == 0 {
+			if len(s.Languages) == 0 {
+				return nil
+			}
+			data, _ := NewLanguagesDescriptor(s.Languages...).MarshalBinary()
+			return data
+		}
+		for _, str := range s.Strings {
+			if str.Index == idx && uint16(str.LangID) == langID {
+				data, _ := NewStringDescriptor(str.Value).MarshalBinary()
+				return data
+			}
+		}
+	}
+	return nil
+}
+
+// sysfsFile returns the content of a sysfs attribute as read from the file.
+func (s *Snapshot) sysfsFile(attr string) ([]byte, error) {
+	if attr == "descriptors" {
+		return s.sysfsDescriptors(), nil
+	}
+	if value, exist := s.Sysfs[attr]; exist {
+		return []byte(value + "\n"), nil
+	}
+	return nil, &os.PathError{Op: "open", Path: formatAttrFileName(s.Name, attr), Err: os.ErrNotExist}
+}
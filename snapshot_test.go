@@ -0,0 +1,93 @@
+package usb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	set, err := BuildDescriptorSet(&DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1d6b, IDProduct: 0x0104, IProduct: 1, BNumConfigurations: 1},
+		NewConfigBuilder(&ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&InterfaceDescriptor{BInterfaceClass: ClassCodeInterfaceHID}).
+			Endpoint(&EndpointDescriptor{BEndpointAddress: 0x81, BmAttributes: 3, WMaxPacketSize: 8, BInterval: 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := set.Device.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := set.Configurations[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := uint16(1)
+	snapshot := &Snapshot{
+		Version:        SnapshotVersion,
+		Name:           "1-1",
+		BusNumber:      1,
+		DeviceNumber:   2,
+		Device:         dev,
+		Configurations: []HexBytes{cfg},
+		Languages:      []LangID{0x0409},
+		Strings:        []SnapshotString{{Index: 1, LangID: 0x0409, Value: "Gadget"}},
+		Status:         &status,
+		Sysfs:          map[string]string{"speed": "12", "idVendor": "1d6b"},
+	}
+	buf := &bytes.Buffer{}
+	if err := snapshot.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	device, err := LoadSnapshot(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, err = device.GetSysfsDescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Configurations) != 1 || set.Device.IDProduct != 0x0104 {
+		t.Fatalf("unexpected sysfs descriptor set %+v", set)
+	}
+	if vendor, err := device.ReadSysfsAttrInt("idVendor", 16, 16); err != nil || vendor != 0x1d6b {
+		t.Fatalf("idVendor = %x, %v", vendor, err)
+	}
+	if _, err := device.ReadSysfsString("serial"); err == nil {
+		t.Fatal("expected missing serial attribute")
+	}
+
+	if err := device.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer device.Close()
+	if str, err := device.GetString(1); err != nil || str != "Gadget" {
+		t.Fatalf("string 1 = %q, %v", str, err)
+	}
+	if _, err := device.GetString(2); err == nil {
+		t.Fatal("expected missing string 2 to fail")
+	}
+	if _, err := device.Bulk(0x01, []byte{0}); err != ErrReadOnly {
+		t.Fatalf("bulk: expected ErrReadOnly, got %v", err)
+	}
+
+	again, err := device.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Configurations[0], cfg) || *again.Status != status || len(again.Strings) != 1 {
+		t.Fatalf("snapshot of snapshot differs: %+v", again)
+	}
+
+	if err := AddVirtualDevice(device); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveVirtualDevice(device)
+	devices, err := EnumerateDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) == 0 || devices[len(devices)-1] != device {
+		t.Fatal("virtual device not enumerated")
+	}
+}
@@ -1,6 +1,9 @@
 package usb
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
 // Standard request codes
 const (
@@ -72,6 +75,26 @@ func (d *Device) ClearFeature(recipient RequestType, feature Feature, idx uint8)
 	return err
 }
 
+// ClearHalt clears the ENDPOINT_HALT feature of an endpoint, resuming transfers after a stall.
+//
+// Backends implementing HostStateBackend, such as usbfs, clear the halt through the host stack,
+// which also resets the data toggle of the host side of the endpoint.
+func (d *Device) ClearHalt(endpoint uint8) error {
+	if host, ok := d.backend.(HostStateBackend); ok {
+		setup := SetupPacket{
+			RequestType: RequestDirectionOut | RequestTypeStandard | RequestRecipientEndpoint,
+			Request:     ReqClearFeature,
+			Value:       uint16(FeatureEndpointHalt),
+			Index:       uint16(endpoint),
+		}
+		_, err := d.captureTransfer(TransferTypeControl, 0, setup, nil, func() (int, error) {
+			return 0, host.ClearHalt(endpoint)
+		})
+		return err
+	}
+	return d.ClearFeature(RequestRecipientEndpoint, FeatureEndpointHalt, endpoint)
+}
+
 // GetConfiguration returns the current device configuration value.
 //
 // if returned value is zero, the device is not configured.
@@ -136,7 +159,13 @@ func (d *Device) GetConfiguration() (int, error) {
 //  Configured state:
 //    This is a valid request when the device is in the configured state.
 func (d *Device) GetDescriptor(descriptorType DescriptorType, idx uint8, languageID uint16) ([]byte, error) {
-	buff := make([]byte, 256)
+	return d.GetDescriptorSize(descriptorType, idx, languageID, 256)
+}
+
+// GetDescriptorSize is GetDescriptor with an explicit request length.
+// Used for descriptors that may be larger than 256 bytes, eg. a complete configuration or BOS.
+func (d *Device) GetDescriptorSize(descriptorType DescriptorType, idx uint8, languageID uint16, size uint16) ([]byte, error) {
+	buff := make([]byte, size)
 	n, err := d.Ctrl(RequestDirectionIn|RequestTypeStandard|RequestRecipientDevice,
 		ReqGetDescriptor, (uint16(descriptorType)<<8)|uint16(idx), languageID, buff)
 	if err != nil {
@@ -349,9 +378,26 @@ func (d *Device) SetAddress(address uint16) error {
 //    If the specified configuration value matches the configuration value from a configuration descriptor, then that
 //    configuration is selected and the device remains in the configured state.
 //    Otherwise, the device responds with a Request Error.
+//
+// Backends implementing HostStateBackend, such as usbfs, select the configuration through the host stack,
+// which also accepts -1 to unconfigure the device. Other backends send the request and only accept 0-255.
 func (d *Device) SetConfiguration(configurationValue int) error {
-	_, err := d.Ctrl(RequestDirectionOut|RequestTypeStandard|RequestRecipientDevice,
-		ReqSetConfiguration, uint16(configurationValue), 0, nil)
+	typ := RequestDirectionOut | RequestTypeStandard | RequestRecipientDevice
+	if host, ok := d.backend.(HostStateBackend); ok {
+		setup := SetupPacket{RequestType: typ, Request: ReqSetConfiguration, Value: uint16(configurationValue)}
+		// The host stack unconfigures the device with SET_CONFIGURATION 0.
+		if configurationValue < 0 {
+			setup.Value = 0
+		}
+		_, err := d.captureTransfer(TransferTypeControl, 0, setup, nil, func() (int, error) {
+			return 0, host.SetConfiguration(configurationValue)
+		})
+		return err
+	}
+	if configurationValue < 0 || configurationValue > 0xFF {
+		return fmt.Errorf("invalid configuration value %d", configurationValue)
+	}
+	_, err := d.Ctrl(typ, ReqSetConfiguration, uint16(configurationValue), 0, nil)
 	return err
 }
 
@@ -521,15 +567,67 @@ func (d *Device) GetDeviceDescriptor() (*DeviceDescriptor, error) {
 	return desc.(*DeviceDescriptor), nil
 }
 
-func (d *Device) GetStringDescriptor(idx uint8, langID uint16) (string, error) {
-	data, err := d.GetDescriptor(DescriptorTypeString, idx, langID)
+// getTotalDescriptor reads a descriptor with a WTotalLength field at offset 2, first
+// reading the header to learn the total length and then the complete descriptor set.
+func (d *Device) getTotalDescriptor(descriptorType DescriptorType, idx uint8, headerSize uint16) ([]byte, error) {
+	data, err := d.GetDescriptorSize(descriptorType, idx, 0, headerSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("short %v: %d bytes", descriptorType, len(data))
+	}
+	totalLength := binary.LittleEndian.Uint16(data[2:])
+	return d.GetDescriptorSize(descriptorType, idx, 0, totalLength)
+}
+
+// GetConfigurationDescriptor returns the complete configuration with the specified index,
+// including all interface, endpoint and class-specific descriptors.
+// Note that idx is the index of the configuration, not the BConfigurationValue.
+func (d *Device) GetConfigurationDescriptor(idx uint8) (*Configuration, error) {
+	data, err := d.getTotalDescriptor(DescriptorTypeConfig, idx, 9)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfiguration(data)
+}
+
+// GetBOSDescriptor returns the BOS descriptor with all device capabilities.
+// Devices with BcdUSB below 0201H are not required to provide a BOS descriptor.
+func (d *Device) GetBOSDescriptor() (*BOS, error) {
+	data, err := d.getTotalDescriptor(DescriptorTypeBOS, 0, 5)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return ParseBOS(data)
+}
+
+// GetDeviceQualifier returns the device qualifier of a high-speed capable device, describing
+// how the device would look operating at its other speed.
+// Full-speed only and Enhanced SuperSpeed devices respond with a request error.
+func (d *Device) GetDeviceQualifier() (*DeviceQualifierDescriptor, error) {
+	data, err := d.GetDescriptor(DescriptorTypeDeviceQualifier, 0, 0)
+	if err != nil {
+		return nil, err
 	}
 	desc, err := ParseDescriptor(data)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	qualifier, ok := desc.(*DeviceQualifierDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("expected device qualifier, got %v", desc.Type())
+	}
+	return qualifier, nil
+}
+
+// GetOtherSpeedConfiguration returns the complete configuration with the specified index as it would
+// look if the device was operating at its other speed.
+// The number of other speed configurations is given by the device qualifier.
+func (d *Device) GetOtherSpeedConfiguration(idx uint8) (*Configuration, error) {
+	data, err := d.getTotalDescriptor(DescriptorTypeOtherSpeedConfiguration, idx, 9)
+	if err != nil {
+		return nil, err
 	}
-	strDesc := desc.(*StringDescriptor)
-	return string(strDesc.Data), nil
+	return ParseConfiguration(data)
 }
@@ -0,0 +1,193 @@
+package usb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+type stringKey struct {
+	idx    uint8
+	langID uint16
+}
+
+// NewStringDescriptor returns a StringDescriptor containing s encoded as UTF-16LE.
+func NewStringDescriptor(s string) *StringDescriptor {
+	codes := utf16.Encode([]rune(s))
+	data := make([]byte, len(codes)*2)
+	for i, code := range codes {
+		binary.LittleEndian.PutUint16(data[i*2:], code)
+	}
+	return &StringDescriptor{
+		DescriptorHeader: DescriptorHeader{
+			Length:         uint8(2 + len(data)),
+			DescriptorType: DescriptorTypeString,
+		},
+		Data: data,
+	}
+}
+
+// NewLanguagesDescriptor returns string descriptor zero listing the specified languages.
+func NewLanguagesDescriptor(languages ...LangID) *StringDescriptor {
+	data := make([]byte, len(languages)*2)
+	for i, lang := range languages {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(lang))
+	}
+	return &StringDescriptor{
+		DescriptorHeader: DescriptorHeader{
+			Length:         uint8(2 + len(data)),
+			DescriptorType: DescriptorTypeString,
+		},
+		Data: data,
+	}
+}
+
+// String decodes the UTF-16LE contents of the descriptor.
+// A trailing odd byte is ignored.
+func (s *StringDescriptor) String() string {
+	codes := make([]uint16, len(s.Data)/2)
+	for i := range codes {
+		codes[i] = binary.LittleEndian.Uint16(s.Data[i*2:])
+	}
+	return string(utf16.Decode(codes))
+}
+
+// Languages interprets the descriptor as string descriptor zero and returns the LANGID array.
+func (s *StringDescriptor) Languages() []LangID {
+	res := make([]LangID, len(s.Data)/2)
+	for i := range res {
+		res[i] = LangID(binary.LittleEndian.Uint16(s.Data[i*2:]))
+	}
+	return res
+}
+
+// GetStringDescriptor returns the decoded string with the specified index and language.
+// Strings are cached per device, see ClearStringCache.
+func (d *Device) GetStringDescriptor(idx uint8, langID uint16) (string, error) {
+	key := stringKey{idx: idx, langID: langID}
+	d.stringLock.Lock()
+	str, cached := d.stringCache[key]
+	d.stringLock.Unlock()
+	if cached {
+		return str, nil
+	}
+	data, err := d.GetDescriptor(DescriptorTypeString, idx, langID)
+	if err != nil {
+		return "", err
+	}
+	desc, err := ParseDescriptor(data)
+	if err != nil {
+		return "", err
+	}
+	strDesc, ok := desc.(*StringDescriptor)
+	if !ok {
+		return "", fmt.Errorf("expected string descriptor, got %v", desc.Type())
+	}
+	str = strDesc.String()
+	d.stringLock.Lock()
+	if d.stringCache == nil {
+		d.stringCache = make(map[stringKey]string)
+	}
+	d.stringCache[key] = str
+	d.stringLock.Unlock()
+	return str, nil
+}
+
+// GetLanguages returns the languages supported by the device, parsed from string descriptor zero.
+// A device without string descriptors returns an empty list. The list is cached per device and
+// a copy is returned.
+func (d *Device) GetLanguages() ([]LangID, error) {
+	d.stringLock.Lock()
+	languages := d.languages
+	d.stringLock.Unlock()
+	if languages != nil {
+		return copyLanguages(languages), nil
+	}
+	data, err := d.GetDescriptor(DescriptorTypeString, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	languages = []LangID{}
+	if len(data) > 0 {
+		desc, err := ParseDescriptor(data)
+		if err != nil {
+			return nil, err
+		}
+		strDesc, ok := desc.(*StringDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("expected string descriptor, got %v", desc.Type())
+		}
+		languages = strDesc.Languages()
+	}
+	d.stringLock.Lock()
+	d.languages = languages
+	d.stringLock.Unlock()
+	return copyLanguages(languages), nil
+}
+
+func copyLanguages(languages []LangID) []LangID {
+	res := make([]LangID, len(languages))
+	copy(res, languages)
+	return res
+}
+
+// ClearStringCache drops all cached strings and languages.
+func (d *Device) ClearStringCache() {
+	d.stringLock.Lock()
+	d.stringCache = nil
+	d.languages = nil
+	d.stringLock.Unlock()
+}
+
+// GetString returns the string with the specified index in the first language reported by the device.
+// An index of zero means no string and returns an empty string.
+func (d *Device) GetString(idx uint8) (string, error) {
+	if idx == 0 {
+		return "", nil
+	}
+	languages, err := d.GetLanguages()
+	if err != nil {
+		return "", err
+	}
+	if len(languages) == 0 {
+		return "", fmt.Errorf("device has no string descriptors")
+	}
+	return d.GetStringDescriptor(idx, uint16(languages[0]))
+}
+
+// GetManufacturer returns the string referenced by IManufacturer in the device descriptor.
+func (d *Device) GetManufacturer() (string, error) {
+	desc, err := d.GetDeviceDescriptor()
+	if err != nil {
+		return "", err
+	}
+	return d.GetString(desc.IManufacturer)
+}
+
+// GetProduct returns the string referenced by IProduct in the device descriptor.
+func (d *Device) GetProduct() (string, error) {
+	desc, err := d.GetDeviceDescriptor()
+	if err != nil {
+		return "", err
+	}
+	return d.GetString(desc.IProduct)
+}
+
+// GetSerialNumber returns the string referenced by ISerialNumber in the device descriptor.
+func (d *Device) GetSerialNumber() (string, error) {
+	desc, err := d.GetDeviceDescriptor()
+	if err != nil {
+		return "", err
+	}
+	return d.GetString(desc.ISerialNumber)
+}
+
+// GetConfigurationString returns the string referenced by IConfiguration.
+func (d *Device) GetConfigurationString(desc *ConfigurationDescriptor) (string, error) {
+	return d.GetString(desc.IConfiguration)
+}
+
+// GetInterfaceString returns the string referenced by IInterface.
+func (d *Device) GetInterfaceString(desc *InterfaceDescriptor) (string, error) {
+	return d.GetString(desc.IInterface)
+}
@@ -0,0 +1,142 @@
+package usb_test
+
+import (
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"syscall"
+	"testing"
+)
+
+// stringDevice returns a device with strings in English and Swedish.
+// String 3 is answered by the Control handler with a raw descriptor.
+func stringDevice(t *testing.T, raw []byte) *sim.Device {
+	simDev, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0200, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0003,
+		IManufacturer: 1, IProduct: 2, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80, BMaxPower: 50}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	simDev.Strings = map[uint8]string{1: "Gadgets 𝄞", 2: "Emoji 😀 board"}
+	simDev.Languages = []usb.LangID{usb.LangIDEnglishUS, usb.LangIDSwedish}
+	simDev.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		if setup.Request == usb.ReqGetDescriptor && setup.Value == uint16(usb.DescriptorTypeString)<<8|3 {
+			return copy(data, raw), nil
+		}
+		return 0, syscall.EPIPE
+	}
+	return simDev
+}
+
+// countCapture counts the transfers of a device.
+type countCapture int
+
+func (c *countCapture) CaptureTransfer(dev *usb.Device, record *usb.CaptureRecord) {
+	*c++
+}
+
+func TestSurrogatePairs(t *testing.T) {
+	dev := openSim(t, stringDevice(t, nil))
+	manufacturer, err := dev.GetManufacturer()
+	if err != nil || manufacturer != "Gadgets 𝄞" {
+		t.Fatalf("manufacturer %q, %v", manufacturer, err)
+	}
+	product, err := dev.GetProduct()
+	if err != nil || product != "Emoji 😀 board" {
+		t.Fatalf("product %q, %v", product, err)
+	}
+	// Both characters are outside the basic multilingual plane and take a surrogate pair.
+	if desc := usb.NewStringDescriptor("😀"); desc.Length != 6 || desc.Data[1] != 0xD8 || desc.Data[3] != 0xDE {
+		t.Fatalf("unexpected encoding % x", desc.Data)
+	}
+}
+
+func TestStringOddLength(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		raw  []byte
+		str  string
+	}{
+		{"odd trailing byte", []byte{5, 3, 'O', 0, 'K'}, "O"},
+		{"unpaired surrogate", []byte{6, 3, 'A', 0, 0x3D, 0xD8}, "A�"},
+		{"surrogate pair cut by odd byte", []byte{7, 3, 'A', 0, 0x3D, 0xD8, 0x00}, "A�"},
+	} {
+		dev := openSim(t, stringDevice(t, test.raw))
+		str, err := dev.GetString(3)
+		if err != nil || str != test.str {
+			t.Fatalf("%s: got %q, %v", test.name, str, err)
+		}
+	}
+}
+
+func TestEmptyLanguages(t *testing.T) {
+	simDev := stringDevice(t, nil)
+	simDev.Languages = []usb.LangID{}
+	dev := openSim(t, simDev)
+	languages, err := dev.GetLanguages()
+	if err != nil || languages == nil || len(languages) != 0 {
+		t.Fatalf("languages %v, %v", languages, err)
+	}
+	if _, err := dev.GetString(1); err == nil {
+		t.Fatal("expected an error for a device without languages")
+	}
+	if str, err := dev.GetString(0); err != nil || str != "" {
+		t.Fatalf("index zero returned %q, %v", str, err)
+	}
+}
+
+func TestStringCache(t *testing.T) {
+	dev := openSim(t, stringDevice(t, nil))
+	var transfers countCapture
+	dev.SetCapture(&transfers)
+
+	languages, err := dev.GetLanguages()
+	if err != nil || len(languages) != 2 || languages[0] != usb.LangIDEnglishUS || languages[1] != usb.LangIDSwedish {
+		t.Fatalf("languages %v, %v", languages, err)
+	}
+	swedish, err := dev.GetStringDescriptor(1, uint16(usb.LangIDSwedish))
+	if err != nil || swedish != "Gadgets 𝄞" {
+		t.Fatalf("swedish %q, %v", swedish, err)
+	}
+	if transfers != 2 {
+		t.Fatalf("expected 2 transfers, got %d", transfers)
+	}
+
+	// The cached values are returned without another transfer, and changing the returned
+	// languages does not change the cache.
+	languages[0] = usb.LangIDJapanese
+	again, err := dev.GetLanguages()
+	if err != nil || len(again) != 2 || again[0] != usb.LangIDEnglishUS {
+		t.Fatalf("cached languages %v, %v", again, err)
+	}
+	if cached, err := dev.GetStringDescriptor(1, uint16(usb.LangIDSwedish)); err != nil || cached != swedish {
+		t.Fatalf("cached string %q, %v", cached, err)
+	}
+	if transfers != 2 {
+		t.Fatalf("expected no transfers from the cache, got %d", transfers-2)
+	}
+
+	dev.ClearStringCache()
+	if _, err := dev.GetLanguages(); err != nil || transfers != 3 {
+		t.Fatalf("expected a transfer after clearing the cache, got %d, %v", transfers, err)
+	}
+}
+
+func TestLangID(t *testing.T) {
+	for _, test := range []struct {
+		id   usb.LangID
+		name string
+	}{
+		{usb.LangIDEnglishUS, "English (United States)"},
+		{usb.LangIDEnglishUK, "English (United Kingdom)"},
+		{usb.LangID(0x7C09), "English (United States) (0x7C09)"},
+		{usb.LangID(0x00FE), "Unknown(0x00FE)"},
+	} {
+		if name := test.id.String(); name != test.name {
+			t.Fatalf("%#x: got %q, want %q", uint16(test.id), name, test.name)
+		}
+	}
+	if id := usb.LangID(0x0809); id.Primary() != 0x09 || id.Sub() != 2 {
+		t.Fatalf("primary %#x, sub %d", uint16(id.Primary()), id.Sub())
+	}
+}
@@ -1,6 +1,7 @@
 package usb
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -8,9 +9,8 @@ import (
 	"strings"
 )
 
-const (
-	sysfsDeviceDir = "/sys/bus/usb/devices"
-)
+// sysfsDeviceDir is a variable so tests can enumerate a directory of their own.
+var sysfsDeviceDir = "/sys/bus/usb/devices"
 
 func formatAttrFileName(devName, attrName string) string {
 	return fmt.Sprintf("%s/%s/%s", sysfsDeviceDir, devName, attrName)
@@ -63,9 +63,15 @@ func getDeviceAddress(devName string) (int, int, error) {
 	return int(busNum), int(devNum), nil
 }
 
+// EnumerateDevices returns the devices in sysfs followed by the devices added with AddVirtualDevice.
+// If sysfs has no USB devices directory, such as in a container, only the virtual devices are returned,
+// and the error is returned if there are none. Any other error reading sysfs is returned.
 func EnumerateDevices() ([]*Device, error) {
 	dirs, err := ioutil.ReadDir(sysfsDeviceDir)
 	if err != nil {
+		if virtual := virtualDevices(); len(virtual) > 0 && os.IsNotExist(err) {
+			return virtual, nil
+		}
 		return nil, err
 	}
 
@@ -85,19 +91,40 @@ func EnumerateDevices() ([]*Device, error) {
 			Name:         name,
 			BusNumber:    busNum,
 			DeviceNumber: devNum,
-			fd:           -1,
 		}
 		res = append(res, device)
 	}
-	return res, nil
+	return append(res, virtualDevices()...), nil
+}
+
+// readSysfsFile reads a sysfs attribute of the device, virtual devices provide their own attributes.
+func (d *Device) readSysfsFile(attrName string) ([]byte, error) {
+	if d.sysfs != nil {
+		return d.sysfs(attrName)
+	}
+	return ioutil.ReadFile(formatAttrFileName(d.Name, attrName))
 }
 
 func (d *Device) ReadSysfsAttrInt(attrName string, base, bitSize int) (int64, error) {
-	return readSysfsAttrInt(d.Name, attrName, base, bitSize)
+	if d.sysfs == nil {
+		return readSysfsAttrInt(d.Name, attrName, base, bitSize)
+	}
+	value, err := d.ReadSysfsString(attrName)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, base, bitSize)
 }
 
 func (d *Device) ReadSysfsString(attrName string) (string, error) {
-	return readSysfsAttrString(d.Name, attrName)
+	if d.sysfs == nil {
+		return readSysfsAttrString(d.Name, attrName)
+	}
+	data, err := d.sysfs(attrName)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(string(data), "\n"), nil
 }
 
 func FindDevices(filter func(device *Device) bool) ([]*Device, error) {
@@ -136,10 +163,9 @@ func (d *Device) GetSysfsDescriptors() (*SysfsDescriptors, error) {
 	if x, err := d.ReadSysfsString("product"); err == nil {
 		res.Product = x
 	}
-	if file, err := openSysfsAttr(d.Name, "descriptors"); err == nil {
+	if data, err := d.readSysfsFile("descriptors"); err == nil {
 		var lastInterfaceDescriptor *InterfaceDescriptor
-		defer file.Close()
-		err := ReadDescriptors(file, func(d Descriptor) {
+		err := ReadDescriptors(bytes.NewReader(data), func(d Descriptor) {
 			switch x := d.(type) {
 			case *DeviceDescriptor:
 				res.DeviceDescriptor = x
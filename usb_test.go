@@ -1,7 +1,63 @@
 package usb
 
-import "testing"
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
 
 func TestEnumerate(t *testing.T) {
 	EnumerateDevices()
 }
+
+func TestEnumerateVirtual(t *testing.T) {
+	dir := t.TempDir()
+	defer func(path string) { sysfsDeviceDir = path }(sysfsDeviceDir)
+	virtual := NewVirtualDevice("virtual", 9, 1, func() (Backend, error) { return &requestBackend{}, nil }, nil)
+	if err := AddVirtualDevice(virtual); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveVirtualDevice(virtual)
+
+	// Without a USB devices directory only the virtual devices are enumerated.
+	sysfsDeviceDir = filepath.Join(dir, "missing")
+	devices, err := EnumerateDevices()
+	if err != nil || len(devices) != 1 || devices[0] != virtual {
+		t.Fatalf("devices = %v, %v", devices, err)
+	}
+
+	// Other errors are not hidden by the virtual devices.
+	sysfsDeviceDir = filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(sysfsDeviceDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if devices, err := EnumerateDevices(); !errors.Is(err, syscall.ENOTDIR) {
+		t.Fatalf("expected ENOTDIR, got %v, %v", devices, err)
+	}
+
+	// Devices in sysfs come first, root hub and interface directories are skipped.
+	sysfsDeviceDir = filepath.Join(dir, "devices")
+	for _, name := range []string{"usb1", "1-1", "1-1:1.0"} {
+		if err := os.MkdirAll(filepath.Join(sysfsDeviceDir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for attr, value := range map[string]string{"busnum": "1\n", "devnum": "4\n"} {
+		if err := ioutil.WriteFile(filepath.Join(sysfsDeviceDir, "1-1", attr), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	devices, err = EnumerateDevices()
+	if err != nil || len(devices) != 2 || devices[0].Name != "1-1" || devices[0].BusNumber != 1 || devices[0].DeviceNumber != 4 || devices[1] != virtual {
+		t.Fatalf("devices = %v, %v", devices, err)
+	}
+
+	RemoveVirtualDevice(virtual)
+	sysfsDeviceDir = filepath.Join(dir, "missing")
+	if _, err := EnumerateDevices(); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error without virtual devices, got %v", err)
+	}
+}
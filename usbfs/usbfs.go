@@ -68,11 +68,11 @@ func GetDriver(fd int, iface uint32) (string, error) {
 }
 
 func ClaimInterface(fd int, iface uint32) error {
-	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_claiminterface, uintptr(iface))
+	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_claiminterface, uintptr(unsafe.Pointer(&iface)))
 }
 
 func ReleaseInterface(fd int, iface uint32) error {
-	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_releaseinterface, uintptr(iface))
+	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_releaseinterface, uintptr(unsafe.Pointer(&iface)))
 }
 
 func GetConnectInfo(fd int) (uint8, error) {
@@ -91,7 +91,7 @@ func DriverIOCTL(fd int, iface uint32, request, data uintptr) error {
 		IoctlCode: int32(request),
 		Data:      data,
 	}
-	return ioctl.Ioctl(uintptr(fd), request, uintptr(unsafe.Pointer(req)))
+	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_ioctl, uintptr(unsafe.Pointer(req)))
 }
 
 func ResetDevice(fd int) error {
@@ -106,8 +106,16 @@ func GetCapabilities(fd int) (Capability, error) {
 	return res, nil
 }
 
+// SetConfiguration selects a configuration, -1 unconfigures the device.
+// Kernel drivers bound to interfaces of the current configuration are unbound first.
 func SetConfiguration(fd, config int) error {
-	return nil
+	value := int32(config)
+	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_setconfiguration, uintptr(unsafe.Pointer(&value)))
+}
+
+// ClearHalt clears the halt of an endpoint and resets its data toggle.
+func ClearHalt(fd int, endpoint uint32) error {
+	return ioctl.Ioctl(uintptr(fd), ctl_usbdevfs_clear_halt, uintptr(unsafe.Pointer(&endpoint)))
 }
 
 func OpenDevice(busNumber, deviceNumber int) (int, error) {
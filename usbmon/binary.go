@@ -0,0 +1,59 @@
+package usbmon
+
+import (
+	"fmt"
+	ioctl "github.com/daedaluz/goioctl"
+	"github.com/daedaluz/gousb/pcap"
+	"syscall"
+	"unsafe"
+)
+
+// monBinGet is struct mon_bin_get from drivers/usb/mon/mon_bin.c.
+type monBinGet struct {
+	hdr   uintptr
+	data  uintptr
+	alloc uintptr
+}
+
+var ctlMonIOCXGetX = ioctl.IOW(0x92, 10, unsafe.Sizeof(monBinGet{}))
+
+// BinaryReader reads the binary interface of usbmon, the same interface libpcap uses.
+type BinaryReader struct {
+	fd     int
+	buffer []byte
+}
+
+// OpenBinary opens /dev/usbmonN of a bus, 0 for all buses.
+func OpenBinary(bus int) (*BinaryReader, error) {
+	return openBinaryPath(fmt.Sprintf(binaryDevicePath, bus))
+}
+
+func openBinaryPath(path string) (*BinaryReader, error) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &BinaryReader{fd: fd, buffer: make([]byte, pcap.HeaderSize+pcap.SnapLen)}, nil
+}
+
+// ReadPacket blocks until the next event.
+// The data area of the kernel holds the isochronous descriptors followed by the data, as in captures.
+func (r *BinaryReader) ReadPacket() (*pcap.Packet, error) {
+	get := &monBinGet{
+		hdr:   uintptr(unsafe.Pointer(&r.buffer[0])),
+		data:  uintptr(unsafe.Pointer(&r.buffer[pcap.HeaderSize])),
+		alloc: uintptr(len(r.buffer) - pcap.HeaderSize),
+	}
+	if err := ioctl.Ioctl(uintptr(r.fd), ctlMonIOCXGetX, uintptr(unsafe.Pointer(get))); err != nil {
+		return nil, err
+	}
+	p := &pcap.Packet{}
+	if err := p.UnmarshalBinary(r.buffer); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *BinaryReader) Close() error {
+	return syscall.Close(r.fd)
+}
@@ -0,0 +1,214 @@
+package usbmon
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/pcap"
+	"io"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TextReader reads the text interface of usbmon, as described in Documentation/usb/usbmon.rst:
+//
+//	ffff8800 3575914555 S Ci:1:001:0 s 80 06 0100 0000 0012 18 <
+//	ffff8800 3575914560 C Ci:1:001:0 0 18 = 12010002 09000040 6b1d0200 06020302 0101
+//
+// Both the "u" format with bus numbers and the older "t" format without them are accepted.
+// The text interface truncates data, Length holds the transferred length and Data what was printed.
+type TextReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewTextReader reads events in the usbmon text format from r.
+func NewTextReader(r io.Reader) *TextReader {
+	return &TextReader{scanner: bufio.NewScanner(r)}
+}
+
+// ReadPacket returns the next event, empty lines are skipped.
+func (r *TextReader) ReadPacket() (*pcap.Packet, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		p, err := ParseTextLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return p, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+var textTransferTypes = map[byte]usb.TransferType{
+	'C': usb.TransferTypeControl,
+	'Z': usb.TransferTypeIsochronous,
+	'I': usb.TransferTypeInterrupt,
+	'B': usb.TransferTypeBulk,
+}
+
+// ParseTextLine parses one event of the usbmon text format.
+func ParseTextLine(line string) (*pcap.Packet, error) {
+	words := strings.Fields(line)
+	if len(words) < 5 {
+		return nil, fmt.Errorf("too few words in %q", line)
+	}
+	next := func() string {
+		if len(words) == 0 {
+			return ""
+		}
+		word := words[0]
+		words = words[1:]
+		return word
+	}
+	p := &pcap.Packet{}
+	var err error
+	if p.ID, err = strconv.ParseUint(next(), 16, 64); err != nil {
+		return nil, fmt.Errorf("invalid URB tag: %w", err)
+	}
+	micros, err := strconv.ParseUint(next(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	p.Timestamp = time.Unix(int64(micros/1000000), int64(micros%1000000)*1000)
+	switch event := next(); event {
+	case "S", "C", "E":
+		p.Event = pcap.EventType(event[0])
+	default:
+		return nil, fmt.Errorf("invalid event type %q", event)
+	}
+	if err := parseAddress(p, next()); err != nil {
+		return nil, err
+	}
+	if p.In() {
+		p.TransferFlags = pcap.URBDirIn
+	}
+
+	if words[0] == "s" {
+		next()
+		if len(words) < 5 {
+			return nil, fmt.Errorf("truncated setup packet")
+		}
+		setup := make([]byte, 0, 8)
+		for i, digits := range []int{2, 2, 4, 4, 4} {
+			value, err := strconv.ParseUint(next(), 16, digits*4)
+			if err != nil {
+				return nil, fmt.Errorf("invalid setup packet: %w", err)
+			}
+			if i < 2 {
+				setup = append(setup, uint8(value))
+			} else {
+				setup = append(setup, uint8(value), uint8(value>>8))
+			}
+		}
+		p.HasSetup = true
+		p.Setup.UnmarshalBinary(setup)
+		p.Status = -int32(syscall.EINPROGRESS)
+	} else if err := parseStatus(p, next()); err != nil {
+		return nil, err
+	}
+
+	// the "u" format follows the status of isochronous transfers with the descriptor count and descriptors
+	if p.TransferType == usb.TransferTypeIsochronous && len(words) > 1 && strings.Contains(words[1], ":") {
+		count, err := strconv.Atoi(next())
+		if err != nil {
+			return nil, fmt.Errorf("invalid isochronous descriptor count: %w", err)
+		}
+		for len(words) > 0 && strings.Contains(words[0], ":") && len(p.ISODescriptors) < count {
+			var desc pcap.ISODescriptor
+			if _, err := fmt.Sscanf(next(), "%d:%d:%d", &desc.Status, &desc.Offset, &desc.Length); err != nil {
+				return nil, fmt.Errorf("invalid isochronous descriptor: %w", err)
+			}
+			p.ISODescriptors = append(p.ISODescriptors, desc)
+		}
+	}
+
+	if len(words) > 0 {
+		length, err := strconv.ParseUint(next(), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data length: %w", err)
+		}
+		p.Length = uint32(length)
+	}
+	p.DataFlag = pcap.DataNotPresent
+	if tag := next(); tag == "=" {
+		p.DataFlag = pcap.DataPresent
+		data, err := hex.DecodeString(strings.Join(words, ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+		p.Data = data
+	} else if tag != "" {
+		p.DataFlag = tag[0]
+	}
+	return p, nil
+}
+
+// parseAddress parses "Ci:1:001:0", or "Ci:001:0" without a bus number.
+func parseAddress(p *pcap.Packet, word string) error {
+	fields := strings.Split(word, ":")
+	if len(fields) < 3 || len(fields[0]) != 2 {
+		return fmt.Errorf("invalid address %q", word)
+	}
+	typ, exist := textTransferTypes[fields[0][0]]
+	if !exist {
+		return fmt.Errorf("invalid transfer type in %q", word)
+	}
+	p.TransferType = typ
+	numbers := make([]uint64, len(fields)-1)
+	for i, field := range fields[1:] {
+		value, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", word, err)
+		}
+		numbers[i] = value
+	}
+	if len(numbers) == 3 {
+		p.BusNumber = uint16(numbers[0])
+		numbers = numbers[1:]
+	}
+	p.DeviceNumber = uint8(numbers[0])
+	p.Endpoint = uint8(numbers[1]) & 0x7F
+	switch fields[0][1] {
+	case 'i':
+		p.Endpoint |= 0x80
+	case 'o':
+	default:
+		return fmt.Errorf("invalid direction in %q", word)
+	}
+	return nil
+}
+
+// parseStatus parses "status[:interval[:start_frame[:error_count]]]".
+func parseStatus(p *pcap.Packet, word string) error {
+	fields := strings.Split(word, ":")
+	values := make([]int32, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseInt(field, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid status %q: %w", word, err)
+		}
+		values[i] = int32(value)
+	}
+	p.Status = values[0]
+	if len(values) > 1 {
+		p.Interval = values[1]
+	}
+	if len(values) > 2 {
+		p.StartFrame = values[2]
+	}
+	if len(values) > 3 {
+		p.ErrorCount = values[3]
+	}
+	return nil
+}
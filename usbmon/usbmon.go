@@ -0,0 +1,124 @@
+// Package usbmon reads captures of the Linux usbmon facility: the binary /dev/usbmonN devices,
+// the text interface in /sys/kernel/debug/usb/usbmon and pcap or pcapng files of the
+// LINKTYPE_USB_LINUX_MMAPPED link type. Every format is decoded to pcap.Packet events.
+//
+// Reading the live interfaces requires root and the usbmon module to be loaded.
+package usbmon
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/daedaluz/gousb/pcap"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	binaryDevicePath = "/dev/usbmon%d"
+	textPath         = "/sys/kernel/debug/usb/usbmon/%du"
+)
+
+// Reader returns usbmon events one by one, io.EOF at the end of a capture.
+type Reader interface {
+	ReadPacket() (*pcap.Packet, error)
+}
+
+// Open opens a capture file or a live usbmon interface. A path starting with /dev/usbmon is read
+// with the binary interface, files starting with a pcap or pcapng magic number as captures
+// and anything else as the text format.
+// Closing the returned io.Closer closes the underlying file.
+func Open(path string) (Reader, io.Closer, error) {
+	if strings.HasPrefix(path, "/dev/usbmon") {
+		r, err := openBinaryPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r, r, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(4)
+	if err != nil && err != io.EOF {
+		file.Close()
+		return nil, nil, err
+	}
+	if isPcap(magic) {
+		r, err := pcap.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return r, file, nil
+	}
+	return NewTextReader(buffered), file, nil
+}
+
+// OpenText opens the text interface of a bus, 0 for all buses.
+func OpenText(bus int) (*TextReader, io.Closer, error) {
+	file, err := os.Open(fmt.Sprintf(textPath, bus))
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewTextReader(file), file, nil
+}
+
+func isPcap(magic []byte) bool {
+	if len(magic) < 4 {
+		return false
+	}
+	for _, m := range [][]byte{
+		{0xD4, 0xC3, 0xB2, 0xA1}, {0xA1, 0xB2, 0xC3, 0xD4},
+		{0x4D, 0x3C, 0xB2, 0xA1}, {0xA1, 0xB2, 0x3C, 0x4D},
+		{0x0A, 0x0D, 0x0D, 0x0A},
+	} {
+		if string(magic[:4]) == string(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the events of r accepted by accept.
+func Filter(r Reader, accept func(p *pcap.Packet) bool) Reader {
+	return &filter{r: r, accept: accept}
+}
+
+// FilterDevice returns the events of one device.
+func FilterDevice(r Reader, bus, devnum int) Reader {
+	return Filter(r, func(p *pcap.Packet) bool {
+		return int(p.BusNumber) == bus && int(p.DeviceNumber) == devnum
+	})
+}
+
+type filter struct {
+	r      Reader
+	accept func(p *pcap.Packet) bool
+}
+
+func (f *filter) ReadPacket() (*pcap.Packet, error) {
+	for {
+		p, err := f.r.ReadPacket()
+		if err != nil || f.accept(p) {
+			return p, err
+		}
+	}
+}
+
+// ReadAll reads events until io.EOF.
+func ReadAll(r Reader) ([]*pcap.Packet, error) {
+	var res []*pcap.Packet
+	for {
+		p, err := r.ReadPacket()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, p)
+	}
+}
@@ -0,0 +1,187 @@
+package usbmon
+
+import (
+	"bytes"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/pcap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestText(t *testing.T) {
+	r, closer, err := Open("testdata/sample.1u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	packets, err := ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 6 {
+		t.Fatalf("expected 6 events, got %d", len(packets))
+	}
+	setup := packets[0]
+	if setup.ID != 0xd5ea89a0 || setup.Event != pcap.EventSubmit || !setup.HasSetup ||
+		setup.Setup != (usb.SetupPacket{RequestType: 0xa3, Request: 0, Value: 0, Index: 3, Length: 4}) ||
+		setup.BusNumber != 1 || setup.DeviceNumber != 1 || setup.Endpoint != 0x80 || setup.DataFlag != pcap.DataInSubmit {
+		t.Fatalf("unexpected setup event %+v", setup)
+	}
+	if status := packets[1]; status.Status != 0 || !bytes.Equal(status.Data, []byte{1, 5, 0, 0}) {
+		t.Fatalf("unexpected completion %+v", status)
+	}
+	if bulk := packets[2]; bulk.TransferType != usb.TransferTypeBulk || bulk.Endpoint != 0x02 || bulk.Length != 31 || len(bulk.Data) != 31 {
+		t.Fatalf("unexpected bulk event %+v", bulk)
+	}
+	if intr := packets[4]; intr.TransferType != usb.TransferTypeInterrupt || intr.Interval != 8 || intr.Endpoint != 0x81 {
+		t.Fatalf("unexpected interrupt event %+v", intr)
+	}
+	iso := packets[5]
+	if iso.TransferType != usb.TransferTypeIsochronous || iso.StartFrame != 348 || len(iso.ISODescriptors) != 2 ||
+		iso.ISODescriptors[1] != (pcap.ISODescriptor{Status: -18, Offset: 192, Length: 192}) || iso.Length != 384 {
+		t.Fatalf("unexpected isochronous event %+v", iso)
+	}
+}
+
+func TestPcap(t *testing.T) {
+	r, closer, err := Open("testdata/loopback.pcapng")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	packets, err := ReadAll(FilterDevice(r, 1, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 8 {
+		t.Fatalf("expected 8 events of device 6, got %d", len(packets))
+	}
+	for _, p := range packets {
+		if p.DeviceNumber != 6 {
+			t.Fatalf("unfiltered event of device %d", p.DeviceNumber)
+		}
+	}
+	if out := packets[4]; out.Event != pcap.EventSubmit || out.Endpoint != 0x02 || string(out.Data) != "USBC" {
+		t.Fatalf("unexpected bulk submit %+v", out)
+	}
+	if in := packets[7]; in.Event != pcap.EventComplete || in.Endpoint != 0x81 || string(in.Data) != "USBC" || in.Length != 4 {
+		t.Fatalf("unexpected bulk completion %+v", in)
+	}
+}
+
+func TestClassicPcap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.pcap")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := pcap.NewWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for devnum := uint8(1); devnum <= 3; devnum++ {
+		if err := w.WritePacket(&pcap.Packet{ID: uint64(devnum), Event: pcap.EventSubmit, TransferType: usb.TransferTypeBulk,
+			Endpoint: 0x02, DeviceNumber: devnum, BusNumber: 1, Length: 4, Data: []byte("USBC")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file.Close()
+
+	r, closer, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	if _, ok := r.(*pcap.Reader); !ok {
+		t.Fatalf("expected a pcap.Reader, got %T", r)
+	}
+	packets, err := ReadAll(FilterDevice(r, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 1 || packets[0].ID != 2 || string(packets[0].Data) != "USBC" {
+		t.Fatalf("unexpected packets %+v", packets)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	// MON_IOCX_GETX is _IOW(0x92, 10, struct mon_bin_get), three pointers.
+	if size := unsafe.Sizeof(monBinGet{}); size != 3*unsafe.Sizeof(uintptr(0)) ||
+		ctlMonIOCXGetX != 1<<30|size<<16|0x92<<8|10 {
+		t.Fatalf("unexpected MON_IOCX_GETX 0x%x", ctlMonIOCXGetX)
+	}
+	if _, _, err := Open("/dev/usbmon-missing"); err == nil {
+		t.Fatal("expected an error for a missing usbmon device")
+	}
+
+	// A file that is not a usbmon device rejects the ioctl.
+	path := filepath.Join(t.TempDir(), "usbmon")
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := openBinaryPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadPacket(); err != syscall.ENOTTY {
+		t.Fatalf("expected ENOTTY, got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestBinaryLive reads the GET_DESCRIPTOR request of a real device from usbmon.
+// It needs root, the usbmon module and a USB device, and is skipped otherwise.
+func TestBinaryLive(t *testing.T) {
+	devices, err := usb.EnumerateDevices()
+	if err != nil {
+		t.Skip(err)
+	}
+	var dev *usb.Device
+	for _, candidate := range devices {
+		if !candidate.IsVirtual() && candidate.Open() == nil {
+			dev = candidate
+			break
+		}
+	}
+	if dev == nil {
+		t.Skip("no device can be opened")
+	}
+	defer dev.Close()
+	r, closer, err := Open(fmt.Sprintf(binaryDevicePath, dev.BusNumber))
+	if err != nil {
+		t.Skip(err)
+	}
+	defer closer.Close()
+
+	errors := make(chan error, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, err := dev.GetDeviceDescriptor()
+		errors <- err
+	}()
+	var submit *pcap.Packet
+	for submit == nil {
+		p, err := r.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(p.DeviceNumber) == dev.DeviceNumber && p.Event == pcap.EventSubmit && p.HasSetup &&
+			p.Setup.Request == usb.ReqGetDescriptor && p.Setup.Value == 0x0100 {
+			submit = p
+		}
+	}
+	if err := <-errors; err != nil {
+		t.Fatal(err)
+	}
+	if int(submit.BusNumber) != dev.BusNumber || submit.Endpoint != 0x80 || submit.TransferType != usb.TransferTypeControl {
+		t.Fatalf("unexpected submission %+v", submit)
+	}
+}
@@ -0,0 +1,158 @@
+// Package webusb decodes the WebUSB platform capability and retrieves URL descriptors.
+//
+// Documentation: https://wicg.github.io/webusb/#webusb-platform-capability-descriptor
+package webusb
+
+import (
+	"encoding/binary"
+	"fmt"
+	usb "github.com/daedaluz/gousb"
+)
+
+// PlatformCapabilityUUID identifies the WebUSB platform capability, {3408B638-09A9-47A0-8BFD-A0768815B665}.
+var PlatformCapabilityUUID = [16]byte{
+	0x38, 0xB6, 0x08, 0x34, 0xA9, 0x09, 0xA0, 0x47,
+	0x8B, 0xFD, 0xA0, 0x76, 0x88, 0x15, 0xB6, 0x65,
+}
+
+// WebUSB requests, used as wIndex of the vendor request.
+const (
+	RequestGetURL = 0x02
+)
+
+// DescriptorTypeURL is the descriptor type of the URL descriptor.
+const DescriptorTypeURL = usb.DescriptorType(0x03)
+
+// URL scheme prefixes
+type Scheme uint8
+
+const (
+	SchemeHTTP  = Scheme(0)
+	SchemeHTTPS = Scheme(1)
+	SchemeNone  = Scheme(255)
+)
+
+// Prefix returns the prefix the scheme adds to the URL.
+func (s Scheme) Prefix() string {
+	switch s {
+	case SchemeHTTP:
+		return "http://"
+	case SchemeHTTPS:
+		return "https://"
+	}
+	return ""
+}
+
+func (s Scheme) String() string {
+	switch s {
+	case SchemeHTTP:
+		return "http"
+	case SchemeHTTPS:
+		return "https"
+	case SchemeNone:
+		return "none"
+	}
+	return fmt.Sprintf("Scheme(%d)", uint8(s))
+}
+
+// Capability is the decoded data of the WebUSB platform capability.
+type Capability struct {
+	// BcdVersion is the WebUSB version supported, 0x0100.
+	BcdVersion uint16
+	// VendorCode is used as bRequest of the WebUSB requests.
+	VendorCode uint8
+	// LandingPage is the URL descriptor index of the landing page, 0 if there is none.
+	LandingPage uint8
+}
+
+// URLDescriptor is the WebUSB URL descriptor.
+type URLDescriptor struct {
+	Scheme Scheme
+	URL    string
+}
+
+// String returns the URL including the scheme prefix.
+func (u *URLDescriptor) String() string {
+	return u.Scheme.Prefix() + u.URL
+}
+
+// FindPlatformCapability returns the WebUSB platform capability of the BOS, or nil if there is none.
+func FindPlatformCapability(bos *usb.BOS) *usb.CapPlatformDescriptor {
+	return bos.FindPlatformCapability(PlatformCapabilityUUID)
+}
+
+// ParsePlatformCapability decodes the capability data of a WebUSB platform capability.
+func ParsePlatformCapability(platform *usb.CapPlatformDescriptor) (*Capability, error) {
+	if platform.PlatformCapabilityUUID != PlatformCapabilityUUID {
+		return nil, fmt.Errorf("not a WebUSB platform capability: %s", platform.UUIDString())
+	}
+	data := platform.CapabilityData
+	if len(data) < 4 {
+		return nil, fmt.Errorf("WebUSB platform capability data too short: %d bytes", len(data))
+	}
+	return &Capability{
+		BcdVersion:  binary.LittleEndian.Uint16(data),
+		VendorCode:  data[2],
+		LandingPage: data[3],
+	}, nil
+}
+
+// GetCapability retrieves the BOS of an open device and decodes its WebUSB platform capability.
+// The returned capability is nil if the device does not support WebUSB.
+func GetCapability(dev *usb.Device) (*Capability, error) {
+	bos, err := dev.GetBOSDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	platform := FindPlatformCapability(bos)
+	if platform == nil {
+		return nil, nil
+	}
+	return ParsePlatformCapability(platform)
+}
+
+// GetURL retrieves the URL descriptor at index.
+func GetURL(dev *usb.Device, capability *Capability, index uint8) (*URLDescriptor, error) {
+	data := make([]byte, 255)
+	n, err := dev.Ctrl(usb.RequestDirectionIn|usb.RequestTypeVendor|usb.RequestRecipientDevice,
+		capability.VendorCode, uint16(index), RequestGetURL, data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseURLDescriptor(data[:n])
+}
+
+// GetLandingPage retrieves the landing page URL, it returns nil if the device has no landing page.
+func GetLandingPage(dev *usb.Device, capability *Capability) (*URLDescriptor, error) {
+	if capability.LandingPage == 0 {
+		return nil, nil
+	}
+	return GetURL(dev, capability, capability.LandingPage)
+}
+
+// ParseURLDescriptor parses a complete URL descriptor.
+func ParseURLDescriptor(data []byte) (*URLDescriptor, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("URL descriptor too short: %d bytes", len(data))
+	}
+	if usb.DescriptorType(data[1]) != DescriptorTypeURL {
+		return nil, fmt.Errorf("expected URL descriptor, got type 0x%.2X", data[1])
+	}
+	length := int(data[0])
+	if length < 3 || length > len(data) {
+		return nil, fmt.Errorf("invalid URL descriptor length %d, got %d bytes", length, len(data))
+	}
+	return &URLDescriptor{
+		Scheme: Scheme(data[2]),
+		URL:    string(data[3:length]),
+	}, nil
+}
+
+// MarshalBinary encodes the URL descriptor.
+func (u *URLDescriptor) MarshalBinary() ([]byte, error) {
+	if len(u.URL) > 252 {
+		return nil, fmt.Errorf("URL too long: %d bytes", len(u.URL))
+	}
+	data := []byte{byte(3 + len(u.URL)), byte(DescriptorTypeURL), byte(u.Scheme)}
+	return append(data, u.URL...), nil
+}
@@ -0,0 +1,127 @@
+package webusb
+
+import (
+	"bytes"
+	usb "github.com/daedaluz/gousb"
+	"github.com/daedaluz/gousb/sim"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// device simulates a device with the capabilities in its BOS, answering GET_URL with vendor code 0x21
+// for URL descriptor 1, https://example.com/app, and 2 without scheme prefix.
+func device(t *testing.T, capabilities ...usb.Descriptor) *usb.Device {
+	res, err := sim.Build(&usb.DeviceDescriptor{BcdUSB: 0x0210, BMaxPacketSize0: 64, IDVendor: 0x1209, IDProduct: 0x0001, BNumConfigurations: 1},
+		usb.NewConfigBuilder(&usb.ConfigurationDescriptor{BConfigurationValue: 1, BmAttributes: 0x80}).
+			Interface(&usb.InterfaceDescriptor{BInterfaceClass: usb.ClassCodeVendorSpecific}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities != nil {
+		res.Descriptors.BOS = &usb.BOS{Capabilities: capabilities}
+	}
+	urls := map[uint16]*URLDescriptor{
+		1: {Scheme: SchemeHTTPS, URL: "example.com/app"},
+		2: {Scheme: SchemeNone, URL: "localhost:8080"},
+	}
+	res.Control = func(setup usb.SetupPacket, data []byte) (int, error) {
+		url, exist := urls[setup.Value]
+		if setup.RequestType != 0xC0 || setup.Request != 0x21 || setup.Index != RequestGetURL || !exist {
+			return 0, syscall.EPIPE
+		}
+		encoded, err := url.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		return copy(data, encoded), nil
+	}
+	dev := res.USB()
+	if err := dev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dev.Close() })
+	return dev
+}
+
+func TestDevice(t *testing.T) {
+	dev := device(t,
+		&usb.CapUSB20ExtensionDescriptor{BMAttributes: 0x02},
+		&usb.CapPlatformDescriptor{PlatformCapabilityUUID: PlatformCapabilityUUID, CapabilityData: []byte{0x00, 0x01, 0x21, 0x01}})
+	capability, err := GetCapability(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capability == nil || *capability != (Capability{BcdVersion: 0x0100, VendorCode: 0x21, LandingPage: 1}) {
+		t.Fatalf("unexpected capability %+v", capability)
+	}
+	landingPage, err := GetLandingPage(dev, capability)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if landingPage.Scheme != SchemeHTTPS || landingPage.String() != "https://example.com/app" {
+		t.Fatalf("unexpected landing page %+v", landingPage)
+	}
+	url, err := GetURL(dev, capability, 2)
+	if err != nil || url.Scheme != SchemeNone || url.String() != "localhost:8080" {
+		t.Fatalf("URL 2 = %+v, %v", url, err)
+	}
+	if _, err := GetURL(dev, capability, 3); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for a missing URL, got %v", err)
+	}
+	if url, err := GetLandingPage(dev, &Capability{BcdVersion: 0x0100, VendorCode: 0x21}); url != nil || err != nil {
+		t.Fatalf("expected no landing page, got %+v, %v", url, err)
+	}
+}
+
+func TestNoCapability(t *testing.T) {
+	other := &usb.CapPlatformDescriptor{PlatformCapabilityUUID: [16]byte{0xDF, 0x60, 0xDD, 0xD8}, CapabilityData: []byte{0, 0, 0, 0}}
+	if capability, err := GetCapability(device(t, other)); capability != nil || err != nil {
+		t.Fatalf("expected no capability, got %+v, %v", capability, err)
+	}
+	if _, err := GetCapability(device(t)); err != syscall.EPIPE {
+		t.Fatalf("expected a stall for a device without BOS, got %v", err)
+	}
+	if _, err := ParsePlatformCapability(other); err == nil {
+		t.Fatal("expected an error for another platform capability")
+	}
+	short := &usb.CapPlatformDescriptor{PlatformCapabilityUUID: PlatformCapabilityUUID, CapabilityData: []byte{0x00, 0x01, 0x21}}
+	if _, err := ParsePlatformCapability(short); err == nil {
+		t.Fatal("expected an error for short capability data")
+	}
+}
+
+func TestParseURLDescriptor(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected *URLDescriptor
+	}{
+		{[]byte{0x0E, 0x03, 0x01, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm'}, &URLDescriptor{SchemeHTTPS, "example.com"}},
+		{[]byte{0x06, 0x03, 0x00, 'a', '.', 'b', 0xAA, 0xBB}, &URLDescriptor{SchemeHTTP, "a.b"}},
+		{[]byte{0x03, 0x03, 0xFF}, &URLDescriptor{SchemeNone, ""}},
+		{[]byte{0x03, 0x03}, nil},
+		{[]byte{0x06, 0x0F, 0x01, 'a', '.', 'b'}, nil},
+		{[]byte{0x07, 0x03, 0x01, 'a', '.', 'b'}, nil},
+		{[]byte{0x02, 0x03, 0x01, 'a'}, nil},
+	}
+	for _, test := range tests {
+		url, err := ParseURLDescriptor(test.data)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("% x: expected an error, got %+v", test.data, url)
+			}
+			continue
+		}
+		if err != nil || *url != *test.expected {
+			t.Errorf("% x = %+v, %v, expected %+v", test.data, url, err, test.expected)
+			continue
+		}
+		encoded, err := url.MarshalBinary()
+		if err != nil || !bytes.Equal(encoded, test.data[:test.data[0]]) {
+			t.Errorf("% x: round trip mismatch % x, %v", test.data, encoded, err)
+		}
+	}
+	if _, err := (&URLDescriptor{URL: strings.Repeat("a", 253)}).MarshalBinary(); err == nil {
+		t.Fatal("expected an error for a URL too long for a descriptor")
+	}
+}